@@ -78,6 +78,8 @@ func init() {
 	configCmd.Flags().IntVar(&conf.Config.DB.IdleInTxTimeout, "dbIdleInTxTimeout", 5000, "DB idle tx timeout")
 	configCmd.Flags().IntVar(&conf.Config.DB.MaxIdleConns, "dbMaxIdleConns", 5, "DB sets the maximum number of connections in the idle connection pool")
 	configCmd.Flags().IntVar(&conf.Config.DB.MaxOpenConns, "dbMaxOpenConns", 100, "sets the maximum number of open connections to the database")
+	configCmd.Flags().StringVar(&conf.Config.DB.Backend, "dbBackend", "postgres", "DB storage backend (postgres, sqlite)")
+	configCmd.Flags().StringVar(&conf.Config.DB.Path, "dbPath", "", "DB file path of the sqlite backend")
 	viper.BindPFlag("DB.Name", configCmd.Flags().Lookup("dbName"))
 	viper.BindPFlag("DB.Host", configCmd.Flags().Lookup("dbHost"))
 	viper.BindPFlag("DB.Port", configCmd.Flags().Lookup("dbPort"))
@@ -87,6 +89,8 @@ func init() {
 	viper.BindPFlag("DB.IdleInTxTimeout", configCmd.Flags().Lookup("dbIdleInTxTimeout"))
 	viper.BindPFlag("DB.MaxIdleConns", configCmd.Flags().Lookup("dbMaxIdleConns"))
 	viper.BindPFlag("DB.MaxOpenConns", configCmd.Flags().Lookup("dbMaxOpenConns"))
+	viper.BindPFlag("DB.Backend", configCmd.Flags().Lookup("dbBackend"))
+	viper.BindPFlag("DB.Path", configCmd.Flags().Lookup("dbPath"))
 
 	//Redis
 	configCmd.Flags().BoolVar(&conf.Config.Redis.Enable, "redisenable", false, "enable redis")
@@ -180,6 +184,36 @@ func init() {
 	viper.BindPFlag("NetworkID", configCmd.Flags().Lookup("networkID"))
 	viper.BindPFlag("OBSMode", configCmd.Flags().Lookup("obsMode"))
 
+	// TCP transport
+	configCmd.Flags().BoolVar(&conf.Config.TCPTransport.Secure, "tcpSecure", false, "Enable the authenticated encrypted sessions between nodes")
+	configCmd.Flags().BoolVar(&conf.Config.TCPTransport.Require, "tcpSecureRequire", false, "Reject the plain connections between nodes")
+	viper.BindPFlag("TCPTransport.Secure", configCmd.Flags().Lookup("tcpSecure"))
+	viper.BindPFlag("TCPTransport.Require", configCmd.Flags().Lookup("tcpSecureRequire"))
+
+	// Peers
+	configCmd.Flags().StringVar(&conf.Config.Peers.Announce, "peersAnnounce", "", "Public TCP address of the node which is gossiped to other nodes")
+	configCmd.Flags().IntVar(&conf.Config.Peers.CollectionPeers, "collectionPeers", 8, "Max count of the best peers which are asked for the max block")
+	viper.BindPFlag("Peers.Announce", configCmd.Flags().Lookup("peersAnnounce"))
+	viper.BindPFlag("Peers.CollectionPeers", configCmd.Flags().Lookup("collectionPeers"))
+
+	// Mempool
+	configCmd.Flags().IntVar(&conf.Config.Mempool.MaxCount, "mempoolMaxCount", 50000, "Max count of pending transactions")
+	configCmd.Flags().IntVar(&conf.Config.Mempool.MaxSize, "mempoolMaxSize", 128, "Max size of pending transactions in megabytes")
+	configCmd.Flags().IntVar(&conf.Config.Mempool.MaxAccountCount, "mempoolMaxAccountCount", 1000, "Max count of pending transactions of one account")
+	viper.BindPFlag("Mempool.MaxCount", configCmd.Flags().Lookup("mempoolMaxCount"))
+	viper.BindPFlag("Mempool.MaxSize", configCmd.Flags().Lookup("mempoolMaxSize"))
+	viper.BindPFlag("Mempool.MaxAccountCount", configCmd.Flags().Lookup("mempoolMaxAccountCount"))
+
+	// Keystore
+	configCmd.Flags().StringVar(&conf.Config.Keystore.PassphraseFile, "keystorePassFile", "", "Filepath of the passphrase of encrypted key files (default $"+consts.KeystorePassphraseEnv+")")
+	viper.BindPFlag("Keystore.PassphraseFile", configCmd.Flags().Lookup("keystorePassFile"))
+
+	// Signer
+	configCmd.Flags().StringVar(&conf.Config.Signer.Remote, "signerSocket", "", "Unix socket of the remote signer of node key (default node key file)")
+	configCmd.Flags().IntVar(&conf.Config.Signer.Timeout, "signerTimeout", 5000, "Timeout of request to the remote signer in ms")
+	viper.BindPFlag("Signer.Remote", configCmd.Flags().Lookup("signerSocket"))
+	viper.BindPFlag("Signer.Timeout", configCmd.Flags().Lookup("signerTimeout"))
+
 	// GFiles
 	configCmd.Flags().BoolVar(&conf.Config.GFiles.GFiles, "gfs", false, "Enable GFiles")
 	configCmd.Flags().StringVar(&conf.Config.GFiles.Host, "gFilesHost", "127.0.0.1:5001", "GFiles host")
@@ -199,6 +233,24 @@ func init() {
 	viper.BindPFlag("PoolPub.TotalCount", configCmd.Flags().Lookup("totalcount"))
 	viper.BindPFlag("PoolPub.RollBack", configCmd.Flags().Lookup("rollback"))
 	viper.BindPFlag("PoolPub.Path", configCmd.Flags().Lookup("poolpath"))
+	// Snapshot
+	configCmd.Flags().StringVar(&conf.Config.Snapshot.Path, "snapshot", "", "Filepath of the state snapshot which is imported at the first start")
+	configCmd.Flags().StringVar(&conf.Config.Snapshot.Hash, "snapshotHash", "", "Trusted state hash of the snapshot, it is required with snapshot")
+	viper.BindPFlag("Snapshot.Path", configCmd.Flags().Lookup("snapshot"))
+	viper.BindPFlag("Snapshot.Hash", configCmd.Flags().Lookup("snapshotHash"))
+	// Light node
+	configCmd.Flags().StringSliceVar(&conf.Config.Light.NodeKeys, "lightNodeKeys", []string{}, "Initial public keys of honor nodes which sign the blocks for the light node")
+	configCmd.Flags().Int64Var(&conf.Config.Light.CheckpointID, "lightCheckpointID", 0, "Id of the trusted block for the light node, it is required")
+	configCmd.Flags().StringVar(&conf.Config.Light.CheckpointHash, "lightCheckpointHash", "", "Hash of the trusted block for the light node")
+	viper.BindPFlag("Light.NodeKeys", configCmd.Flags().Lookup("lightNodeKeys"))
+	viper.BindPFlag("Light.CheckpointID", configCmd.Flags().Lookup("lightCheckpointID"))
+	viper.BindPFlag("Light.CheckpointHash", configCmd.Flags().Lookup("lightCheckpointHash"))
+	// Debug
+	configCmd.Flags().BoolVar(&conf.Config.Debug.TraceTx, "debugTraceTx", false, "Enable /debug/traceTx for the node owner")
+	viper.BindPFlag("Debug.TraceTx", configCmd.Flags().Lookup("debugTraceTx"))
+	// WebSocket
+	configCmd.Flags().StringSliceVar(&conf.Config.WebSocket.AllowedOrigins, "wsAllowedOrigins", []string{}, "Origins of the browser clients of WebSocket subscriptions (default same origin)")
+	viper.BindPFlag("WebSocket.AllowedOrigins", configCmd.Flags().Lookup("wsAllowedOrigins"))
 	// CryptoSettings
 	configCmd.Flags().StringVar(&conf.Config.CryptoSettings.Hasher, "hasher", "SHA256", "Hash Algorithm")
 	configCmd.Flags().StringVar(&conf.Config.CryptoSettings.Cryptoer, "cryptoer", "ECDSA", "Key and Sign Algorithm")
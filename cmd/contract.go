@@ -0,0 +1,230 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/lsp"
+	"github.com/IBAX-io/go-ibax/packages/script"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/smarttest"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	contractEcosystem int64
+	contractRun       string
+	contractHasher    string
+	contractCryptoer  string
+)
+
+// contractCmd is the parent of the commands which work with the source files of contracts
+var contractCmd = &cobra.Command{
+	Use:   "contract",
+	Short: "Work with the contract source files",
+}
+
+// contractCheckCmd compiles the source files of the directory without the database and lints them
+var contractCheckCmd = &cobra.Command{
+	Use:   "check <dir>",
+	Short: "Compile and lint the contract source files of the directory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out := os.Stdout
+		// the compiler prints and logs the errors, they are reported with the positions below
+		os.Stdout = os.Stderr
+		log.SetLevel(log.FatalLevel)
+
+		files, err := contractFiles(args[0])
+		if err != nil {
+			log.WithError(err).Fatal("reading contract sources")
+		}
+		vm := loadContractVM(contractEcosystem)
+		problems := checkContracts(vm, files, &script.OwnerInfo{StateID: uint32(contractEcosystem),
+			Version: script.LatestVersion})
+		for _, p := range problems {
+			fmt.Fprintln(out, p)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// contractTestCmd runs the tests of *_test.sim files on the throwaway chain
+var contractTestCmd = &cobra.Command{
+	Use:   "test <dir>",
+	Short: "Run the tests of the contract source files of the directory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out := os.Stdout
+		os.Stdout = os.Stderr
+		log.SetLevel(log.FatalLevel)
+
+		var filter *regexp.Regexp
+		if len(contractRun) > 0 {
+			var err error
+			if filter, err = regexp.Compile(contractRun); err != nil {
+				log.WithError(err).Fatal("compiling run pattern")
+			}
+		}
+		crypto.InitHash(contractHasher)
+		crypto.InitCurve(contractCryptoer)
+
+		start := time.Now()
+		results, err := smarttest.RunDir(args[0], filter)
+		if err != nil {
+			log.WithError(err).Fatal("running contract tests")
+		}
+		failed := 0
+		for _, r := range results {
+			if r.Error == nil {
+				fmt.Fprintf(out, "--- PASS: %s (%.2fs)\n", r.Name, r.Duration.Seconds())
+				continue
+			}
+			failed++
+			fmt.Fprintf(out, "--- FAIL: %s (%.2fs)\n    %s: %v\n", r.Name, r.Duration.Seconds(), r.File, r.Error)
+		}
+		if failed > 0 {
+			fmt.Fprintf(out, "FAIL\t%s\t%.3fs\n", args[0], time.Since(start).Seconds())
+			os.Exit(1)
+		}
+		fmt.Fprintf(out, "ok\t%s\t%.3fs\n", args[0], time.Since(start).Seconds())
+	},
+}
+
+func init() {
+	contractCheckCmd.Flags().Int64Var(&contractEcosystem, "ecosystem", 1, "Ecosystem of contracts")
+	contractTestCmd.Flags().StringVar(&contractRun, "run", "", "Run only the tests matching the regular expression")
+	contractTestCmd.Flags().StringVar(&contractHasher, "hasher", "SHA256", "Hash Algorithm")
+	contractTestCmd.Flags().StringVar(&contractCryptoer, "cryptoer", "ECDSA", "Key and Sign Algorithm")
+	contractCmd.AddCommand(contractCheckCmd, contractTestCmd)
+}
+
+// loadContractVM returns the virtual machine with the builtin functions of contracts,
+// the functions which use the database are compiled but they can't be called
+func loadContractVM(ecosystem int64) *script.VM {
+	smart.InitVM()
+	vm := smart.GetVM()
+	if err := smart.LoadSysFuncs(vm, int(ecosystem)); err != nil {
+		log.WithError(err).Fatal("loading system functions")
+	}
+	return vm
+}
+
+type contractFile struct {
+	path   string
+	source []rune
+}
+
+// contractFiles reads the source files of the directory sorted by the path
+func contractFiles(dir string) ([]*contractFile, error) {
+	var files []*contractFile
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// the tests use the builtin functions of the test runner
+		if info.IsDir() || filepath.Ext(path) != lsp.SourceExt || strings.HasSuffix(path, smarttest.TestSuffix) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &contractFile{path: path, source: []rune(string(data))})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, err
+}
+
+// compileContract returns the compiled block, the panic of the compiler is returned as the error
+func compileContract(vm *script.VM, source []rune, owner *script.OwnerInfo) (block *script.Block, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return vm.CompileBlock(source, owner)
+}
+
+// contractDecl is the contract or the function of the source file which is compiled separately,
+// so the error of one declaration doesn't hide the errors of the next ones
+type contractDecl struct {
+	file   *contractFile
+	source []rune
+}
+
+// checkContracts compiles and lints the files and returns the problems as path:line:column: message.
+// The contracts can call the contracts of other files, so the failed declarations are compiled again
+// while any declaration is compiled successfully.
+func checkContracts(vm *script.VM, files []*contractFile, owner *script.OwnerInfo) []string {
+	var (
+		problems []string
+		decls    []*contractDecl
+	)
+	for _, file := range files {
+		for _, source := range script.SplitDeclarations(file.source) {
+			decls = append(decls, &contractDecl{file: file, source: source})
+		}
+	}
+	errs := make(map[*contractDecl]error)
+	pending := decls
+	for len(pending) > 0 {
+		var failed []*contractDecl
+		for _, decl := range pending {
+			block, err := compileContract(vm, decl.source, owner)
+			if err != nil {
+				errs[decl] = err
+				failed = append(failed, decl)
+				continue
+			}
+			delete(errs, decl)
+			vm.FlushBlock(block)
+		}
+		if len(failed) == len(pending) {
+			break
+		}
+		pending = failed
+	}
+	fileErrs := make(map[*contractFile][]error)
+	for _, decl := range decls {
+		if err, ok := errs[decl]; ok {
+			fileErrs[decl.file] = append(fileErrs[decl.file], err)
+		}
+	}
+	for _, file := range files {
+		if list, ok := fileErrs[file]; ok {
+			for _, err := range list {
+				message, line, column := script.ErrorPosition(err)
+				problems = append(problems, fmt.Sprintf("%s:%d:%d: %s", file.path, line, column, message))
+			}
+			continue
+		}
+		issues, err := vm.Lint(file.source)
+		if err != nil {
+			message, line, column := script.ErrorPosition(err)
+			problems = append(problems, fmt.Sprintf("%s:%d:%d: %s", file.path, line, column, message))
+			continue
+		}
+		for _, issue := range issues {
+			problems = append(problems, fmt.Sprintf("%s:%d:%d: %s [%s]", file.path, issue.Line, issue.Column,
+				issue.Message, issue.Rule))
+		}
+	}
+	return problems
+}
@@ -106,5 +106,5 @@ var generateFirstBlockCmd = &cobra.Command{
 	return block.MarshallBlock(header, [][]byte{tx}, &utils.BlockData{
 		Hash:          []byte(`0`),
 		RollbacksHash: []byte(`0`),
-	}, "")
+	}, nil)
 }
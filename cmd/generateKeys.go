@@ -7,37 +7,63 @@ package cmd
 
 import (
 	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
-	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
-	"github.com/spf13/cobra"
 	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
 )
 
 const fileMode = 0600
 
+var (
+	generateKeysMnemonic     bool
+	generateKeysMnemonicFile string
+	generateKeysKeystore     bool
+	generateKeysKDF          string
+)
+
 // generateKeysCmd represents the generateKeys command
 var generateKeysCmd = &cobra.Command{
 	Use:    "generateKeys",
 	Short:  "Keys generation",
 	PreRun: loadConfig,
 	Run: func(cmd *cobra.Command, args []string) {
-		_, publicKey, err := createKeyPair(
+		if generateKeysKeystore {
+			if err := conf.FillKeystorePassphrase(); err != nil {
+				log.WithFields(log.Fields{"error": err}).Fatal("reading keystore passphrase")
+				return
+			}
+			if len(conf.Config.Keystore.Passphrase) == 0 {
+				log.Fatal("keystore passphrase is empty")
+				return
+			}
+		}
+		privateKey, nodePrivateKey, err := generatePrivateKeys()
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Fatal("generating private keys")
+			return
+		}
+		publicKey, err := createKeyPair(
 			filepath.Join(conf.Config.KeysDir, consts.PrivateKeyFilename),
 			filepath.Join(conf.Config.KeysDir, consts.PublicKeyFilename),
+			privateKey,
 		)
 		if err != nil {
 			log.WithFields(log.Fields{"error": err}).Fatal("generating user keys")
 			return
 		}
-		_, _, err = createKeyPair(
+		_, err = createKeyPair(
 			filepath.Join(conf.Config.KeysDir, consts.NodePrivateKeyFilename),
 			filepath.Join(conf.Config.KeysDir, consts.NodePublicKeyFilename),
+			nodePrivateKey,
 		)
 		if err != nil {
 			log.WithFields(log.Fields{"error": err}).Fatal("generating node keys")
@@ -54,6 +80,48 @@ var generateKeysCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	generateKeysCmd.Flags().BoolVar(&generateKeysMnemonic, "mnemonic", false, "Derive the keys from the new mnemonic which is printed for backup")
+	generateKeysCmd.Flags().StringVar(&generateKeysMnemonicFile, "mnemonicFile", "", "Restore the keys from the mnemonic in the file")
+	generateKeysCmd.Flags().BoolVar(&generateKeysKeystore, "keystore", false, "Write the private keys to the keystore files encrypted with the passphrase")
+	generateKeysCmd.Flags().StringVar(&generateKeysKDF, "kdf", crypto.KDFScrypt, "Key derivation function of the keystore files (scrypt or argon2id)")
+}
+
+// generatePrivateKeys returns the random wallet and node keys or the keys which are derived
+// from the mnemonic. The wallet key is the first HD key and the node key is the second one.
+func generatePrivateKeys() (privateKey, nodePrivateKey []byte, err error) {
+	var mnemonic string
+	switch {
+	case len(generateKeysMnemonicFile) > 0:
+		var data []byte
+		if data, err = os.ReadFile(generateKeysMnemonicFile); err != nil {
+			return
+		}
+		mnemonic = strings.Join(strings.Fields(string(data)), " ")
+	case generateKeysMnemonic:
+		if mnemonic, err = crypto.NewMnemonic(); err != nil {
+			return
+		}
+		fmt.Printf("Mnemonic (write it down and keep it in a safe place, it restores all keys):\n%s\n", mnemonic)
+	default:
+		if privateKey, _, err = crypto.GenKeyPair(); err != nil {
+			return
+		}
+		nodePrivateKey, _, err = crypto.GenKeyPair()
+		return
+	}
+
+	seed, err := crypto.MnemonicToSeed(mnemonic, "")
+	if err != nil {
+		return
+	}
+	if privateKey, err = crypto.DeriveKey(seed, crypto.HDPath(0)); err != nil {
+		return
+	}
+	nodePrivateKey, err = crypto.DeriveKey(seed, crypto.HDPath(1))
+	return
+}
+
 func createFile(filename string, data []byte) error {
 	dir := filepath.Dir(filename)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -66,14 +134,28 @@ func createFile(filename string, data []byte) error {
 	return os.WriteFile(filename, data, fileMode)
 }
 
-func createKeyPair(privFilename, pubFilename string) (priv, pub []byte, err error) {
-	priv, pub, err = crypto.GenKeyPair()
+func createKeyPair(privFilename, pubFilename string, priv []byte) (pub []byte, err error) {
+	pub, err = crypto.PrivateToPublic(priv)
 	if err != nil {
-		log.WithFields(log.Fields{"error": err}).Error("generate keys")
+		log.WithFields(log.Fields{"error": err}).Error("converting private key to public")
 		return
 	}
 
-	err = createFile(privFilename, []byte(hex.EncodeToString(priv)))
+	data := []byte(hex.EncodeToString(priv))
+	if generateKeysKeystore {
+		if data, err = crypto.EncryptKey(priv, conf.Config.Keystore.Passphrase, generateKeysKDF); err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("encrypting private key")
+			return
+		}
+	}
+	err = createFile(privFilename, data)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "path": privFilename}).Error("creating private key")
+		return
+	}
+
+	err = createFile(pubFilename, []byte(crypto.PubToHex(pub)))
+	if err != nil {
 		log.WithFields(log.Fields{"error": err, "path": pubFilename}).Error("creating public key")
 		return
 	}
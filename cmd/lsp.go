@@ -0,0 +1,63 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/lsp"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lspNode      string
+	lspKey       string
+	lspEcosystem int64
+	lspHasher    string
+	lspCryptoer  string
+)
+
+// lspCmd runs the language server of the contract language on stdin and stdout
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run the language server for the contract source files",
+	Run: func(cmd *cobra.Command, args []string) {
+		out := os.Stdout
+		// the compiler prints the parsing errors, they mustn't get into the protocol stream
+		os.Stdout = os.Stderr
+		log.SetOutput(os.Stderr)
+
+		crypto.InitHash(lspHasher)
+		crypto.InitCurve(lspCryptoer)
+		vm := loadContractVM(lspEcosystem)
+
+		var schema lsp.Schema
+		if len(lspNode) > 0 {
+			nodeSchema, err := lsp.NewNodeSchema(lspNode, lspKey, lspEcosystem)
+			if err != nil {
+				// the server works without the completion of tables
+				log.WithError(err).Error("connecting to node for table names")
+			} else {
+				schema = nodeSchema
+			}
+		}
+
+		if err := lsp.NewServer(os.Stdin, out, vm, lspEcosystem, schema).Run(); err != nil {
+			log.WithError(err).Fatal("running language server")
+		}
+	},
+}
+
+func init() {
+	lspCmd.Flags().StringVar(&lspNode, "node", "", "API address of the node for the completion of tables and columns, e.g. http://127.0.0.1:7079")
+	lspCmd.Flags().StringVar(&lspKey, "key", "PrivateKey", "File of the private key to log in to the node")
+	lspCmd.Flags().Int64Var(&lspEcosystem, "ecosystem", 1, "Ecosystem of contracts")
+	lspCmd.Flags().StringVar(&lspHasher, "hasher", "SHA256", "Hash Algorithm")
+	lspCmd.Flags().StringVar(&lspCryptoer, "cryptoer", "ECDSA", "Key and Sign Algorithm")
+}
@@ -26,10 +26,14 @@ func init() {
 		generateKeysCmd,
 		initDatabaseCmd,
 		rollbackCmd,
+		snapshotCmd,
 		startCmd,
 		configCmd,
 		stopNetworkCmd,
 		versionCmd,
+		signerCmd,
+		lspCmd,
+		contractCmd,
 	)
 
 	// This flags are visible for all child commands
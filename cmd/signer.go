@@ -0,0 +1,65 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/signer"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var signerSocket string
+
+// signerCmd runs the daemon which keeps the node key and signs the data for the node
+var signerCmd = &cobra.Command{
+	Use:    "signer",
+	Short:  "Run the remote signer of node key on the unix socket",
+	PreRun: loadConfig,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := conf.FillKeystorePassphrase(); err != nil {
+			log.WithError(err).Fatal("reading keystore passphrase")
+		}
+		s, err := signer.LoadLocalSigner(filepath.Join(conf.Config.KeysDir, consts.NodePrivateKeyFilename),
+			conf.Config.Keystore.Passphrase)
+		if err != nil {
+			log.WithError(err).Fatal("loading node private key")
+		}
+
+		os.Remove(signerSocket)
+		l, err := net.Listen("unix", signerSocket)
+		if err != nil {
+			log.WithError(err).Fatal("listening signer socket")
+		}
+		if err = os.Chmod(signerSocket, 0600); err != nil {
+			log.WithError(err).Fatal("changing mode of signer socket")
+		}
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			l.Close()
+		}()
+
+		log.WithFields(log.Fields{"socket": signerSocket}).Info("signer is started")
+		if err = signer.Serve(l, s); err != nil {
+			log.WithFields(log.Fields{"error": err}).Info("signer is stopped")
+		}
+	},
+}
+
+func init() {
+	signerCmd.Flags().StringVar(&signerSocket, "socket", "signer.sock", "Unix socket of the signer, it is set as --signerSocket of the node")
+}
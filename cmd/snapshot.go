@@ -0,0 +1,104 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"encoding/hex"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/snapshot"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotBlockID   int64
+	snapshotFile      string
+	snapshotChunkRows int
+	snapshotHash      string
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Export or import the state snapshot",
+}
+
+// snapshotExportCmd writes the state at the block to the snapshot file
+var snapshotExportCmd = &cobra.Command{
+	Use:    "export",
+	Short:  "Export the state at blockID to the snapshot file",
+	PreRun: loadConfigWKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := utils.LockOrDie(conf.Config.LockFilePath)
+		defer f.Unlock()
+
+		if err := model.OpenStorage(conf.Config.DB); err != nil {
+			log.WithError(err).Fatal("init db")
+			return
+		}
+		if err := syspar.SysUpdate(nil); err != nil {
+			log.WithError(err).Fatal("updating sys parameters")
+			return
+		}
+		if err := syspar.SysTableColType(nil); err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("updating sys table col type")
+		}
+
+		smart.InitVM()
+		if err := smart.LoadContracts(); err != nil {
+			log.WithError(err).Fatal("loading contracts")
+			return
+		}
+		m, err := snapshot.ExportFile(snapshotFile, snapshotBlockID, snapshotChunkRows, log.WithFields(log.Fields{"file": snapshotFile}))
+		if err != nil {
+			log.WithError(err).Fatal("exporting snapshot")
+			return
+		}
+		log.WithFields(log.Fields{"block_id": m.BlockID, "state_hash": hex.EncodeToString(m.StateHash)}).Info("snapshot has been exported")
+	},
+}
+
+// snapshotImportCmd loads the snapshot file to the empty database
+var snapshotImportCmd = &cobra.Command{
+	Use:    "import",
+	Short:  "Import the snapshot file to the empty database",
+	PreRun: loadConfigWKey,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := utils.LockOrDie(conf.Config.LockFilePath)
+		defer f.Unlock()
+
+		hash, err := hex.DecodeString(snapshotHash)
+		if err != nil {
+			log.WithError(err).Fatal("decoding state hash")
+			return
+		}
+		if err := model.OpenStorage(conf.Config.DB); err != nil {
+			log.WithError(err).Fatal("init db")
+			return
+		}
+		if _, err := snapshot.ImportFile(snapshotFile, hash, log.WithFields(log.Fields{"file": snapshotFile})); err != nil {
+			log.WithError(err).Fatal("importing snapshot")
+			return
+		}
+	},
+}
+
+func init() {
+	snapshotExportCmd.Flags().Int64Var(&snapshotBlockID, "block", 0, "blockID of the state, the last block by default")
+	snapshotExportCmd.Flags().IntVar(&snapshotChunkRows, "chunkRows", snapshot.DefaultChunkRows, "count of rows in the chunk")
+	snapshotImportCmd.Flags().StringVar(&snapshotHash, "hash", "", "trusted state hash of the snapshot, it is required")
+	snapshotImportCmd.MarkFlagRequired("hash")
+	for _, c := range []*cobra.Command{snapshotExportCmd, snapshotImportCmd} {
+		c.Flags().StringVar(&snapshotFile, "file", "snapshot.tar", "filepath of the snapshot")
+	}
+	snapshotCmd.AddCommand(snapshotExportCmd, snapshotImportCmd)
+}
@@ -12,6 +12,9 @@ require (
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/fsnotify/fsnotify v1.4.9 // indirect
 	github.com/go-redis/redis v6.15.9+incompatible
+	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db
+	github.com/gorilla/websocket v1.4.2
+	github.com/mattn/go-sqlite3 v1.14.5
 	github.com/ochinchina/supervisord/config v0.0.0-20210709021912-96855de42ff6
 	github.com/ochinchina/supervisord/events v0.0.0-20210709021912-96855de42ff6 // indirect
 	github.com/ochinchina/supervisord/faults v0.0.0-20210709021912-96855de42ff6 // indirect
@@ -36,11 +39,14 @@ require (
 	github.com/syndtr/goleveldb v1.0.0
 	github.com/theckman/go-flock v0.8.0
 	github.com/tjfoc/gmsm v1.4.1
+	github.com/tyler-smith/go-bip39 v1.1.0
 	github.com/vmihailenco/msgpack/v5 v5.3.4
+	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2
 	golang.org/x/sys v0.0.0-20210908233432-aa78b53d3365 // indirect
 	google.golang.org/protobuf v1.25.0 // indirect
 	gopkg.in/ini.v1 v1.61.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776 // indirect
 	gorm.io/driver/postgres v1.1.0
+	gorm.io/driver/sqlite v1.1.4
 	gorm.io/gorm v1.21.13
 )
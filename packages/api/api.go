@@ -29,6 +29,8 @@ type Mode struct {
 	EcosysLookupGetter types.EcosystemLookupGetter
 	ContractRunner     types.SmartContractRunner
 	ClientTxProcessor  types.ClientTxPreprocessor
+	TxTracer           types.TxTracer
+	TxSimulator        types.TxSimulator
 }
 
 // Client represents data of client
@@ -50,6 +50,7 @@ type blockInfoResult struct {
 	Time          int64  `json:"time"`
 	Tx            int32  `json:"tx_count"`
 	RollbacksHash []byte `json:"rollbacks_hash"`
+	StateRoot     []byte `json:"state_root"`
 	NodePosition  int64  `json:"node_position"`
 }
 
@@ -78,6 +79,7 @@ func getBlockInfoHandler(w http.ResponseWriter, r *http.Request) {
 		Time:          block.Time,
 		Tx:            block.Tx,
 		RollbacksHash: block.RollbacksHash,
+		StateRoot:     block.StateRoot,
 		NodePosition:  block.NodePosition,
 	})
 }
@@ -180,6 +182,7 @@ type BlockDetailedInfo struct {
 	Time          int64            `json:"time"`
 	Tx            int32            `json:"tx_count"`
 	RollbacksHash []byte           `json:"rollbacks_hash"`
+	StateRoot     []byte           `json:"state_root"`
 	MrklRoot      []byte           `json:"mrkl_root"`
 	BinData       []byte           `json:"bin_data"`
 	SysUpdate     bool             `json:"-"`
@@ -262,6 +265,7 @@ func getBlocksDetailedInfoHandler(w http.ResponseWriter, r *http.Request) {
 			Time:          blockModel.Time,
 			Tx:            blockModel.Tx,
 			RollbacksHash: blockModel.RollbacksHash,
+			StateRoot:     blockModel.StateRoot,
 			MrklRoot:      blck.MrklRoot,
 			BinData:       blck.BinData,
 			SysUpdate:     blck.SysUpdate,
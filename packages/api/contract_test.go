@@ -1720,7 +1720,7 @@ func TestErrors(t *testing.T) {
 			}}`}, `Conditions`: {`true`}, `ApplicationId`: {`1`}}
 	assert.NoError(t, postTx(`NewContract`, &form))
 	assert.EqualError(t, postTx(name+`5`, &url.Values{}),
-		`{"type":"panic","error":"throw message [Throw @1`+name+`5:4]"}`)
+		`{"type":"exception","error":"throw message","id":"Problem"}`)
 
 	form = url.Values{`Value`: {`contract ` + name + `4 {
 			action {
@@ -0,0 +1,63 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/rollback"
+	"github.com/IBAX-io/go-ibax/packages/script"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+var errReplayDepth = errType{"E_REPLAYDEPTH", "Transaction is older than %d blocks", http.StatusBadRequest}
+
+type traceTxForm struct {
+	Steps int `schema:"steps"`
+}
+
+func (f *traceTxForm) Validate(r *http.Request) error {
+	if f.Steps <= 0 || f.Steps > script.DefaultTraceSteps {
+		f.Steps = script.DefaultTraceSteps
+	}
+	return nil
+}
+
+func (m Mode) traceTxHandler(w http.ResponseWriter, r *http.Request) {
+	form := &traceTxForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	hash, err := hex.DecodeString(mux.Vars(r)["hash"])
+	if err != nil {
+		errorResponse(w, errHashWrong)
+		return
+	}
+
+	logger := getLogger(r)
+	trace, err := m.TxTracer.TraceTx(hash, form.Steps, logger)
+	switch err {
+	case nil:
+	case rollback.ErrReplayTxNotFound:
+		errorResponse(w, errHashNotFound)
+		return
+	case rollback.ErrReplayDepth:
+		errorResponse(w, errReplayDepth.Errorf(rollback.MaxReplayBlocks))
+		return
+	default:
+		logger.WithFields(log.Fields{"type": consts.ContractError, "error": err}).Error("replaying transaction")
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, trace)
+}
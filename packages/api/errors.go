@@ -41,11 +41,13 @@ var (
 	errTableNotFound     = errType{"E_TABLENOTFOUND", "Table %s has not been found", http.StatusNotFound}
 	errToken             = errType{"E_TOKEN", "Token is not valid", defaultStatus}
 	errTokenExpired      = errType{"E_TOKENEXPIRED", "Token is expired by %s", http.StatusUnauthorized}
+	errTxProof           = errType{"E_TXPROOF", "Transaction proof is incorrect: %s", http.StatusBadRequest}
 	errUnauthorized      = errType{"E_UNAUTHORIZED", "Unauthorized", http.StatusUnauthorized}
 	errUndefineval       = errType{"E_UNDEFINEVAL", "Value %s is undefined", defaultStatus}
 	errUnknownUID        = errType{"E_UNKNOWNUID", "Unknown uid", defaultStatus}
 	errOBS               = errType{"E_OBS", "Virtual Dedicated Ecosystem %d doesn't exist", defaultStatus}
 	errOBSCreated        = errType{"E_OBSCREATED", "Virtual Dedicated Ecosystem is already created", http.StatusBadRequest}
+	errSimulateLimit     = errType{"E_SIMULATELIMIT", "Too many simulations, try again later", http.StatusTooManyRequests}
 	Err     string `json:"error"`
 	Message string `json:"msg"`
 	Status  int    `json:"-"`
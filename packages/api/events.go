@@ -0,0 +1,95 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type eventsForm struct {
+	paginatorForm
+	Ecosystem int64    `schema:"ecosystem"`
+	Contract  string   `schema:"contract"`
+	Name      string   `schema:"name"`
+	TxHash    hexValue `schema:"tx_hash"`
+	FromBlock int64    `schema:"block_from"`
+	ToBlock   int64    `schema:"block_to"`
+}
+
+func (f *eventsForm) Validate(r *http.Request) error {
+	if f.FromBlock < 0 || f.ToBlock < 0 || (f.ToBlock > 0 && f.FromBlock > f.ToBlock) {
+		return errUndefineval.Errorf("block range")
+	}
+	return f.paginatorForm.Validate(r)
+}
+
+type eventResult struct {
+	ID        int64           `json:"id"`
+	BlockID   int64           `json:"block_id"`
+	TxHash    string          `json:"tx_hash"`
+	Ecosystem int64           `json:"ecosystem"`
+	Contract  string          `json:"contract"`
+	Name      string          `json:"name"`
+	Data      json.RawMessage `json:"data"`
+	Time      int64           `json:"time"`
+}
+
+type eventsResult struct {
+	List []eventResult `json:"list"`
+}
+
+func getEventsHandler(w http.ResponseWriter, r *http.Request) {
+	form := &eventsForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	logger := getLogger(r)
+
+	events, err := model.GetContractEvents(model.ContractEventFilter{
+		Ecosystem: form.Ecosystem,
+		Contract:  form.Contract,
+		Name:      form.Name,
+		TxHash:    form.TxHash.Bytes(),
+		FromBlock: form.FromBlock,
+		ToBlock:   form.ToBlock,
+		Offset:    form.Offset,
+		Limit:     form.Limit,
+	})
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting contract events")
+		errorResponse(w, errQuery)
+		return
+	}
+
+	result := &eventsResult{List: make([]eventResult, 0, len(events))}
+	for _, e := range events {
+		data := json.RawMessage(e.Data)
+		if len(data) == 0 {
+			data = json.RawMessage("{}")
+		}
+		result.List = append(result.List, eventResult{
+			ID:        e.ID,
+			BlockID:   e.BlockID,
+			TxHash:    hex.EncodeToString(e.TxHash),
+			Ecosystem: e.Ecosystem,
+			Contract:  e.Contract,
+			Name:      e.Name,
+			Data:      data,
+			Time:      e.Time,
+		})
+	}
+
+	jsonResponse(w, result)
+}
@@ -0,0 +1,414 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	jsonRPCVersion = "2.0"
+
+	// JSON-RPC 2.0 predefined error codes
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+
+	// implementation-defined server errors
+	rpcServerError   = -32000
+	rpcUnauthorized  = -32001
+	rpcForbidden     = -32002
+	rpcNotFound      = -32004
+	rpcNodeNotReady  = -32005
+	rpcLimitExceeded = -32006
+)
+
+// rpcErrorCodes maps the E_* codes from errors.go to JSON-RPC error codes
+var rpcErrorCodes = map[string]int{
+	errUnauthorized.Err:   rpcUnauthorized,
+	errToken.Err:          rpcUnauthorized,
+	errTokenExpired.Err:   rpcUnauthorized,
+	errPermission.Err:     rpcForbidden,
+	errDeletedKey.Err:     rpcForbidden,
+	errStateLogin.Err:     rpcForbidden,
+	errNotFound.Err:       rpcNotFound,
+	errKeyNotFound.Err:    rpcNotFound,
+	errHashNotFound.Err:   rpcNotFound,
+	errParamNotFound.Err:  rpcNotFound,
+	errTableNotFound.Err:  rpcNotFound,
+	errContract.Err:       rpcNotFound,
+	errEcosystem.Err:      rpcNotFound,
+	errUpdating.Err:       rpcNodeNotReady,
+	errStopping.Err:       rpcNodeNotReady,
+	errLimitTxSize.Err:    rpcLimitExceeded,
+	errLimitForsign.Err:   rpcLimitExceeded,
+	errHashWrong.Err:      rpcInvalidParams,
+	errInvalidWallet.Err:  rpcInvalidParams,
+	errEmptyPublic.Err:    rpcInvalidParams,
+	errEmptySign.Err:      rpcInvalidParams,
+	errSignature.Err:      rpcInvalidParams,
+	errUndefineval.Err:    rpcInvalidParams,
+	errQuery.Err:          rpcInternalError,
+	errRecovered.Err:      rpcInternalError,
+	errDBNil.Err:          rpcInternalError,
+	errServer.Err:         rpcServerError,
+	errBannded.Err:        rpcForbidden,
+	errOBS.Err:            rpcNotFound,
+	errUnknownUID.Err:     rpcInvalidParams,
+	errUnknownSign.Err:    rpcInvalidParams,
+	errHeavyPage.Err:      rpcLimitExceeded,
+	errInstalled.Err:      rpcServerError,
+	errOBSCreated.Err:     rpcServerError,
+	errNotFoundRecord.Err: rpcNotFound,
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+func (req *rpcRequest) isNotification() bool {
+	return len(req.ID) == 0
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcMethod describes how a JSON-RPC method is dispatched to a REST handler.
+// Vars are the names of the route variables that are taken from the params,
+// all other params are passed as form values.
+type rpcMethod struct {
+	Handler    http.HandlerFunc
+	HTTPMethod string
+	Vars       []string
+}
+
+type rpcServer struct {
+	methods map[string]rpcMethod
+}
+
+func newRPCServer() *rpcServer {
+	return &rpcServer{methods: make(map[string]rpcMethod)}
+}
+
+func (s *rpcServer) register(name, httpMethod string, handler http.HandlerFunc, vars ...string) {
+	s.methods[name] = rpcMethod{
+		Handler:    handler,
+		HTTPMethod: httpMethod,
+		Vars:       vars,
+	}
+}
+
+func (m Mode) setCommonRPCMethods(s *rpcServer) {
+	s.register("getVersion", "GET", getVersionHandler)
+	s.register("getUid", "GET", getUIDHandler)
+	s.register("getTxStatus", "POST", authRequire(getTxStatusHandler))
+	s.register("sendSignTx", "POST", m.sendSignTxHandler)
+	s.register("listWhere", "POST", authRequire(getListWhereHandler), "name")
+	s.register("sumWhere", "POST", authRequire(getsumWhereHandler), "name")
+	s.register("getRow", "GET", authRequire(getRowHandler), "name", "column", "id")
+	s.register("getTable", "GET", authRequire(getTableHandler), "name")
+	s.register("getTables", "GET", authRequire(getTablesHandler))
+	s.register("getContract", "GET", authRequire(getContractInfoHandler), "name")
+	s.register("getMember", "GET", getMemberHandler, "ecosystem", "account")
+}
+
+func (m Mode) setBlockchainRPCMethods(s *rpcServer) {
+	s.register("getMaxBlock", "GET", getMaxBlockHandler)
+	s.register("getBlockInfo", "GET", getBlockInfoHandler, "id")
+	s.register("getBlocks", "GET", getBlocksTxInfoHandler)
+	s.register("getDetailedBlocks", "GET", getBlocksDetailedInfoHandler)
+	s.register("getTxInfo", "GET", authRequire(getTxInfoHandler), "hash")
+	s.register("getTxProof", "GET", getTxProofHandler, "hash")
+	s.register("getBalance", "GET", authRequire(m.getBalanceHandler), "wallet")
+	s.register("getMyBalance", "GET", authRequire(m.getMyBalanceHandler))
+	s.register("getEcosystemParams", "GET", authRequire(m.getEcosystemParamsHandler))
+	s.register("getEcosystemParam", "GET", authRequire(m.getEcosystemParamHandler), "name")
+	s.register("getSystemParams", "GET", authRequire(getSystemParamsHandler))
+	s.register("getEcosystemName", "GET", getEcosystemNameHandler)
+	s.register("getEvents", "GET", getEventsHandler)
+	if conf.Config.Debug.TraceTx {
+		s.register("traceTx", "GET", nodeOwnerRequire(m.traceTxHandler), "hash")
+	}
+	s.register("simulate", "POST", authRequire(m.simulateHandler))
+}
+
+func (m Mode) setLightRPCMethods(s *rpcServer) {
+	s.register("getLightHeader", "GET", getLightHeaderHandler, "id")
+	s.register("getLightMaxBlock", "GET", getLightMaxBlockHandler)
+	s.register("verifyTxProof", "POST", verifyTxProofHandler)
+}
+
+// ServeHTTP handles single and batch JSON-RPC 2.0 requests over HTTP POST
+func (s *rpcServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		rpcJSONResponse(w, newRPCErrorResponse(nil, rpcParseError, "Parse error", err.Error()))
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			rpcJSONResponse(w, newRPCErrorResponse(nil, rpcParseError, "Parse error", err.Error()))
+			return
+		}
+		if len(batch) == 0 {
+			rpcJSONResponse(w, newRPCErrorResponse(nil, rpcInvalidRequest, "Invalid Request", nil))
+			return
+		}
+
+		result := make([]*rpcResponse, 0, len(batch))
+		for _, raw := range batch {
+			if resp := s.call(r, raw); resp != nil {
+				result = append(result, resp)
+			}
+		}
+		if len(result) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		rpcJSONResponse(w, result)
+		return
+	}
+
+	if resp := s.call(r, body); resp != nil {
+		rpcJSONResponse(w, resp)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// call executes a single request, it returns nil for notifications
+func (s *rpcServer) call(r *http.Request, raw json.RawMessage) *rpcResponse {
+	req := &rpcRequest{}
+	if err := json.Unmarshal(raw, req); err != nil || req.JSONRPC != jsonRPCVersion || len(req.Method) == 0 {
+		return newRPCErrorResponse(nil, rpcInvalidRequest, "Invalid Request", nil)
+	}
+
+	method, ok := s.methods[req.Method]
+	if !ok {
+		if req.isNotification() {
+			return nil
+		}
+		return newRPCErrorResponse(req.ID, rpcMethodNotFound, "Method not found", req.Method)
+	}
+
+	params, err := parseRPCParams(req.Params)
+	if err != nil {
+		if req.isNotification() {
+			return nil
+		}
+		return newRPCErrorResponse(req.ID, rpcInvalidParams, "Invalid params", err.Error())
+	}
+
+	resp := s.invoke(r, req, method, params)
+	if req.isNotification() {
+		return nil
+	}
+	return resp
+}
+
+func (s *rpcServer) invoke(r *http.Request, req *rpcRequest, method rpcMethod, params map[string]string) *rpcResponse {
+	logger := getLogger(r)
+
+	vars := make(map[string]string, len(method.Vars))
+	for _, name := range method.Vars {
+		v, ok := params[name]
+		if !ok {
+			return newRPCErrorResponse(req.ID, rpcInvalidParams, "Invalid params", fmt.Sprintf("parameter %s is required", name))
+		}
+		vars[name] = v
+		delete(params, name)
+	}
+
+	hr, err := newRPCHandlerRequest(r, method.HTTPMethod, params)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ParseError, "error": err, "method": req.Method}).Error("building json-rpc handler request")
+		return newRPCErrorResponse(req.ID, rpcInternalError, "Internal error", err.Error())
+	}
+	hr = mux.SetURLVars(hr, vars)
+
+	rw := newRPCResponseWriter()
+	method.Handler(rw, hr)
+
+	if rw.status != http.StatusOK {
+		et := errType{}
+		if err := json.Unmarshal(rw.body.Bytes(), &et); err != nil || len(et.Err) == 0 {
+			et = errServer
+			et.Message = strings.TrimSpace(rw.body.String())
+		}
+		et.Status = rw.status
+		return newRPCErrorResponse(req.ID, rpcErrorCode(et), et.Message, et)
+	}
+
+	result := rw.body.Bytes()
+	if len(result) == 0 {
+		result = []byte("null")
+	}
+	return &rpcResponse{
+		JSONRPC: jsonRPCVersion,
+		ID:      req.ID,
+		Result:  json.RawMessage(result),
+	}
+}
+
+// parseRPCParams accepts params as a JSON object, string values are passed as is
+// and all other values are passed as JSON text
+func parseRPCParams(raw json.RawMessage) (map[string]string, error) {
+	params := make(map[string]string)
+	if len(raw) == 0 || string(raw) == "null" {
+		return params, nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("params must be an object")
+	}
+	for k, v := range obj {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			params[k] = s
+			continue
+		}
+		params[k] = string(v)
+	}
+	return params, nil
+}
+
+// newRPCHandlerRequest creates a request for the REST handler that inherits the context
+// (logger, token and client) and the headers of the JSON-RPC request
+func newRPCHandlerRequest(r *http.Request, httpMethod string, params map[string]string) (*http.Request, error) {
+	if httpMethod == "GET" {
+		form := url.Values{}
+		for k, v := range params {
+			form.Set(k, v)
+		}
+		hr, err := http.NewRequest(httpMethod, r.URL.Path+"?"+form.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		hr.Header = r.Header.Clone()
+		hr.Header.Del(contentType)
+		return hr.WithContext(r.Context()), nil
+	}
+
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+	for k, v := range params {
+		if err := mw.WriteField(k, v); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	hr, err := http.NewRequest(httpMethod, r.URL.Path, buf)
+	if err != nil {
+		return nil, err
+	}
+	hr.Header = r.Header.Clone()
+	hr.Header.Set(contentType, mw.FormDataContentType())
+	return hr.WithContext(r.Context()), nil
+}
+
+func rpcErrorCode(et errType) int {
+	if code, ok := rpcErrorCodes[et.Err]; ok {
+		return code
+	}
+	switch {
+	case et.Status == http.StatusUnauthorized:
+		return rpcUnauthorized
+	case et.Status == http.StatusForbidden:
+		return rpcForbidden
+	case et.Status == http.StatusNotFound:
+		return rpcNotFound
+	case et.Status >= http.StatusInternalServerError:
+		return rpcInternalError
+	}
+	return rpcServerError
+}
+
+func newRPCErrorResponse(id json.RawMessage, code int, msg string, data interface{}) *rpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{
+		JSONRPC: jsonRPCVersion,
+		ID:      id,
+		Error: &rpcError{
+			Code:    code,
+			Message: msg,
+			Data:    data,
+		},
+	}
+}
+
+func rpcJSONResponse(w http.ResponseWriter, v interface{}) {
+	jsonResult, err := json.Marshal(v)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("marshalling json-rpc response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(contentType, "application/json; charset=utf-8")
+	w.Write(jsonResult)
+}
+
+// rpcResponseWriter collects the output of the REST handler
+type rpcResponseWriter struct {
+	header http.Header
+	body   *bytes.Buffer
+	status int
+}
+
+func newRPCResponseWriter() *rpcResponseWriter {
+	return &rpcResponseWriter{
+		header: make(http.Header),
+		body:   &bytes.Buffer{},
+		status: http.StatusOK,
+	}
+}
+
+func (rw *rpcResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *rpcResponseWriter) Write(data []byte) (int, error) {
+	return rw.body.Write(data)
+}
+
+func (rw *rpcResponseWriter) WriteHeader(status int) {
+	rw.status = status
+}
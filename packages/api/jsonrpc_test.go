@@ -0,0 +1,87 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRPCServer() *rpcServer {
+	s := newRPCServer()
+	s.register("echo", "POST", func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, map[string]string{"name": mux.Vars(r)["name"], "value": r.FormValue("value")})
+	}, "name")
+	s.register("fail", "GET", func(w http.ResponseWriter, r *http.Request) {
+		errorResponse(w, errTableNotFound.Errorf("test"))
+	})
+	return s
+}
+
+func sendTestRPC(s *rpcServer, body string) *rpcResponseWriter {
+	r, _ := http.NewRequest("POST", "/api/v2/jsonrpc", strings.NewReader(body))
+	w := newRPCResponseWriter()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestJSONRPCCall(t *testing.T) {
+	s := newTestRPCServer()
+
+	w := sendTestRPC(s, `{"jsonrpc":"2.0","id":1,"method":"echo","params":{"name":"keys","value":10}}`)
+	var resp rpcResponse
+	assert.NoError(t, json.Unmarshal(w.body.Bytes(), &resp))
+	assert.Nil(t, resp.Error)
+	assert.JSONEq(t, `{"name":"keys","value":"10"}`, string(resp.Result))
+
+	w = sendTestRPC(s, `{"jsonrpc":"2.0","id":2,"method":"fail"}`)
+	resp = rpcResponse{}
+	assert.NoError(t, json.Unmarshal(w.body.Bytes(), &resp))
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, rpcNotFound, resp.Error.Code)
+		assert.Equal(t, "Table test has not been found", resp.Error.Message)
+	}
+
+	w = sendTestRPC(s, `{"jsonrpc":"2.0","id":3,"method":"echo","params":{}}`)
+	resp = rpcResponse{}
+	assert.NoError(t, json.Unmarshal(w.body.Bytes(), &resp))
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, rpcInvalidParams, resp.Error.Code)
+	}
+}
+
+func TestJSONRPCBatch(t *testing.T) {
+	s := newTestRPCServer()
+
+	w := sendTestRPC(s, `[
+		{"jsonrpc":"2.0","id":1,"method":"echo","params":{"name":"a"}},
+		{"jsonrpc":"2.0","method":"echo","params":{"name":"b"}},
+		{"jsonrpc":"2.0","id":2,"method":"unknown"},
+		{"foo":"bar"}
+	]`)
+	var resp []rpcResponse
+	assert.NoError(t, json.Unmarshal(w.body.Bytes(), &resp))
+	if assert.Len(t, resp, 3) {
+		assert.Nil(t, resp[0].Error)
+		assert.Equal(t, rpcMethodNotFound, resp[1].Error.Code)
+		assert.Equal(t, rpcInvalidRequest, resp[2].Error.Code)
+	}
+
+	w = sendTestRPC(s, `[]`)
+	var single rpcResponse
+	assert.NoError(t, json.Unmarshal(w.body.Bytes(), &single))
+	assert.Equal(t, rpcInvalidRequest, single.Error.Code)
+
+	w = sendTestRPC(s, `{"jsonrpc":"2.0",`)
+	single = rpcResponse{}
+	assert.NoError(t, json.Unmarshal(w.body.Bytes(), &single))
+	assert.Equal(t, rpcParseError, single.Error.Code)
+}
@@ -0,0 +1,127 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/light"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+type lightHeaderResult struct {
+	ID                int64  `json:"id"`
+	Hash              []byte `json:"hash"`
+	MrklRoot          []byte `json:"mrkl_root"`
+	PrevRollbacksHash []byte `json:"prev_rollbacks_hash"`
+	PrevStateRoot     []byte `json:"prev_state_root"`
+	EcosystemID       int64  `json:"ecosystem_id"`
+	KeyID             int64  `json:"key_id"`
+	NodePosition      int64  `json:"node_position"`
+	Time              int64  `json:"time"`
+	Version           int    `json:"version"`
+	Tx                int32  `json:"tx_count"`
+}
+
+func getLightHeaderHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+	blockID := converter.StrToInt64(mux.Vars(r)["id"])
+
+	header := &model.LightHeader{}
+	found, err := header.Get(blockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting light header")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errNotFoundRecord)
+		return
+	}
+
+	jsonResponse(w, &lightHeaderResult{
+		ID:                header.ID,
+		Hash:              header.Hash,
+		MrklRoot:          header.MrklRoot,
+		PrevRollbacksHash: header.PrevRollbacksHash,
+		PrevStateRoot:     header.PrevStateRoot,
+		EcosystemID:       header.EcosystemID,
+		KeyID:             header.KeyID,
+		NodePosition:      header.NodePosition,
+		Time:              header.Time,
+		Version:           header.Version,
+		Tx:                header.Tx,
+	})
+}
+
+func getLightMaxBlockHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+
+	header := &model.LightHeader{}
+	found, err := header.GetLast()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting last light header")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errNotFoundRecord)
+		return
+	}
+
+	jsonResponse(w, &maxBlockResult{header.ID})
+}
+
+type verifyTxProofForm struct {
+	Proof string `schema:"proof"`
+}
+
+func (f *verifyTxProofForm) Validate(r *http.Request) error {
+	if len(f.Proof) == 0 {
+		return errParamNotFound.Errorf("proof")
+	}
+	return nil
+}
+
+type verifyTxProofResult struct {
+	BlockID int64  `json:"block_id"`
+	TxHash  []byte `json:"tx_hash"`
+}
+
+func verifyTxProofHandler(w http.ResponseWriter, r *http.Request) {
+	form := &verifyTxProofForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	logger := getLogger(r)
+	proof := &utils.TxProof{}
+	if err := json.Unmarshal([]byte(form.Proof), proof); err != nil {
+		logger.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("unmarshalling tx proof")
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	err := light.VerifyTxProof(proof)
+	if err == light.ErrHeaderNotFound {
+		errorResponse(w, errNotFoundRecord)
+		return
+	}
+	if err != nil {
+		errorResponse(w, errTxProof.Errorf(err))
+		return
+	}
+
+	jsonResponse(w, &verifyTxProofResult{BlockID: proof.BlockID, TxHash: proof.TxHash})
+}
@@ -152,8 +152,6 @@ func (m Mode) loginHandler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			nodePrivateKey := syspar.GetNodePrivKey()
-
 			contract := smart.GetContract("NewUser", 1)
 			sc := tx.SmartContract{
 				Header: tx.Header{
@@ -169,7 +167,7 @@ func (m Mode) loginHandler(w http.ResponseWriter, r *http.Request) {
 				},
 			}
 
-			txData, txHash, err := tx.NewInternalTransaction(sc, nodePrivateKey)
+			txData, txHash, err := tx.NewInternalTransaction(sc, syspar.GetNodeSigner())
 			if err != nil {
 				log.WithFields(log.Fields{"type": consts.ContractError, "err": err}).Error("Building transaction")
 				errorResponse(w, err)
@@ -0,0 +1,69 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/mempool"
+)
+
+type mempoolTxResult struct {
+	Hash     string `json:"hash"`
+	Address  string `json:"address"`
+	HighRate int8   `json:"high_rate"`
+	Expedite string `json:"expedite"`
+	Time     int64  `json:"time"`
+	Size     int    `json:"size"`
+}
+
+type mempoolResult struct {
+	Count           int                `json:"count"`
+	Size            int                `json:"size"`
+	Accounts        int                `json:"accounts"`
+	MaxCount        int                `json:"max_count"`
+	MaxSize         int                `json:"max_size"`
+	MaxAccountCount int                `json:"max_account_count"`
+	List            []*mempoolTxResult `json:"list"`
+}
+
+// getMempoolHandler returns the state of mempool and the pending transactions in the order in which they are played
+func getMempoolHandler(w http.ResponseWriter, r *http.Request) {
+	form := &paginatorForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	pool := mempool.GetPool()
+	stats := pool.Stats()
+	result := &mempoolResult{
+		Count:           stats.Count,
+		Size:            stats.Size,
+		Accounts:        stats.Accounts,
+		MaxCount:        stats.Limits.MaxCount,
+		MaxSize:         stats.Limits.MaxSize,
+		MaxAccountCount: stats.Limits.MaxAccountCount,
+	}
+
+	pending := pool.Pending(form.Offset + form.Limit)
+	if form.Offset < len(pending) {
+		for _, e := range pending[form.Offset:] {
+			result.List = append(result.List, &mempoolTxResult{
+				Hash:     hex.EncodeToString(e.Hash),
+				Address:  converter.AddressToString(e.KeyID),
+				HighRate: e.HighRate,
+				Expedite: e.Expedite.String(),
+				Time:     e.Time,
+				Size:     e.Size,
+			})
+		}
+	}
+
+	jsonResponse(w, result)
+}
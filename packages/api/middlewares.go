@@ -11,6 +11,7 @@ import (
 	"runtime/debug"
 	"time"
 
+	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/service"
 	"github.com/IBAX-io/go-ibax/packages/statsd"
@@ -33,6 +34,20 @@ func authRequire(next func(w http.ResponseWriter, r *http.Request)) func(w http.
 	}
 }
 
+// nodeOwnerRequire allows the request only for the owner of the node key
+func nodeOwnerRequire(next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return authRequire(func(w http.ResponseWriter, r *http.Request) {
+		if getClient(r).KeyID == conf.Config.KeyID {
+			next(w, r)
+			return
+		}
+
+		logger := getLogger(r)
+		logger.WithFields(log.Fields{"type": consts.AccessDenied}).Warning("request of the node owner from another account")
+		errorResponse(w, errPermission)
+	})
+}
+
 func loggerFromRequest(r *http.Request) *log.Entry {
 	return log.WithFields(log.Fields{
 		"headers":  r.Header,
@@ -0,0 +1,300 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// multisigExpiration is the time while the signatures of transaction are collected
+	multisigExpiration = 24 * time.Hour
+	// multisigMaxCount is the max count of transactions which are collected by the node
+	multisigMaxCount = 10000
+)
+
+// multisigTx is the transaction of multi-signature account which is waiting for the signatures
+type multisigTx struct {
+	payload    []byte
+	hash       []byte
+	signers    [][]byte
+	signatures [][]byte
+}
+
+// multisigMutex serializes the changes of the collected signatures which are stored in the multisig_txs table
+var multisigMutex sync.Mutex
+
+type multisigResult struct {
+	Hash    string   `json:"hash"`
+	Signers []string `json:"signers"`
+	Signed  []string `json:"signed"`
+	Tx      string   `json:"tx,omitempty"`
+}
+
+func (mtx *multisigTx) result() *multisigResult {
+	result := &multisigResult{
+		Hash:    hex.EncodeToString(mtx.hash),
+		Signers: make([]string, 0, len(mtx.signers)),
+		Signed:  make([]string, 0, len(mtx.signers)),
+	}
+	complete := true
+	for i, signer := range mtx.signers {
+		result.Signers = append(result.Signers, crypto.PubToHex(signer))
+		if mtx.signatures[i] == nil {
+			complete = false
+			continue
+		}
+		result.Signed = append(result.Signed, crypto.PubToHex(signer))
+	}
+	if complete {
+		result.Tx = hex.EncodeToString(tx.CombineSignatures(mtx.payload, mtx.signatures))
+	}
+	return result
+}
+
+// parseMultisigPayload returns the transaction with the signers from the payload
+func parseMultisigPayload(payload []byte) (*multisigTx, error) {
+	smartTx := tx.SmartContract{}
+	if err := msgpack.Unmarshal(payload, &smartTx); err != nil {
+		return nil, err
+	}
+	return &multisigTx{
+		payload:    payload,
+		hash:       crypto.DoubleHash(payload),
+		signers:    smartTx.Signers,
+		signatures: make([][]byte, len(smartTx.Signers)),
+	}, nil
+}
+
+// getMultisigTx returns the collected transaction which hasn't been expired
+func getMultisigTx(hash string) (*multisigTx, error) {
+	bhash, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, nil
+	}
+	item := &model.MultisigTx{}
+	found, err := item.Get(bhash, time.Now().Unix())
+	if err != nil || !found {
+		return nil, err
+	}
+	mtx, err := parseMultisigPayload(item.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if err = msgpack.Unmarshal(item.Signatures, &mtx.signatures); err != nil {
+		return nil, err
+	}
+	if len(mtx.signatures) != len(mtx.signers) {
+		mtx.signatures = make([][]byte, len(mtx.signers))
+	}
+	return mtx, nil
+}
+
+// encodeSignatures returns the signatures to store them in the multisig_txs table
+func (mtx *multisigTx) encodeSignatures() ([]byte, error) {
+	return msgpack.Marshal(mtx.signatures)
+}
+
+type multisigForm struct {
+	Payload string `schema:"payload"`
+}
+
+func (f *multisigForm) Validate(r *http.Request) error {
+	if len(f.Payload) == 0 {
+		return errParamNotFound.Errorf("payload")
+	}
+	return nil
+}
+
+// newMultisigHandler starts collecting the signatures of unsigned transaction of multi-signature account
+func newMultisigHandler(w http.ResponseWriter, r *http.Request) {
+	form := &multisigForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	logger := getLogger(r)
+	payload, err := hex.DecodeString(form.Payload)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding multisig payload from hex")
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	if int64(len(payload)) > syspar.GetMaxTxSize() {
+		errorResponse(w, errLimitTxSize.Errorf(len(payload)))
+		return
+	}
+	mtx, err := parseMultisigPayload(payload)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("unmarshalling multisig payload")
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	if len(mtx.signers) == 0 || len(mtx.signers) > crypto.MaxMultisigKeys {
+		errorResponse(w, errParamNotFound.Errorf("signers"))
+		return
+	}
+
+	multisigMutex.Lock()
+	defer multisigMutex.Unlock()
+	exist, err := getMultisigTx(hex.EncodeToString(mtx.hash))
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting multisig transaction")
+		errorResponse(w, err)
+		return
+	}
+	if exist != nil {
+		jsonResponse(w, exist.result())
+		return
+	}
+	now := time.Now()
+	if err = model.DeleteExpiredMultisigTxs(now.Unix()); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting expired multisig transactions")
+		errorResponse(w, err)
+		return
+	}
+	count, err := model.CountMultisigTxs()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("counting multisig transactions")
+		errorResponse(w, err)
+		return
+	}
+	if count >= multisigMaxCount {
+		errorResponse(w, errServer)
+		return
+	}
+	signatures, err := mtx.encodeSignatures()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling multisig signatures")
+		errorResponse(w, err)
+		return
+	}
+	item := &model.MultisigTx{
+		Hash:       mtx.hash,
+		Payload:    payload,
+		Signatures: signatures,
+		Expire:     now.Add(multisigExpiration).Unix(),
+	}
+	if err = item.Create(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("creating multisig transaction")
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, mtx.result())
+}
+
+type multisigSignForm struct {
+	Pubkey    string `schema:"pubkey"`
+	Signature string `schema:"signature"`
+}
+
+func (f *multisigSignForm) Validate(r *http.Request) error {
+	if len(f.Pubkey) == 0 {
+		return errEmptyPublic
+	}
+	if len(f.Signature) == 0 {
+		return errEmptySign
+	}
+	return nil
+}
+
+// signMultisigHandler adds the signature of one signer to the collected transaction
+func signMultisigHandler(w http.ResponseWriter, r *http.Request) {
+	form := &multisigSignForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	logger := getLogger(r)
+	pub, err := hex.DecodeString(form.Pubkey)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding public key from hex")
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	signature, err := hex.DecodeString(form.Signature)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding signature from hex")
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+	pub = crypto.CutPub(pub)
+
+	multisigMutex.Lock()
+	defer multisigMutex.Unlock()
+	mtx, err := getMultisigTx(mux.Vars(r)["hash"])
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting multisig transaction")
+		errorResponse(w, err)
+		return
+	}
+	if mtx == nil {
+		errorResponse(w, errHashNotFound)
+		return
+	}
+	index := -1
+	for i, signer := range mtx.signers {
+		if bytes.Equal(signer, pub) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		errorResponse(w, errPermission)
+		return
+	}
+	if ok, err := crypto.CheckSign(pub, mtx.hash, signature); err != nil || !ok {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("checking multisig signature")
+		errorResponse(w, errSignature)
+		return
+	}
+	mtx.signatures[index] = signature
+	item := &model.MultisigTx{Hash: mtx.hash}
+	if item.Signatures, err = mtx.encodeSignatures(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling multisig signatures")
+		errorResponse(w, err)
+		return
+	}
+	if err = item.UpdateSignatures(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating multisig signatures")
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, mtx.result())
+}
+
+// getMultisigHandler returns the collected signatures, the signed transaction is returned when all signers have signed it
+func getMultisigHandler(w http.ResponseWriter, r *http.Request) {
+	mtx, err := getMultisigTx(mux.Vars(r)["hash"])
+	if err != nil {
+		getLogger(r).WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting multisig transaction")
+		errorResponse(w, err)
+		return
+	}
+	if mtx == nil {
+		errorResponse(w, errHashNotFound)
+		return
+	}
+	jsonResponse(w, mtx.result())
+}
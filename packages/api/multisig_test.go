@@ -0,0 +1,82 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/hex"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMultisig(t *testing.T) {
+	require.NoError(t, keyLogin(1))
+
+	var privates, signers [][]byte
+	for i := 0; i < 2; i++ {
+		private, public, err := crypto.GenKeyPair()
+		require.NoError(t, err)
+		privates = append(privates, private)
+		signers = append(signers, public)
+	}
+	payload, err := msgpack.Marshal(tx.SmartContract{
+		Header: tx.Header{
+			ID:          1,
+			Time:        time.Now().Unix(),
+			EcosystemID: 1,
+			KeyID:       crypto.Address(signers[0]),
+			NetworkID:   conf.Config.NetworkID,
+			Signers:     signers,
+		},
+		Lang: "en",
+	})
+	require.NoError(t, err)
+
+	var ret multisigResult
+	require.NoError(t, sendPost(`multisig`, &url.Values{"payload": {hex.EncodeToString(payload)}}, &ret))
+	hash := hex.EncodeToString(crypto.DoubleHash(payload))
+	assert.Equal(t, hash, ret.Hash)
+	assert.Len(t, ret.Signers, 2)
+	assert.Empty(t, ret.Signed)
+
+	sign := func(i int, private []byte) error {
+		signature, err := crypto.Sign(private, crypto.DoubleHash(payload))
+		require.NoError(t, err)
+		return sendPost(`multisig/`+hash+`/sign`, &url.Values{
+			"pubkey":    {crypto.PubToHex(signers[i])},
+			"signature": {hex.EncodeToString(signature)},
+		}, &ret)
+	}
+	// the signature of other key isn't accepted
+	assert.Error(t, sign(0, privates[1]))
+	assert.Error(t, sendPost(`multisig/00/sign`, &url.Values{
+		"pubkey": {crypto.PubToHex(signers[0])}, "signature": {"00"},
+	}, &ret))
+
+	require.NoError(t, sign(0, privates[0]))
+	assert.Len(t, ret.Signed, 1)
+	assert.Empty(t, ret.Tx)
+
+	// the signatures are kept by the node until all signers have signed the transaction
+	require.NoError(t, sendGet(`multisig/`+hash, nil, &ret))
+	assert.Len(t, ret.Signed, 1)
+
+	require.NoError(t, sign(1, privates[1]))
+	assert.Len(t, ret.Signed, 2)
+	require.NotEmpty(t, ret.Tx)
+
+	data, err := hex.DecodeString(ret.Tx)
+	require.NoError(t, err)
+	assert.Equal(t, byte(128), data[0])
+}
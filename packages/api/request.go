@@ -22,6 +22,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/signer"
 	"github.com/IBAX-io/go-ibax/packages/utils/tx"
 )
 
@@ -30,6 +31,8 @@ type Connect struct {
 	Root       string
 	PrivateKey []byte
 	PublicKey  string
+	// Signer signs the requests instead of PrivateKey if it is set
+	Signer signer.Signer
 }
 
 type WaitResult struct {
@@ -239,8 +242,8 @@ func (connect *Connect) PostTxResult(name string, form *url.Values) (id int64, m
 		}
 	}
 
-	var publicKey []byte
-	if publicKey, err = crypto.PrivateToPublic(connect.PrivateKey); err != nil {
+	s, err := connect.signer()
+	if err != nil {
 		return
 	}
 	txTime := time.Now().Unix()
@@ -249,16 +252,16 @@ func (connect *Connect) PostTxResult(name string, form *url.Values) (id int64, m
 		txTime = converter.StrToInt64(newTime)
 	}
 
-	data, txhash, err := tx.NewTransaction(tx.SmartContract{
+	data, txhash, err := tx.NewSignerTransaction(tx.SmartContract{
 		Header: tx.Header{
 			ID:          int(contract.ID),
 			Time:        txTime,
 			EcosystemID: 1,
-			KeyID:       crypto.Address(publicKey),
+			KeyID:       crypto.Address(s.PublicKey()),
 			NetworkID:   conf.Config.NetworkID,
 		},
 		Params: params,
-	}, connect.PrivateKey)
+	}, s)
 	if err != nil {
 		return 0, "", err
 	}
@@ -283,6 +286,14 @@ func (connect *Connect) PostTxResult(name string, form *url.Values) (id int64, m
 	return
 }
 
+// signer returns the signer of requests, it is the signer with PrivateKey if Signer isn't set
+func (connect *Connect) signer() (signer.Signer, error) {
+	if connect.Signer != nil {
+		return connect.Signer, nil
+	}
+	return signer.NewLocalSigner(connect.PrivateKey)
+}
+
 func (connect *Connect) Login() error {
 	var (
 		sign []byte
@@ -296,11 +307,15 @@ func (connect *Connect) Login() error {
 		return nil
 	}
 	connect.Auth = ret.Token
-	sign, err = crypto.SignString(hex.EncodeToString(connect.PrivateKey), `LOGIN`+ret.NetworkID+ret.UID)
+	s, err := connect.signer()
+	if err != nil {
+		return err
+	}
+	sign, err = s.Sign([]byte(`LOGIN` + ret.NetworkID + ret.UID))
 	if err != nil {
 		return err
 	}
-	form := url.Values{"pubkey": {connect.PublicKey}, "signature": {hex.EncodeToString(sign)},
+	form := url.Values{"pubkey": {crypto.PubToHex(s.PublicKey())}, "signature": {hex.EncodeToString(sign)},
 		`ecosystem`: {`1`}, "role_id": {"0"}}
 	var logret loginResult
 	err = connect.SendPost(`login`, &form, &logret)
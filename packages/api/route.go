@@ -8,6 +8,8 @@ package api
 import (
 	"net/http"
 
+	"github.com/IBAX-io/go-ibax/packages/conf"
+
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 )
@@ -17,6 +19,7 @@ const corsMaxAge = 600
 type Router struct {
 	main        *mux.Router
 	apiVersions map[string]*mux.Router
+	rpc         *rpcServer
 }
 
 func (r Router) GetAPI() *mux.Router {
@@ -72,6 +75,9 @@ func (m Mode) SetCommonRoutes(r Router) {
 	api.HandleFunc("/metrics/mem", memStatHandler).Methods("GET")
 	api.HandleFunc("/metrics/ban", banStatHandler).Methods("GET")
 
+	m.setCommonRPCMethods(r.rpc)
+	api.Handle("/jsonrpc", r.rpc).Methods("POST")
+	api.HandleFunc("/ws", m.wsHandler).Methods("GET")
 }
 
 func (m Mode) SetBlockchainRoutes(r Router) {
@@ -80,6 +86,7 @@ func (m Mode) SetBlockchainRoutes(r Router) {
 	api.HandleFunc("/metrics/honornodes", honorNodesCountHandler).Methods("GET")
 	api.HandleFunc("/txinfo/{hash}", authRequire(getTxInfoHandler)).Methods("GET")
 	api.HandleFunc("/txinfomultiple", authRequire(getTxInfoMultiHandler)).Methods("GET")
+	api.HandleFunc("/txproof/{hash}", getTxProofHandler).Methods("GET")
 	api.HandleFunc("/appparam/{appID}/{name}", authRequire(m.GetAppParamHandler)).Methods("GET")
 	api.HandleFunc("/appparams/{appID}", authRequire(m.getAppParamsHandler)).Methods("GET")
 	api.HandleFunc("/appcontent/{appID}", authRequire(m.getAppContentHandler)).Methods("GET")
@@ -88,6 +95,10 @@ func (m Mode) SetBlockchainRoutes(r Router) {
 	api.HandleFunc("/assignbalance/{wallet}", authRequire(m.getMyAssignBalanceHandler)).Methods("GET")
 	api.HandleFunc("/block/{id}", getBlockInfoHandler).Methods("GET")
 	api.HandleFunc("/maxblockid", getMaxBlockHandler).Methods("GET")
+	api.HandleFunc("/mempool", getMempoolHandler).Methods("GET")
+	api.HandleFunc("/multisig", authRequire(newMultisigHandler)).Methods("POST")
+	api.HandleFunc("/multisig/{hash}/sign", authRequire(signMultisigHandler)).Methods("POST")
+	api.HandleFunc("/multisig/{hash}", getMultisigHandler).Methods("GET")
 	api.HandleFunc("/blocks", getBlocksTxInfoHandler).Methods("GET")
 	api.HandleFunc("/detailed_blocks", getBlocksDetailedInfoHandler).Methods("GET")
 	api.HandleFunc("/ecosystemparams", authRequire(m.getEcosystemParamsHandler)).Methods("GET")
@@ -95,6 +106,22 @@ func (m Mode) SetBlockchainRoutes(r Router) {
 	api.HandleFunc("/ecosystemparam/{name}", authRequire(m.getEcosystemParamHandler)).Methods("GET")
 	api.HandleFunc("/ecosystemname", getEcosystemNameHandler).Methods("GET")
 	api.HandleFunc("/mintcount/{id}", m.getMintCountHandler).Methods("GET")
+	api.HandleFunc("/events", getEventsHandler).Methods("GET")
+	if conf.Config.Debug.TraceTx {
+		api.HandleFunc("/debug/traceTx/{hash}", nodeOwnerRequire(m.traceTxHandler)).Methods("GET")
+	}
+	api.HandleFunc("/simulate", authRequire(m.simulateHandler)).Methods("POST")
+
+	m.setBlockchainRPCMethods(r.rpc)
+}
+
+func (m Mode) SetLightRoutes(r Router) {
+	api := r.GetAPIVersion("/api/v2")
+	api.HandleFunc("/light/header/{id}", getLightHeaderHandler).Methods("GET")
+	api.HandleFunc("/light/maxblockid", getLightMaxBlockHandler).Methods("GET")
+	api.HandleFunc("/light/verifytxproof", verifyTxProofHandler).Methods("POST")
+
+	m.setLightRPCMethods(r.rpc)
 }
 
 func SetOtherCommonRoutes(api *mux.Router, m Mode) {
@@ -286,6 +313,7 @@ func NewRouter(m Mode) Router {
 	api := Router{
 		main:        r,
 		apiVersions: make(map[string]*mux.Router),
+		rpc:         newRPCServer(),
 	}
 	m.SetCommonRoutes(api)
 	return api
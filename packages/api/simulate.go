@@ -0,0 +1,184 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/script"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type simulateForm struct {
+	Contract string `schema:"contract"`
+	Params   string `schema:"params"`
+	KeyID    int64  `schema:"key_id"`
+	Expedite string `schema:"expedite"`
+}
+
+func (f *simulateForm) Validate(r *http.Request) error {
+	if len(f.Contract) == 0 {
+		return errParamNotFound.Errorf("contract")
+	}
+	// only the node owner can simulate the contract on behalf of another account
+	if client := getClient(r); f.KeyID == 0 || client.KeyID != conf.Config.KeyID {
+		f.KeyID = client.KeyID
+	}
+	return nil
+}
+
+const simulateInterval = time.Second
+
+// simulateLimiter allows the account to start one simulation per interval
+type simulateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[int64]time.Time
+}
+
+var simulateLimit = &simulateLimiter{interval: simulateInterval, last: make(map[int64]time.Time)}
+
+func (l *simulateLimiter) allow(keyID int64, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if last, ok := l.last[keyID]; ok && now.Sub(last) < l.interval {
+		return false
+	}
+	for id, last := range l.last {
+		if now.Sub(last) >= l.interval {
+			delete(l.last, id)
+		}
+	}
+	l.last[keyID] = now
+	return true
+}
+
+// decodeSimulateParams converts the JSON parameters to the types expected by the fields of the contract
+func decodeSimulateParams(fields []*script.FieldInfo, data string) (map[string]interface{}, error) {
+	raw := make(map[string]interface{})
+	if len(data) > 0 {
+		dec := json.NewDecoder(bytes.NewBufferString(data))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+	}
+
+	params := make(map[string]interface{}, len(raw))
+	for _, field := range fields {
+		val, ok := raw[field.Name]
+		if !ok {
+			continue
+		}
+		switch field.Original {
+		case script.DtInt, script.DtAddress:
+			if num, ok := val.(json.Number); ok {
+				v, err := num.Int64()
+				if err != nil {
+					return nil, fmt.Errorf("invalid param '%s': %w", field.Name, err)
+				}
+				val = v
+			}
+		case script.DtFloat:
+			if num, ok := val.(json.Number); ok {
+				v, err := num.Float64()
+				if err != nil {
+					return nil, fmt.Errorf("invalid param '%s': %w", field.Name, err)
+				}
+				val = v
+			}
+		case script.DtMoney:
+			if num, ok := val.(json.Number); ok {
+				val = num.String()
+			}
+		case script.DtBytes:
+			if s, ok := val.(string); ok {
+				v, err := hex.DecodeString(strings.TrimPrefix(s, `0x`))
+				if err != nil {
+					return nil, fmt.Errorf("invalid param '%s': %w", field.Name, err)
+				}
+				val = v
+			}
+		default:
+			val = normalizeSimulateValue(val)
+		}
+		params[field.Name] = val
+	}
+	return params, nil
+}
+
+func normalizeSimulateValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		return v.String()
+	case map[string]interface{}:
+		out := make(map[interface{}]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalizeSimulateValue(item)
+		}
+		return out
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeSimulateValue(item)
+		}
+	}
+	return val
+}
+
+func (m Mode) simulateHandler(w http.ResponseWriter, r *http.Request) {
+	form := &simulateForm{}
+	if err := parseForm(r, form); err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	client := getClient(r)
+	logger := getLogger(r)
+
+	if !simulateLimit.allow(client.KeyID, time.Now()) {
+		errorResponse(w, errSimulateLimit)
+		return
+	}
+
+	contract := smart.GetContract(form.Contract, uint32(client.EcosystemID))
+	if contract == nil {
+		errorResponse(w, errContract.Errorf(form.Contract))
+		return
+	}
+	var fields []*script.FieldInfo
+	if info := contract.Info(); info.Tx != nil {
+		fields = *info.Tx
+	}
+	params, err := decodeSimulateParams(fields, form.Params)
+	if err != nil {
+		errorResponse(w, err, http.StatusBadRequest)
+		return
+	}
+
+	result, err := m.TxSimulator.SimulateTx(form.Contract, client.EcosystemID, form.KeyID, params, form.Expedite, logger)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.ContractError, "error": err}).Error("simulating contract")
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, result)
+}
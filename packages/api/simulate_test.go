@@ -0,0 +1,95 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/script"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func simulateRequest(client *Client, values url.Values) *http.Request {
+	r := httptest.NewRequest("POST", "/api/v2/simulate", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return setClient(r, client)
+}
+
+func TestSimulateKeyID(t *testing.T) {
+	conf.Config.KeyID = 100
+	defer func() { conf.Config.KeyID = 0 }()
+
+	for _, item := range []struct {
+		client, keyID, want int64
+	}{
+		{client: 42, keyID: 0, want: 42},
+		{client: 42, keyID: 7, want: 42},
+		{client: 100, keyID: 7, want: 7},
+		{client: 100, keyID: 0, want: 100},
+	} {
+		form := &simulateForm{}
+		r := simulateRequest(&Client{KeyID: item.client, EcosystemID: 1}, url.Values{
+			"contract": {"Greet"},
+			"key_id":   {strconv.FormatInt(item.keyID, 10)},
+		})
+		require.NoError(t, parseForm(r, form))
+		assert.Equal(t, item.want, form.KeyID, item)
+	}
+}
+
+func TestSimulateLimit(t *testing.T) {
+	limiter := &simulateLimiter{interval: time.Second, last: make(map[int64]time.Time)}
+	now := time.Now()
+	assert.True(t, limiter.allow(1, now))
+	assert.False(t, limiter.allow(1, now.Add(time.Second/2)))
+	assert.True(t, limiter.allow(2, now.Add(time.Second/2)))
+	assert.True(t, limiter.allow(1, now.Add(time.Second)))
+	assert.Len(t, limiter.last, 2)
+	assert.True(t, limiter.allow(3, now.Add(2*time.Second)))
+	assert.Len(t, limiter.last, 1)
+
+	client := &Client{KeyID: 77, EcosystemID: 1}
+	values := url.Values{"contract": {"UnknownSimulated"}}
+
+	w := httptest.NewRecorder()
+	Mode{}.simulateHandler(w, simulateRequest(client, values))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	Mode{}.simulateHandler(w, simulateRequest(client, values))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), errSimulateLimit.Err)
+}
+
+func TestDecodeSimulateParams(t *testing.T) {
+	fields := []*script.FieldInfo{
+		{Name: "Amount", Original: script.DtMoney},
+		{Name: "ID", Original: script.DtInt},
+		{Name: "Data", Original: script.DtBytes},
+		{Name: "Info", Original: script.DtMap},
+	}
+	params, err := decodeSimulateParams(fields,
+		`{"Amount": 1000000000000000000000, "ID": 12, "Data": "0x0102", "Info": {"n": 1}, "Other": 1}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"Amount": "1000000000000000000000",
+		"ID":     int64(12),
+		"Data":   []byte{1, 2},
+		"Info":   map[interface{}]interface{}{"n": int64(1)},
+	}, params)
+
+	_, err = decodeSimulateParams(fields, `{"ID": 1.5}`)
+	assert.Error(t, err)
+}
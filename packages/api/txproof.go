@@ -0,0 +1,51 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+func getTxProofHandler(w http.ResponseWriter, r *http.Request) {
+	hash, err := hex.DecodeString(mux.Vars(r)["hash"])
+	if err != nil {
+		errorResponse(w, errHashWrong)
+		return
+	}
+
+	logger := getLogger(r)
+	ltx := &model.LogTransaction{}
+	found, err := ltx.GetByHash(hash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting log transaction by hash")
+		errorResponse(w, err)
+		return
+	}
+	if !found {
+		errorResponse(w, errHashNotFound)
+		return
+	}
+
+	proof, err := block.GetTxProof(ltx.Block, hash)
+	if err == block.ErrTxNotInBlock {
+		errorResponse(w, errHashNotFound)
+		return
+	}
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
+	jsonResponse(w, proof)
+}
@@ -0,0 +1,338 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/pubsub"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	wsWriteTimeout    = 10 * time.Second
+	wsPongTimeout     = 60 * time.Second
+	wsPingPeriod      = wsPongTimeout * 9 / 10
+	wsMaxMessageSize  = 4096
+	wsMaxSubscribes   = 32
+	wsMaxBufferSize   = 1024
+	wsSendQueueSize   = 64
+	wsMethodSubscribe = "subscribe"
+	wsMethodUnsubscr  = "unsubscribe"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     checkWSOrigin,
+}
+
+// checkWSOrigin allows the clients without Origin header, the browser clients of the same origin
+// and of the origins from the config
+func checkWSOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range conf.Config.WebSocket.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+type wsRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params map[string]string `json:"params"`
+}
+
+type wsSubscribeResult struct {
+	Subscription uint64 `json:"subscription"`
+}
+
+type wsResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  *errType        `json:"error,omitempty"`
+}
+
+type wsEvent struct {
+	Subscription uint64      `json:"subscription"`
+	Topic        string      `json:"topic"`
+	Data         interface{} `json:"data"`
+	Missed       uint64      `json:"missed,omitempty"`
+}
+
+var (
+	errWSTopic       = errType{"E_WSTOPIC", "Unknown topic %s", http.StatusBadRequest}
+	errWSMethod      = errType{"E_WSMETHOD", "Unknown method %s", http.StatusBadRequest}
+	errWSLimit       = errType{"E_WSLIMIT", "The number of subscriptions exceeds %d", http.StatusBadRequest}
+	errWSUnknownSubs = errType{"E_WSUNKNOWNSUBS", "Unknown subscription %s", http.StatusBadRequest}
+)
+
+type wsConnection struct {
+	conn   *websocket.Conn
+	client *Client
+	logger *log.Entry
+	send   chan interface{}
+	done   chan struct{}
+
+	mu   sync.Mutex
+	subs map[uint64]*pubsub.Subscription
+}
+
+// wsHandler serves subscriptions to node events over WebSocket.
+// The client can be authorized by the Authorization header or by the token parameter
+func (m Mode) wsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := getLogger(r)
+	client := getClient(r)
+
+	if token := r.URL.Query().Get("token"); len(token) > 0 && (client == nil || client.KeyID == 0) {
+		jwtToken, err := parseJWTToken(jwtPrefix + token)
+		if err != nil || jwtToken == nil || !jwtToken.Valid {
+			errorResponse(w, errToken)
+			return
+		}
+		if client, err = getClientFromToken(jwtToken, m.EcosysNameGetter); err != nil {
+			errorResponse(w, err)
+			return
+		}
+		if client == nil {
+			errorResponse(w, errToken)
+			return
+		}
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("upgrading websocket connection")
+		return
+	}
+
+	c := &wsConnection{
+		conn:   conn,
+		client: client,
+		logger: logger,
+		send:   make(chan interface{}, wsSendQueueSize),
+		done:   make(chan struct{}),
+		subs:   make(map[uint64]*pubsub.Subscription),
+	}
+	go c.writeLoop()
+	c.readLoop()
+}
+
+func (c *wsConnection) readLoop() {
+	defer c.close()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		req := &wsRequest{}
+		if err := c.conn.ReadJSON(req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				c.logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Debug("reading websocket message")
+			}
+			return
+		}
+
+		var (
+			result interface{}
+			err    error
+		)
+		switch req.Method {
+		case wsMethodSubscribe:
+			result, err = c.subscribe(req.Params)
+		case wsMethodUnsubscr:
+			result, err = c.unsubscribe(req.Params)
+		default:
+			err = errWSMethod.Errorf(req.Method)
+		}
+
+		resp := &wsResponse{ID: req.ID, Result: result}
+		if err != nil {
+			et, ok := err.(errType)
+			if !ok {
+				et = errServer
+				et.Message = err.Error()
+			}
+			resp.Error = &et
+		}
+		if !c.push(resp) {
+			return
+		}
+	}
+}
+
+func (c *wsConnection) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Debug("writing websocket message")
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(wsWriteTimeout))
+			return
+		}
+	}
+}
+
+// push queues the message for sending, it returns false if the connection has been closed
+func (c *wsConnection) push(msg interface{}) bool {
+	select {
+	case c.send <- msg:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+func (c *wsConnection) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+		close(c.done)
+	}
+	for id, s := range c.subs {
+		s.Unsubscribe()
+		delete(c.subs, id)
+	}
+}
+
+func (c *wsConnection) subscribe(params map[string]string) (interface{}, error) {
+	topic := params["topic"]
+	size := wsSendQueueSize
+	if v, ok := params["buffer"]; ok {
+		size = converter.StrToInt(v)
+		if size <= 0 || size > wsMaxBufferSize {
+			size = wsMaxBufferSize
+		}
+	}
+
+	filter := pubsub.Filter{}
+	switch topic {
+	case pubsub.TopicNewBlock:
+		filter["ecosystem"] = params["ecosystem"]
+	case pubsub.TopicTxStatus:
+		if len(params["hash"]) == 0 {
+			return nil, errUndefineval.Errorf("hash")
+		}
+		filter["hash"] = params["hash"]
+	case pubsub.TopicTableChanged:
+		if len(params["table"]) == 0 {
+			return nil, errUndefineval.Errorf("table")
+		}
+		client := Client{EcosystemID: 1}
+		if c.client != nil {
+			client = *c.client
+		}
+		if ecosystem := params["ecosystem"]; len(ecosystem) > 0 {
+			if client.EcosystemID = converter.StrToInt64(ecosystem); client.EcosystemID <= 0 {
+				return nil, errEcosystem.Errorf(client.EcosystemID)
+			}
+		}
+		// the changes are sent only to the clients which can read the table
+		if _, _, err := checkAccess(params["table"], "", &client); err != nil {
+			return nil, errPermission
+		}
+		filter["ecosystem"] = strconv.FormatInt(client.EcosystemID, 10)
+		filter["table"] = params["table"]
+	case pubsub.TopicNotifications:
+		// clients can receive only their own notifications
+		if c.client == nil || c.client.KeyID == 0 {
+			return nil, errUnauthorized
+		}
+		filter["ecosystem"] = strconv.FormatInt(c.client.EcosystemID, 10)
+		filter["account"] = c.client.AccountID
+	default:
+		return nil, errWSTopic.Errorf(topic)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.subs) >= wsMaxSubscribes {
+		return nil, errWSLimit.Errorf(wsMaxSubscribes)
+	}
+
+	s := pubsub.Subscribe(topic, filter, size)
+	c.subs[s.ID] = s
+	go c.forward(s)
+
+	return &wsSubscribeResult{Subscription: s.ID}, nil
+}
+
+func (c *wsConnection) unsubscribe(params map[string]string) (interface{}, error) {
+	id := converter.StrToUint64(params["subscription"])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.subs[id]
+	if !ok {
+		return nil, errWSUnknownSubs.Errorf(params["subscription"])
+	}
+	s.Unsubscribe()
+	delete(c.subs, id)
+	return true, nil
+}
+
+// forward sends events of the subscription to the connection. If the client reads slowly
+// then the queue of the subscription overflows and the count of missed events is reported
+func (c *wsConnection) forward(s *pubsub.Subscription) {
+	for e := range s.C {
+		if !c.push(&wsEvent{
+			Subscription: s.ID,
+			Topic:        e.Topic,
+			Data:         e.Data,
+			Missed:       s.Dropped(),
+		}) {
+			return
+		}
+	}
+}
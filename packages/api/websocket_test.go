@@ -0,0 +1,43 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWSOrigin(t *testing.T) {
+	conf.Config.WebSocket.AllowedOrigins = []string{"https://wallet.example.com/"}
+	defer func() { conf.Config.WebSocket.AllowedOrigins = nil }()
+
+	for origin, allowed := range map[string]bool{
+		"":                             true,
+		"http://node.example.com":      true,
+		"https://wallet.example.com":   true,
+		"https://evil.example.com":     false,
+		"http://node.example.com.evil": false,
+	} {
+		r := httptest.NewRequest("GET", "http://node.example.com/api/v2/ws", nil)
+		if len(origin) > 0 {
+			r.Header.Set("Origin", origin)
+		}
+		assert.Equal(t, allowed, checkWSOrigin(r), origin)
+	}
+}
+
+func TestWSTokenWithoutClient(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/v2/ws?token=wrong", nil)
+	w := httptest.NewRecorder()
+	Mode{}.wsHandler(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), errToken.Err)
+}
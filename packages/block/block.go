@@ -19,6 +19,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/mempool"
 	"github.com/IBAX-io/go-ibax/packages/model"
 	"github.com/IBAX-io/go-ibax/packages/protocols"
 	"github.com/IBAX-io/go-ibax/packages/script"
@@ -34,6 +35,9 @@ import (
 
 var (
 	ErrIncorrectRollbackHash = errors.New("Rollback hash doesn't match")
+	ErrIncorrectStateRoot    = errors.New("State root doesn't match")
+	ErrIncorrectPrevHash     = errors.New("Previous block hash doesn't match")
+	ErrIncorrectBlockVersion = utils.WithBan(errors.New("Incorrect block version"))
 	ErrEmptyBlock            = errors.New("Block doesn't contain transactions")
 	ErrIncorrectBlockTime    = utils.WithBan(errors.New("Incorrect block time"))
 )
@@ -43,12 +47,15 @@ type Block struct {
 	Header            utils.BlockData
 	PrevHeader        *utils.BlockData
 	PrevRollbacksHash []byte
+	PrevStateRoot     []byte
 	MrklRoot          []byte
 	BinData           []byte
 	Transactions      []*transaction.Transaction
 	SysUpdate         bool
 	GenBlock          bool // it equals true when we are generating a new block
 	Notifications     []types.Notifications
+
+	checkedSign *signCheck
 }
 
 func (b Block) String() string {
@@ -130,6 +137,7 @@ func (b *Block) PlaySafe() error {
 	for _, q := range b.Notifications {
 		q.Send()
 	}
+	b.PublishEvents()
 	return nil
 }
 
@@ -138,14 +146,14 @@ func (b *Block) repeatMarshallBlock() error {
 	for _, tr := range b.Transactions {
 		trData = append(trData, tr.TxFullData)
 	}
-	NodePrivateKey, _ := utils.GetNodeKeys()
-	if len(NodePrivateKey) < 1 {
+	nodeSigner := syspar.GetNodeSigner()
+	if nodeSigner == nil {
 		err := errors.New(`empty private node key`)
 		log.WithFields(log.Fields{"type": consts.NodePrivateKeyFilename, "error": err}).Error("reading node private key")
 		return err
 	}
 
-	newBlockData, err := MarshallBlock(&b.Header, trData, b.PrevHeader, NodePrivateKey)
+	newBlockData, err := MarshallBlock(&b.Header, trData, b.PrevHeader, nodeSigner)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("marshalling new block")
 		return err
@@ -189,6 +197,8 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) (batchErr error) {
 			batchErr = err
 			return
 		}
+		// the transactions which have been used in the block are removed from the mempool
+		mempool.GetPool().Remove(playTxs.UsedTx...)
 	}()
 
 	for curTx, t := range b.Transactions {
@@ -209,7 +219,13 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) (batchErr error) {
 		t.GenBlock = b.GenBlock
 		t.TimeLimit = timeLimit
 		t.PrevBlock = b.PrevHeader
-		msg, flush, err = t.Play(curTx)
+		if b.Header.Version >= consts.BvValidUntil {
+			// the expired transaction is skipped in the generated block
+			err = t.CheckValidUntil(b.Header.Time)
+		}
+		if err == nil {
+			msg, flush, err = t.Play(curTx)
+		}
 		if err == nil && t.TxSmart != nil {
 			err = limits.CheckLimit(t)
 		}
@@ -289,6 +305,14 @@ func (b *Block) Play(dbTransaction *model.DbTransaction) (batchErr error) {
 		proccessedTx = append(proccessedTx, t)
 	}
 
+	if b.Header.Version >= consts.BvStateRoot {
+		stateRoot, err := updateState(dbTransaction, playTxs.Rts)
+		if err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("calculating state root")
+			return err
+		}
+		b.Header.StateRoot = stateRoot
+	}
 	return nil
 }
 
@@ -308,6 +332,11 @@ func (b *Block) Check() error {
 		logger.WithFields(log.Fields{"type": consts.ParameterExceeded}).Error("block time is larger than now")
 		return ErrIncorrectBlockTime
 	}
+	// the state root is included in the blocks from the height of the system parameter
+	if (b.Header.Version >= consts.BvStateRoot) != syspar.IsStateRootBlock(b.Header.BlockID) {
+		logger.WithFields(log.Fields{"type": consts.BlockError}).Error("incorrect block version")
+		return ErrIncorrectBlockVersion
+	}
 
 	// is this block too early? Allowable error = error_time
 	if b.PrevHeader != nil {
@@ -322,6 +351,13 @@ func (b *Block) Check() error {
 			logger.WithFields(log.Fields{"type": consts.BlockError, "error": err}).Warn("incorrect block time")
 			return utils.WithBan(fmt.Errorf("%s %d", ErrIncorrectBlockTime, b.PrevHeader.Time))
 		}
+
+		// the state root of previous block must be equal to our state after playing that block
+		if b.Header.Version >= consts.BvStateRoot && !bytes.Equal(b.PrevStateRoot, b.PrevHeader.StateRoot) {
+			logger.WithFields(log.Fields{"type": consts.BlockError, "state_root": fmt.Sprintf("%x", b.PrevStateRoot),
+				"prev_state_root": fmt.Sprintf("%x", b.PrevHeader.StateRoot)}).Error("state root doesn't match")
+			return ErrIncorrectStateRoot
+		}
 	}
 
 	// check each transaction
@@ -342,6 +378,11 @@ func (b *Block) Check() error {
 			return utils.WithBan(utils.ErrInfo(fmt.Errorf("max_block_user_transactions")))
 		}
 
+		// the cancel only drops the pending transactions, it can't be played
+		if t.TxType == consts.TxTypeCancel {
+			return utils.WithBan(utils.ErrInfo(fmt.Errorf("cancel transaction %s in the block", hexHash)))
+		}
+
 		if err := t.CheckTime(b.Header.Time); err != nil {
 			return errors.Wrap(err, "check transaction")
 		}
@@ -377,18 +418,27 @@ func (b *Block) CheckHash() (bool, error) {
 		}
 
 		signSource := b.Header.ForSign(b.PrevHeader, b.MrklRoot)
+		if b.isSignChecked(nodePublicKey, signSource) {
+			return true, nil
+		}
 
 		resultCheckSign, err := utils.CheckSign(
 			[][]byte{nodePublicKey},
 			[]byte(signSource),
 			b.Header.Sign,
 			true)
+		if err == nil && !resultCheckSign {
+			err = crypto.ErrIncorrectSign
+		}
 
 		if err != nil {
 			if err == crypto.ErrIncorrectSign {
 				if !bytes.Equal(b.PrevRollbacksHash, b.PrevHeader.RollbacksHash) {
 					return false, ErrIncorrectRollbackHash
 				}
+				// the hash of previous block is signed, so the sign doesn't match in the case of fork
+				logger.WithFields(log.Fields{"error": err, "type": consts.CryptoError}).Error("checking block header sign")
+				return false, errors.Wrapf(ErrIncorrectPrevHash, "block.PrevHeader.BlockID: %d / block.PrevHeader.Hash: %x", b.PrevHeader.BlockID, b.PrevHeader.Hash)
 			}
 			logger.WithFields(log.Fields{"error": err, "type": consts.CryptoError}).Error("checking block header sign")
 			return false, utils.ErrInfo(fmt.Errorf("err: %v / block.PrevHeader.BlockID: %d /  block.PrevHeader.Hash: %x / ", err, b.PrevHeader.BlockID, b.PrevHeader.Hash))
@@ -428,22 +478,10 @@ var (
 
 // ProcessBlockWherePrevFromBlockchainTable is processing block with in table previous block
 func ProcessBlockWherePrevFromBlockchainTable(data []byte, checkSize bool) (*Block, error) {
-	if checkSize && int64(len(data)) > syspar.GetMaxBlockSize() {
-		log.WithFields(log.Fields{"check_size": checkSize, "size": len(data), "max_size": syspar.GetMaxBlockSize(), "type": consts.ParameterExceeded}).Error("binary block size exceeds max block size")
-		return nil, ErrMaxBlockSize
-	}
-
-	buf := bytes.NewBuffer(data)
-	if buf.Len() == 0 {
-		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("buffer is empty")
-		return nil, ErrZeroBlockSize
-	}
-
-	block, err := UnmarshallBlock(buf, true)
+	block, err := ParseBlock(data, checkSize)
 	if err != nil {
-		return nil, errors.Wrap(ErrUnmarshallBlock, err.Error())
+		return nil, err
 	}
-	block.BinData = data
 
 	if err := block.readPreviousBlockFromBlockchainTable(); err != nil {
 		return nil, err
@@ -104,6 +104,7 @@ func InsertIntoBlockchain(transaction *model.DbTransaction, block *Block) error
 		NodePosition:  block.Header.NodePosition,
 		Time:          block.Header.Time,
 		RollbacksHash: rollbacksHash,
+		StateRoot:     block.Header.StateRoot,
 		Tx:            int32(len(block.Transactions)),
 	}
 	validBlockTime := true
@@ -125,6 +126,10 @@ func InsertIntoBlockchain(transaction *model.DbTransaction, block *Block) error
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating info block")
 			return err
 		}
+		if err = model.UpdStateRoot(transaction, block.Header.StateRoot); err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating state root of info block")
+			return err
+		}
 	} else {
 		err := fmt.Errorf("invalid block time: %d", block.Header.Time)
 		log.WithFields(log.Fields{"type": consts.BlockError, "error": err}).Error("invalid block time")
@@ -152,6 +157,7 @@ func GetBlockDataFromBlockChain(blockID int64) (*utils.BlockData, error) {
 	BlockData = &header
 	BlockData.Hash = block.Hash
 	BlockData.RollbacksHash = block.RollbacksHash
+	BlockData.StateRoot = block.StateRoot
 	return BlockData, nil
 }
 
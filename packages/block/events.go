@@ -0,0 +1,75 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package block
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/pubsub"
+)
+
+// PublishEvents sends events about the committed block to subscribers
+func (b *Block) PublishEvents() {
+	if pubsub.HasSubscribers(pubsub.TopicNewBlock) {
+		pubsub.Publish(pubsub.NewBlock(&pubsub.BlockEvent{
+			BlockID:      b.Header.BlockID,
+			Hash:         hex.EncodeToString(b.Header.Hash),
+			Time:         b.Header.Time,
+			EcosystemID:  b.Header.EcosystemID,
+			KeyID:        b.Header.KeyID,
+			NodePosition: b.Header.NodePosition,
+			TxCount:      len(b.Transactions),
+		}))
+	}
+
+	txStatus := pubsub.HasSubscribers(pubsub.TopicTxStatus)
+	tableChanged := pubsub.HasSubscribers(pubsub.TopicTableChanged)
+	if !txStatus && !tableChanged {
+		return
+	}
+
+	for _, t := range b.Transactions {
+		if txStatus {
+			pubsub.Publish(pubsub.NewTxStatus(t.TxHash, b.Header.BlockID, ""))
+		}
+		if !tableChanged {
+			continue
+		}
+
+		tables := make(map[string]*pubsub.TableChangedEvent)
+		order := make([]string, 0)
+		for _, rt := range t.RollBackTx {
+			e, ok := tables[rt.NameTable]
+			if !ok {
+				ecosystem, name := splitTableName(rt.NameTable)
+				e = &pubsub.TableChangedEvent{
+					Ecosystem: ecosystem,
+					Table:     name,
+					BlockID:   b.Header.BlockID,
+					TxHash:    hex.EncodeToString(t.TxHash),
+				}
+				tables[rt.NameTable] = e
+				order = append(order, rt.NameTable)
+			}
+			e.RowIDs = append(e.RowIDs, rt.TableID)
+		}
+		for _, name := range order {
+			pubsub.Publish(pubsub.NewTableChanged(tables[name]))
+		}
+	}
+}
+
+// splitTableName splits the name of the table like 1_keys to the ecosystem and the name
+func splitTableName(table string) (int64, string) {
+	if i := strings.IndexByte(table, '_'); i > 0 {
+		if ecosystem := converter.StrToInt64(table[:i]); ecosystem > 0 {
+			return ecosystem, table[i+1:]
+		}
+	}
+	return 0, table
+}
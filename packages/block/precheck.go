@@ -0,0 +1,85 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package block
+
+import (
+	"bytes"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// signCheck is the sign of block which has been checked before the previous block is played
+type signCheck struct {
+	key    []byte
+	source string
+}
+
+// ParseBlock unmarshals the block without reading the previous block from the blockchain table,
+// so the blocks can be parsed in parallel
+func ParseBlock(data []byte, checkSize bool) (*Block, error) {
+	if checkSize && int64(len(data)) > syspar.GetMaxBlockSize() {
+		log.WithFields(log.Fields{"check_size": checkSize, "size": len(data), "max_size": syspar.GetMaxBlockSize(), "type": consts.ParameterExceeded}).Error("binary block size exceeds max block size")
+		return nil, ErrMaxBlockSize
+	}
+
+	buf := bytes.NewBuffer(data)
+	if buf.Len() == 0 {
+		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("buffer is empty")
+		return nil, ErrZeroBlockSize
+	}
+
+	block, err := UnmarshallBlock(buf, true)
+	if err != nil {
+		return nil, errors.Wrap(ErrUnmarshallBlock, err.Error())
+	}
+	block.BinData = data
+	return block, nil
+}
+
+// prevData returns the data of previous block which is used in the hash and the sign of block
+func (b *Block) prevData(prevHash []byte) *utils.BlockData {
+	return &utils.BlockData{
+		BlockID:       b.Header.BlockID - 1,
+		Hash:          prevHash,
+		RollbacksHash: b.PrevRollbacksHash,
+		StateRoot:     b.PrevStateRoot,
+	}
+}
+
+// HashWithPrev returns the hash of block which follows the block with prevHash
+func (b *Block) HashWithPrev(prevHash []byte) []byte {
+	return crypto.DoubleHash([]byte(b.Header.ForSha(b.prevData(prevHash), b.MrklRoot)))
+}
+
+// PreCheckSign checks the sign of block with the hash of previous block before the previous block is played.
+// CheckHash doesn't check the sign again if the key of node and the signed data haven't been changed.
+func (b *Block) PreCheckSign(prevHash []byte) error {
+	key, err := syspar.GetNodePublicKeyByPosition(b.Header.NodePosition)
+	if err != nil {
+		return err
+	}
+	source := b.Header.ForSign(b.prevData(prevHash), b.MrklRoot)
+	ok, err := utils.CheckSign([][]byte{key}, []byte(source), b.Header.Sign, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return crypto.ErrIncorrectSign
+	}
+	b.checkedSign = &signCheck{key: key, source: source}
+	return nil
+}
+
+// isSignChecked returns true if the sign has been checked by PreCheckSign with the same key and data
+func (b *Block) isSignChecked(key []byte, source string) bool {
+	return b.checkedSign != nil && b.checkedSign.source == source && bytes.Equal(b.checkedSign.key, key)
+}
@@ -0,0 +1,79 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package block
+
+import (
+	"bytes"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrTxNotInBlock is returned when the transaction is absent in the block
+var ErrTxNotInBlock = errors.New("Transaction has not been found in the block")
+
+// GetTxProof returns the Merkle proof of the inclusion of transaction in the block
+func GetTxProof(blockID int64, txHash []byte) (*utils.TxProof, error) {
+	logger := log.WithFields(log.Fields{"block_id": blockID, "tx_hash": txHash})
+	b := &model.Block{}
+	found, err := b.Get(blockID)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting block")
+		return nil, err
+	}
+	if !found {
+		return nil, ErrTxNotInBlock
+	}
+	bl, err := UnmarshallBlock(bytes.NewBuffer(b.Data), false)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("unmarshalling block")
+		return nil, err
+	}
+
+	var txData []byte
+	index := -1
+	leaves := make([][]byte, 0, len(bl.Transactions))
+	for _, t := range bl.Transactions {
+		if bytes.Equal(t.TxHash, txHash) {
+			index = len(leaves)
+			txData = t.TxFullData
+		}
+		leaves = append(leaves, utils.MrklLeaf(t.TxFullData))
+	}
+	if index < 0 {
+		return nil, ErrTxNotInBlock
+	}
+	path, err := utils.MerkleTreeProof(leaves, index)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := &utils.BlockData{}
+	if blockID > 1 {
+		if prev, err = GetBlockDataFromBlockChain(blockID - 1); err != nil {
+			return nil, err
+		}
+	}
+	return &utils.TxProof{
+		BlockID:           bl.Header.BlockID,
+		Time:              bl.Header.Time,
+		EcosystemID:       bl.Header.EcosystemID,
+		KeyID:             bl.Header.KeyID,
+		NodePosition:      bl.Header.NodePosition,
+		Version:           bl.Header.Version,
+		PrevHash:          prev.Hash,
+		PrevRollbacksHash: prev.RollbacksHash,
+		PrevStateRoot:     prev.StateRoot,
+		MrklRoot:          bl.MrklRoot,
+		TxHash:            txHash,
+		TxData:            txData,
+		Path:              path,
+	}, nil
+}
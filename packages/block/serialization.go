@@ -9,9 +9,11 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/signer"
 	"github.com/IBAX-io/go-ibax/packages/transaction"
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
@@ -19,7 +21,12 @@ import (
 )
 
 // MarshallBlock is marshalling block
-func MarshallBlock(header *utils.BlockData, trData [][]byte, prev *utils.BlockData, key string) ([]byte, error) {
+// the block is signed by the signer of node key, it isn't signed if s is nil.
+// The version of the signed block is defined by its height.
+func MarshallBlock(header *utils.BlockData, trData [][]byte, prev *utils.BlockData, s signer.Signer) ([]byte, error) {
+	if s != nil {
+		header.Version = syspar.GetBlockVersion(header.BlockID)
+	}
 	var mrklArray [][]byte
 	var blockDataTx []byte
 	var signed []byte
@@ -30,7 +37,7 @@ func MarshallBlock(header *utils.BlockData, trData [][]byte, prev *utils.BlockDa
 		blockDataTx = append(blockDataTx, converter.EncodeLengthPlusData(tr)...)
 	}
 
-	if key != "" {
+	if s != nil {
 		if len(mrklArray) == 0 {
 			mrklArray = append(mrklArray, []byte("0"))
 		}
@@ -39,7 +46,7 @@ func MarshallBlock(header *utils.BlockData, trData [][]byte, prev *utils.BlockDa
 			return nil, err
 		}
 		signSource := header.ForSign(prev, mrklRoot)
-		signed, err = crypto.SignString(key, signSource)
+		signed, err = s.Sign([]byte(signSource))
 		if err != nil {
 			logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing block")
 			return nil, err
@@ -56,6 +63,9 @@ func MarshallBlock(header *utils.BlockData, trData [][]byte, prev *utils.BlockDa
 	buf.Write(converter.EncodeLenInt64InPlace(header.KeyID))
 	buf.Write(converter.DecToBin(header.NodePosition, 1))
 	buf.Write(converter.EncodeLengthPlusData(prev.RollbacksHash))
+	if header.Version >= consts.BvStateRoot {
+		buf.Write(converter.EncodeLengthPlusData(prev.StateRoot))
+	}
 
 	// fill signature
 	buf.Write(converter.EncodeLengthPlusData(signed))
@@ -118,6 +128,7 @@ func UnmarshallBlock(blockBuffer *bytes.Buffer, fillData bool) (*Block, error) {
 	return &Block{
 		Header:            header,
 		PrevRollbacksHash: prev.RollbacksHash,
+		PrevStateRoot:     prev.StateRoot,
 		Transactions:      transactions,
 		MrklRoot:          mrkl,
 	}, nil
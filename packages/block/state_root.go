@@ -0,0 +1,436 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package block
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+)
+
+// systemTablePrefix is the prefix of rollback records which don't change the rows of tables
+const systemTablePrefix = `@`
+
+// nullValue is the canonical encoding of NULL, it can't be the value of text column
+const nullValue = "\x00"
+
+// columnKind defines the canonical encoding of the values of the column. The drivers of
+// storage backends return the values of the same type in the different formats, so the values
+// are converted to the same form and the state root doesn't depend on the backend.
+type columnKind int
+
+const (
+	kindText columnKind = iota
+	kindInt
+	kindBool
+	kindBytes
+	kindJSON
+	kindTime
+)
+
+// timeLayouts are the formats of timestamps returned by the drivers
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// stateRow is the row of the table which has been changed in the block
+type stateRow struct {
+	table     string
+	id        string
+	ecosystem string
+}
+
+func (r stateRow) key() string {
+	return r.table + `,` + r.id + `,` + r.ecosystem
+}
+
+// newStateRow gets the row from the rollback record. The rows of the tables shared by
+// all ecosystems are identified by the ecosystem in the same way as in rollback.
+func newStateRow(rt *model.RollbackTx) (stateRow, error) {
+	row := stateRow{table: rt.NameTable, id: rt.TableID}
+	under := strings.IndexByte(rt.NameTable, '_')
+	if under <= 0 || !converter.FirstEcosystemTables[rt.NameTable[under+1:]] {
+		return row, nil
+	}
+	row.table = `1_` + rt.NameTable[under+1:]
+	if len(rt.Data) == 0 {
+		if comma := strings.IndexByte(rt.TableID, ','); comma > 0 {
+			row.id, row.ecosystem = rt.TableID[:comma], rt.TableID[comma+1:]
+		}
+		return row, nil
+	}
+	var data map[string]string
+	if err := json.Unmarshal([]byte(rt.Data), &data); err != nil {
+		return row, err
+	}
+	row.ecosystem = data[`ecosystem`]
+	return row, nil
+}
+
+// changedRows returns the unique rows changed by the rollback records sorted by the table and the id
+func changedRows(rts []*model.RollbackTx) ([]stateRow, error) {
+	rows := make(map[string]stateRow)
+	for _, rt := range rts {
+		if strings.HasPrefix(rt.NameTable, systemTablePrefix) {
+			continue
+		}
+		row, err := newStateRow(rt)
+		if err != nil {
+			return nil, err
+		}
+		rows[row.key()] = row
+	}
+	list := make([]stateRow, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, row)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].key() < list[j].key()
+	})
+	return list, nil
+}
+
+// columnKinds are the kinds of the column types of PostgreSQL and SQLite, the other types are text
+var columnKinds = map[string]columnKind{
+	"int":                         kindInt,
+	"int2":                        kindInt,
+	"int4":                        kindInt,
+	"int8":                        kindInt,
+	"integer":                     kindInt,
+	"smallint":                    kindInt,
+	"bigint":                      kindInt,
+	"bool":                        kindBool,
+	"boolean":                     kindBool,
+	"bytea":                       kindBytes,
+	"blob":                        kindBytes,
+	"json":                        kindJSON,
+	"jsonb":                       kindJSON,
+	"date":                        kindTime,
+	"datetime":                    kindTime,
+	"timestamp":                   kindTime,
+	"timestamptz":                 kindTime,
+	"timestamp without time zone": kindTime,
+	"timestamp with time zone":    kindTime,
+}
+
+// columnKindOf returns the kind of the column by the type name of PostgreSQL or SQLite,
+// the size of the type like varchar(255) is ignored
+func columnKindOf(typeName string) columnKind {
+	name := strings.ToLower(typeName)
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+	return columnKinds[strings.TrimSpace(name)]
+}
+
+// canonicalValue returns the value in the canonical encoding of the kind of column.
+// The value is hashed as is if it can't be parsed as the value of its kind.
+func canonicalValue(kind columnKind, value []byte) string {
+	if value == nil {
+		return nullValue
+	}
+	switch kind {
+	case kindInt:
+		if n, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case kindBool:
+		if b, err := strconv.ParseBool(string(value)); err == nil {
+			return strconv.FormatBool(b)
+		}
+	case kindBytes:
+		// PostgreSQL returns bytea in the hex format if it is read as text
+		if bytes.HasPrefix(value, []byte(`\x`)) {
+			if data, err := hex.DecodeString(string(value[2:])); err == nil {
+				return string(data)
+			}
+		}
+	case kindJSON:
+		var v interface{}
+		dec := json.NewDecoder(bytes.NewReader(value))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err == nil {
+			// the keys of objects are sorted by Marshal
+			if out, err := json.Marshal(v); err == nil {
+				return string(out)
+			}
+		}
+	case kindTime:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, string(value)); err == nil {
+				return t.UTC().Format(time.RFC3339Nano)
+			}
+		}
+	}
+	return string(value)
+}
+
+// canonicalRow returns the values of the row in the canonical encoding of the column types
+func canonicalRow(columns, typeNames []string, values [][]byte) map[string]string {
+	row := make(map[string]string, len(columns))
+	for i, column := range columns {
+		row[column] = canonicalValue(columnKindOf(typeNames[i]), values[i])
+	}
+	return row
+}
+
+// scanRow returns the canonical values of the current row
+func scanRow(rows *sql.Rows) (map[string]string, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(types))
+	typeNames := make([]string, len(types))
+	values := make([][]byte, len(types))
+	scanArgs := make([]interface{}, len(types))
+	for i, item := range types {
+		columns[i], typeNames[i] = item.Name(), item.DatabaseTypeName()
+		scanArgs[i] = &values[i]
+	}
+	if err = rows.Scan(scanArgs...); err != nil {
+		return nil, err
+	}
+	return canonicalRow(columns, typeNames, values), nil
+}
+
+// rowValues returns the canonical values of the row selected by the query, the map is empty
+// if the row has been deleted
+func rowValues(dbTransaction *model.DbTransaction, query string, args ...interface{}) (map[string]string, error) {
+	rows, err := model.GetDB(dbTransaction).Raw(query, args...).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("%s in query %s %s", err, query, args)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return map[string]string{}, rows.Err()
+	}
+	return scanRow(rows)
+}
+
+// hashRow returns the hash of the row key and the values sorted by the column names
+func hashRow(row stateRow, values map[string]string) []byte {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var buf bytes.Buffer
+	buf.WriteString(row.key())
+	for _, column := range columns {
+		buf.WriteByte(0)
+		buf.WriteString(column)
+		buf.WriteByte('=')
+		buf.WriteString(values[column])
+	}
+	return crypto.Hash(buf.Bytes())
+}
+
+// stateHashSize is the size of StateHash, it is 1024 numbers of 16 bits
+const stateHashSize = 2048
+
+// StateHash is the homomorphic hash of the set of rows (LtHash). It is the sum of the hashes
+// of the rows expanded to 1024 numbers modulo 2^16, so the row can be added or removed
+// without the other rows and the hash doesn't depend on the order of rows.
+type StateHash [stateHashSize]byte
+
+// expandHash expands the hash of the row to the size of StateHash
+func expandHash(rowHash []byte) *StateHash {
+	var (
+		out     StateHash
+		counter [4]byte
+	)
+	for i := 0; i < stateHashSize/sha256.Size; i++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(i))
+		h := sha256.New()
+		h.Write(counter[:])
+		h.Write(rowHash)
+		copy(out[i*sha256.Size:], h.Sum(nil))
+	}
+	return &out
+}
+
+// Add adds the row with the hash to the set
+func (s *StateHash) Add(rowHash []byte) {
+	item := expandHash(rowHash)
+	for i := 0; i < stateHashSize; i += 2 {
+		binary.LittleEndian.PutUint16(s[i:], binary.LittleEndian.Uint16(s[i:])+binary.LittleEndian.Uint16(item[i:]))
+	}
+}
+
+// Remove removes the row with the hash from the set
+func (s *StateHash) Remove(rowHash []byte) {
+	item := expandHash(rowHash)
+	for i := 0; i < stateHashSize; i += 2 {
+		binary.LittleEndian.PutUint16(s[i:], binary.LittleEndian.Uint16(s[i:])-binary.LittleEndian.Uint16(item[i:]))
+	}
+}
+
+// Root returns the state root which is committed in the block
+func (s *StateHash) Root() []byte {
+	return crypto.Hash(s[:])
+}
+
+// stateTables returns the tables of ecosystems which keep the state of blockchain
+func stateTables(dbTransaction *model.DbTransaction) ([]string, error) {
+	names, err := model.Storage().Tables(model.GetDB(dbTransaction))
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0, len(names))
+	for _, name := range names {
+		under := strings.IndexByte(name, '_')
+		if under <= 0 || converter.StrToInt64(name[:under]) <= 0 {
+			continue
+		}
+		// the tables of the first ecosystem are shared by all ecosystems
+		if name[:under] != `1` && converter.FirstEcosystemTables[name[under+1:]] {
+			continue
+		}
+		tables = append(tables, name)
+	}
+	return tables, nil
+}
+
+// initState calculates the hashes of all rows of the state when the state root is enabled
+func initState(dbTransaction *model.DbTransaction) (*StateHash, error) {
+	tables, err := stateTables(dbTransaction)
+	if err != nil {
+		return nil, err
+	}
+	state := &StateHash{}
+	for _, table := range tables {
+		shared := converter.FirstEcosystemTables[table[strings.IndexByte(table, '_')+1:]]
+		rows, err := model.GetDB(dbTransaction).Raw(fmt.Sprintf(`SELECT * FROM "%s"`, table)).Rows()
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			values, err := scanRow(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			row := stateRow{table: table, id: values[`id`]}
+			if shared {
+				row.ecosystem = values[`ecosystem`]
+			}
+			hash := hashRow(row, values)
+			sr := &model.StateRow{Table: row.table, RowID: row.id, Ecosystem: row.ecosystem, Hash: hash}
+			if err = sr.Save(dbTransaction); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			state.Add(hash)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// updateState replaces the hashes of the rows changed by the rollback records in the state
+// and returns the new state root. The rows can be changed by playing or by rolling back the block.
+func updateState(dbTransaction *model.DbTransaction, rts []*model.RollbackTx) ([]byte, error) {
+	root := &model.StateRow{}
+	found, err := root.Get(dbTransaction, ``, ``, ``)
+	if err != nil {
+		return nil, err
+	}
+	state := &StateHash{}
+	if !found {
+		// the first block with the state root, the changes of the block are already in the state
+		if state, err = initState(dbTransaction); err != nil {
+			return nil, err
+		}
+		rts = nil
+	} else if len(root.Hash) == stateHashSize {
+		copy(state[:], root.Hash)
+	} else {
+		return nil, fmt.Errorf("wrong size of state hash %d", len(root.Hash))
+	}
+
+	rows, err := changedRows(rts)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		sr := &model.StateRow{}
+		found, err := sr.Get(dbTransaction, row.table, row.id, row.ecosystem)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			state.Remove(sr.Hash)
+		}
+
+		query := fmt.Sprintf(`SELECT * FROM "%s" WHERE id = ?`, row.table)
+		args := []interface{}{row.id}
+		if len(row.ecosystem) > 0 {
+			query += ` AND ecosystem = ?`
+			args = append(args, row.ecosystem)
+		}
+		values, err := rowValues(dbTransaction, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		sr = &model.StateRow{Table: row.table, RowID: row.id, Ecosystem: row.ecosystem}
+		if len(values) == 0 {
+			err = sr.Delete(dbTransaction)
+		} else {
+			sr.Hash = hashRow(row, values)
+			state.Add(sr.Hash)
+			err = sr.Save(dbTransaction)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	root = &model.StateRow{Hash: state[:]}
+	if err = root.Save(dbTransaction); err != nil {
+		return nil, err
+	}
+	return state.Root(), nil
+}
+
+// RollbackState returns the hashes of the state rows to the state before the block.
+// It is called after the transactions of the block have been rolled back.
+func (b *Block) RollbackState(dbTransaction *model.DbTransaction, rts []*model.RollbackTx) error {
+	if b.Header.Version < consts.BvStateRoot {
+		return nil
+	}
+	prev := &model.Block{}
+	if _, err := prev.Get(b.Header.BlockID - 1); err != nil {
+		return err
+	}
+	// the state root has been enabled by the block
+	if len(prev.StateRoot) == 0 {
+		return model.DeleteStateRows(dbTransaction)
+	}
+	_, err := updateState(dbTransaction, rts)
+	return err
+}
@@ -0,0 +1,114 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package block
+
+import (
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedRows(t *testing.T) {
+	rows, err := changedRows([]*model.RollbackTx{
+		{NameTable: "1_pages", TableID: "5"},
+		{NameTable: "@system", TableID: "1", Data: `{"type":"NewTable"}`},
+		{NameTable: "1_keys", TableID: "10,2"},
+		{NameTable: "2_keys", TableID: "10", Data: `{"amount":"5","ecosystem":"2"}`},
+		{NameTable: "1_pages", TableID: "5", Data: `{"value":"old"}`},
+		{NameTable: "1_contracts", TableID: "12"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []stateRow{
+		{table: "1_contracts", id: "12"},
+		{table: "1_keys", id: "10", ecosystem: "2"},
+		{table: "1_pages", id: "5"},
+	}, rows)
+
+	_, err = changedRows([]*model.RollbackTx{{NameTable: "1_keys", TableID: "10", Data: `{`}})
+	assert.Error(t, err)
+}
+
+func TestStateHash(t *testing.T) {
+	crypto.InitHash("SHA256")
+	row := stateRow{table: "1_keys", id: "10", ecosystem: "1"}
+	values := map[string]string{"id": "10", "amount": "100", "ecosystem": "1"}
+	hash := hashRow(row, values)
+	assert.Equal(t, hash, hashRow(row, map[string]string{"ecosystem": "1", "amount": "100", "id": "10"}))
+	assert.NotEqual(t, hash, hashRow(row, map[string]string{"id": "10", "amount": "101", "ecosystem": "1"}))
+	assert.NotEqual(t, hash, hashRow(row, nil))
+	other := hashRow(stateRow{table: "1_pages", id: "1"}, map[string]string{"id": "1", "value": "page"})
+
+	empty := &StateHash{}
+	emptyRoot := empty.Root()
+
+	// the hash of the state doesn't depend on the order of rows
+	first, second := &StateHash{}, &StateHash{}
+	first.Add(hash)
+	first.Add(other)
+	second.Add(other)
+	second.Add(hash)
+	assert.Equal(t, first.Root(), second.Root())
+	assert.NotEqual(t, emptyRoot, first.Root())
+
+	// the removed row doesn't affect the hash of the state
+	first.Remove(hash)
+	only := &StateHash{}
+	only.Add(other)
+	assert.Equal(t, only.Root(), first.Root())
+	first.Remove(other)
+	assert.Equal(t, emptyRoot, first.Root())
+
+	// the same row added twice isn't the same state
+	twice := &StateHash{}
+	twice.Add(hash)
+	twice.Add(hash)
+	once := &StateHash{}
+	once.Add(hash)
+	assert.NotEqual(t, once.Root(), twice.Root())
+}
+
+func TestColumnKind(t *testing.T) {
+	for name, kind := range map[string]columnKind{
+		"INT8":                        kindInt,
+		"bigint":                      kindInt,
+		"INTEGER":                     kindInt,
+		"point":                       kindText,
+		"interval":                    kindText,
+		"varchar(255)":                kindText,
+		"numeric(30,0)":               kindText,
+		"BYTEA":                       kindBytes,
+		"jsonb":                       kindJSON,
+		"timestamp without time zone": kindTime,
+		"DATETIME":                    kindTime,
+		"boolean":                     kindBool,
+	} {
+		assert.Equal(t, kind, columnKindOf(name), name)
+	}
+}
+
+func TestCanonicalRow(t *testing.T) {
+	crypto.InitHash("SHA256")
+	row := stateRow{table: "1_keys", id: "10", ecosystem: "1"}
+	columns := []string{"id", "amount", "pub", "info", "created", "deleted"}
+	postgres := canonicalRow(columns, []string{"INT8", "NUMERIC", "BYTEA", "JSONB", "TIMESTAMP", "BOOL"},
+		[][]byte{[]byte("10"), []byte("100"), []byte(`\x0102ff`), []byte(`{"a": 1, "b": [1.5, "x"]}`),
+			[]byte("2021-09-01T10:00:00Z"), []byte("true")})
+	sqlite := canonicalRow(columns, []string{"bigint", "text", "BLOB", "json", "DATETIME", "boolean"},
+		[][]byte{[]byte("10"), []byte("100"), {1, 2, 0xff}, []byte(`{"b":[1.5,"x"],"a":1}`),
+			[]byte("2021-09-01 10:00:00"), []byte("1")})
+	assert.Equal(t, postgres, sqlite)
+	assert.Equal(t, hashRow(row, postgres), hashRow(row, sqlite))
+
+	// NULL differs from the text
+	assert.NotEqual(t, canonicalRow([]string{"name"}, []string{"TEXT"}, [][]byte{nil}),
+		canonicalRow([]string{"name"}, []string{"TEXT"}, [][]byte{[]byte("NULL")}))
+	// the value which can't be parsed is hashed as is
+	assert.Equal(t, "abc", canonicalValue(kindInt, []byte("abc")))
+}
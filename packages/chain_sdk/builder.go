@@ -22,6 +22,7 @@ type Header struct {
 	NetworkID   int64
 	PublicKey   []byte
 	PrivateFor  []string
+	Signers     [][]byte `msgpack:",omitempty"`
 }
 
 // SmartContract is storing smart contract data
@@ -0,0 +1,51 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package chain_sdk
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+)
+
+// PostCancel drops the pending transactions of the account by the signed cancel transaction,
+// hashes are the hex hashes of the transactions which have been sent by the account.
+// It returns the hash of cancel.
+func PostCancel(apiAddress string, gAuth string, privateKey []byte, hashes ...string) (string, error) {
+	public, err := crypto.PrivateToPublic(privateKey)
+	if err != nil {
+		return "", err
+	}
+	cancel := tx.Cancel{
+		Header: tx.Header{
+			Time:      time.Now().Unix(),
+			KeyID:     crypto.Address(public),
+			NetworkID: conf.Config.NetworkID,
+		},
+	}
+	for _, hash := range hashes {
+		bhash, err := hex.DecodeString(hash)
+		if err != nil {
+			return "", err
+		}
+		cancel.Hashes = append(cancel.Hashes, bhash)
+	}
+	data, _, err := tx.NewCancel(cancel, privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	ret := &sendTxResult{}
+	if err = sendMultipart(apiAddress, gAuth, "sendTx", map[string][]byte{
+		"data": data,
+	}, &ret); err != nil {
+		return "", err
+	}
+	return ret.Hashes["data"], nil
+}
@@ -0,0 +1,95 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package chain_sdk
+
+import (
+	"encoding/hex"
+	"net/url"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MultisigResult is the state of collecting the signatures of multi-signature transaction
+type MultisigResult struct {
+	Hash    string   `json:"hash"`
+	Signers []string `json:"signers"`
+	Signed  []string `json:"signed"`
+	Tx      string   `json:"tx,omitempty"`
+}
+
+// NewMultisigTransaction returns the unsigned transaction of multi-signature account and its hash,
+// KeyID of smartTx is the id of multi-signature account
+func NewMultisigTransaction(smartTx SmartContract, signers [][]byte) (payload, hash []byte, err error) {
+	smartTx.PublicKey = nil
+	smartTx.SignedBy = 0
+	smartTx.Signers = make([][]byte, 0, len(signers))
+	for _, signer := range signers {
+		smartTx.Signers = append(smartTx.Signers, crypto.CutPub(signer))
+	}
+	if payload, err = msgpack.Marshal(smartTx); err != nil {
+		log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling multisig smart contract to msgpack")
+		return
+	}
+	hash = crypto.DoubleHash(payload)
+	return
+}
+
+// SignPartial returns the signature of one signer of multi-signature transaction
+func SignPartial(hash, privateKey []byte) ([]byte, error) {
+	signature, err := crypto.Sign(privateKey, hash)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing multisig transaction")
+		return nil, err
+	}
+	return signature, nil
+}
+
+// CombineSignatures returns the binary transaction of multi-signature account which can be sent by sendTx,
+// signatures must be in the same order as the signers
+func CombineSignatures(payload []byte, signatures [][]byte) []byte {
+	return tx.CombineSignatures(payload, signatures)
+}
+
+// PostMultisig sends the unsigned transaction to the node which collects the signatures
+func PostMultisig(apiAddress string, gAuth string, payload []byte) (*MultisigResult, error) {
+	var result MultisigResult
+	err := sendPost(apiAddress, gAuth, `multisig`, &url.Values{"payload": {hex.EncodeToString(payload)}}, &result)
+	return &result, err
+}
+
+// PostMultisigSign signs the collected transaction by the private key of signer and sends the signature
+func PostMultisigSign(apiAddress string, gAuth string, hash string, privateKey []byte) (*MultisigResult, error) {
+	bhash, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := SignPartial(bhash, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	public, err := crypto.PrivateToPublic(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	var result MultisigResult
+	err = sendPost(apiAddress, gAuth, `multisig/`+hash+`/sign`, &url.Values{
+		"pubkey":    {hex.EncodeToString(public)},
+		"signature": {hex.EncodeToString(signature)},
+	}, &result)
+	return &result, err
+}
+
+// GetMultisig returns the collected signatures, Tx contains the signed transaction when all signers have signed it
+func GetMultisig(apiAddress string, gAuth string, hash string) (*MultisigResult, error) {
+	var result MultisigResult
+	err := sendGet(apiAddress, gAuth, `multisig/`+hash, nil, &result)
+	return &result, err
+}
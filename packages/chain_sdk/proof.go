@@ -0,0 +1,34 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package chain_sdk
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/utils"
+)
+
+var (
+	// ErrProofTxHash is returned when the transaction data of proof doesn't match the transaction hash
+	ErrProofTxHash = utils.ErrProofTxHash
+	// ErrProofMrklRoot is returned when the Merkle path doesn't lead to the Merkle root of block
+	ErrProofMrklRoot = utils.ErrProofMrklRoot
+	// ErrProofBlockHash is returned when the block header of proof doesn't match the block hash
+	ErrProofBlockHash = utils.ErrProofBlockHash
+)
+
+// GetTxProof requests the proof of the inclusion of transaction in the block
+func GetTxProof(apiAddress string, gAuth string, hash string) (*utils.TxProof, error) {
+	var proof utils.TxProof
+	if err := sendGet(apiAddress, gAuth, `txproof/`+hash, nil, &proof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// VerifyTxProof checks offline that the transaction of proof is included in the block with blockHash.
+// The block hash must be received from the trusted source. The hash algorithm must be the same as on the nodes.
+func VerifyTxProof(proof *utils.TxProof, blockHash []byte) error {
+	return proof.Verify(blockHash)
+}
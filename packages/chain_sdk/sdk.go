@@ -438,6 +438,11 @@ func KeyLogin(apiAddress string, from string, state int64) (gAuth string, gAddre
 	if err != nil {
 		return "", "", "", "", false, err
 	}
+	if crypto.IsKeystore(key) {
+		if key, err = crypto.UnlockKeystore(key, os.Getenv(consts.KeystorePassphraseEnv)); err != nil {
+			return "", "", "", "", false, err
+		}
+	}
 	if len(key) > 64 {
 		key = key[:64]
 	}
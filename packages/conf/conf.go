@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
@@ -37,10 +38,12 @@ type DBConfig struct {
 	Port            int    // must be in range 1..65535
 	User            string
 	Password        string
-	LockTimeout     int // lock_timeout in milliseconds
-	IdleInTxTimeout int // postgres parameter idle_in_transaction_session_timeout
-	MaxIdleConns    int // sets the maximum number of connections in the idle connection pool
-	MaxOpenConns    int // sets the maximum number of open connections to the database
+	LockTimeout     int    // lock_timeout in milliseconds
+	IdleInTxTimeout int    // postgres parameter idle_in_transaction_session_timeout
+	MaxIdleConns    int    // sets the maximum number of connections in the idle connection pool
+	MaxOpenConns    int    // sets the maximum number of open connections to the database
+	Backend         string // storage backend: postgres or sqlite
+	Path            string // path to the database file of the embedded backend
 }
 
 //RedisConfig get redis information from config.yml
@@ -102,6 +105,60 @@ type GFilesConfig struct {
 	Host   string
 }
 
+// SnapshotConfig is the state snapshot which is imported at the first start of the node
+type SnapshotConfig struct {
+	Path string // filepath of the snapshot, the node is bootstrapped from genesis if it is empty
+	Hash string // hex of the trusted state hash of the snapshot
+}
+
+// TCPTransportConfig is the settings of the authenticated encrypted sessions between nodes
+type TCPTransportConfig struct {
+	Secure  bool // start the secure session with other nodes, the plain connection is used for the nodes of previous versions
+	Require bool // reject the plain connections and don't fall back to them
+}
+
+// PeersConfig is the settings of the peer discovery
+type PeersConfig struct {
+	Announce        string // public TCP address of the node which is gossiped to other nodes, the node isn't announced if it is empty
+	CollectionPeers int    // max count of the best peers which are asked for the max block
+}
+
+// MempoolConfig is the limits of pending transactions
+type MempoolConfig struct {
+	MaxCount        int // max count of pending transactions
+	MaxSize         int // max size of pending transactions in megabytes
+	MaxAccountCount int // max count of pending transactions of one account
+}
+
+// KeystoreConfig is the settings of encrypted key files
+type KeystoreConfig struct {
+	PassphraseFile string // filepath of the passphrase, it is taken from IBAX_KEYSTORE_PASSPHRASE if it is empty
+	Passphrase     string `toml:"-"`
+}
+
+// SignerConfig is the settings of the signer of node key
+type SignerConfig struct {
+	Remote  string // unix socket of the remote signer, the node key is read from the file if it is empty
+	Timeout int    // timeout of request to the remote signer in milliseconds
+}
+
+// LightConfig is the trust settings of the light node which syncs the headers of blocks only
+type LightConfig struct {
+	NodeKeys       []string // hex public keys of honor nodes ordered by the node position, they are updated from the nodes
+	CheckpointID   int64    // id of the trusted block, it is required because the genesis block isn't signed
+	CheckpointHash string   // hex hash of the trusted block
+}
+
+// WebSocketConfig is the settings of the subscriptions over WebSocket
+type WebSocketConfig struct {
+	AllowedOrigins []string // origins of the browser clients in addition to the same origin, "*" allows any origin
+}
+
+// DebugConfig is the settings of the debug API
+type DebugConfig struct {
+	TraceTx bool // serve /debug/traceTx to the node owner, the replay locks the node and rolls back blocks
+}
+
 type PoolPubConfig struct {
 	Enable      bool //Pool is on/off.
 	MinersCount bool
@@ -123,8 +180,13 @@ type GlobalConfig struct {
 
 	MaxPageGenerationTime int64 // in milliseconds
 
-	TCPServer HostPort
-	HTTP      HostPort
+	TCPServer    HostPort
+	TCPTransport TCPTransportConfig
+	Peers        PeersConfig
+	Mempool      MempoolConfig
+	Keystore     KeystoreConfig
+	Signer       SignerConfig
+	HTTP         HostPort
 
 	DB             DBConfig
 	Redis          RedisConfig
@@ -135,6 +197,10 @@ type GlobalConfig struct {
 	BanKey         BanKeyConfig
 	GFiles         GFilesConfig
 	PoolPub        PoolPubConfig
+	Snapshot       SnapshotConfig
+	Light          LightConfig
+	Debug          DebugConfig
+	WebSocket      WebSocketConfig
 	NodesAddr      []string
 	CryptoSettings CryptoSettings
 }
@@ -281,6 +347,21 @@ func FillRuntimeKey() error {
 		return errors.New("converting keyID to int")
 	}
 
+	return FillKeystorePassphrase()
+}
+
+// FillKeystorePassphrase reads the passphrase which unlocks the encrypted key files
+func FillKeystorePassphrase() error {
+	if len(Config.Keystore.PassphraseFile) == 0 {
+		Config.Keystore.Passphrase = os.Getenv(consts.KeystorePassphraseEnv)
+		return nil
+	}
+	data, err := os.ReadFile(Config.Keystore.PassphraseFile)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": Config.Keystore.PassphraseFile}).Error("reading keystore passphrase file")
+		return err
+	}
+	Config.Keystore.Passphrase = strings.TrimRight(string(data), "\r\n")
 	return nil
 }
 
@@ -326,6 +407,11 @@ func (c GlobalConfig) IsSubNode() bool {
 	return RunMode(c.OBSMode).IsSubNode()
 }
 
+// IsLightNode check running mode
+func (c GlobalConfig) IsLightNode() bool {
+	return RunMode(c.OBSMode).IsLightNode()
+}
+
 func registerCrypto(c CryptoSettings) {
 	crypto.InitCurve(c.Cryptoer)
 	crypto.InitHash(c.Hasher)
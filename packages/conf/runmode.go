@@ -20,6 +20,9 @@ const node RunMode = "NONE"
 //Add sub node processing
 const subNode RunMode = "SubNode"
 
+// LightNode const label for running mode
+const lightNode RunMode = "LightNode"
+
 // IsOBSMaster returns true if mode equal obsMaster
 func (rm RunMode) IsOBSMaster() bool {
 	return rm == obsMaster
@@ -44,3 +47,8 @@ func (rm RunMode) IsSupportingOBS() bool {
 //Add sub node processing
 func (rm RunMode) IsSubNode() bool {
 	return rm == subNode
+
+// IsLightNode returns true if mode equal lightNode
+func (rm RunMode) IsLightNode() bool {
+	return rm == lightNode
+}
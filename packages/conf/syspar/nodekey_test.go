@@ -0,0 +1,50 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package syspar
+
+import (
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/signer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeDecryptKey(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	priv, _, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	local, err := signer.NewLocalSigner(priv)
+	require.NoError(t, err)
+	defer func() {
+		nodePrivKey, nodeSigner = nil, nil
+	}()
+
+	nodePrivKey, nodeSigner = nil, nil
+	_, err = GetNodeDecryptKey()
+	assert.Equal(t, signer.ErrEmptyKey, err)
+
+	// the node key of the remote signer isn't available
+	nodeSigner = local
+	_, err = GetNodeDecryptKey()
+	assert.Equal(t, ErrRemoteNodeKey, err)
+
+	nodePrivKey = priv
+	key, err := GetNodeDecryptKey()
+	require.NoError(t, err)
+	assert.Equal(t, priv, key)
+
+	// the subnode can't start with the remote signer
+	defer func(config conf.GlobalConfig) {
+		conf.Config = config
+	}(conf.Config)
+	conf.Config.OBSMode = "SubNode"
+	conf.Config.Signer.Remote = "signer.sock"
+	assert.Equal(t, ErrRemoteNodeKey, ReadNodeKeys())
+}
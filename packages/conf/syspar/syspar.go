@@ -20,6 +20,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
 	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/signer"
 
 	"time"
 
@@ -63,6 +64,8 @@ const (
 	Test = `test`
 	// PrivateBlockchain is value defining blockchain mode
 	PrivateBlockchain = `private_blockchain`
+	// StateRootBlock is the block from which the blocks include the state root, 0 disables it
+	StateRootBlock = `state_root_block`
 
 	// CostDefault is the default maximum cost of F
 	CostDefault = int64(20000000)
@@ -82,30 +85,53 @@ var (
 	firstBlockData    *consts.FirstBlock
 	errFirstBlockData = errors.New("Failed to get data of the first block")
 	errNodeDisabled   = errors.New("node is disabled")
+	// ErrRemoteNodeKey is returned when the raw node key is required but it is kept by the remote signer
+	ErrRemoteNodeKey  = errors.New("node key is kept by the remote signer")
 	nodePubKey        []byte
 	nodePrivKey       []byte
+	nodeSigner        signer.Signer
 	cacheTableColType = make([]map[string]string, 0)
 )
 
+// ReadNodeKeys reads the node key or connects to the remote signer of node key
 func ReadNodeKeys() (err error) {
 	var (
 		nprivkey []byte
 	)
+	if len(conf.Config.Signer.Remote) > 0 {
+		// the subnode decrypts the private data by the node key, the signer can only sign
+		if conf.Config.IsSubNode() {
+			err = ErrRemoteNodeKey
+			log.WithFields(log.Fields{"type": consts.ConfigError, "error": err}).Error("subnode requires the node key file")
+			return
+		}
+		var remote *signer.RemoteSigner
+		remote, err = signer.NewRemoteSigner(conf.Config.Signer.Remote, time.Duration(conf.Config.Signer.Timeout)*time.Millisecond)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err}).Error("connecting to remote signer")
+			return
+		}
+		nodeSigner = remote
+		nodePubKey = remote.PublicKey()
+		return
+	}
 	nprivkey, err = os.ReadFile(filepath.Join(conf.Config.KeysDir, consts.NodePrivateKeyFilename))
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading node private key from file")
 		return
 	}
-	nodePrivKey, err = hex.DecodeString(string(nprivkey))
+	nodePrivKey, err = crypto.DecodePrivateKey(nprivkey, conf.Config.Keystore.Passphrase)
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding private key from hex")
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding node private key")
 		return
 	}
-	nodePubKey, err = crypto.PrivateToPublic(nodePrivKey)
+	local, err := signer.NewLocalSigner(nodePrivKey)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("converting node private key to public")
 		return
 	}
+	nodeSigner = local
+	nodePubKey = local.PublicKey()
 	return
 }
 
@@ -113,10 +139,27 @@ func GetNodePubKey() []byte {
 	return nodePubKey
 }
 
+// GetNodeSigner returns the signer of node key, it is nil if the node keys haven't been read
+func GetNodeSigner() signer.Signer {
+	return nodeSigner
+}
+
 func GetNodePrivKey() []byte {
 	return nodePrivKey
 }
 
+// GetNodeDecryptKey returns the node key which decrypts the data sent to the node,
+// it is unavailable if the node key is kept by the remote signer
+func GetNodeDecryptKey() ([]byte, error) {
+	if len(nodePrivKey) == 0 {
+		if nodeSigner != nil {
+			return nil, ErrRemoteNodeKey
+		}
+		return nil, signer.ErrEmptyKey
+	}
+	return nodePrivKey, nil
+}
+
 // SysUpdate reloads/updates values of system parameters
 func SysUpdate(dbTransaction *model.DbTransaction) error {
 	var err error
@@ -424,6 +467,20 @@ func GetMaxBlockUserTx() int {
 	return converter.StrToInt(SysString(MaxBlockUserTx))
 }
 
+// IsStateRootBlock returns true if the block with blockID must include the state root
+func IsStateRootBlock(blockID int64) bool {
+	height := converter.StrToInt64(SysString(StateRootBlock))
+	return height > 0 && blockID >= height
+}
+
+// GetBlockVersion returns the version of the block with blockID
+func GetBlockVersion(blockID int64) int {
+	if IsStateRootBlock(blockID) {
+		return consts.BvStateRoot
+	}
+	return consts.BlockVersion
+}
+
 func IsTestMode() bool {
 	return SysString(Test) == `true` || SysString(Test) == `1`
 }
@@ -17,6 +17,22 @@ const VERSION = "1.3.0"
 const BvRollbackHash = 2
 const BvIncludeRollbackHash = 3
 
+// BvStateRoot is version of block with the state root of the previous block,
+// it is used from the height of the state_root_block system parameter
+const BvStateRoot = 4
+
+// BvValidUntil is version of block which checks the ValidUntil time of transactions,
+// it is enabled together with the state root
+const BvValidUntil = BvStateRoot
+
+// BvStatements is version of block from which the contracts can use the for and switch statements,
+// the words for, in, switch, case and default are the identifiers in the previous blocks
+const BvStatements = BvStateRoot
+
+// BvTryCatch is version of block from which the contracts can catch the errors by try and catch,
+// the words try and catch are the identifiers in the previous blocks
+const BvTryCatch = BvStateRoot
+
 // BlockVersion is block version
 const BlockVersion = BvIncludeRollbackHash
 
@@ -94,6 +110,8 @@ const (
 	TxTypeEcosystemMiner = 4
 	TxTypeSystemMiner    = 5
 	TxTypeStopNetwork    = 6
+	// TxTypeCancel drops the pending transactions of the account, it is never played in the block
+	TxTypeCancel = 7
 
 	TxTypeParserFirstBlock  = "FirstBlock"
 	TxTypeSystemServerWork  = "SystemServerWork"
@@ -151,6 +169,9 @@ const (
 	// KeyIDFilename generated KeyID
 	KeyIDFilename = "KeyID"
 
+	// KeystorePassphraseEnv name of environment variable with the passphrase of encrypted key files
+	KeystorePassphraseEnv = "IBAX_KEYSTORE_PASSPHRASE"
+
 	// RollbackResultFilename rollback result file
 	RollbackResultFilename = "rollback_result"
 
@@ -201,6 +222,7 @@ const (
 const (
 	SavePointMarkBlock = "block"
 	SavePointMarkTx    = "tx"
+	SavePointMarkTry   = "try"
 )
 
 func Version() string {
@@ -210,3 +232,8 @@ func Version() string {
 func SetSavePointMarkBlock(idTx int) string {
 	return fmt.Sprintf("\"%s-%d\";", SavePointMarkBlock, idTx)
 }
+
+// SetSavePointMarkTry returns the savepoint of the try block of the contract, depth is the count of outer try blocks
+func SetSavePointMarkTry(depth int) string {
+	return fmt.Sprintf("\"%s-%d\";", SavePointMarkTry, depth)
+}
@@ -0,0 +1,179 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package crypto
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key
+	HardenedKeyStart uint32 = 0x80000000
+	// hdPathPrefix is BIP44 path of the keys without the index of key
+	hdPathPrefix = "m/44'/6666'/0'/0/"
+	// DefaultHDPath is BIP44 path of the first key
+	DefaultHDPath = hdPathPrefix + "0"
+)
+
+var (
+	ErrHDSeed = errors.New("seed must be between 16 and 64 bytes")
+	ErrHDPath = errors.New("incorrect derivation path")
+)
+
+// ExtendedKey is the private key with the chain code which is used to derive child keys.
+// The keys are derived as in BIP32 and SLIP-0010 for the curve of node.
+type ExtendedKey struct {
+	PrivateKey []byte
+	ChainCode  []byte
+	Depth      uint8
+	Index      uint32
+}
+
+// hdCurve returns the curve of keys and the HMAC key of the master key
+func hdCurve() (elliptic.Curve, []byte) {
+	switch curve.name {
+	case cSM2:
+		return sm2.P256Sm2(), []byte("SM2 seed")
+	case cECDSA:
+		return elliptic.P256(), []byte("Nist256p1 seed")
+	default:
+		panic(fmt.Errorf("crypto is not supported yet or empty"))
+	}
+}
+
+// NewMasterKey returns the master key which is derived from the seed
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, ErrHDSeed
+	}
+	c, key := hdCurve()
+	n := c.Params().N
+	data := seed
+	for {
+		mac := hmac.New(sha512.New, key)
+		mac.Write(data)
+		I := mac.Sum(nil)
+		k := new(big.Int).SetBytes(I[:32])
+		if k.Sign() > 0 && k.Cmp(n) < 0 {
+			return &ExtendedKey{PrivateKey: I[:32], ChainCode: I[32:]}, nil
+		}
+		data = I
+	}
+}
+
+// Child returns the child key with the index, the key is hardened if index is greater than HardenedKeyStart
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	c, _ := hdCurve()
+	n := c.Params().N
+
+	var data []byte
+	if index >= HardenedKeyStart {
+		data = append([]byte{0}, converter.FillLeft(k.PrivateKey)...)
+	} else {
+		x, y := c.ScalarBaseMult(k.PrivateKey)
+		data = elliptic.MarshalCompressed(c, x, y)
+	}
+	data = append(data, make([]byte, 4)...)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index)
+
+	parent := new(big.Int).SetBytes(k.PrivateKey)
+	for {
+		mac := hmac.New(sha512.New, k.ChainCode)
+		mac.Write(data)
+		I := mac.Sum(nil)
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Cmp(n) < 0 {
+			child := il.Add(il, parent)
+			child.Mod(child, n)
+			if child.Sign() != 0 {
+				return &ExtendedKey{
+					PrivateKey: converter.FillLeft(child.Bytes()),
+					ChainCode:  I[32:],
+					Depth:      k.Depth + 1,
+					Index:      index,
+				}, nil
+			}
+		}
+		data = append([]byte{1}, I[32:]...)
+		data = append(data, make([]byte, 4)...)
+		binary.BigEndian.PutUint32(data[len(data)-4:], index)
+	}
+}
+
+// Derive returns the key with the path like m/44'/6666'/0'/0/0
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParseHDPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PublicKey returns the public key of the extended key
+func (k *ExtendedKey) PublicKey() ([]byte, error) {
+	return PrivateToPublic(k.PrivateKey)
+}
+
+// ParseHDPath returns the indexes of child keys of the path
+func ParseHDPath(path string) ([]uint32, error) {
+	items := strings.Split(strings.TrimSpace(path), "/")
+	if len(items) == 0 || items[0] != "m" {
+		return nil, ErrHDPath
+	}
+	indexes := make([]uint32, 0, len(items)-1)
+	for _, item := range items[1:] {
+		var hardened bool
+		if strings.HasSuffix(item, "'") || strings.HasSuffix(item, "h") {
+			hardened = true
+			item = item[:len(item)-1]
+		}
+		index, err := strconv.ParseUint(item, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, ErrHDPath
+		}
+		if hardened {
+			index += uint64(HardenedKeyStart)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// HDPath returns BIP44 path of the key with the index
+func HDPath(index uint32) string {
+	return hdPathPrefix + strconv.FormatUint(uint64(index), 10)
+}
+
+// DeriveKey returns the private key with the path which is derived from the seed
+func DeriveKey(seed []byte, path string) ([]byte, error) {
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	key, err := master.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
@@ -0,0 +1,143 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package crypto
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHDKeyVectors(t *testing.T) {
+	InitCurve(cECDSA)
+	// test vector 1 of SLIP-0010 for nist256p1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	require.NoError(t, err)
+	assert.Equal(t, "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2", hex.EncodeToString(master.PrivateKey))
+	assert.Equal(t, "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea", hex.EncodeToString(master.ChainCode))
+
+	key, err := master.Derive("m/0'")
+	require.NoError(t, err)
+	assert.Equal(t, "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c", hex.EncodeToString(key.PrivateKey))
+	assert.Equal(t, "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11", hex.EncodeToString(key.ChainCode))
+
+	key, err = master.Derive("m/0'/1")
+	require.NoError(t, err)
+	assert.Equal(t, "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129", hex.EncodeToString(key.PrivateKey))
+	assert.Equal(t, uint8(2), key.Depth)
+}
+
+func TestHDPath(t *testing.T) {
+	indexes, err := ParseHDPath(HDPath(3))
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{44 + HardenedKeyStart, 6666 + HardenedKeyStart, HardenedKeyStart, 0, 3}, indexes)
+
+	for _, path := range []string{"", "44'/0", "m/x", "m/2147483648"} {
+		_, err = ParseHDPath(path)
+		assert.Equal(t, ErrHDPath, err, path)
+	}
+}
+
+func TestMnemonicKeys(t *testing.T) {
+	for _, name := range []string{cECDSA, cSM2} {
+		InitCurve(name)
+		mnemonic, err := NewMnemonic()
+		require.NoError(t, err)
+
+		first, err := KeyFromMnemonic(mnemonic, "", HDPath(0))
+		require.NoError(t, err)
+		again, err := KeyFromMnemonic(mnemonic, "", HDPath(0))
+		require.NoError(t, err)
+		assert.Equal(t, first, again)
+
+		second, err := KeyFromMnemonic(mnemonic, "", HDPath(1))
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+		protected, err := KeyFromMnemonic(mnemonic, "password", HDPath(0))
+		require.NoError(t, err)
+		assert.NotEqual(t, first, protected)
+
+		_, err = KeyFromMnemonic(mnemonic+" abandon", "", HDPath(0))
+		assert.Equal(t, ErrMnemonic, err)
+	}
+	InitCurve(cECDSA)
+}
+
+func TestKeystore(t *testing.T) {
+	InitCurve(cECDSA)
+	keystoreScryptN = 1 << 10
+	keystoreArgon2Memory = 1024
+
+	privateKey, _, err := GenKeyPair()
+	require.NoError(t, err)
+	for _, kdf := range []string{KDFScrypt, KDFArgon2} {
+		data, err := EncryptKey(privateKey, "secret", kdf)
+		require.NoError(t, err)
+		assert.True(t, IsKeystore(data))
+
+		key, err := DecodePrivateKey(data, "secret")
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(privateKey), hex.EncodeToString(key))
+
+		_, err = DecryptKey(data, "wrong")
+		assert.Equal(t, ErrKeystorePassphrase, err)
+
+		InitCurve(cSM2)
+		_, err = DecryptKey(data, "secret")
+		assert.Equal(t, ErrKeystoreCurve, err)
+		InitCurve(cECDSA)
+	}
+
+	data, err := EncryptKey(privateKey, "secret", KDFScrypt)
+	require.NoError(t, err)
+	tamper := func(f func(ks *Keystore)) []byte {
+		ks := &Keystore{}
+		require.NoError(t, json.Unmarshal(data, ks))
+		f(ks)
+		out, err := json.Marshal(ks)
+		require.NoError(t, err)
+		return out
+	}
+	_, err = DecryptKey(tamper(func(ks *Keystore) { ks.Address = "0000-0000-0000-0000-0000" }), "secret")
+	assert.Equal(t, ErrKeystoreAddress, err)
+	_, err = DecryptKey(tamper(func(ks *Keystore) { ks.Crypto.KDFParams.N = 1 << 30 }), "secret")
+	assert.Equal(t, ErrKeystoreKDFParams, err)
+	_, err = DecryptKey(tamper(func(ks *Keystore) { ks.Crypto.KDFParams.P = 0 }), "secret")
+	assert.Equal(t, ErrKeystore, err)
+	_, err = DecryptKey(tamper(func(ks *Keystore) {
+		ks.Crypto.KDF = KDFArgon2
+		ks.Crypto.KDFParams.Time, ks.Crypto.KDFParams.Memory, ks.Crypto.KDFParams.Threads = 1, 1<<30, 1
+	}), "secret")
+	assert.Equal(t, ErrKeystoreKDFParams, err)
+
+	_, err = EncryptKey(privateKey, "secret", "md5")
+	assert.Equal(t, ErrKeystoreKDF, err)
+
+	key, err := DecodePrivateKey([]byte(hex.EncodeToString(privateKey)+"\n"), "")
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, key)
+
+	filename := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, WriteKeystoreFile(filename, hex.EncodeToString(privateKey), "secret", KDFArgon2))
+	hexKey, err := ReadKeystoreFile(filename, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(privateKey), hexKey)
+}
+
+func TestKeystoreLimits(t *testing.T) {
+	// the keystore files of stronger settings than the defaults are accepted
+	assert.Greater(t, keystoreMaxScryptN, 1<<18)
+	assert.Greater(t, keystoreMaxScryptR, 8)
+	assert.Greater(t, keystoreMaxScryptP, 1)
+	assert.Greater(t, keystoreMaxArgon2Time, uint32(3))
+	assert.Greater(t, keystoreMaxArgon2Memory, uint32(64*1024))
+	assert.Greater(t, keystoreMaxArgon2Threads, uint8(4))
+}
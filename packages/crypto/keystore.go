@@ -0,0 +1,269 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/converter"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeystoreVersion is the version of format of keystore file
+	KeystoreVersion = 1
+	// KDFScrypt derives the key of keystore by scrypt
+	KDFScrypt = "scrypt"
+	// KDFArgon2 derives the key of keystore by argon2id
+	KDFArgon2 = "argon2id"
+
+	keystoreCipher = "aes-256-gcm"
+	keystoreKeyLen = 32
+	keystoreSalt   = 32
+
+	// the upper limits of the key derivation parameters accepted from a keystore file,
+	// they leave room for the files of stronger settings, but larger values could make
+	// the decryption take unbounded time and memory
+	keystoreMaxScryptN       = 1 << 20
+	keystoreMaxScryptR       = 16
+	keystoreMaxScryptP       = 4
+	keystoreMaxArgon2Time    = uint32(16)
+	keystoreMaxArgon2Memory  = uint32(1024 * 1024)
+	keystoreMaxArgon2Threads = uint8(16)
+)
+
+// the parameters of key derivation of new keystore, they are stored in the keystore file
+var (
+	keystoreScryptN       = 1 << 18
+	keystoreScryptR       = 8
+	keystoreScryptP       = 1
+	keystoreArgon2Time    = uint32(3)
+	keystoreArgon2Memory  = uint32(64 * 1024)
+	keystoreArgon2Threads = uint8(4)
+)
+
+var (
+	ErrKeystore           = errors.New("incorrect keystore file")
+	ErrKeystoreKDF        = errors.New("unsupported key derivation function of keystore")
+	ErrKeystoreCurve      = errors.New("keystore is encrypted for other curve")
+	ErrKeystorePassphrase = errors.New("could not decrypt keystore with given passphrase")
+	ErrKeystoreKDFParams  = errors.New("key derivation parameters of keystore exceed the limits")
+	ErrKeystoreAddress    = errors.New("keystore address does not match the private key")
+)
+
+// Keystore is the private key which is encrypted by the key derived from the passphrase
+type Keystore struct {
+	Version int            `json:"version"`
+	Address string         `json:"address"`
+	Curve   string         `json:"curve"`
+	Crypto  KeystoreCrypto `json:"crypto"`
+}
+
+// KeystoreCrypto is the encrypted private key with the parameters of encryption
+type KeystoreCrypto struct {
+	Cipher     string    `json:"cipher"`
+	CipherText string    `json:"ciphertext"`
+	Nonce      string    `json:"nonce"`
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdfparams"`
+}
+
+// KDFParams are the parameters of scrypt or argon2id
+type KDFParams struct {
+	Salt    string `json:"salt"`
+	DKLen   int    `json:"dklen"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+func (p *KDFParams) deriveKey(kdf, passphrase string) ([]byte, error) {
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil || p.DKLen != keystoreKeyLen {
+		return nil, ErrKeystore
+	}
+	switch kdf {
+	case KDFScrypt:
+		if p.N <= 1 || p.R <= 0 || p.P <= 0 {
+			return nil, ErrKeystore
+		}
+		if p.N > keystoreMaxScryptN || p.R > keystoreMaxScryptR || p.P > keystoreMaxScryptP {
+			return nil, ErrKeystoreKDFParams
+		}
+		return scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, p.DKLen)
+	case KDFArgon2:
+		if p.Time == 0 || p.Memory == 0 || p.Threads == 0 {
+			return nil, ErrKeystore
+		}
+		if p.Time > keystoreMaxArgon2Time || p.Memory > keystoreMaxArgon2Memory ||
+			p.Threads > keystoreMaxArgon2Threads {
+			return nil, ErrKeystoreKDFParams
+		}
+		return argon2.IDKey([]byte(passphrase), salt, p.Time, p.Memory, p.Threads, uint32(p.DKLen)), nil
+	}
+	return nil, ErrKeystoreKDF
+}
+
+// EncryptKey returns the keystore file of the private key, kdf is KDFScrypt or KDFArgon2
+func EncryptKey(privateKey []byte, passphrase, kdf string) ([]byte, error) {
+	public, err := PrivateToPublic(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	params := KDFParams{DKLen: keystoreKeyLen}
+	switch kdf {
+	case KDFScrypt:
+		params.N, params.R, params.P = keystoreScryptN, keystoreScryptR, keystoreScryptP
+	case KDFArgon2:
+		params.Time, params.Memory, params.Threads = keystoreArgon2Time, keystoreArgon2Memory, keystoreArgon2Threads
+	default:
+		return nil, ErrKeystoreKDF
+	}
+	salt := make([]byte, keystoreSalt)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params.Salt = hex.EncodeToString(salt)
+
+	key, err := params.deriveKey(kdf, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newKeystoreCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(&Keystore{
+		Version: KeystoreVersion,
+		Address: converter.AddressToString(Address(public)),
+		Curve:   curve.name,
+		Crypto: KeystoreCrypto{
+			Cipher:     keystoreCipher,
+			CipherText: hex.EncodeToString(gcm.Seal(nil, nonce, converter.FillLeft(privateKey), nil)),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        kdf,
+			KDFParams:  params,
+		},
+	}, "", "  ")
+}
+
+// DecryptKey returns the private key from the keystore file
+func DecryptKey(data []byte, passphrase string) ([]byte, error) {
+	ks := &Keystore{}
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, ErrKeystore
+	}
+	if ks.Version != KeystoreVersion || ks.Crypto.Cipher != keystoreCipher {
+		return nil, ErrKeystore
+	}
+	if ks.Curve != curve.name {
+		return nil, ErrKeystoreCurve
+	}
+	key, err := ks.Crypto.KDFParams.deriveKey(ks.Crypto.KDF, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newKeystoreCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(ks.Crypto.Nonce)
+	if err != nil || len(nonce) != gcm.NonceSize() {
+		return nil, ErrKeystore
+	}
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, ErrKeystore
+	}
+	privateKey, err := gcm.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return nil, ErrKeystorePassphrase
+	}
+	public, err := PrivateToPublic(privateKey)
+	if err != nil {
+		return nil, ErrKeystore
+	}
+	if converter.AddressToString(Address(public)) != ks.Address {
+		return nil, ErrKeystoreAddress
+	}
+	return privateKey, nil
+}
+
+func newKeystoreCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// IsKeystore returns true if the data is the keystore file but not the hex private key
+func IsKeystore(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+// DecodePrivateKey returns the private key from the key file which contains
+// the hex private key or the keystore which is decrypted with the passphrase
+func DecodePrivateKey(data []byte, passphrase string) ([]byte, error) {
+	if IsKeystore(data) {
+		return DecryptKey(data, passphrase)
+	}
+	return hex.DecodeString(strings.TrimSpace(string(data)))
+}
+
+// UnlockKeystore returns the hex private key from the keystore which is decrypted with the passphrase
+func UnlockKeystore(data []byte, passphrase string) ([]byte, error) {
+	privateKey, err := DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(privateKey)), nil
+}
+
+// ReadKeystoreFile returns the hex private key from the keystore file which is decrypted with the passphrase
+func ReadKeystoreFile(filename, passphrase string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	key, err := UnlockKeystore(data, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
+
+// WriteKeystoreFile encrypts the hex private key with the passphrase and writes the keystore file,
+// kdf is KDFScrypt or KDFArgon2
+func WriteKeystoreFile(filename, hexKey, passphrase, kdf string) error {
+	privateKey, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return err
+	}
+	data, err := EncryptKey(privateKey, passphrase, kdf)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0600)
+}
@@ -0,0 +1,54 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// mnemonicEntropySize is the size of entropy of 24 words mnemonic in bits
+const mnemonicEntropySize = 256
+
+var ErrMnemonic = errors.New("incorrect mnemonic")
+
+// NewMnemonic returns the random BIP39 mnemonic of 24 words which is the backup of all HD keys
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropySize)
+	if err != nil {
+		return ``, err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// MnemonicToSeed returns the seed of HD keys, passphrase is the optional BIP39 password
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, ErrMnemonic
+	}
+	return seed, nil
+}
+
+// KeyFromMnemonic returns the private key with the derivation path which is restored from the mnemonic
+func KeyFromMnemonic(mnemonic, passphrase, path string) ([]byte, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveKey(seed, path)
+}
+
+// HexKeyFromMnemonic returns the hex private key with the index of HD path which is restored from the mnemonic
+func HexKeyFromMnemonic(mnemonic, passphrase string, index uint32) (string, error) {
+	privateKey, err := KeyFromMnemonic(mnemonic, passphrase, HDPath(index))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(privateKey), nil
+}
@@ -0,0 +1,77 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+const (
+	// PublicKeySize is the size of public key without the first 04 byte
+	PublicKeySize = 64
+	// MaxMultisigKeys is the max count of public keys of multi-signature account
+	MaxMultisigKeys = 20
+)
+
+var (
+	ErrMultisigKey       = errors.New("incorrect public key of multi-signature account")
+	ErrMultisigThreshold = errors.New("threshold must be between 1 and the count of public keys")
+)
+
+// SortPublicKeys cuts and sorts the public keys of multi-signature account and checks that they are unique
+func SortPublicKeys(threshold int64, pubs [][]byte) ([][]byte, error) {
+	if len(pubs) == 0 || len(pubs) > MaxMultisigKeys || threshold < 1 || threshold > int64(len(pubs)) {
+		return nil, ErrMultisigThreshold
+	}
+	sorted := make([][]byte, len(pubs))
+	for i, pub := range pubs {
+		if pub = CutPub(pub); len(pub) != PublicKeySize {
+			return nil, ErrMultisigKey
+		}
+		sorted[i] = pub
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+	for i := 1; i < len(sorted); i++ {
+		if bytes.Equal(sorted[i-1], sorted[i]) {
+			return nil, ErrMultisigKey
+		}
+	}
+	return sorted, nil
+}
+
+// MultisigAddress returns the address of multi-signature account, it doesn't depend on the order of keys
+func MultisigAddress(threshold int64, pubs [][]byte) (int64, error) {
+	sorted, err := SortPublicKeys(threshold, pubs)
+	if err != nil {
+		return 0, err
+	}
+	data := []byte("multisig:" + strconv.FormatInt(threshold, 10) + ":")
+	for _, pub := range sorted {
+		data = append(data, pub...)
+	}
+	return Address(Hash(data)), nil
+}
+
+// JoinPublicKeys returns the public keys of multi-signature account which are stored in the key record
+func JoinPublicKeys(pubs [][]byte) []byte {
+	return bytes.Join(pubs, nil)
+}
+
+// SplitPublicKeys returns the public keys of multi-signature account from the key record
+func SplitPublicKeys(data []byte) ([][]byte, error) {
+	if len(data) == 0 || len(data)%PublicKeySize != 0 {
+		return nil, ErrMultisigKey
+	}
+	pubs := make([][]byte, 0, len(data)/PublicKeySize)
+	for i := 0; i < len(data); i += PublicKeySize {
+		pubs = append(pubs, data[i:i+PublicKeySize])
+	}
+	return pubs, nil
+}
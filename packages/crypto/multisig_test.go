@@ -0,0 +1,59 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPublicKeys(count int) [][]byte {
+	pubs := make([][]byte, count)
+	for i := range pubs {
+		pubs[i] = bytes.Repeat([]byte{byte(count - i)}, PublicKeySize)
+	}
+	return pubs
+}
+
+func TestMultisigAddress(t *testing.T) {
+	pubs := testPublicKeys(3)
+	id, err := MultisigAddress(2, pubs)
+	assert.NoError(t, err)
+
+	// the address doesn't depend on the order of keys and the first 04 byte
+	reversed := [][]byte{pubs[2], append([]byte{4}, pubs[1]...), pubs[0]}
+	rid, err := MultisigAddress(2, reversed)
+	assert.NoError(t, err)
+	assert.Equal(t, id, rid)
+
+	other, err := MultisigAddress(3, pubs)
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, other)
+
+	_, err = MultisigAddress(0, pubs)
+	assert.Equal(t, ErrMultisigThreshold, err)
+	_, err = MultisigAddress(4, pubs)
+	assert.Equal(t, ErrMultisigThreshold, err)
+	_, err = MultisigAddress(1, [][]byte{pubs[0], pubs[0]})
+	assert.Equal(t, ErrMultisigKey, err)
+	_, err = MultisigAddress(1, [][]byte{pubs[0][1:]})
+	assert.Equal(t, ErrMultisigKey, err)
+}
+
+func TestSplitPublicKeys(t *testing.T) {
+	sorted, err := SortPublicKeys(2, testPublicKeys(3))
+	assert.NoError(t, err)
+	assert.True(t, bytes.Compare(sorted[0], sorted[1]) < 0)
+
+	pubs, err := SplitPublicKeys(JoinPublicKeys(sorted))
+	assert.NoError(t, err)
+	assert.Equal(t, sorted, pubs)
+
+	_, err = SplitPublicKeys(sorted[0][1:])
+	assert.Equal(t, ErrMultisigKey, err)
+}
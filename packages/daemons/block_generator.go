@@ -19,6 +19,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/model"
 	"github.com/IBAX-io/go-ibax/packages/protocols"
 	"github.com/IBAX-io/go-ibax/packages/service"
+	"github.com/IBAX-io/go-ibax/packages/signer"
 	"github.com/IBAX-io/go-ibax/packages/transaction"
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
@@ -104,17 +105,16 @@ func BlockGenerator(ctx context.Context, d *daemon) error {
 		return err
 	}
 
-	NodePrivateKey, NodePublicKey := utils.GetNodeKeys()
-	if len(NodePrivateKey) < 1 {
+	nodeSigner := syspar.GetNodeSigner()
+	if nodeSigner == nil {
 		d.logger.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node private key is empty")
 		return errors.New(`node private key is empty`)
 	}
 
 	dtx := DelayedTx{
-		privateKey: NodePrivateKey,
-		publicKey:  NodePublicKey,
-		logger:     d.logger,
-		time:       st.Unix(),
+		signer: nodeSigner,
+		logger: d.logger,
+		time:   st.Unix(),
 	}
 
 	txs, err := dtx.RunForDelayBlockID(prevBlock.BlockID + 1)
@@ -145,13 +145,13 @@ func BlockGenerator(ctx context.Context, d *daemon) error {
 	return nil
 }
 
-func generateNextBlock(blockHeader *utils.BlockData, trs []*model.Transaction, key string, prevBlock *utils.BlockData) ([]byte, error) {
+func generateNextBlock(blockHeader *utils.BlockData, trs []*model.Transaction, s signer.Signer, prevBlock *utils.BlockData) ([]byte, error) {
 	trData := make([][]byte, 0, len(trs))
 	for _, tr := range trs {
 		trData = append(trData, tr.Data)
 	}
 
-	return block.MarshallBlock(blockHeader, trData, prevBlock, key)
+	return block.MarshallBlock(blockHeader, trData, prevBlock, s)
 }
 
 func processTransactions(logger *log.Entry, txs []*model.Transaction, done <-chan time.Time, st int64) ([]*model.Transaction, error) {
@@ -163,9 +163,9 @@ func processTransactions(logger *log.Entry, txs []*model.Transaction, done <-cha
 	//	return nil, err
 	//}
 
-	trs, err := model.GetAllUnusedTransactions(p.DbTransaction, syspar.GetMaxTxCount())
+	trs, err := transaction.PendingTransactions(p.DbTransaction, syspar.GetMaxTxCount())
 	if err != nil {
-		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting all unused transactions")
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting pending transactions from mempool")
 		return nil, err
 	}
 
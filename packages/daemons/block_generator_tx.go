@@ -6,7 +6,8 @@
 package daemons
 
 import (
-	"encoding/hex"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/signer"
 
 	callDelayedContract = "CallDelayedContract"
 	firstEcosystemID    = 1
@@ -14,10 +15,9 @@ import (
 
 // DelayedTx represents struct which works with delayed contracts
 type DelayedTx struct {
-	logger     *log.Entry
-	privateKey string
-	publicKey  string
-	time       int64
+	logger *log.Entry
+	signer signer.Signer
+	time   int64
 }
 
 // RunForDelayBlockID creates the transactions that need to be run for blockID
@@ -56,16 +56,11 @@ func (dtx *DelayedTx) createDelayTx(keyID, highRate int64, params map[string]int
 			KeyID:       keyID,
 			NetworkID:   conf.Config.NetworkID,
 		},
-		SignedBy: smart.PubToID(dtx.publicKey),
+		SignedBy: crypto.Address(dtx.signer.PublicKey()),
 		Params:   params,
 	}
 
-	privateKey, err := hex.DecodeString(dtx.privateKey)
-	if err != nil {
-		return nil, err
-	}
-
-	txData, txHash, err := tx.NewInternalTransaction(smartTx, privateKey)
+	txData, txHash, err := tx.NewInternalTransaction(smartTx, dtx.signer)
 	if err != nil {
 		return nil, err
 	}
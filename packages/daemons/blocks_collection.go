@@ -8,15 +8,13 @@ package daemons
 import (
 	"context"
 	"encoding/hex"
-	"fmt"
-	"strconv"
 	"sync/atomic"
 	"time"
 
 	"github.com/IBAX-io/go-ibax/packages/smart"
 
 	"github.com/IBAX-io/go-ibax/packages/block"
-	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/peers"
 	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
 
 	"github.com/IBAX-io/go-ibax/packages/conf"
@@ -26,6 +24,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/model"
 	"github.com/IBAX-io/go-ibax/packages/rollback"
 	"github.com/IBAX-io/go-ibax/packages/service"
+	"github.com/IBAX-io/go-ibax/packages/statsd"
 	"github.com/IBAX-io/go-ibax/packages/transaction"
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
@@ -101,118 +100,115 @@ func UpdateChain(ctx context.Context, d *daemon, host string, maxBlockID int64)
 		return ctx.Err()
 	}
 
-	playRawBlock := func(rb []byte) error {
-		var lastBlockID, lastBlockTime int64
-		var err error
-		defer func(err2 *error) {
-			if err2 != nil {
-				banNodePause(host, lastBlockID, lastBlockTime, *err2)
-			}
-		}(&err)
-		bl, err := block.ProcessBlockWherePrevFromBlockchainTable(rb, true)
-		if err != nil {
-			d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("processing block")
-			return err
-		}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		curBlock := &model.InfoBlock{}
-		if _, err = curBlock.Get(); err != nil {
-			d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("Getting info block")
-			return err
-		}
+	var count int
+	var playTime time.Duration
+	st := time.Now()
+	defer func() {
+		d.logger.WithFields(log.Fields{"count": count, "time": time.Since(st).String(), "play_time": playTime.String(),
+			"download_time": (time.Since(st) - playTime).String()}).Info("blocks downloaded")
+	}()
 
-		if bl.PrevHeader != nil {
-			if curBlock.BlockID != bl.PrevHeader.BlockID {
-				d.logger.WithFields(log.Fields{"type": consts.DBError}).Error("Getting info block  err curBlock.BlockID: " + strconv.FormatInt(curBlock.BlockID, 10) + "bl.PrevHeader.BlockID: " + strconv.FormatInt(bl.PrevHeader.BlockID, 10))
-				return err
-			}
-		} else {
-			d.logger.WithFields(log.Fields{"type": consts.DBError}).Error("Getting info block PrevHeader nil")
-			return err
+	// the ranges of blocks are downloaded from several peers and checked in parallel,
+	// they are played in order while the next ranges are being downloaded
+	bd := newBlocksDownloader(ctx, d.logger, host)
+	d.logger.WithFields(log.Fields{"min_block": curBlock.BlockID, "max_block": maxBlockID, "count": maxBlockID - curBlock.BlockID, "peers": bd.hosts}).Info("starting downloading blocks")
+	for r := range bd.run(curBlock.BlockID+1, maxBlockID, curBlock.Hash) {
+		if r.err != nil {
+			blockID, blockTime := failedBlock(r.err, r.from)
+			d.logger.WithFields(log.Fields{"error": r.err, "host": r.host, "from": r.from, "block_id": blockID, "type": consts.BlockError}).Error("getting blocks bodies")
+			banNodePause(r.host, blockID, blockTime, r.err)
+			return r.err
 		}
 
-		lastBlockID = bl.Header.BlockID
-		lastBlockTime = bl.Header.Time
-
-		if err = bl.Check(); err != nil {
-			var replaceCount int64 = 1
-			if err == block.ErrIncorrectRollbackHash {
-				replaceCount++
-			}
-			d.logger.WithFields(log.Fields{"error": err, "from_host": host, "different": fmt.Errorf("not match block %d, prev_position %d, current_position %d", bl.PrevHeader.BlockID, bl.PrevHeader.NodePosition, bl.Header.NodePosition), "type": consts.BlockError, "replaceCount": replaceCount}).Error("checking block hash")
-			//it should be fork, replace our previous blocks to ones from the host
-			if errReplace := ReplaceBlocksFromHost(ctx, host, bl.PrevHeader.BlockID, replaceCount); errReplace != nil {
-				return errReplace
+		start := time.Now()
+		err := playBlocks(r.blocks)
+		playTime += time.Since(start)
+		statsd.Client.TimingDuration(statsd.BlocksCollectionCounterName("play")+statsd.Time, time.Since(start), 1.0)
+		if err != nil {
+			blockID, blockTime := failedBlock(err, r.from)
+			d.logger.WithFields(log.Fields{"error": err, "from_host": r.host, "from": r.from, "to": r.to, "block_id": blockID, "type": consts.BlockError}).Error("playing blocks")
+			banNodePause(r.host, blockID, blockTime, err)
+			if replaceCount := forkDepth(err, blockID, curBlock.BlockID); replaceCount > 0 {
+				// it should be fork, replace our previous blocks to ones from the host
+				if errReplace := ReplaceBlocksFromHost(ctx, r.host, curBlock.BlockID, replaceCount); errReplace != nil {
+					return errReplace
+				}
 			}
 			return err
 		}
-		return bl.PlaySafe()
+
+		peers.GetManager().RecordBlocks(r.host, int64(len(r.blocks)))
+		statsd.Client.Inc(statsd.BlocksCollectionCounterName("blocks")+statsd.Count, int64(len(r.blocks)), 1.0)
+		count += len(r.blocks)
 	}
+	return ctx.Err()
+}
 
-	var count int
-	st := time.Now()
+// blockError is the error of block which has failed to be parsed or played
+type blockError struct {
+	id, time int64
+	err      error
+}
 
-	//if conf.Config.PoolPub.Enable {
-	//	bi := model.BlockID{}
-	//	f, err := bi.GetRangeByName(consts.MintMax, consts.ChainMax, 2000)
-	//	fmt.Println(f, err)
-	//	if err != nil {
-	//		return err
-	//	}
-	//	if f {
-	//		time.Sleep(4 * time.Second)
-	//		return errors.New("deal mint blockid please wait")
-	//	}
-	//}
+func (e *blockError) Error() string {
+	return e.err.Error()
+}
 
-	d.logger.WithFields(log.Fields{"min_block": curBlock.BlockID, "max_block": maxBlockID, "count": maxBlockID - curBlock.BlockID}).Info("starting downloading blocks")
-	for blockID := curBlock.BlockID + 1; blockID <= maxBlockID; blockID += int64(network.BlocksPerRequest) {
+// Cause returns the error of block, it is used by errors.Cause
+func (e *blockError) Cause() error {
+	return e.err
+}
 
-		if loopErr := func() error {
-			ctxDone, cancel := context.WithCancel(ctx)
-			defer func() {
-				cancel()
-				d.logger.WithFields(log.Fields{"count": count, "time": time.Since(st).String()}).Info("blocks downloaded")
-			}()
+// failedBlock returns the id and the time of block which has caused the error,
+// blockID is returned if the error doesn't belong to the block
+func failedBlock(err error, blockID int64) (int64, int64) {
+	if e, ok := err.(*blockError); ok {
+		return e.id, e.time
+	}
+	return blockID, 0
+}
 
-			rawBlocksChan, err := tcpclient.GetBlocksBodies(ctxDone, host, blockID, false)
-			if err != nil {
-				d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("getting block body")
-				return err
-			}
+// forkDepth returns the count of our blocks which should be replaced by the blocks from the host.
+// The fork is only detected if the block following our last block doesn't match it.
+func forkDepth(err error, blockID, curBlockID int64) int64 {
+	if blockID != curBlockID+1 {
+		return 0
+	}
+	switch errors.Cause(err) {
+	case block.ErrIncorrectPrevHash:
+		return 1
+	case block.ErrIncorrectRollbackHash, block.ErrIncorrectStateRoot:
+		return 2
+	}
+	return 0
+}
 
-			for rawBlock := range rawBlocksChan {
-
-				//if conf.Config.PoolPub.Enable {
-				//	bi := model.BlockID{}
-				//	f, err := bi.GetRangeByName(consts.MintMax, consts.ChainMax, 2000)
-				//	if err != nil {
-				//		return err
-				//	}
-				//	if !f {
-				//		if err = playRawBlock(rawBlock); err != nil {
-				//			d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("playing raw block")
-				//			return err
-				//		}
-				//	} else {
-				//		time.Sleep(4 * time.Second)
-				//		return errors.New("deal mint blockid please wait")
-				//	}
-				//} else {
-
-				if err = playRawBlock(rawBlock); err != nil {
-					d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("playing raw block")
-					return err
-				}
-				count++
-				//}
-			}
+// playBlocks plays the range of blocks in one db transaction
+func playBlocks(blocks []*block.Block) error {
+	prev, err := block.GetBlockDataFromBlockChain(blocks[0].Header.BlockID - 1)
+	if err != nil {
+		return err
+	}
+	blocks[0].PrevHeader = prev
+	for _, b := range blocks[1:] {
+		b.PrevHeader = &utils.BlockData{}
+	}
 
-			return nil
-		}(); loopErr != nil {
-			return loopErr
-		}
+	// processBlocks goes through the blocks from the end of slice
+	desc := make([]*block.Block, len(blocks))
+	for i, b := range blocks {
+		desc[len(blocks)-1-i] = b
+	}
+
+	smart.SavepointSmartVMObjects()
+	if err = processBlocks(desc); err != nil {
+		smart.RollbackSmartVMObjects()
+		return err
 	}
+	smart.ReleaseSmartVMObjects()
 	return nil
 }
 
@@ -220,6 +216,7 @@ func banNodePause(host string, blockID, blockTime int64, err error) {
 	if err == nil || !utils.IsBanError(err) {
 		return
 	}
+	peers.GetManager().RecordBadBlock(host, syspar.GetLocalNodeBanTime())
 
 	reason := err.Error()
 	}
@@ -229,20 +226,61 @@ func banNodePause(host string, blockID, blockTime int64, err error) {
 func getHostWithMaxID(ctx context.Context, logger *log.Entry) (host string, maxBlockID int64, err error) {
 
 	nbs := service.GetNodesBanService()
-	hosts, err := nbs.FilterBannedHosts(syspar.GetRemoteHosts())
+	hosts, banHosts, err := nbs.FilterHosts(syspar.GetRemoteHosts())
 	if err != nil {
 		logger.WithFields(log.Fields{"error": err}).Error("on filtering banned hosts")
 	}
 
-	host, maxBlockID, err = tcpclient.HostWithMaxBlock(ctx, hosts)
+	// the best of honor nodes and discovered peers are asked instead of all hosts
+	pm := peers.GetManager()
+	banned := make(map[string]bool, len(banHosts))
+	for _, h := range banHosts {
+		banned[h] = true
+	}
+	for _, h := range pm.Hosts(0) {
+		if !banned[h] {
+			hosts = append(hosts, h)
+		}
+	}
+	hosts = pm.Best(hosts, conf.Config.Peers.CollectionPeers)
+
+	limit, err := maxPossibleBlockID(time.Now())
+	if err != nil {
+		return "", 0, err
+	}
+	host, maxBlockID, overclaimed, err := tcpclient.HostWithMaxBlockLimit(ctx, hosts, limit)
+	for _, h := range overclaimed {
+		logger.WithFields(log.Fields{"type": consts.BlockError, "host": h, "limit": limit}).Warn("host claims the block which can't be generated yet")
+		pm.RecordBadBlock(h, syspar.GetLocalNodeBanTime())
+	}
 	if len(hosts) == 0 || err == tcpclient.ErrNodesUnavailable {
 		hosts = conf.GetNodesAddr()
-		return tcpclient.HostWithMaxBlock(ctx, hosts)
+		host, maxBlockID, _, err = tcpclient.HostWithMaxBlockLimit(ctx, hosts, limit)
 	}
 
 	return
 }
 
+// maxPossibleBlockID returns the max block id which can be generated till the time,
+// no more than one block is generated in every time slot after the last block
+func maxPossibleBlockID(now time.Time) (int64, error) {
+	infoBlock := &model.InfoBlock{}
+	found, err := infoBlock.Get()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("Getting cur blockID")
+		return 0, err
+	}
+	if !found {
+		return 0, nil
+	}
+	slot := time.Millisecond*time.Duration(syspar.GetMaxBlockGenerationTime()) +
+		time.Second*time.Duration(syspar.GetGapsBetweenBlocks())
+	if slot < time.Second {
+		slot = time.Second
+	}
+	return infoBlock.BlockID + int64(now.Sub(time.Unix(infoBlock.Time, 0))/slot) + 1, nil
+}
+
 // ReplaceBlocksFromHost replaces blockchain received from the host.
 // Number (replaceCount) of blocks starting from blockID will be re-played.
 func ReplaceBlocksFromHost(ctx context.Context, host string, blockID, replaceCount int64) error {
@@ -367,6 +405,7 @@ func processBlocks(blocks []*block.Block) error {
 		if prevBlocks[b.Header.BlockID-1] != nil {
 			b.PrevHeader.Hash = prevBlocks[b.Header.BlockID-1].Header.Hash
 			b.PrevHeader.RollbacksHash = prevBlocks[b.Header.BlockID-1].Header.RollbacksHash
+			b.PrevHeader.StateRoot = prevBlocks[b.Header.BlockID-1].Header.StateRoot
 			b.PrevHeader.Time = prevBlocks[b.Header.BlockID-1].Header.Time
 			b.PrevHeader.BlockID = prevBlocks[b.Header.BlockID-1].Header.BlockID
 			b.PrevHeader.EcosystemID = prevBlocks[b.Header.BlockID-1].Header.EcosystemID
@@ -378,12 +417,12 @@ func processBlocks(blocks []*block.Block) error {
 
 		if err := b.Check(); err != nil {
 			dbTransaction.Rollback()
-			return err
+			return &blockError{id: b.Header.BlockID, time: b.Header.Time, err: err}
 		}
 
 		if err := b.Play(dbTransaction); err != nil {
 			dbTransaction.Rollback()
-			return utils.ErrInfo(err)
+			return &blockError{id: b.Header.BlockID, time: b.Header.Time, err: utils.ErrInfo(err)}
 		}
 		prevBlocks[b.Header.BlockID] = b
 
@@ -413,5 +452,15 @@ func processBlocks(blocks []*block.Block) error {
 		}
 	}
 
-	return dbTransaction.Commit()
+	if err := dbTransaction.Commit(); err != nil {
+		return err
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		for _, q := range blocks[i].Notifications {
+			q.Send()
+		}
+		blocks[i].PublishEvents()
+	}
+	return nil
 }
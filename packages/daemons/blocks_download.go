@@ -0,0 +1,229 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/peers"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+	"github.com/IBAX-io/go-ibax/packages/statsd"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxDownloadPeers is the max count of peers which the blocks are downloaded from at the same time
+	maxDownloadPeers = 4
+	// rangesPerPeer is the count of ranges which are downloaded ahead for every peer
+	rangesPerPeer = 2
+)
+
+// blocksRange is the range of blocks which are downloaded by one request
+type blocksRange struct {
+	from, to int64
+	host     string
+	blocks   []*block.Block
+	err      error
+}
+
+// blocksDownloader downloads the ranges of blocks from several peers concurrently,
+// parses and checks the signs of blocks in parallel and returns the ranges in order
+type blocksDownloader struct {
+	ctx    context.Context
+	logger *log.Entry
+	// host has the max block, the ranges which haven't been got from other peers are downloaded from it
+	host  string
+	hosts []string
+}
+
+func newBlocksDownloader(ctx context.Context, logger *log.Entry, host string) *blocksDownloader {
+	hosts := []string{host}
+	for _, h := range peers.GetManager().Hosts(maxDownloadPeers) {
+		if h != host && len(hosts) < maxDownloadPeers {
+			hosts = append(hosts, h)
+		}
+	}
+	return &blocksDownloader{ctx: ctx, logger: logger, host: host, hosts: hosts}
+}
+
+// parallel calls f for 0..n-1 in the goroutines, their count is limited by the count of CPU
+func parallel(n int, f func(i int) error) error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = f(i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetch downloads and parses the range of blocks from the host
+func (bd *blocksDownloader) fetch(r *blocksRange, host string) error {
+	ctx, cancel := context.WithCancel(bd.ctx)
+	defer cancel()
+
+	start := time.Now()
+	rawBlocksChan, err := tcpclient.GetBlocksBodies(ctx, host, r.from, false)
+	if err != nil {
+		return err
+	}
+
+	count := int(r.to - r.from + 1)
+	raw := make([][]byte, 0, count)
+	for data := range rawBlocksChan {
+		if len(raw) < count {
+			// the bodies are stored in the pooled buffer which is released after cancel
+			raw = append(raw, append([]byte{}, data...))
+		}
+	}
+	if len(raw) < count {
+		return fmt.Errorf("host %s returned %d blocks from %d instead of %d", host, len(raw), r.from, count)
+	}
+	statsd.Client.TimingDuration(statsd.BlocksCollectionCounterName("download")+statsd.Time, time.Since(start), 1.0)
+
+	start = time.Now()
+	blocks := make([]*block.Block, count)
+	err = parallel(count, func(i int) (err error) {
+		if blocks[i], err = block.ParseBlock(raw[i], true); err != nil {
+			return &blockError{id: r.from + int64(i), err: err}
+		}
+		if blocks[i].Header.BlockID != r.from+int64(i) {
+			return &blockError{id: r.from + int64(i), time: blocks[i].Header.Time,
+				err: utils.WithBan(errors.New("bad block_data['block_id']"))}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	statsd.Client.TimingDuration(statsd.BlocksCollectionCounterName("parse")+statsd.Time, time.Since(start), 1.0)
+
+	r.blocks, r.host = blocks, host
+	return nil
+}
+
+// download fetches the range from the assigned peer, the range is downloaded from the host
+// with the max block if the peer has failed
+func (bd *blocksDownloader) download(r *blocksRange, host string) {
+	err := bd.fetch(r, host)
+	if err != nil && host != bd.host && bd.ctx.Err() == nil {
+		bd.logger.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "host": host, "from": r.from}).Warn("downloading blocks from peer")
+		if utils.IsBanError(err) {
+			peers.GetManager().RecordBadBlock(host, syspar.GetLocalNodeBanTime())
+		} else {
+			peers.GetManager().RecordFailure(host)
+		}
+		host = bd.host
+		err = bd.fetch(r, host)
+	}
+	if err != nil {
+		r.host, r.err = host, err
+	}
+}
+
+// run downloads the blocks from..to and returns the ranges of blocks in order.
+// The signs of blocks are checked in parallel with the hash of previous block, prevHash is the hash of block from-1.
+func (bd *blocksDownloader) run(from, to int64, prevHash []byte) <-chan *blocksRange {
+	var ranges []*blocksRange
+	for id := from; id <= to; id += int64(network.BlocksPerRequest) {
+		r := &blocksRange{from: id, to: id + int64(network.BlocksPerRequest) - 1}
+		if r.to > to {
+			r.to = to
+		}
+		ranges = append(ranges, r)
+	}
+
+	// the window limits the count of ranges which are kept in memory
+	window := make(chan struct{}, len(bd.hosts)*rangesPerPeer)
+	done := make([]chan struct{}, len(ranges))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	go func() {
+		for i, r := range ranges {
+			select {
+			case window <- struct{}{}:
+			case <-bd.ctx.Done():
+				return
+			}
+			go func(i int, r *blocksRange) {
+				bd.download(r, bd.hosts[i%len(bd.hosts)])
+				close(done[i])
+			}(i, r)
+		}
+	}()
+
+	out := make(chan *blocksRange, 1)
+	go func() {
+		defer close(out)
+		for i, r := range ranges {
+			select {
+			case <-done[i]:
+			case <-bd.ctx.Done():
+				return
+			}
+			<-window
+
+			if r.err == nil {
+				start := time.Now()
+				prevHash = bd.checkSigns(r.blocks, prevHash)
+				statsd.Client.TimingDuration(statsd.BlocksCollectionCounterName("verify")+statsd.Time, time.Since(start), 1.0)
+			}
+			select {
+			case out <- r:
+			case <-bd.ctx.Done():
+				return
+			}
+			if r.err != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// checkSigns builds the chain of hashes of blocks and checks their signs in parallel, it returns the hash of last block.
+// The wrong sign isn't the error here because the node keys can be changed by the previous blocks,
+// such blocks are checked again when they are played.
+func (bd *blocksDownloader) checkSigns(blocks []*block.Block, prevHash []byte) []byte {
+	prevHashes := make([][]byte, len(blocks))
+	for i, b := range blocks {
+		prevHashes[i] = prevHash
+		prevHash = b.HashWithPrev(prevHash)
+	}
+	parallel(len(blocks), func(i int) error {
+		if err := blocks[i].PreCheckSign(prevHashes[i]); err != nil {
+			bd.logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err, "block_id": blocks[i].Header.BlockID}).Debug("pre-checking block sign")
+		}
+		return nil
+	})
+	return prevHash
+}
@@ -0,0 +1,81 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallel(t *testing.T) {
+	var running, maxRunning int32
+	results := make([]int, 100)
+	err := parallel(len(results), func(i int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		results[i] = i * i
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, int(maxRunning), runtime.NumCPU())
+	for i, v := range results {
+		assert.Equal(t, i*i, v)
+	}
+
+	errBlock := errors.New("bad block")
+	err = parallel(10, func(i int) error {
+		if i == 7 {
+			return errBlock
+		}
+		return nil
+	})
+	assert.Equal(t, errBlock, err)
+}
+
+func TestFailedBlock(t *testing.T) {
+	errBlock := errors.New("bad block")
+	id, tm := failedBlock(&blockError{id: 15, time: 1000, err: errBlock}, 11)
+	assert.Equal(t, int64(15), id)
+	assert.Equal(t, int64(1000), tm)
+	assert.Equal(t, errBlock, pkgerrors.Cause(&blockError{id: 15, err: errBlock}))
+
+	// the error which doesn't belong to the block points to the first block of range
+	id, tm = failedBlock(errBlock, 11)
+	assert.Equal(t, int64(11), id)
+	assert.Equal(t, int64(0), tm)
+}
+
+func TestForkDepth(t *testing.T) {
+	prevHash := &blockError{id: 11, err: pkgerrors.Wrap(block.ErrIncorrectPrevHash, "block 10")}
+	for _, v := range []struct {
+		err     error
+		blockID int64
+		depth   int64
+	}{
+		{prevHash, 11, 1},
+		{&blockError{id: 11, err: block.ErrIncorrectRollbackHash}, 11, 2},
+		{&blockError{id: 11, err: block.ErrIncorrectStateRoot}, 11, 2},
+		// the invalid block isn't the fork
+		{&blockError{id: 11, err: errors.New("bad block")}, 11, 0},
+		// the fork is only checked for the block following our last block
+		{prevHash, 12, 0},
+	} {
+		assert.Equal(t, v.depth, forkDepth(v.err, v.blockID, 10), v.err.Error())
+	}
+}
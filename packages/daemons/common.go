@@ -44,6 +44,8 @@ var daemonsList = map[string]func(context.Context, *daemon) error{
 	"Confirmations":     Confirmations,
 	"Scheduler":         Scheduler,
 	"ExternalNetwork":   ExternalNetwork,
+	"LightHeaders":      LightHeaders,
+	"PeersDiscovery":    PeersDiscovery,
 
 	"SubNodeSrcTaskInstallChannel": SubNodeSrcTaskInstallChannel,
 	"SubNodeSrcData":               SubNodeSrcData,
@@ -9,6 +9,7 @@ import (
 	"context"
 	"sync/atomic"
 
+	"github.com/IBAX-io/go-ibax/packages/network/peers"
 	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
 
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
@@ -62,7 +63,11 @@ func sendTransactions(ctx context.Context, logger *log.Entry) error {
 		return nil
 	}
 
+	// the transactions are sent to the honor nodes which aren't banned, the best ones are first
 	hosts := syspar.GetDefaultRemoteHosts()
+	if best := peers.GetManager().Best(hosts, 0); len(best) > 0 {
+		hosts = best
+	}
 
 	if err := tcpclient.SendTransacitionsToAll(ctx, hosts, *trs); err != nil {
 		log.WithFields(log.Fields{"type": consts.NetworkError, "error": err}).Error("on sending transactions")
@@ -20,6 +20,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
 	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/signer"
 	"github.com/IBAX-io/go-ibax/packages/transaction"
 
 	log "github.com/sirupsen/logrus"
@@ -38,27 +39,22 @@ const (
 )
 
 var (
-	nodePrivateKey []byte
-	nodeKeyID      int64
-	nodePublicKey  string
-	authNet        = map[string]string{}
+	nodeKeyID int64
+	authNet   = map[string]string{}
 )
 
 func loginNetwork(urlPath string) (connect *api.Connect, err error) {
-	if len(nodePrivateKey) == 0 {
-		var pubKey []byte
-		nodePrivateKey = syspar.GetNodePrivKey()
-		if pubKey, err = crypto.PrivateToPublic(nodePrivateKey); err != nil {
-			return
-		}
-		nodeKeyID = crypto.Address(pubKey)
-		nodePublicKey = crypto.PubToHex(pubKey)
+	nodeSigner := syspar.GetNodeSigner()
+	if nodeSigner == nil {
+		err = signer.ErrEmptyKey
+		return
 	}
+	nodeKeyID = crypto.Address(nodeSigner.PublicKey())
 	connect = &api.Connect{
-		Auth:       authNet[urlPath],
-		PrivateKey: nodePrivateKey,
-		PublicKey:  nodePublicKey,
-		Root:       urlPath,
+		Auth:      authNet[urlPath],
+		PublicKey: crypto.PubToHex(nodeSigner.PublicKey()),
+		Signer:    nodeSigner,
+		Root:      urlPath,
 	}
 	if err = connect.Login(); err != nil {
 		authNet[urlPath] = connect.Auth
@@ -93,7 +89,7 @@ func SendExternalTransaction() error {
 				"Msg":    resText,
 				"Block":  block,
 				"UID":    item.Uid,
-			}, nodePrivateKey); err != nil {
+			}, syspar.GetNodeSigner()); err != nil {
 			log.WithFields(log.Fields{"type": consts.ContractError, "err": err}).Error("CreateContract")
 		}
 	}
@@ -0,0 +1,220 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"sync/atomic"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/light"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// lightKeys are the keys of honor nodes which are trusted by the light node
+var lightKeys *light.NodeKeys
+
+// LightHeaders downloads the blocks and stores their headers after checking the chain of hashes
+// and the signs of nodes. The transactions of blocks aren't played.
+func LightHeaders(ctx context.Context, d *daemon) error {
+	if ctx.Err() != nil {
+		d.logger.WithFields(log.Fields{"type": consts.ContextError, "error": ctx.Err()}).Error("context error")
+		return ctx.Err()
+	}
+	if !atomic.CompareAndSwapUint32(&d.atomic, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreUint32(&d.atomic, 0)
+
+	if lightKeys == nil {
+		nodeKeys, err := lightNodeKeys()
+		if err != nil {
+			d.logger.WithFields(log.Fields{"type": consts.ParseError, "error": err}).Error("decoding node keys")
+			return err
+		}
+		lightKeys = light.NewNodeKeys(nodeKeys)
+	}
+
+	last, err := lastLightHeader()
+	if err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting last header")
+		return err
+	}
+	curBlockID := last.ID
+
+	host, maxBlockID, err := tcpclient.HostWithMaxBlock(ctx, conf.GetNodesAddr())
+	if err != nil {
+		d.logger.WithFields(log.Fields{"error": err}).Warn("on checking best host")
+		return err
+	}
+	if curBlockID >= maxBlockID {
+		return nil
+	}
+
+	d.logger.WithFields(log.Fields{"min_block": curBlockID, "max_block": maxBlockID}).Info("starting downloading headers")
+	for blockID := curBlockID + 1; blockID <= maxBlockID; blockID += int64(network.BlocksPerRequest) {
+		if loopErr := func() error {
+			ctxDone, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			rawBlocksChan, err := tcpclient.GetBlocksBodies(ctxDone, host, blockID, false)
+			if err != nil {
+				d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("getting block body")
+				return err
+			}
+			for rawBlock := range rawBlocksChan {
+				header, err := light.ParseBlock(rawBlock)
+				if err != nil {
+					d.logger.WithFields(log.Fields{"error": err, "type": consts.BlockError}).Error("parsing block")
+					return err
+				}
+				if err = verifyLightHeader(host, header, last); err != nil {
+					d.logger.WithFields(log.Fields{"error": err, "from_host": host, "block_id": header.BlockID, "type": consts.BlockError}).Error("checking block header")
+					// the headers after the common block are replaced on the next run if the host has another chain
+					ancestor, rerr := lightRollback(ctx, host, last)
+					if rerr != nil || ancestor == nil {
+						return err
+					}
+					d.logger.WithFields(log.Fields{"from_host": host, "block_id": ancestor.ID, "last_block_id": last.ID}).Warn("rolled back light headers")
+					return nil
+				}
+				last = header.LightHeader()
+				if err = last.Create(nil); err != nil {
+					d.logger.WithFields(log.Fields{"error": err, "type": consts.DBError}).Error("creating light header")
+					return err
+				}
+			}
+			return nil
+		}(); loopErr != nil {
+			return loopErr
+		}
+	}
+	return nil
+}
+
+// lightNodeKeys returns the public keys of honor nodes from the config
+func lightNodeKeys() ([][]byte, error) {
+	keys := make([][]byte, 0, len(conf.Config.Light.NodeKeys))
+	for _, key := range conf.Config.Light.NodeKeys {
+		pub, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, pub)
+	}
+	return keys, nil
+}
+
+// verifyLightHeader checks the header by the trusted keys. The keys of honor nodes are requested
+// again if the block is signed by an unknown key, the honor nodes could be changed by the chain.
+func verifyLightHeader(host string, header *light.Header, last *model.LightHeader) (err error) {
+	if header.Version == consts.BvRollbackHash {
+		if header.Prev.RollbacksHash, err = tcpclient.GetRollbacksHash(host, last.ID); err != nil {
+			return err
+		}
+	}
+	if err = lightKeys.Verify(header, last); err != light.ErrNodeKey && err != light.ErrBlockSign {
+		return err
+	}
+	if !updateLightKeys() {
+		return err
+	}
+	return lightKeys.Verify(header, last)
+}
+
+// updateLightKeys requests the keys of honor nodes from the nodes of the config. The keys
+// are trusted if they are received from the majority of nodes, it returns true if they have been changed.
+func updateLightKeys() bool {
+	hosts := conf.GetNodesAddr()
+	lists := make([][][]byte, 0, len(hosts))
+	for _, host := range hosts {
+		keys, err := tcpclient.GetHonorNodes(host)
+		if err != nil {
+			continue
+		}
+		lists = append(lists, keys)
+	}
+	keys, ok := light.MajorityKeys(lists, len(hosts))
+	if !ok {
+		log.WithFields(log.Fields{"type": consts.NotFound, "hosts": len(hosts)}).Warn("honor nodes aren't confirmed by the majority of nodes")
+		return false
+	}
+	return lightKeys.Add(keys)
+}
+
+// lightRollback searches the last header which is the same in the chain of the host. The headers after it
+// are deleted and it is returned. It returns nil if the host doesn't have another chain. The search is limited
+// by the count of blocks per request and the checkpoint isn't rolled back.
+func lightRollback(ctx context.Context, host string, last *model.LightHeader) (*model.LightHeader, error) {
+	ctxDone, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rawBlocksChan, err := tcpclient.GetBlocksBodies(ctxDone, host, last.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	for rawBlock := range rawBlocksChan {
+		header, err := light.ParseBlock(rawBlock)
+		if err != nil {
+			return nil, err
+		}
+		prev, cur := &model.LightHeader{}, &model.LightHeader{}
+		found, err := prev.Get(header.BlockID - 1)
+		if err != nil || !found {
+			return nil, err
+		}
+		if err = verifyLightHeader(host, header, prev); err != nil {
+			continue
+		}
+		if found, err = cur.Get(header.BlockID); err != nil {
+			return nil, err
+		}
+		if found && bytes.Equal(cur.Hash, header.Hash) {
+			// the next block of the host is wrong, the chain is the same
+			if cur.ID == last.ID {
+				return nil, nil
+			}
+			prev = cur
+		}
+		if err = model.DeleteLightHeadersAfter(nil, prev.ID); err != nil {
+			return nil, err
+		}
+		return prev, nil
+	}
+	return nil, nil
+}
+
+// lastLightHeader returns the last stored header. The trusted checkpoint is stored
+// as the first header, it is required to start the sync.
+func lastLightHeader() (*model.LightHeader, error) {
+	last := &model.LightHeader{}
+	found, err := last.GetLast()
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return last, nil
+	}
+	if conf.Config.Light.CheckpointID == 0 {
+		return nil, light.ErrNoCheckpoint
+	}
+	hash, err := hex.DecodeString(conf.Config.Light.CheckpointHash)
+	if err != nil {
+		return nil, err
+	}
+	last = &model.LightHeader{ID: conf.Config.Light.CheckpointID, Hash: hash}
+	if err = last.Create(nil); err != nil {
+		return nil, err
+	}
+	return last, nil
+}
@@ -0,0 +1,104 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package daemons
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/peers"
+	"github.com/IBAX-io/go-ibax/packages/network/tcpclient"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// gossipPeers is the count of the best peers which are asked for their peers every round
+	gossipPeers = 3
+	// checkPeers is the count of the discovered peers which are checked every round
+	checkPeers = 10
+)
+
+// PeersDiscovery gossips the known addresses between nodes and checks the discovered peers.
+// The statistics of peers are used to choose the best peers for block collection and
+// transaction dissemination.
+func PeersDiscovery(ctx context.Context, d *daemon) error {
+	if ctx.Err() != nil {
+		d.logger.WithFields(log.Fields{"type": consts.ContextError, "error": ctx.Err()}).Error("context error")
+		return ctx.Err()
+	}
+	if !atomic.CompareAndSwapUint32(&d.atomic, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreUint32(&d.atomic, 0)
+	d.sleepTime = 30 * time.Second
+
+	pm := peers.GetManager()
+	pm.AddStatic(syspar.GetRemoteHosts()...)
+	pm.AddStatic(conf.GetNodesAddr()...)
+	if err := restoreBans(pm); err != nil {
+		d.logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("restoring bans of nodes")
+	}
+
+	self := map[string]bool{
+		conf.Config.TCPServer.Str(): true,
+		conf.Config.Peers.Announce:  true,
+	}
+	for _, host := range pm.Best(pm.Hosts(0), gossipPeers) {
+		hosts, err := tcpclient.GetPeers(host, conf.Config.Peers.Announce)
+		if err != nil {
+			if err != network.ErrUnsupportedRequest {
+				d.logger.WithFields(log.Fields{"type": consts.NetworkError, "error": err, "host": host}).Debug("getting peers")
+			}
+			continue
+		}
+		for _, h := range hosts {
+			if !self[h] {
+				pm.Add(h)
+			}
+		}
+	}
+
+	// the discovered peers are asked for the max block to measure their latency
+	var wg sync.WaitGroup
+	for _, host := range pm.Unchecked(checkPeers) {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			start := time.Now()
+			if _, err := tcpclient.GetMaxBlockID(host); err != nil {
+				pm.RecordFailure(host)
+				return
+			}
+			pm.RecordLatency(host, time.Since(start))
+		}(host)
+	}
+	wg.Wait()
+	return nil
+}
+
+// restoreBans bans the honor nodes which have been banned in node_ban_logs,
+// so the bans are kept after the restart of node
+func restoreBans(pm *peers.Manager) error {
+	bans, err := (&model.NodeBanLogs{}).GetNodeBans(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, node := range syspar.GetNodes() {
+		if to, ok := bans[crypto.Address(node.PublicKey)]; ok {
+			pm.Ban(node.TCPAddress, to)
+		}
+	}
+	return nil
+}
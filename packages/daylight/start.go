@@ -8,6 +8,7 @@ package daylight
 import (
 	"context"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -30,6 +31,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/obsmanager"
 	"github.com/IBAX-io/go-ibax/packages/publisher"
 	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/snapshot"
 	"github.com/IBAX-io/go-ibax/packages/statsd"
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
@@ -43,6 +45,29 @@ func initStatsd() {
 	}
 }
 
+// importSnapshot loads the state snapshot if the node doesn't have blocks yet,
+// so the blocks collection continues from the next block after the snapshot
+func importSnapshot(cfg conf.SnapshotConfig) error {
+	if len(cfg.Path) == 0 {
+		return nil
+	}
+	hash, err := hex.DecodeString(cfg.Hash)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConfigError, "error": err}).Error("decoding snapshot hash")
+		return err
+	}
+	logger := log.WithFields(log.Fields{"snapshot": cfg.Path})
+	_, err = snapshot.ImportFile(cfg.Path, hash, logger)
+	if err == snapshot.ErrNotEmpty {
+		logger.Info("blockchain isn't empty, snapshot is skipped")
+		return nil
+	}
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("importing snapshot")
+	}
+	return err
+}
+
 func killOld() {
 	pidPath := conf.Config.GetPidPath()
 	if _, err := os.Stat(pidPath); err == nil {
@@ -225,6 +250,10 @@ func Start() {
 	initGorm(conf.Config.DB)
 	log.WithFields(log.Fields{"work_dir": conf.Config.DataDir, "version": consts.Version()}).Info("started with")
 
+	if err := importSnapshot(conf.Config.Snapshot); err != nil {
+		Exit(1)
+	}
+
 	killOld()
 
 	publisher.InitCentrifugo(conf.Config.Centrifugo)
@@ -0,0 +1,152 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package light
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+)
+
+const (
+	firstBlock   = 1
+	minBlockSize = 9
+)
+
+var (
+	// ErrBlockSize is returned when the binary block is too short
+	ErrBlockSize = errors.New("Bad block size")
+	// ErrRollbacksHash is returned when the rollbacks hash of the previous block is required to check the block
+	ErrRollbacksHash = errors.New("Rollbacks hash of the previous block is unknown")
+	// ErrNoCheckpoint is returned when the trusted checkpoint isn't set
+	ErrNoCheckpoint = errors.New("Checkpoint of light node isn't set")
+	// ErrPrevBlock is returned when the block doesn't follow the last checked block
+	ErrPrevBlock = errors.New("Block doesn't follow the previous block")
+	// ErrBlockTime is returned when the block is older than the previous block
+	ErrBlockTime = errors.New("Time of block is less than time of the previous block")
+	// ErrNodeKey is returned when the public key of the node which has generated the block is unknown
+	ErrNodeKey = errors.New("Public key of node isn't found")
+	// ErrBlockSign is returned when the sign of block is incorrect
+	ErrBlockSign = errors.New("Incorrect sign of block")
+)
+
+// Header is the header of block with the Merkle root of its transactions
+type Header struct {
+	utils.BlockData
+	// Prev contains the rollbacks hash and the state root of previous block embedded in the header
+	Prev     utils.BlockData
+	MrklRoot []byte
+	TxCount  int
+}
+
+// ParseBlock parses the binary block as UnmarshallBlock does, but the transactions are only
+// hashed for the Merkle root and they aren't unmarshalled. The size of block isn't limited by
+// the system parameters because the light node doesn't have them.
+func ParseBlock(data []byte) (*Header, error) {
+	if len(data) < minBlockSize {
+		return nil, ErrBlockSize
+	}
+	buf := bytes.NewBuffer(data)
+	h := &Header{}
+	h.Version = int(converter.BinToDec(buf.Next(2)))
+	h.BlockID = converter.BinToDec(buf.Next(4))
+	h.Time = converter.BinToDec(buf.Next(4))
+	h.EcosystemID = converter.BinToDec(buf.Next(4))
+	var err error
+	if h.KeyID, err = converter.DecodeLenInt64Buf(buf); err != nil {
+		return nil, err
+	}
+	h.NodePosition = converter.BinToDec(buf.Next(1))
+	if h.Version >= consts.BvIncludeRollbackHash {
+		if h.Prev.RollbacksHash, err = converter.DecodeBytesBuf(buf); err != nil {
+			return nil, err
+		}
+	}
+	if h.Version >= consts.BvStateRoot {
+		if h.Prev.StateRoot, err = converter.DecodeBytesBuf(buf); err != nil {
+			return nil, err
+		}
+	}
+	if h.BlockID == firstBlock {
+		buf.Next(1)
+	} else if h.Sign, err = converter.DecodeBytesBuf(buf); err != nil {
+		return nil, err
+	}
+
+	mrklSlice := make([][]byte, 0)
+	for buf.Len() > 0 {
+		size, err := converter.DecodeLengthBuf(buf)
+		if err != nil {
+			return nil, fmt.Errorf("bad block format (%s)", err)
+		}
+		if size == 0 || buf.Len() < size {
+			return nil, fmt.Errorf("bad block format (transaction len is wrong: %d)", size)
+		}
+		mrklSlice = append(mrklSlice, utils.MrklLeaf(buf.Next(size)))
+	}
+	h.TxCount = len(mrklSlice)
+	if len(mrklSlice) == 0 {
+		mrklSlice = append(mrklSlice, []byte("0"))
+	}
+	if h.MrklRoot, err = utils.MerkleTreeRoot(mrklSlice); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Verify checks that the block follows the previous checked block and it is signed by the node
+// at its position. The chain starts from the trusted checkpoint, so the previous header is required
+// even for the blocks after genesis. The hash of block is set on success.
+func (h *Header) Verify(prev *model.LightHeader, nodeKeys [][]byte) error {
+	if prev == nil || h.BlockID != prev.ID+1 {
+		return ErrPrevBlock
+	}
+	// this version signs the rollbacks hash of previous block without including it in the block,
+	// the hash is requested from the node and the sign of block proves it
+	if h.Version == consts.BvRollbackHash && len(h.Prev.RollbacksHash) == 0 {
+		return ErrRollbacksHash
+	}
+	if h.Time < prev.Time {
+		return ErrBlockTime
+	}
+	if h.NodePosition < 0 || h.NodePosition >= int64(len(nodeKeys)) {
+		return ErrNodeKey
+	}
+	prevData := utils.BlockData{
+		Hash:          prev.Hash,
+		RollbacksHash: h.Prev.RollbacksHash,
+		StateRoot:     h.Prev.StateRoot,
+	}
+	forSign := h.ForSign(&prevData, h.MrklRoot)
+	if ok, err := utils.CheckSign([][]byte{nodeKeys[h.NodePosition]}, []byte(forSign), h.Sign, true); err != nil || !ok {
+		return ErrBlockSign
+	}
+	h.Hash = crypto.DoubleHash([]byte(h.ForSha(&prevData, h.MrklRoot)))
+	return nil
+}
+
+// LightHeader returns the model of checked header
+func (h *Header) LightHeader() *model.LightHeader {
+	return &model.LightHeader{
+		ID:                h.BlockID,
+		Hash:              h.Hash,
+		MrklRoot:          h.MrklRoot,
+		PrevRollbacksHash: h.Prev.RollbacksHash,
+		PrevStateRoot:     h.Prev.StateRoot,
+		EcosystemID:       h.EcosystemID,
+		KeyID:             h.KeyID,
+		NodePosition:      h.NodePosition,
+		Time:              h.Time,
+		Version:           h.Version,
+		Tx:                int32(h.TxCount),
+	}
+}
@@ -0,0 +1,160 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package light
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// marshallBlock builds the binary block in the same way as block.MarshallBlock
+func marshallBlock(t *testing.T, header, prev *utils.BlockData, txs [][]byte, key []byte) []byte {
+	mrklArray := make([][]byte, 0, len(txs))
+	for _, tx := range txs {
+		mrklArray = append(mrklArray, utils.MrklLeaf(tx))
+	}
+	if len(mrklArray) == 0 {
+		mrklArray = append(mrklArray, []byte("0"))
+	}
+	mrklRoot, err := utils.MerkleTreeRoot(mrklArray)
+	require.NoError(t, err)
+
+	var sign []byte
+	if key != nil {
+		sign, err = crypto.Sign(key, []byte(header.ForSign(prev, mrklRoot)))
+		require.NoError(t, err)
+	}
+
+	buf := new(bytes.Buffer)
+	buf.Write(converter.DecToBin(header.Version, 2))
+	buf.Write(converter.DecToBin(header.BlockID, 4))
+	buf.Write(converter.DecToBin(header.Time, 4))
+	buf.Write(converter.DecToBin(header.EcosystemID, 4))
+	buf.Write(converter.EncodeLenInt64InPlace(header.KeyID))
+	buf.Write(converter.DecToBin(header.NodePosition, 1))
+	if header.Version >= consts.BvIncludeRollbackHash {
+		buf.Write(converter.EncodeLengthPlusData(prev.RollbacksHash))
+	}
+	if header.Version >= consts.BvStateRoot {
+		buf.Write(converter.EncodeLengthPlusData(prev.StateRoot))
+	}
+	if header.BlockID == 1 {
+		buf.WriteByte(0)
+	} else {
+		buf.Write(converter.EncodeLengthPlusData(sign))
+	}
+	for _, tx := range txs {
+		buf.Write(converter.EncodeLengthPlusData(tx))
+	}
+	return buf.Bytes()
+}
+
+func TestVerifyHeaders(t *testing.T) {
+	crypto.InitHash("SHA256")
+	crypto.InitCurve("ECDSA")
+	private, public, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	_, otherPublic, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+
+	genesis := &utils.BlockData{BlockID: 1, Time: 100, EcosystemID: 1, KeyID: 5, Version: consts.BvStateRoot}
+	first, err := ParseBlock(marshallBlock(t, genesis, &utils.BlockData{}, [][]byte{[]byte("first")}, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.TxCount)
+	// the genesis block isn't signed, so the chain starts from the trusted checkpoint
+	assert.Equal(t, ErrPrevBlock, first.Verify(nil, [][]byte{public}))
+	last := &model.LightHeader{ID: 1, Time: 100,
+		Hash: crypto.DoubleHash([]byte(genesis.ForSha(&utils.BlockData{}, first.MrklRoot)))}
+
+	header := &utils.BlockData{BlockID: 2, Time: 110, EcosystemID: 1, KeyID: 5, Version: consts.BvStateRoot}
+	prev := &utils.BlockData{Hash: last.Hash, RollbacksHash: []byte("rollbacks"), StateRoot: []byte("state")}
+	data := marshallBlock(t, header, prev, [][]byte{[]byte("tx1"), []byte("tx2")}, private)
+
+	next, err := ParseBlock(data)
+	require.NoError(t, err)
+	assert.Equal(t, ErrNodeKey, next.Verify(last, nil))
+	assert.Equal(t, ErrBlockSign, next.Verify(last, [][]byte{otherPublic}))
+	require.NoError(t, next.Verify(last, [][]byte{public}))
+	assert.Equal(t, crypto.DoubleHash([]byte(header.ForSha(prev, next.MrklRoot))), next.Hash)
+	assert.Equal(t, prev.StateRoot, next.LightHeader().PrevStateRoot)
+
+	// the block must follow the last checked block
+	assert.Equal(t, ErrPrevBlock, next.Verify(next.LightHeader(), [][]byte{public}))
+	assert.Equal(t, ErrPrevBlock, next.Verify(nil, [][]byte{public}))
+
+	// the changed transactions don't match the signed Merkle root
+	data = append(data[:len(data)-3], []byte("tx3")...)
+	changed, err := ParseBlock(data)
+	require.NoError(t, err)
+	assert.Equal(t, ErrBlockSign, changed.Verify(last, [][]byte{public}))
+
+	_, err = ParseBlock([]byte{0, 4})
+	assert.Equal(t, ErrBlockSize, err)
+}
+
+func TestVerifyRollbackHash(t *testing.T) {
+	crypto.InitHash("SHA256")
+	crypto.InitCurve("ECDSA")
+	private, public, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+
+	last := &model.LightHeader{ID: 7, Time: 100, Hash: []byte("checkpoint")}
+	header := &utils.BlockData{BlockID: 8, Time: 110, EcosystemID: 1, KeyID: 5, Version: consts.BvRollbackHash}
+	prev := &utils.BlockData{Hash: last.Hash, RollbacksHash: []byte("rollbacks")}
+	next, err := ParseBlock(marshallBlock(t, header, prev, [][]byte{[]byte("tx")}, private))
+	require.NoError(t, err)
+
+	// the rollbacks hash isn't included in the block of this version
+	assert.Equal(t, ErrRollbacksHash, next.Verify(last, [][]byte{public}))
+	next.Prev.RollbacksHash = []byte("wrong")
+	assert.Equal(t, ErrBlockSign, next.Verify(last, [][]byte{public}))
+	next.Prev.RollbacksHash = prev.RollbacksHash
+	require.NoError(t, next.Verify(last, [][]byte{public}))
+	assert.Equal(t, crypto.DoubleHash([]byte(header.ForSha(prev, next.MrklRoot))), next.Hash)
+}
+
+func TestNodeKeys(t *testing.T) {
+	crypto.InitHash("SHA256")
+	crypto.InitCurve("ECDSA")
+	oldPrivate, oldPublic, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	newPrivate, newPublic, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+
+	keys := NewNodeKeys([][]byte{oldPublic})
+	assert.False(t, keys.Add([][]byte{oldPublic}))
+	assert.True(t, keys.Add([][]byte{newPublic}))
+	assert.Equal(t, [][]byte{newPublic}, keys.Newest())
+
+	block := func(id int64, key []byte) (*Header, *model.LightHeader) {
+		last := &model.LightHeader{ID: id - 1, Time: 100, Hash: []byte("prev")}
+		header := &utils.BlockData{BlockID: id, Time: 110, EcosystemID: 1, KeyID: 5, Version: consts.BvStateRoot}
+		next, err := ParseBlock(marshallBlock(t, header, &utils.BlockData{Hash: last.Hash}, nil, key))
+		require.NoError(t, err)
+		return next, last
+	}
+	// the old list checks the previous blocks until the block of the new list
+	require.NoError(t, keys.Verify(block(10, oldPrivate)))
+	require.NoError(t, keys.Verify(block(11, newPrivate)))
+	assert.Equal(t, ErrBlockSign, keys.Verify(block(12, oldPrivate)))
+
+	_, otherPublic, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	_, ok := MajorityKeys([][][]byte{{otherPublic}, {newPublic}, nil, {newPublic}}, 4)
+	assert.False(t, ok)
+	list, ok := MajorityKeys([][][]byte{{newPublic}, {otherPublic}, {newPublic}}, 3)
+	assert.True(t, ok)
+	assert.Equal(t, [][]byte{newPublic}, list)
+}
@@ -0,0 +1,94 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package light
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/model"
+)
+
+// NodeKeys is the history of the lists of honor node keys which are trusted by the light node.
+// The new list is added when the honor nodes are changed, the older lists are kept
+// to check the previous blocks until a block signed by the newer list is reached.
+type NodeKeys struct {
+	lists [][][]byte
+}
+
+// NewNodeKeys returns the history which starts from the keys of the config
+func NewNodeKeys(keys [][]byte) *NodeKeys {
+	return &NodeKeys{lists: [][][]byte{keys}}
+}
+
+// Add appends the newest list of keys, it returns false if the list is the same as the newest one
+func (k *NodeKeys) Add(keys [][]byte) bool {
+	if len(k.lists) > 0 && equalKeys(k.lists[len(k.lists)-1], keys) {
+		return false
+	}
+	k.lists = append(k.lists, keys)
+	return true
+}
+
+// Newest returns the newest list of keys
+func (k *NodeKeys) Newest() [][]byte {
+	if len(k.lists) == 0 {
+		return nil
+	}
+	return k.lists[len(k.lists)-1]
+}
+
+// Verify checks the header by the lists from the oldest to the newest. The lists which are
+// older than the list which has signed the block are dropped.
+func (k *NodeKeys) Verify(h *Header, prev *model.LightHeader) (err error) {
+	err = ErrNodeKey
+	for i, keys := range k.lists {
+		if err = h.Verify(prev, keys); err == nil {
+			k.lists = k.lists[i:]
+			return nil
+		}
+		if err != ErrNodeKey && err != ErrBlockSign {
+			return err
+		}
+	}
+	return err
+}
+
+// MajorityKeys returns the list of keys which has been received from more than half of hosts
+func MajorityKeys(lists [][][]byte, hosts int) ([][]byte, bool) {
+	counts := make(map[string]int)
+	for _, keys := range lists {
+		if len(keys) == 0 {
+			continue
+		}
+		id := joinKeys(keys)
+		if counts[id]++; counts[id]*2 > hosts {
+			return keys, true
+		}
+	}
+	return nil, false
+}
+
+func joinKeys(keys [][]byte) string {
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, hex.EncodeToString(key))
+	}
+	return strings.Join(items, ",")
+}
+
+func equalKeys(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
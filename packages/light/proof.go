@@ -0,0 +1,30 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package light
+
+import (
+	"errors"
+
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+)
+
+// ErrHeaderNotFound is returned when the header of block hasn't been synced yet
+var ErrHeaderNotFound = errors.New("Header of block has not been found")
+
+// VerifyTxProof checks the proof of the inclusion of transaction against the header of block
+// which has been checked and stored by the light node
+func VerifyTxProof(proof *utils.TxProof) error {
+	header := &model.LightHeader{}
+	found, err := header.Get(proof.BlockID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrHeaderNotFound
+	}
+	return proof.Verify(header.Hash)
+}
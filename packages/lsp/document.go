@@ -0,0 +1,197 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/IBAX-io/go-ibax/packages/script"
+)
+
+// The positions of the protocol are counted in runes, the contracts use ASCII identifiers
+// so it doesn't matter for the names even if the editor counts UTF-16 units.
+
+var (
+	tableFuncs = `DBFind|DBRow|DBInsert|DBUpdate|DBUpdateExt|DBSelect|DBCount|PermTable|PermColumn|` +
+		`CreateColumn|DelColumn|DelTable|TableConditions|ColumnCondition|RowConditions|GetColumnType`
+	// tableName matches the table name which is typed as the first parameter
+	tableName = regexp.MustCompile(`(?:` + tableFuncs + `)\(\s*["` + "`" + `]([\w@]*)$`)
+	// tableCall matches the calls with the table name to find the columns of the statement
+	tableCall = regexp.MustCompile(`(?:` + tableFuncs + `)\(\s*["` + "`" + `]([\w@]+)["` + "`" + `]`)
+)
+
+// columnLines is the count of previous lines where the table of the columns is searched
+const columnLines = 10
+
+// document is the source code of contracts which is opened in the editor or read from the workspace
+type document struct {
+	text    string
+	lines   []string
+	symbols []*script.Symbol
+}
+
+// newDocument parses the declarations of the text, the declarations of the previous
+// version of the document are kept until the lexical errors are fixed
+func newDocument(text string, prev *document) *document {
+	doc := &document{
+		text:  text,
+		lines: strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n"),
+	}
+	var err error
+	if doc.symbols, err = script.Symbols([]rune(text)); err != nil && prev != nil {
+		doc.symbols = prev.symbols
+	}
+	return doc
+}
+
+func (doc *document) line(n int) []rune {
+	if n < 0 || n >= len(doc.lines) {
+		return nil
+	}
+	return []rune(doc.lines[n])
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordAt returns the identifier under the cursor
+func (doc *document) wordAt(pos Position) string {
+	line := doc.line(pos.Line)
+	if pos.Character > len(line) {
+		return ``
+	}
+	start, end := pos.Character, pos.Character
+	for start > 0 && isIdentRune(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isIdentRune(line[end]) {
+		end++
+	}
+	return string(line[start:end])
+}
+
+// stringPrefix returns the beginning of the string literal which is typed at the cursor,
+// ok is false if the cursor isn't inside the string
+func (doc *document) stringPrefix(pos Position) (prefix string, ok bool) {
+	line := doc.line(pos.Line)
+	if pos.Character > len(line) {
+		return ``, false
+	}
+	var (
+		quote rune
+		start int
+	)
+	for i := 0; i < pos.Character; i++ {
+		switch {
+		case quote == 0 && (line[i] == '"' || line[i] == '`'):
+			quote, start = line[i], i+1
+		case quote == '"' && line[i] == '\\':
+			i++
+		case quote != 0 && line[i] == quote:
+			quote = 0
+		case quote == 0 && line[i] == '/' && i+1 < len(line) && line[i+1] == '/':
+			return ``, false
+		}
+	}
+	if quote == 0 {
+		return ``, false
+	}
+	return string(line[start:pos.Character]), true
+}
+
+// before returns the text of the previous lines and the current line until the cursor
+func (doc *document) before(pos Position, count int) string {
+	first := pos.Line - count
+	if first < 0 {
+		first = 0
+	}
+	var buf strings.Builder
+	for i := first; i < pos.Line && i < len(doc.lines); i++ {
+		buf.WriteString(doc.lines[i])
+		buf.WriteByte('\n')
+	}
+	line := doc.line(pos.Line)
+	if pos.Character <= len(line) {
+		buf.WriteString(string(line[:pos.Character]))
+	}
+	return buf.String()
+}
+
+// completingTable returns true if the cursor is at the table name
+func (doc *document) completingTable(pos Position) bool {
+	return tableName.MatchString(doc.before(pos, 0))
+}
+
+// statementTable returns the table of the nearest previous call with the table name
+func (doc *document) statementTable(pos Position) string {
+	calls := tableCall.FindAllStringSubmatch(doc.before(pos, columnLines), -1)
+	if len(calls) == 0 {
+		return ``
+	}
+	return calls[len(calls)-1][1]
+}
+
+// lexPosition converts the position of the lexer to the position in the document. The lexer counts
+// the columns of the first line from 1 and the columns of the next lines from the preceding line feed.
+func lexPosition(line, column int) Position {
+	var pos Position
+	if line > 0 {
+		pos.Line = line - 1
+	}
+	if column > 0 {
+		pos.Character = column - 1
+		if line > 1 && pos.Character > 0 {
+			pos.Character--
+		}
+	}
+	return pos
+}
+
+// symbolRange returns the range of the name of the declaration
+func symbolRange(symbol *script.Symbol) Range {
+	start := lexPosition(symbol.Line, symbol.Column)
+	return Range{Start: start, End: Position{Line: start.Line, Character: start.Character + len([]rune(symbol.Name))}}
+}
+
+// errorDiagnostic converts the error of the compiler to the diagnostic. The errors without
+// the position are shown at the first line.
+func (doc *document) errorDiagnostic(err error) Diagnostic {
+	message, line, column := script.ErrorPosition(err)
+	start := lexPosition(line, column)
+	end := len(doc.line(start.Line))
+	if end <= start.Character {
+		end = start.Character + 1
+	}
+	return Diagnostic{
+		Range: Range{
+			Start: start,
+			End:   Position{Line: start.Line, Character: end},
+		},
+		Severity: severityError,
+		Source:   serverName,
+		Message:  message,
+	}
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ``
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,116 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/script"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSource = `contract Transfer {
+    data {
+        Recipient string
+    }
+    action {
+        var row map
+        row = DBFind("keys").Columns("amount, pub").Where({"id": $key_id, "": 0}).Row()
+        DBInsert("
+    }
+}`
+
+func TestDocument(t *testing.T) {
+	doc := newDocument(testSource, nil)
+
+	assert.Equal(t, "DBFind", doc.wordAt(Position{Line: 6, Character: 16}))
+	assert.Equal(t, "Recipient", doc.wordAt(Position{Line: 2, Character: 8}))
+	assert.Equal(t, "", doc.wordAt(Position{Line: 20, Character: 0}))
+
+	prefix, ok := doc.stringPrefix(Position{Line: 6, Character: 24})
+	assert.True(t, ok)
+	assert.Equal(t, "ke", prefix)
+	_, ok = doc.stringPrefix(Position{Line: 6, Character: 28})
+	assert.False(t, ok)
+
+	// the table of DBFind
+	assert.True(t, doc.completingTable(Position{Line: 6, Character: 24}))
+	// the columns of the table of the statement
+	pos := Position{Line: 6, Character: 45}
+	assert.False(t, doc.completingTable(pos))
+	assert.Equal(t, "keys", doc.statementTable(pos))
+	pos = Position{Line: 6, Character: 75}
+	_, ok = doc.stringPrefix(pos)
+	assert.True(t, ok)
+	assert.Equal(t, "keys", doc.statementTable(pos))
+	assert.True(t, doc.completingTable(Position{Line: 7, Character: 18}))
+}
+
+func TestErrorDiagnostic(t *testing.T) {
+	doc := newDocument(testSource, nil)
+
+	d := doc.errorDiagnostic(errors.New(`unknown lexem " [Ln:8 Col:19]`))
+	assert.Equal(t, `unknown lexem "`, d.Message)
+	assert.Equal(t, Range{Start: Position{Line: 7, Character: 17}, End: Position{Line: 7, Character: 18}}, d.Range)
+
+	d = doc.errorDiagnostic(errors.New(`must be '}' (unexpected new line) [Ln:3]`))
+	assert.Equal(t, `must be '}' (unexpected new line)`, d.Message)
+	assert.Equal(t, Range{Start: Position{Line: 2, Character: 0}, End: Position{Line: 2, Character: 24}}, d.Range)
+
+	// the error without the position is shown at the first line
+	d = doc.errorDiagnostic(errors.New(`unknown identifier row`))
+	assert.Equal(t, Range{End: Position{Character: 19}}, d.Range)
+}
+
+func TestSymbolRange(t *testing.T) {
+	doc := newDocument("contract Transfer {\n    action {}\n}\nfunc sum(a int) int {\n    return a\n}", nil)
+	ranges := make(map[string]Range)
+	for _, symbol := range doc.symbols {
+		ranges[symbol.Name] = symbolRange(symbol)
+	}
+	assert.Equal(t, Range{Start: Position{Line: 0, Character: 9}, End: Position{Line: 0, Character: 17}}, ranges["Transfer"])
+	assert.Equal(t, Range{Start: Position{Line: 3, Character: 5}, End: Position{Line: 3, Character: 8}}, ranges["sum"])
+}
+
+func TestWorkspaceCompile(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewServer(&buf, &buf, script.NewVM(), 1, nil)
+	s.docs["file:///sum.sim"] = newDocument("func sum(a, b int) int {\n    return a + b\n}", nil)
+
+	// the function of the other document of the workspace is known to the compiler
+	require.NoError(t, s.update("file:///double.sim", "func double(a int) int {\n    return sum(a, a)\n}"))
+	assert.Contains(t, buf.String(), `"diagnostics":[]`)
+	assert.Contains(t, s.flushed, "file:///sum.sim")
+	assert.NotContains(t, s.flushed, "file:///double.sim")
+}
+
+func TestMessages(t *testing.T) {
+	var buf bytes.Buffer
+	id := json.RawMessage(`1`)
+	require.NoError(t, writeMessage(&buf, &response{JSONRPC: jsonrpcVersion, ID: &id, Result: nil}))
+	require.NoError(t, writeMessage(&buf, &notification{JSONRPC: jsonrpcVersion, Method: "exit"}))
+	assert.Contains(t, buf.String(), "Content-Length: 38\r\n\r\n{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":null}")
+
+	r := bufio.NewReader(&buf)
+	body, err := readMessage(r)
+	require.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":null}`, string(body))
+	body, err = readMessage(r)
+	require.NoError(t, err)
+	var req request
+	require.NoError(t, json.Unmarshal(body, &req))
+	assert.Equal(t, "exit", req.Method)
+	assert.Nil(t, req.ID)
+
+	_, err = readMessage(bufio.NewReader(bytes.NewBufferString("Content-Type: text\r\n\r\n{}")))
+	assert.Equal(t, ErrHeader, err)
+}
@@ -0,0 +1,121 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package lsp
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/IBAX-io/go-ibax/packages/chain_sdk"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Schema returns the names of tables and columns for the completion
+type Schema interface {
+	Tables() ([]string, error)
+	Columns(table string) ([]string, error)
+}
+
+type tablesResult struct {
+	Count int64 `json:"count"`
+	List  []struct {
+		Name string `json:"name"`
+	} `json:"list"`
+}
+
+type tableResult struct {
+	Columns []struct {
+		Name string `json:"name"`
+	} `json:"columns"`
+}
+
+// tablesLimit is the max count of tables which the node returns in one request
+const tablesLimit = 1000
+
+// NodeSchema gets the tables and columns of the ecosystem from the API of the node.
+// They are cached because the completion is requested on every key press.
+type NodeSchema struct {
+	apiAddress string
+	auth       string
+
+	mu      sync.Mutex
+	tables  []string
+	columns map[string][]string
+}
+
+// NewNodeSchema logs in to the node with the key from the file
+func NewNodeSchema(apiAddress, keyFile string, ecosystem int64) (*NodeSchema, error) {
+	auth, _, _, _, _, err := chain_sdk.KeyLogin(apiAddress, keyFile, ecosystem)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "api": apiAddress}).Error("logging in to node")
+		return nil, err
+	}
+	return &NodeSchema{
+		apiAddress: apiAddress,
+		auth:       auth,
+		columns:    make(map[string][]string),
+	}, nil
+}
+
+// Tables returns the names of tables of the ecosystem
+func (s *NodeSchema) Tables() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tables != nil {
+		return s.tables, nil
+	}
+	tables := make([]string, 0)
+	for offset := 0; ; offset += tablesLimit {
+		var ret tablesResult
+		form := url.Values{"limit": {strconv.Itoa(tablesLimit)}, "offset": {strconv.Itoa(offset)}}
+		if err := chain_sdk.SendGet(s.apiAddress, s.auth, "tables?"+form.Encode(), nil, &ret); err != nil {
+			log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err}).Error("getting tables from node")
+			return nil, err
+		}
+		for _, item := range ret.List {
+			tables = append(tables, item.Name)
+		}
+		if len(ret.List) < tablesLimit {
+			break
+		}
+	}
+	sort.Strings(tables)
+	s.tables = tables
+	return tables, nil
+}
+
+// Columns returns the names of columns of the table, the table may have the prefix of ecosystem such as @1
+func (s *NodeSchema) Columns(table string) ([]string, error) {
+	table = strings.ToLower(table)
+	if strings.HasPrefix(table, "@") {
+		table = strings.TrimLeft(table[1:], "0123456789")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if columns, ok := s.columns[table]; ok {
+		return columns, nil
+	}
+	var ret tableResult
+	if err := chain_sdk.SendGet(s.apiAddress, s.auth, "table/"+url.PathEscape(table), nil, &ret); err != nil {
+		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "table": table}).Error("getting table from node")
+		return nil, err
+	}
+	columns := []string{"id"}
+	for _, item := range ret.Columns {
+		columns = append(columns, item.Name)
+	}
+	sort.Strings(columns[1:])
+	s.columns[table] = columns
+	return columns, nil
+}
@@ -0,0 +1,427 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/script"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	serverName = "ibax-lsp"
+	// SourceExt is the extension of the files with the source code of contracts
+	SourceExt = ".sim"
+)
+
+// Server is the language server of the contract language. It compiles the opened documents
+// by the virtual machine with the embedded functions to report the errors, the contracts
+// and functions of the workspace are used for go-to-definition and completion.
+type Server struct {
+	in     *bufio.Reader
+	out    io.Writer
+	vm     *script.VM
+	owner  *script.OwnerInfo
+	schema Schema
+
+	docs    map[string]*document // all documents of the workspace by uri
+	flushed map[string]string    // texts of the documents whose contracts have been flushed into the VM
+	root    string
+}
+
+// NewServer returns the server which reads the requests from in and writes the responses to out.
+// The schema is used to complete the names of tables and columns, it can be nil.
+func NewServer(in io.Reader, out io.Writer, vm *script.VM, ecosystem int64, schema Schema) *Server {
+	return &Server{
+		in:      bufio.NewReader(in),
+		out:     out,
+		vm:      vm,
+		owner:   &script.OwnerInfo{StateID: uint32(ecosystem), Version: script.LatestVersion},
+		schema:  schema,
+		docs:    make(map[string]*document),
+		flushed: make(map[string]string),
+	}
+}
+
+// Run serves the requests until the exit notification or the end of input
+func (s *Server) Run() error {
+	for {
+		body, err := readMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading lsp message")
+			return err
+		}
+		var req request
+		if err = json.Unmarshal(body, &req); err != nil {
+			log.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("unmarshalling lsp message")
+			if err = s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+		result, err := s.handle(&req)
+		if req.ID == nil {
+			// notifications haven't responses
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.ParseError, "error": err, "method": req.Method}).Error("processing lsp notification")
+			}
+			continue
+		}
+		if err = s.reply(req.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, err error) error {
+	if err == nil {
+		return writeMessage(s.out, &response{JSONRPC: jsonrpcVersion, ID: id, Result: result})
+	}
+	rerr, ok := err.(*responseError)
+	if !ok {
+		rerr = &responseError{Code: codeInternalError, Message: err.Error()}
+	}
+	return writeMessage(s.out, &errorResponse{JSONRPC: jsonrpcVersion, ID: id, Error: rerr})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return writeMessage(s.out, &notification{JSONRPC: jsonrpcVersion, Method: method, Params: params})
+}
+
+func unmarshalParams(req *request, v interface{}) error {
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) handle(req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		var params initializeParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(&params), nil
+	case "initialized", "shutdown", "textDocument/didSave", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.close(params.TextDocument.URI)
+	case "textDocument/definition":
+		var params positionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(&params), nil
+	case "textDocument/hover":
+		var params positionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(&params), nil
+	case "textDocument/completion":
+		var params positionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(&params), nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+// initialize reads the source files of the workspace to find the declarations
+func (s *Server) initialize(params *initializeParams) *initializeResult {
+	if s.root = uriToPath(params.RootURI); len(s.root) > 0 {
+		filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || filepath.Ext(path) != SourceExt {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("reading contract source")
+				return nil
+			}
+			s.docs[pathToURI(path)] = newDocument(string(data), nil)
+			return nil
+		})
+	}
+	return &initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync:   syncFull,
+			HoverProvider:      true,
+			DefinitionProvider: true,
+			CompletionProvider: completionOptions{TriggerCharacters: []string{`"`, `.`}},
+		},
+		ServerInfo: serverInfo{Name: serverName, Version: consts.VERSION},
+	}
+}
+
+// compile returns the compiled block, the panic of the compiler on the incomplete code is returned as the error
+func (s *Server) compile(text string) (block *script.Block, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithFields(log.Fields{"type": consts.PanicRecoveredError, "error": r}).Error("compiling contract source")
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return s.vm.CompileBlock([]rune(text), s.owner)
+}
+
+// flushWorkspace flushes the contracts of the other documents into the VM, so the document can call them.
+// The contracts can call the contracts of other documents, so the failed documents are compiled again
+// while any document is compiled successfully.
+func (s *Server) flushWorkspace(current string) {
+	var pending []string
+	for uri, doc := range s.docs {
+		if uri != current && s.flushed[uri] != doc.text {
+			pending = append(pending, uri)
+		}
+	}
+	sort.Strings(pending)
+	for len(pending) > 0 {
+		var failed []string
+		for _, uri := range pending {
+			block, err := s.compile(s.docs[uri].text)
+			if err != nil {
+				failed = append(failed, uri)
+				continue
+			}
+			s.vm.FlushBlock(block)
+			s.flushed[uri] = s.docs[uri].text
+		}
+		if len(failed) == len(pending) {
+			break
+		}
+		pending = failed
+	}
+}
+
+// update compiles the new text of the document and publishes its errors
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(text, s.docs[uri])
+	s.docs[uri] = doc
+	s.flushWorkspace(uri)
+	diagnostics := make([]Diagnostic, 0, 1)
+	if _, err := s.compile(text); err != nil {
+		diagnostics = append(diagnostics, doc.errorDiagnostic(err))
+	}
+	return s.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// close returns the document to the saved version of the file and clears its errors
+func (s *Server) close(uri string) error {
+	if data, err := os.ReadFile(uriToPath(uri)); err == nil {
+		s.docs[uri] = newDocument(string(data), s.docs[uri])
+	} else {
+		delete(s.docs, uri)
+	}
+	return s.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}})
+}
+
+// findSymbols returns the declarations with the name, the declarations of the current document
+// go first and the functions of the current contract go before the other ones
+func (s *Server) findSymbols(uri string, pos Position, name string) []Location {
+	var (
+		current   string
+		first     []Location
+		locations []Location
+	)
+	if doc, ok := s.docs[uri]; ok {
+		for _, symbol := range doc.symbols {
+			if symbol.Type == script.ObjContract && symbol.Line-1 <= pos.Line {
+				current = symbol.Name
+			}
+		}
+		for _, symbol := range doc.symbols {
+			if symbol.Name != name {
+				continue
+			}
+			loc := Location{URI: uri, Range: symbolRange(symbol)}
+			if symbol.Type == script.ObjFunc && symbol.Contract == current {
+				first = append(first, loc)
+			} else {
+				locations = append(locations, loc)
+			}
+		}
+	}
+	uris := make([]string, 0, len(s.docs))
+	for key := range s.docs {
+		if key != uri {
+			uris = append(uris, key)
+		}
+	}
+	sort.Strings(uris)
+	for _, key := range uris {
+		for _, symbol := range s.docs[key].symbols {
+			// the functions of other contracts aren't visible
+			if symbol.Name == name && (symbol.Type == script.ObjContract || len(symbol.Contract) == 0) {
+				locations = append(locations, Location{URI: key, Range: symbolRange(symbol)})
+			}
+		}
+	}
+	return append(first, locations...)
+}
+
+func (s *Server) definition(params *positionParams) []Location {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return []Location{}
+	}
+	name := doc.wordAt(params.Position)
+	if len(name) == 0 {
+		return []Location{}
+	}
+	locations := s.findSymbols(params.TextDocument.URI, params.Position, name)
+	if locations == nil {
+		return []Location{}
+	}
+	return locations
+}
+
+// description returns the signature of the embedded function or the function of the virtual machine
+func (s *Server) description(name string) (string, bool) {
+	obj, ok := s.vm.Objects[name]
+	if !ok {
+		return ``, false
+	}
+	switch obj.Type {
+	case script.ObjExtFunc:
+		info := obj.Value.(script.ExtFuncInfo)
+		ret := info.Signature()
+		if info.CanWrite {
+			ret += "\n\n// changes the database"
+		}
+		return ret, true
+	case script.ObjFunc:
+		return obj.Value.(*script.Block).Info.(*script.FuncInfo).Signature(name), true
+	}
+	return ``, false
+}
+
+func (s *Server) hover(params *positionParams) *Hover {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	name := doc.wordAt(params.Position)
+	if len(name) == 0 {
+		return nil
+	}
+	text, ok := s.description(name)
+	if !ok {
+		locations := s.findSymbols(params.TextDocument.URI, params.Position, name)
+		if len(locations) == 0 {
+			return nil
+		}
+		loc := locations[0]
+		line := s.docs[loc.URI].line(loc.Range.Start.Line)
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(string(line)), `{`))
+		if path := uriToPath(loc.URI); len(s.root) > 0 && loc.URI != params.TextDocument.URI {
+			if rel, err := filepath.Rel(s.root, path); err == nil {
+				text += "\n\n// " + filepath.ToSlash(rel)
+			}
+		}
+	}
+	return &Hover{Contents: markupContent{Kind: markupMarkdown, Value: "```\n" + text + "\n```"}}
+}
+
+func (s *Server) completion(params *positionParams) *completionList {
+	list := &completionList{Items: make([]CompletionItem, 0)}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return list
+	}
+	if _, ok := doc.stringPrefix(params.Position); ok {
+		if s.schema == nil {
+			return list
+		}
+		if doc.completingTable(params.Position) {
+			tables, err := s.schema.Tables()
+			if err != nil {
+				return list
+			}
+			for _, name := range tables {
+				list.Items = append(list.Items, CompletionItem{Label: name, Kind: completionValue, Detail: "table"})
+			}
+			return list
+		}
+		if table := doc.statementTable(params.Position); len(table) > 0 {
+			columns, err := s.schema.Columns(table)
+			if err != nil {
+				return list
+			}
+			for _, name := range columns {
+				list.Items = append(list.Items, CompletionItem{Label: name, Kind: completionField, Detail: "column of " + table})
+			}
+		}
+		return list
+	}
+
+	found := make(map[string]bool)
+	add := func(item CompletionItem) {
+		if !found[item.Label] {
+			found[item.Label] = true
+			list.Items = append(list.Items, item)
+		}
+	}
+	for _, name := range script.Keywords() {
+		add(CompletionItem{Label: name, Kind: completionKeyword})
+	}
+	for name := range s.vm.Objects {
+		if detail, ok := s.description(name); ok {
+			add(CompletionItem{Label: name, Kind: completionFunction, Detail: strings.SplitN(detail, "\n", 2)[0]})
+		}
+	}
+	for uri, item := range s.docs {
+		for _, symbol := range item.symbols {
+			switch {
+			case symbol.Type == script.ObjContract:
+				add(CompletionItem{Label: symbol.Name, Kind: completionClass, Detail: "contract"})
+			case uri == params.TextDocument.URI || len(symbol.Contract) == 0:
+				add(CompletionItem{Label: symbol.Name, Kind: completionFunction, Detail: "func"})
+			}
+		}
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Label < list.Items[j].Label
+	})
+	return list
+}
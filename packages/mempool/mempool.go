@@ -0,0 +1,339 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package mempool
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultMaxCount is the max count of transactions in the mempool if it isn't set in the config
+	DefaultMaxCount = 50000
+	// DefaultMaxSize is the max size of transactions in the mempool if it isn't set in the config
+	DefaultMaxSize = 128 << 20
+	// DefaultMaxAccountCount is the max count of pending transactions of one account if it isn't set in the config
+	DefaultMaxAccountCount = 1000
+)
+
+var (
+	// ErrMempoolFull is returned if the mempool is full and the transaction hasn't a higher priority than pending ones
+	ErrMempoolFull = errors.New("Mempool is full")
+	// ErrAccountFull is returned if the account has the max count of pending transactions
+	ErrAccountFull = errors.New("Too many pending transactions of the account")
+	// ErrUnderpriced is returned if the replacing transaction hasn't a greater expedite than the pending one
+	ErrUnderpriced = errors.New("Expedite of the replacing transaction must be greater than the pending one")
+)
+
+// Entry is the pending transaction, the data of transaction is stored in the transactions table
+type Entry struct {
+	Hash     []byte
+	KeyID    int64
+	HighRate int8
+	Expedite decimal.Decimal
+	Time     int64
+	Size     int
+	// Slot is the key of the transaction without the expedite, the transaction of the account
+	// with the same slot and greater expedite replaces the pending one. It is empty if it isn't replaceable.
+	Slot string
+}
+
+// before returns true if the entry has a higher priority than e.
+// The order is the same as the order of unused transactions in the transactions table.
+func (entry *Entry) before(e *Entry) bool {
+	if entry.HighRate != e.HighRate {
+		return entry.HighRate < e.HighRate
+	}
+	if c := entry.Expedite.Cmp(e.Expedite); c != 0 {
+		return c > 0
+	}
+	if entry.Time != e.Time {
+		return entry.Time < e.Time
+	}
+	return bytes.Compare(entry.Hash, e.Hash) < 0
+}
+
+// nonceBefore returns true if the entry must be played before e of the same account
+func (entry *Entry) nonceBefore(e *Entry) bool {
+	if entry.Time != e.Time {
+		return entry.Time < e.Time
+	}
+	return entry.before(e)
+}
+
+// Limits are the limits of mempool, zero value means no limit
+type Limits struct {
+	MaxCount        int
+	MaxSize         int
+	MaxAccountCount int
+}
+
+// Stats is the state of mempool
+type Stats struct {
+	Count    int
+	Size     int
+	Accounts int
+	Limits   Limits
+}
+
+// Pool keeps the pending transactions in the priority order. The transactions of every account
+// are played in order of their time like nonces, so the account's transaction with a higher priority
+// can't be taken before the previous transactions of this account.
+type Pool struct {
+	mu       sync.Mutex
+	limits   Limits
+	entries  map[string]*Entry
+	accounts map[int64][]*Entry
+	size     int
+}
+
+var (
+	pool     *Pool
+	poolOnce sync.Once
+)
+
+// GetPool returns the mempool of node with the limits from the config
+func GetPool() *Pool {
+	poolOnce.Do(func() {
+		limits := Limits{
+			MaxCount:        conf.Config.Mempool.MaxCount,
+			MaxSize:         conf.Config.Mempool.MaxSize << 20,
+			MaxAccountCount: conf.Config.Mempool.MaxAccountCount,
+		}
+		if limits.MaxCount <= 0 {
+			limits.MaxCount = DefaultMaxCount
+		}
+		if limits.MaxSize <= 0 {
+			limits.MaxSize = DefaultMaxSize
+		}
+		if limits.MaxAccountCount <= 0 {
+			limits.MaxAccountCount = DefaultMaxAccountCount
+		}
+		pool = NewPool(limits)
+	})
+	return pool
+}
+
+// NewPool returns the empty mempool
+func NewPool(limits Limits) *Pool {
+	return &Pool{
+		limits:   limits,
+		entries:  make(map[string]*Entry),
+		accounts: make(map[int64][]*Entry),
+	}
+}
+
+func (p *Pool) insert(e *Entry) {
+	list := p.accounts[e.KeyID]
+	i := sort.Search(len(list), func(i int) bool {
+		return e.nonceBefore(list[i])
+	})
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = e
+	p.accounts[e.KeyID] = list
+	p.entries[hex.EncodeToString(e.Hash)] = e
+	p.size += e.Size
+}
+
+func (p *Pool) remove(e *Entry) {
+	list := p.accounts[e.KeyID]
+	for i, item := range list {
+		if item == e {
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(list) == 0 {
+		delete(p.accounts, e.KeyID)
+	} else {
+		p.accounts[e.KeyID] = list
+	}
+	delete(p.entries, hex.EncodeToString(e.Hash))
+	p.size -= e.Size
+}
+
+func (p *Pool) isFull(count, size int) bool {
+	return (p.limits.MaxCount > 0 && count > p.limits.MaxCount) ||
+		(p.limits.MaxSize > 0 && size > p.limits.MaxSize)
+}
+
+// worst returns the pending transaction with the lowest priority among the last transactions of accounts,
+// the last transaction is evicted to keep the order of previous ones
+func (p *Pool) worst() *Entry {
+	var worst *Entry
+	for _, list := range p.accounts {
+		tail := list[len(list)-1]
+		if worst == nil || worst.before(tail) {
+			worst = tail
+		}
+	}
+	return worst
+}
+
+// Add adds the transaction to the mempool. It returns the hashes of transactions which have been
+// replaced or evicted by the new one, they must be removed from the transactions table.
+func (p *Pool) Add(e *Entry) (removed [][]byte, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.entries[hex.EncodeToString(e.Hash)]; ok {
+		return nil, nil
+	}
+
+	var replaced *Entry
+	if len(e.Slot) > 0 {
+		for _, item := range p.accounts[e.KeyID] {
+			if item.Slot == e.Slot {
+				replaced = item
+				break
+			}
+		}
+	}
+	if replaced != nil {
+		if e.Expedite.Cmp(replaced.Expedite) <= 0 {
+			return nil, ErrUnderpriced
+		}
+	} else if p.limits.MaxAccountCount > 0 && len(p.accounts[e.KeyID]) >= p.limits.MaxAccountCount {
+		return nil, ErrAccountFull
+	}
+
+	var evicted []*Entry
+	if replaced != nil {
+		p.remove(replaced)
+		evicted = append(evicted, replaced)
+	}
+	for p.isFull(len(p.entries)+1, p.size+e.Size) {
+		worst := p.worst()
+		if worst == nil || !e.before(worst) {
+			for _, item := range evicted {
+				p.insert(item)
+			}
+			return nil, ErrMempoolFull
+		}
+		p.remove(worst)
+		evicted = append(evicted, worst)
+	}
+	p.insert(e)
+
+	for _, item := range evicted {
+		removed = append(removed, item.Hash)
+	}
+	return removed, nil
+}
+
+// Remove removes the transactions which have been played or marked as bad
+func (p *Pool) Remove(hashes ...[]byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, hash := range hashes {
+		if e, ok := p.entries[hex.EncodeToString(hash)]; ok {
+			p.remove(e)
+		}
+	}
+}
+
+// Sync makes the mempool consistent with the unused transactions in the transactions table.
+// The transactions which are missing in the mempool are added without the limits
+// because they have been accepted before, for example, before the restart of node.
+func (p *Pool) Sync(list []*Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keep := make(map[string]bool, len(list))
+	for _, e := range list {
+		key := hex.EncodeToString(e.Hash)
+		keep[key] = true
+		if _, ok := p.entries[key]; !ok {
+			p.insert(e)
+		}
+	}
+	for key, e := range p.entries {
+		if !keep[key] {
+			p.remove(e)
+		}
+	}
+}
+
+// Missing returns the entries of list which aren't in the mempool
+func (p *Pool) Missing(list []*Entry) []*Entry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	missing := make([]*Entry, 0)
+	for _, e := range list {
+		if _, ok := p.entries[hex.EncodeToString(e.Hash)]; !ok {
+			missing = append(missing, e)
+		}
+	}
+	return missing
+}
+
+// heads is the heap of the first pending transactions of accounts
+type heads []*Entry
+
+func (h heads) Len() int            { return len(h) }
+func (h heads) Less(i, j int) bool  { return h[i].before(h[j]) }
+func (h heads) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *heads) Push(x interface{}) { *h = append(*h, x.(*Entry)) }
+func (h *heads) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// Pending returns the pending transactions in the order in which they must be played,
+// all transactions are returned if limit is zero
+func (p *Pool) Pending(limit int) []*Entry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if limit <= 0 || limit > len(p.entries) {
+		limit = len(p.entries)
+	}
+	h := make(heads, 0, len(p.accounts))
+	next := make(map[int64]int, len(p.accounts))
+	for keyID, list := range p.accounts {
+		h = append(h, list[0])
+		next[keyID] = 1
+	}
+	heap.Init(&h)
+
+	result := make([]*Entry, 0, limit)
+	for len(result) < limit && h.Len() > 0 {
+		e := heap.Pop(&h).(*Entry)
+		result = append(result, e)
+		list := p.accounts[e.KeyID]
+		if i := next[e.KeyID]; i < len(list) {
+			heap.Push(&h, list[i])
+			next[e.KeyID] = i + 1
+		}
+	}
+	return result
+}
+
+// Stats returns the state of mempool
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return Stats{
+		Count:    len(p.entries),
+		Size:     p.size,
+		Accounts: len(p.accounts),
+		Limits:   p.limits,
+	}
+}
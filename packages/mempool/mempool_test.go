@@ -0,0 +1,134 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package mempool
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEntry(hash string, keyID int64, expedite int64, time int64) *Entry {
+	return &Entry{
+		Hash:     []byte(hash),
+		KeyID:    keyID,
+		Expedite: decimal.New(expedite, 0),
+		Time:     time,
+		Size:     10,
+	}
+}
+
+func hashes(list []*Entry) []string {
+	var result []string
+	for _, e := range list {
+		result = append(result, string(e.Hash))
+	}
+	return result
+}
+
+func TestPendingOrder(t *testing.T) {
+	p := NewPool(Limits{})
+	for _, e := range []*Entry{
+		newEntry("a2", 1, 10, 2),
+		newEntry("a1", 1, 0, 1),
+		newEntry("b1", 2, 5, 1),
+		newEntry("c1", 3, 1, 1),
+	} {
+		_, err := p.Add(e)
+		assert.NoError(t, err)
+	}
+	sys := newEntry("s1", 4, 0, 5)
+	sys.HighRate = -1
+	_, err := p.Add(sys)
+	assert.NoError(t, err)
+
+	// a2 has the greatest expedite, but it can't be played before a1 of the same account
+	assert.Equal(t, []string{"s1", "b1", "c1", "a1", "a2"}, hashes(p.Pending(0)))
+	assert.Equal(t, []string{"s1", "b1"}, hashes(p.Pending(2)))
+
+	p.Remove([]byte("a1"), []byte("b1"))
+	assert.Equal(t, []string{"s1", "a2", "c1"}, hashes(p.Pending(0)))
+	assert.Equal(t, Stats{Count: 3, Size: 30, Accounts: 3}, p.Stats())
+}
+
+func TestReplace(t *testing.T) {
+	p := NewPool(Limits{})
+	a := newEntry("a", 1, 5, 1)
+	a.Slot = "transfer"
+	_, err := p.Add(a)
+	assert.NoError(t, err)
+
+	b := newEntry("b", 1, 5, 1)
+	b.Slot = "transfer"
+	_, err = p.Add(b)
+	assert.Equal(t, ErrUnderpriced, err)
+
+	c := newEntry("c", 1, 6, 1)
+	c.Slot = "transfer"
+	removed, err := p.Add(c)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a")}, removed)
+	assert.Equal(t, []string{"c"}, hashes(p.Pending(0)))
+}
+
+func TestEviction(t *testing.T) {
+	p := NewPool(Limits{MaxCount: 3, MaxAccountCount: 2})
+	for _, e := range []*Entry{
+		newEntry("a1", 1, 1, 1),
+		newEntry("a2", 1, 9, 2),
+		newEntry("b1", 2, 5, 1),
+	} {
+		_, err := p.Add(e)
+		assert.NoError(t, err)
+	}
+
+	_, err := p.Add(newEntry("a3", 1, 9, 3))
+	assert.Equal(t, ErrAccountFull, err)
+
+	_, err = p.Add(newEntry("c1", 3, 1, 1))
+	assert.Equal(t, ErrMempoolFull, err)
+
+	// a1 has the lowest priority, but the last transaction of account is evicted to keep the order
+	removed, err := p.Add(newEntry("c1", 3, 7, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("b1")}, removed)
+	assert.Equal(t, []string{"c1", "a1", "a2"}, hashes(p.Pending(0)))
+
+	p = NewPool(Limits{MaxSize: 25})
+	_, err = p.Add(newEntry("a1", 1, 1, 1))
+	assert.NoError(t, err)
+	_, err = p.Add(newEntry("b1", 2, 2, 1))
+	assert.NoError(t, err)
+	removed, err = p.Add(newEntry("c1", 3, 3, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a1")}, removed)
+}
+
+func TestSync(t *testing.T) {
+	p := NewPool(Limits{MaxCount: 1})
+	_, err := p.Add(newEntry("a1", 1, 1, 1))
+	assert.NoError(t, err)
+
+	b1, c1 := newEntry("b1", 2, 1, 1), newEntry("c1", 3, 2, 1)
+	assert.Equal(t, []string{"b1", "c1"}, hashes(p.Missing([]*Entry{newEntry("a1", 1, 1, 1), b1, c1})))
+
+	p.Sync([]*Entry{b1, c1})
+	assert.Equal(t, []string{"c1", "b1"}, hashes(p.Pending(0)))
+	assert.Empty(t, p.Missing([]*Entry{b1, c1}))
+
+	// the slot is rebuilt before the sync, so the synced transaction can be replaced
+	p = NewPool(Limits{})
+	b1 = newEntry("b1", 2, 1, 1)
+	b1.Slot = "transfer"
+	p.Sync([]*Entry{b1})
+
+	b2 := newEntry("b2", 2, 3, 1)
+	b2.Slot = "transfer"
+	removed, err := p.Add(b2)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("b1")}, removed)
+}
@@ -0,0 +1,90 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobuffalo/fizz"
+	"github.com/gobuffalo/fizz/translators"
+)
+
+// the names of the storage backends are the same as in the model package
+const (
+	backendPostgres = "postgres"
+	backendSQLite   = "sqlite"
+)
+
+var (
+	// backend is the storage backend which the migrations are translated for
+	backend = backendPostgres
+	// sqliteEnums is the list of enum types which are replaced with text in SQLite
+	sqliteEnums = map[string]bool{}
+)
+
+// SetBackend chooses the translator of the migrations and the SQL which depends on the storage backend
+func SetBackend(name string) error {
+	switch name {
+	case backendPostgres:
+		translator = translators.NewPostgres()
+		migrationNextID.data = migrationInitialSchema
+	case backendSQLite:
+		translator = newSQLiteTranslator()
+		// SQLite has no stored functions so next_id is registered by the driver of the backend
+		migrationNextID.data = `SELECT 1;`
+	default:
+		return fmt.Errorf(`unknown storage backend %s`, name)
+	}
+	backend = name
+	return nil
+}
+
+// sqliteTranslator creates the tables of the migrations in SQLite. The id columns of sequences
+// become the autoincrement primary keys and the types of PostgreSQL are replaced with the SQLite ones.
+type sqliteTranslator struct {
+	*translators.SQLite
+}
+
+func newSQLiteTranslator() *sqliteTranslator {
+	return &sqliteTranslator{translators.NewSQLite(``)}
+}
+
+func (p *sqliteTranslator) CreateTable(t fizz.Table) (string, error) {
+	columns := make([]fizz.Column, len(t.Columns))
+	for i, c := range t.Columns {
+		options := fizz.Options{}
+		for key, value := range c.Options {
+			options[key] = value
+		}
+		if raw, ok := options["default_raw"].(string); ok && strings.HasPrefix(raw, `nextval(`) {
+			delete(options, "default_raw")
+			c.ColType = "integer"
+			c.Primary = true
+		}
+		c.ColType = sqliteColType(c.ColType)
+		c.Options = options
+		columns[i] = c
+	}
+	t.Columns = columns
+	return p.SQLite.CreateTable(t)
+}
+
+func sqliteColType(colType string) string {
+	name := strings.ToLower(colType)
+	switch {
+	case name == "bytea":
+		return "blob"
+	case name == "jsonb":
+		return "json"
+	case strings.HasPrefix(name, "decimal"), strings.HasPrefix(name, "numeric"):
+		// SQLite converts the long numbers to real so decimals are kept as text without losing precision
+		return "text"
+	case sqliteEnums[colType]:
+		return "text"
+	}
+	return colType
+}
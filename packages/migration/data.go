@@ -21,6 +21,7 @@ var (
 		t.Column("id", "bigint", {"default": "0"})
 		t.Column("hash", "bytea", {"default": ""})
 		t.Column("rollbacks_hash", "bytea", {"default": ""})
+		t.Column("state_root", "bytea", {"default": ""})
 		t.Column("data", "bytea", {"default": ""})
 		t.Column("ecosystem_id", "int", {"default": "0"})
 		t.Column("key_id", "bigint", {"default": "0"})
@@ -52,6 +53,7 @@ var (
 	{{head "info_block"}}
 		t.Column("hash", "bytea", {"default": ""})
 		t.Column("rollbacks_hash", "bytea", {"default": ""})
+		t.Column("state_root", "bytea", {"default": ""})
 		t.Column("block_id", "int", {"default": "0"})
 		t.Column("node_position", "int", {"default": "0"})
 		t.Column("ecosystem_id", "bigint", {"default": "0"})
@@ -70,8 +72,7 @@ var (
 		t.Column("block", "int", {"default": "0"})
 	{{footer "primary(hash)"}}
 
-	sql("DROP TYPE IF EXISTS \"my_node_keys_enum_status\" CASCADE;")
-	sql("CREATE TYPE \"my_node_keys_enum_status\" AS ENUM ('my_pending','approved');")
+	{{enum "my_node_keys_enum_status" "my_pending" "approved"}}
 
 	{{headseq "my_node_keys"}}
 		t.Column("id", "bigint", {"default_raw": "nextval('my_node_keys_id_seq')"})
@@ -107,6 +108,31 @@ var (
 		t.Column("data", "text", {"default": ""})
 	{{footer "seq" "primary" "index(table_name, table_id, block_id)"}}
 
+	{{head "contract_events"}}
+		t.Column("id", "bigint", {"default": "0"})
+		t.Column("block_id", "bigint", {"default": "0"})
+		t.Column("tx_hash", "bytea", {"default": ""})
+		t.Column("ecosystem", "bigint", {"default": "0"})
+		t.Column("contract", "string", {"default": "", "size":255})
+		t.Column("name", "string", {"default": "", "size":255})
+		t.Column("data", "jsonb", {"null": true})
+		t.Column("time", "bigint", {"default": "0"})
+	{{footer "primary" "index(ecosystem, contract, name)" "index(block_id)" "index(tx_hash)"}}
+
+	{{head "state_rows"}}
+		t.Column("table_name", "string", {"default": "", "size":255})
+		t.Column("row_id", "string", {"default": "", "size":255})
+		t.Column("ecosystem", "string", {"default": "", "size":255})
+		t.Column("hash", "bytea", {"default": ""})
+	{{footer "primary(table_name, row_id, ecosystem)"}}
+
+	{{head "multisig_txs"}}
+		t.Column("hash", "bytea", {"default": ""})
+		t.Column("payload", "bytea", {"default": ""})
+		t.Column("signatures", "bytea", {"default": ""})
+		t.Column("expire", "bigint", {"default": "0"})
+	{{footer "primary(hash)" "index(expire)"}}
+
 	{{head "stop_daemons"}}
 		t.Column("stop_time", "int", {"default": "0"})
 	{{footer}}
@@ -348,6 +374,22 @@ var (
 	{{footer "seq" "primary"}}
 `
 
+	migrationInitialTablesLight = `
+	{{head "light_headers"}}
+		t.Column("id", "bigint", {"default": "0"})
+		t.Column("hash", "bytea", {"default": ""})
+		t.Column("mrkl_root", "bytea", {"default": ""})
+		t.Column("prev_rollbacks_hash", "bytea", {"default": ""})
+		t.Column("prev_state_root", "bytea", {"default": ""})
+		t.Column("ecosystem_id", "bigint", {"default": "0"})
+		t.Column("key_id", "bigint", {"default": "0"})
+		t.Column("node_position", "bigint", {"default": "0"})
+		t.Column("time", "int", {"default": "0"})
+		t.Column("version", "int", {"default": "0"})
+		t.Column("tx", "int", {"default": "0"})
+	{{footer "primary"}}
+`
+
 	migrationInitialTablesCLB = `
 	{{headseq "vde_src_task"}}
 		t.Column("id", "int", {"default_raw": "nextval('vde_src_task_id_seq')"})
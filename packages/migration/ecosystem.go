@@ -28,7 +28,10 @@ type SqlData struct {
 }
 
 var _ fizz.Translator = (*translators.Postgres)(nil)
-var pgt = translators.NewPostgres()
+var _ fizz.Translator = (*sqliteTranslator)(nil)
+
+// translator translates the migrations for the storage backend, it is chosen by SetBackend
+var translator fizz.Translator = translators.NewPostgres()
 var tblName string
 
 const (
@@ -39,6 +42,10 @@ const (
 )
 
 func sqlHeadSequence(name string) string {
+	if backend == backendSQLite {
+		// the id column of the sequence becomes the autoincrement primary key
+		return sqlHead(name)
+	}
 	ret := fmt.Sprintf(`sql("DROP SEQUENCE IF EXISTS %[1]s_id_seq CASCADE;")
 sql("CREATE SEQUENCE %[1]s_id_seq START WITH 1;")`, name)
 
@@ -57,6 +64,9 @@ func sqlEnd(options ...string) (ret string) {
 	for _, opt := range options {
 		var cname string
 		if strings.HasPrefix(opt, sqlSeq) {
+			if backend == backendSQLite {
+				continue
+			}
 			ret += fmt.Sprintf(`
 		sql("ALTER SEQUENCE %[1]s_id_seq owned by %[1]s.id;")`, tblName)
 			continue
@@ -91,18 +101,35 @@ func sqlEnd(options ...string) (ret string) {
 			}
 			continue
 		}
+		if backend == backendSQLite {
+			// SQLite can't add the constraints to the existing table so they are unique indexes
+			ret += fmt.Sprintf(`
+	sql("CREATE UNIQUE INDEX \"%[1]s_%[3]s\" ON \"%[1]s\" %[2]s;")`, tblName, opt[strings.Index(opt, `(`):], cname)
+			continue
+		}
 		ret += fmt.Sprintf(`
 	sql("ALTER TABLE ONLY \"%[1]s\" ADD CONSTRAINT \"%[1]s_%[3]s\" %[2]s;")`, tblName, opt, cname)
 	}
 	return
 }
 
+// sqlEnum creates the enum type, the columns of enum are text in SQLite
+func sqlEnum(name string, values ...string) string {
+	if backend == backendSQLite {
+		sqliteEnums[name] = true
+		return ``
+	}
+	return fmt.Sprintf(`sql("DROP TYPE IF EXISTS \"%[1]s\" CASCADE;")
+	sql("CREATE TYPE \"%[1]s\" AS ENUM ('%[2]s');")`, name, strings.Join(values, `','`))
+}
+
 func sqlConvert(in []string) (ret string, err error) {
 	var item string
 	funcs := template.FuncMap{
 		"head":    sqlHead,
 		"footer":  sqlEnd,
 		"headseq": sqlHeadSequence,
+		"enum":    sqlEnum,
 	}
 	sqlTmpl := template.New("sql").Funcs(funcs)
 	for _, sql := range in {
@@ -117,7 +144,7 @@ func sqlConvert(in []string) (ret string, err error) {
 		if err = tmpl.Execute(io.Writer(&out), nil); err != nil {
 			return
 		}
-		item, err = fizz.AString(out.String(), pgt)
+		item, err = fizz.AString(out.String(), translator)
 		if err != nil {
 			return
 		}
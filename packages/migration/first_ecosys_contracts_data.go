@@ -737,6 +737,23 @@ VALUES
     }
 }
 ', '1', 'ContractConditions("MainCondition")', '1', '1'),
+	(next_id('1_contracts'), 'NewMultisig', 'contract NewMultisig {
+    data {
+        Keys array
+        Threshold int
+    }
+
+    conditions {
+        if $Threshold < 1 || $Threshold > Len($Keys) {
+            warning Sprintf("Threshold must be between 1 and %d", Len($Keys))
+        }
+    }
+
+    action {
+        $result = IdToAddress(NewMultisigKey($Threshold, $Keys))
+    }
+}
+', '1', 'true', '1', '1'),
 	(next_id('1_contracts'), 'NewPage', 'contract NewPage {
     data {
         ApplicationId int
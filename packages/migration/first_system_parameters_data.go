@@ -76,4 +76,5 @@ INSERT INTO "1_system_parameters" ("id","name", "value", "conditions") VALUES
 	(next_id('1_system_parameters'),'node_ban_time_local','1800000','ContractAccess("@1UpdateSysParam")'),
 	(next_id('1_system_parameters'),'price_tx_size_wallet', '15', 'ContractAccess("@1UpdateSysParam")'),
 	(next_id('1_system_parameters'),'price_create_rate', '1000000', 'ContractAccess("@1UpdateSysParam")'),
+	(next_id('1_system_parameters'),'state_root_block', '0', 'ContractAccess("@1UpdateSysParam")'),
 	(next_id('1_system_parameters'),'test','false','false'),
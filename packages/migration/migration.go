@@ -27,16 +27,24 @@ var migrations = []*migration{
 
 	// Initial schema
 	&migration{"0.1.5", migrationInitialTables, true},
-	&migration{"0.1.6", migrationInitialSchema, false},
+	migrationNextID,
 }
 
+// migrationNextID creates the function next_id which is used by the data of ecosystems
+var migrationNextID = &migration{"0.1.6", migrationInitialSchema, false}
+
 var migrationsSub = &migration{"0.1.7", migrationInitialTablesSub, true}
 
 var migrationsCLB = &migration{"0.1.8", migrationInitialTablesCLB, true}
 
+var migrationsLight = &migration{"0.1.9", migrationInitialTablesLight, true}
+
 var updateMigrations = []*migration{
 	&migration{"3.1.0", updates.M310, false},
 	&migration{"3.2.0", updates.M320, false},
+	&migration{"3.3.0", updates.M330, false},
+	&migration{"3.4.0", updates.M340, false},
+	&migration{"3.5.0", updates.M350, false},
 
 type database interface {
 	CurrentVersion() (string, error)
@@ -93,13 +101,15 @@ func migrate(db database, appVer string, migrations []*migration) error {
 		} else if cmp >= 0 {
 			continue
 		}
+		// the template is translated every time because the translation depends on the storage backend
+		data := m.data
 		if m.template {
-			m.data, err = sqlConvert([]string{m.data})
+			data, err = sqlConvert([]string{m.data})
 			if err != nil {
 				return err
 			}
 		}
-		err = db.ApplyMigration(m.version, m.data)
+		err = db.ApplyMigration(m.version, data)
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.DBError, "err": err, "version": m.version}).Errorf("apply migration")
 			return err
@@ -124,6 +134,9 @@ func InitMigrate(db database) error {
 	if conf.Config.IsSupportingOBS() {
 		mig = append(mig, migrationsCLB)
 	}
+	if conf.Config.IsLightNode() {
+		mig = append(mig, migrationsLight)
+	}
 	return runMigrations(db, mig)
 }
 
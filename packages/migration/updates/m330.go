@@ -0,0 +1,28 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+var M330 = `
+
+DROP TABLE IF EXISTS "contract_events";
+CREATE TABLE "contract_events" (
+	"id" bigint NOT NULL DEFAULT '0',
+	"block_id" bigint NOT NULL DEFAULT '0',
+	"tx_hash" bytea NOT NULL DEFAULT '',
+	"ecosystem" bigint NOT NULL DEFAULT '0',
+	"contract" varchar(255) NOT NULL DEFAULT '',
+	"name" varchar(255) NOT NULL DEFAULT '',
+	"data" jsonb,
+	"time" bigint NOT NULL DEFAULT '0'
+);
+ALTER TABLE ONLY "contract_events" ADD CONSTRAINT "contract_events_pkey" PRIMARY KEY (id);
+CREATE INDEX "contract_events_index_name" ON "contract_events" (ecosystem, contract, name);
+CREATE INDEX "contract_events_index_block" ON "contract_events" (block_id);
+CREATE INDEX "contract_events_index_tx_hash" ON "contract_events" (tx_hash);
+
+INSERT INTO "1_system_parameters" (id, name, value, conditions) VALUES
+	(next_id('1_system_parameters'), 'price_exec_emit_event', '50', 'ContractAccess("@1UpdateSysParam")');
+`
@@ -0,0 +1,20 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+var M340 = `
+
+ALTER TABLE "block_chain" ADD COLUMN IF NOT EXISTS "state_root" bytea NOT NULL DEFAULT '';
+ALTER TABLE "info_block" ADD COLUMN IF NOT EXISTS "state_root" bytea NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS "state_rows" (
+	"table_name" varchar(255) NOT NULL DEFAULT '',
+	"row_id" varchar(255) NOT NULL DEFAULT '',
+	"ecosystem" varchar(255) NOT NULL DEFAULT '',
+	"hash" bytea NOT NULL DEFAULT '',
+	CONSTRAINT "state_rows_pkey" PRIMARY KEY ("table_name", "row_id", "ecosystem")
+);
+`
@@ -0,0 +1,18 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package updates
+
+var M350 = `
+
+CREATE TABLE IF NOT EXISTS "multisig_txs" (
+	"hash" bytea NOT NULL DEFAULT '',
+	"payload" bytea NOT NULL DEFAULT '',
+	"signatures" bytea NOT NULL DEFAULT '',
+	"expire" bigint NOT NULL DEFAULT '0',
+	CONSTRAINT "multisig_txs_pkey" PRIMARY KEY ("hash")
+);
+CREATE INDEX IF NOT EXISTS "multisig_txs_index_expire" ON "multisig_txs" (expire);
+`
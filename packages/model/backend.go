@@ -0,0 +1,125 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// PostgresBackend is the name of PostgreSQL backend
+	PostgresBackend = "postgres"
+	// SQLiteBackend is the name of the embedded SQLite backend
+	SQLiteBackend = "sqlite"
+)
+
+var (
+	// ErrUnknownBackend is returned when the storage backend has not been registered
+	ErrUnknownBackend = errors.New("Unknown storage backend")
+	// ErrQueryPlanUnsupported is returned when the backend can't estimate the cost of the query
+	ErrQueryPlanUnsupported = errors.New("Query plan is not supported by storage backend")
+)
+
+// StorageBackend is the database engine which keeps the state of the node.
+// The models work with the gorm connection returned by the backend, so the backend
+// implements only the operations which can't be expressed in the common SQL.
+type StorageBackend interface {
+	// Name returns the name of the backend
+	Name() string
+	// Open opens the connection to the database
+	Open(cfg conf.DBConfig) (*gorm.DB, error)
+	// SetupConn sets the options of the connection or the transaction
+	SetupConn(conn *gorm.DB) error
+	// SetupSnapshot makes the transaction see the snapshot of the database taken by the first query,
+	// the transaction doesn't wait for the locks of other transactions
+	SetupSnapshot(conn *gorm.DB) error
+	// DropTables drops all tables of the database
+	DropTables(conn *gorm.DB) error
+	// DropDatabase drops the database with the specified name
+	DropDatabase(conn *gorm.DB, name string) error
+	// HasTable checks whether the table or the view exists, tableType is "table", "view" or empty for both
+	HasTable(conn *gorm.DB, name, tableType string) bool
+	// Tables returns the names of the tables of the database without the views
+	Tables(conn *gorm.DB) ([]string, error)
+	// QueryPlanCost returns the cost of the query estimated by the planner of the database
+	QueryPlanCost(conn *gorm.DB, analyze bool, query string, args ...interface{}) (int64, error)
+	// JSONText returns the expression which selects the text of the JSON field by the path of keys
+	JSONText(column string, path ...string) string
+	// JSONLiteral returns the expression of the JSON value, the value must be escaped by the caller
+	JSONLiteral(value string) string
+}
+
+var (
+	backendsMutex sync.RWMutex
+	backends      = map[string]StorageBackend{}
+	storage       StorageBackend
+)
+
+func init() {
+	RegisterStorageBackend(&postgresBackend{})
+	RegisterStorageBackend(&sqliteBackend{})
+	storage = backends[PostgresBackend]
+}
+
+// RegisterStorageBackend adds the backend to the list of available backends
+func RegisterStorageBackend(b StorageBackend) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	backends[b.Name()] = b
+}
+
+// GetStorageBackend returns the registered backend by name, empty name means PostgreSQL
+func GetStorageBackend(name string) (StorageBackend, error) {
+	if len(name) == 0 {
+		name = PostgresBackend
+	}
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+	b, ok := backends[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, name)
+	}
+	return b, nil
+}
+
+// StorageBackends returns the names of the registered backends
+func StorageBackends() []string {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Storage returns the backend of the current connection
+func Storage() StorageBackend {
+	return storage
+}
+
+// jsonText returns the JSON value as the ->> operator of PostgreSQL does,
+// the strings are unquoted and null is empty
+func jsonText(value json.RawMessage) string {
+	var s string
+	switch {
+	case len(value) == 0 || string(value) == `null`:
+		return ``
+	case json.Unmarshal(value, &s) == nil:
+		return s
+	}
+	return string(value)
+}
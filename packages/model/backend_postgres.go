@@ -0,0 +1,218 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// postgresBackend keeps the state in PostgreSQL database
+type postgresBackend struct{}
+
+func (*postgresBackend) Name() string {
+	return PostgresBackend
+}
+
+func (b *postgresBackend) Open(cfg conf.DBConfig) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=disable password=%s TimeZone=UTC",
+		cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Password)
+open:
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN:                  dsn,
+		PreferSimpleProtocol: true, // disables implicit prepared statement usage
+	}), &gorm.Config{
+		AllowGlobalUpdate: true, //allow global update
+		//PrepareStmt:       true,
+		Logger: logger.Default.LogMode(logger.Silent), // start Logger，show detail log
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), fmt.Sprintf("database \"%s\" does not exist", cfg.Name)) {
+			err := b.createDatabase(fmt.Sprintf("host=%s port=%d user=%s sslmode=disable TimeZone=UTC",
+				cfg.Host, cfg.Port, cfg.User), cfg.Name)
+			if err != nil {
+				return nil, err
+			}
+			goto open
+		}
+		return nil, err
+	}
+	return db, nil
+}
+
+func (*postgresBackend) createDatabase(dsn string, dbName string) error {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Error),
+	})
+	if err != nil {
+		return err
+	}
+	result := db.Exec("create database " + dbName)
+	defer func() {
+		d, _ := db.DB()
+		d.Close()
+	}()
+	return result.Error
+}
+
+func (*postgresBackend) SetupConn(conn *gorm.DB) error {
+	if err := conn.Exec(fmt.Sprintf(`set lock_timeout = %d;`, conf.Config.DB.LockTimeout)).Error; err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("can't set lock timeout")
+		return err
+	}
+
+	if err := conn.Exec(fmt.Sprintf(`set idle_in_transaction_session_timeout = %d;`, conf.Config.DB.IdleInTxTimeout)).Error; err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("can't set idle_in_transaction_session_timeout")
+		return err
+	}
+	return conn.Exec("SET TIME ZONE 'UTC'").Error
+}
+
+func (*postgresBackend) SetupSnapshot(conn *gorm.DB) error {
+	if err := conn.Exec(`SET TRANSACTION ISOLATION LEVEL REPEATABLE READ`).Error; err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("can't set isolation level")
+		return err
+	}
+	return conn.Exec(`SET LOCAL lock_timeout = 1`).Error
+}
+
+func (*postgresBackend) DropTables(conn *gorm.DB) error {
+	return conn.Exec(`
+	DO $$ DECLARE
+	    r RECORD;
+	BEGIN
+	    FOR r IN (SELECT tablename FROM pg_tables WHERE schemaname = current_schema()) LOOP
+		EXECUTE 'DROP TABLE IF EXISTS ' || quote_ident(r.tablename) || ' CASCADE';
+	    END LOOP;
+	END $$;
+	`).Error
+}
+
+func (*postgresBackend) DropDatabase(conn *gorm.DB, name string) error {
+	query := `SELECT
+	pg_terminate_backend (pg_stat_activity.pid)
+   FROM
+	pg_stat_activity
+   WHERE
+	pg_stat_activity.datname = ?`
+
+	if err := conn.Exec(query, name).Error; err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "dbname": name}).Error("on kill db process")
+		return err
+	}
+
+	if err := conn.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", name)).Error; err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "dbname": name}).Error("on drop db")
+		return err
+	}
+	return nil
+}
+
+func (*postgresBackend) Tables(conn *gorm.DB) ([]string, error) {
+	var names []string
+	err := conn.Table("information_schema.tables").
+		Where("table_type = 'BASE TABLE' AND table_schema = current_schema()").
+		Order("table_name").Pluck("table_name", &names).Error
+	return names, err
+}
+
+func (*postgresBackend) HasTable(conn *gorm.DB, names, tableType string) bool {
+	var typs string
+	switch tableType {
+	case "table":
+		typs = `= 'BASE TABLE'`
+	case "view":
+		typs = `= 'VIEW'`
+	default:
+		typs = `IN ('BASE TABLE', 'VIEW')`
+	}
+	var name string
+	conn.Table("information_schema.tables").
+		Where(fmt.Sprintf("table_type %s AND table_schema NOT IN ('pg_catalog', 'information_schema') AND table_name=?", typs), names).
+		Select("table_name").Row().Scan(&name)
+	return name == names
+}
+
+// QueryPlanCost returns the total cost of the query plan
+func (*postgresBackend) QueryPlanCost(conn *gorm.DB, withAnalyze bool, query string, args ...interface{}) (int64, error) {
+	var planStr string
+	explainTpl := "EXPLAIN (FORMAT JSON) %s"
+	if withAnalyze {
+		explainTpl = "EXPLAIN ANALYZE (FORMAT JSON) %s"
+	}
+	err := conn.Raw(fmt.Sprintf(explainTpl, query), args...).Row().Scan(&planStr)
+	switch {
+	case err == sql.ErrNoRows:
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "query": query}).Error("no rows while explaining query")
+		return 0, errors.New("No rows")
+	case err != nil:
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "query": query}).Error("error explaining query")
+		return 0, err
+	}
+	var queryPlan []map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(planStr))
+	dec.UseNumber()
+	if err := dec.Decode(&queryPlan); err != nil {
+		log.WithFields(log.Fields{"type": consts.JSONUnmarshallError, "error": err}).Error("decoding query plan from JSON")
+		return 0, err
+	}
+	if len(queryPlan) == 0 {
+		log.Error("Query plan is empty")
+		return 0, errors.New("Query plan is empty")
+	}
+	firstNode := queryPlan[0]
+	var plan interface{}
+	var ok bool
+	if plan, ok = firstNode["Plan"]; !ok {
+		log.Error("No Plan key in result")
+		return 0, errors.New("No Plan key in result")
+	}
+
+	planMap, ok := plan.(map[string]interface{})
+	if !ok {
+		log.Error("Plan is not map[string]interface{}")
+		return 0, errors.New("Plan is not map[string]interface{}")
+	}
+
+	totalCost, ok := planMap["Total Cost"]
+	if !ok {
+		return 0, errors.New("PlanMap has no TotalCost")
+	}
+
+	totalCostNum, ok := totalCost.(json.Number)
+	if !ok {
+		log.Error("Total cost is not a number")
+		return 0, errors.New("Total cost is not a number")
+	}
+	totalCostF64, err := totalCostNum.Float64()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.ParseError, "error": err}).Error("parsing total cost of query plan")
+		return 0, err
+	}
+	return int64(totalCostF64), nil
+}
+
+func (*postgresBackend) JSONText(column string, path ...string) string {
+	if len(path) == 1 {
+		return fmt.Sprintf(`%s::jsonb->>'%s'`, column, path[0])
+	}
+	return fmt.Sprintf(`%s::jsonb#>>'{%s}'`, column, strings.Join(path, `,`))
+}
+
+func (*postgresBackend) JSONLiteral(value string) string {
+	return `'` + value + `'::jsonb`
+}
@@ -0,0 +1,245 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqliteDriverName is the SQLite driver with the functions which are used by the migrations
+const sqliteDriverName = "sqlite3_ibax"
+
+// sqliteFuncs are the functions of PostgreSQL which are used by the runtime queries
+var sqliteFuncs = map[string]interface{}{
+	"decode":       sqliteDecode,
+	"octet_length": sqliteOctetLength,
+	"to_timestamp": sqliteToTimestamp,
+	"json_text":    sqliteJSONText,
+}
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			for name, fn := range sqliteFuncs {
+				if err := conn.RegisterFunc(name, fn, true); err != nil {
+					return err
+				}
+			}
+			return conn.RegisterFunc("next_id", func(table string) (int64, error) {
+				return sqliteNextID(conn, table)
+			}, false)
+		},
+	})
+}
+
+// sqliteDecode decodes the text to the bytes as decode of PostgreSQL does
+func sqliteDecode(value, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "hex":
+		return hex.DecodeString(value)
+	case "base64":
+		return base64.StdEncoding.DecodeString(value)
+	case "escape":
+		return []byte(value), nil
+	}
+	return nil, fmt.Errorf("unrecognized encoding: %s", format)
+}
+
+// sqliteOctetLength returns the number of bytes of the value, NULL has no bytes
+func sqliteOctetLength(value interface{}) int64 {
+	switch v := value.(type) {
+	case []byte:
+		return int64(len(v))
+	case string:
+		return int64(len(v))
+	}
+	return int64(len(fmt.Sprint(value)))
+}
+
+// sqliteToTimestamp converts Unix time to the text of UTC time which is kept in timestamp columns
+func sqliteToTimestamp(value interface{}) (string, error) {
+	var sec float64
+	switch v := value.(type) {
+	case int64:
+		sec = float64(v)
+	case float64:
+		sec = v
+	case string:
+		var err error
+		if sec, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+			return ``, err
+		}
+	default:
+		return ``, fmt.Errorf("invalid timestamp: %v", value)
+	}
+	whole, frac := math.Modf(sec)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC().Format("2006-01-02 15:04:05.999999"), nil
+}
+
+// sqliteJSONText is ->> and #>> operators of PostgreSQL, it returns an empty string
+// instead of NULL if the path doesn't exist
+func sqliteJSONText(value interface{}, path ...string) string {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return ``
+	}
+	raw := json.RawMessage(data)
+	for _, key := range path {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			var list []json.RawMessage
+			i, ierr := strconv.Atoi(key)
+			if json.Unmarshal(raw, &list) != nil || ierr != nil || i < 0 || i >= len(list) {
+				return ``
+			}
+			raw = list[i]
+			continue
+		}
+		if raw = obj[key]; raw == nil {
+			return ``
+		}
+	}
+	return jsonText(raw)
+}
+
+// sqliteNextID is next_id of the migrations which is a plpgsql function in PostgreSQL.
+// It is run on the connection of the statement so it counts the rows inserted by the statement too.
+func sqliteNextID(conn *sqlite3.SQLiteConn, table string) (int64, error) {
+	rows, err := conn.Query(fmt.Sprintf(`SELECT COUNT(*) + 1 FROM "%s"`, table), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 1)
+	if err = rows.Next(dest); err != nil && err != io.EOF {
+		return 0, err
+	}
+	id, _ := dest[0].(int64)
+	return id, nil
+}
+
+// sqliteBackend keeps the state in the embedded SQLite database file.
+// It is intended for the small test networks and the embedded deployments.
+type sqliteBackend struct{}
+
+func (*sqliteBackend) Name() string {
+	return SQLiteBackend
+}
+
+// dbPath returns the path to the database file, the name of database is used if the path is empty
+func (*sqliteBackend) dbPath(cfg conf.DBConfig) string {
+	if len(cfg.Path) > 0 {
+		return cfg.Path
+	}
+	return cfg.Name + ".db"
+}
+
+func (b *sqliteBackend) Open(cfg conf.DBConfig) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=%d&_journal_mode=WAL&_foreign_keys=1",
+		b.dbPath(cfg), cfg.LockTimeout)
+	return gorm.Open(&sqlite.Dialector{DriverName: sqliteDriverName, DSN: dsn}, &gorm.Config{
+		AllowGlobalUpdate: true,
+		Logger:            logger.Default.LogMode(logger.Silent),
+	})
+}
+
+func (*sqliteBackend) SetupConn(conn *gorm.DB) error {
+	if err := conn.Exec(fmt.Sprintf(`PRAGMA busy_timeout = %d;`, conf.Config.DB.LockTimeout)).Error; err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("can't set busy timeout")
+		return err
+	}
+	return nil
+}
+
+// SetupSnapshot does nothing because the transactions of SQLite are serializable
+// and the readers don't wait for the writer in WAL mode
+func (*sqliteBackend) SetupSnapshot(conn *gorm.DB) error {
+	return nil
+}
+
+func (*sqliteBackend) DropTables(conn *gorm.DB) error {
+	var tables []string
+	err := conn.Raw(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`).
+		Scan(&tables).Error
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if err = conn.Exec(`DROP TABLE IF EXISTS "` + table + `"`).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropDatabase removes the file of database with the journal files
+func (*sqliteBackend) DropDatabase(conn *gorm.DB, name string) error {
+	for _, suffix := range []string{``, `-wal`, `-shm`} {
+		if err := os.Remove(name + suffix); err != nil && !os.IsNotExist(err) {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "dbname": name}).Error("on drop db")
+			return err
+		}
+	}
+	return nil
+}
+
+func (*sqliteBackend) Tables(conn *gorm.DB) ([]string, error) {
+	var names []string
+	err := conn.Table("sqlite_master").Where("type = 'table' AND name NOT LIKE 'sqlite_%'").
+		Order("name").Pluck("name", &names).Error
+	return names, err
+}
+
+func (*sqliteBackend) HasTable(conn *gorm.DB, names, tableType string) bool {
+	types := []string{"table", "view"}
+	if tableType == "table" || tableType == "view" {
+		types = []string{tableType}
+	}
+	var name string
+	conn.Table("sqlite_master").Where("type IN ? AND name = ?", types, names).
+		Select("name").Row().Scan(&name)
+	return name == names
+}
+
+// QueryPlanCost isn't supported because EXPLAIN QUERY PLAN of SQLite doesn't estimate the cost
+func (*sqliteBackend) QueryPlanCost(conn *gorm.DB, analyze bool, query string, args ...interface{}) (int64, error) {
+	return 0, ErrQueryPlanUnsupported
+}
+
+// JSONText calls json_text because the bundled SQLite has neither JSON1 nor ->> operator
+func (*sqliteBackend) JSONText(column string, path ...string) string {
+	return fmt.Sprintf(`json_text(%s, '%s')`, column, strings.Join(path, `', '`))
+}
+
+// JSONLiteral returns the string literal, JSON is kept as the text in SQLite
+func (*sqliteBackend) JSONLiteral(value string) string {
+	return `'` + value + `'`
+}
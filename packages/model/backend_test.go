@@ -0,0 +1,148 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/migration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStorageBackend(t *testing.T) {
+	b, err := GetStorageBackend(``)
+	require.NoError(t, err)
+	assert.Equal(t, PostgresBackend, b.Name())
+
+	b, err = GetStorageBackend(`SQLite`)
+	require.NoError(t, err)
+	assert.Equal(t, SQLiteBackend, b.Name())
+
+	_, err = GetStorageBackend(`oracle`)
+	assert.True(t, errors.Is(err, ErrUnknownBackend))
+
+	assert.Equal(t, []string{PostgresBackend, SQLiteBackend}, StorageBackends())
+}
+
+func TestSQLiteBackend(t *testing.T) {
+	dir, err := os.MkdirTemp(``, `sqlite`)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, `node.db`)
+	b := &sqliteBackend{}
+	db, err := b.Open(conf.DBConfig{Path: path, LockTimeout: 1000})
+	require.NoError(t, err)
+	require.NoError(t, b.SetupConn(db))
+
+	require.NoError(t, db.Exec(`CREATE TABLE "queue_tx" (hash blob, data blob)`).Error)
+	require.NoError(t, db.Exec(`CREATE VIEW "queue_view" AS SELECT hash FROM queue_tx`).Error)
+	assert.True(t, b.HasTable(db, `queue_tx`, `table`))
+	assert.False(t, b.HasTable(db, `queue_tx`, `view`))
+	assert.True(t, b.HasTable(db, `queue_view`, ``))
+	assert.False(t, b.HasTable(db, `block_chain`, ``))
+
+	_, err = b.QueryPlanCost(db, false, `SELECT * FROM queue_tx`)
+	assert.Equal(t, ErrQueryPlanUnsupported, err)
+
+	require.NoError(t, db.Exec(`DROP VIEW "queue_view"`).Error)
+	require.NoError(t, b.DropTables(db))
+	assert.False(t, b.HasTable(db, `queue_tx`, ``))
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	require.NoError(t, b.DropDatabase(nil, path))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSQLiteFuncs(t *testing.T) {
+	dir, err := os.MkdirTemp(``, `sqlite`)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	b := &sqliteBackend{}
+	db, err := b.Open(conf.DBConfig{Path: filepath.Join(dir, `node.db`), LockTimeout: 1000})
+	require.NoError(t, err)
+	defer func() {
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+	}()
+
+	var data []byte
+	require.NoError(t, db.Raw(`SELECT decode('0aff', 'HEX')`).Row().Scan(&data))
+	assert.Equal(t, []byte{0x0a, 0xff}, data)
+	assert.Error(t, db.Raw(`SELECT decode('0aff', 'latin')`).Row().Scan(&data))
+
+	var size int64
+	require.NoError(t, db.Raw(`SELECT octet_length(decode('0aff01', 'hex'))`).Row().Scan(&size))
+	assert.Equal(t, int64(3), size)
+
+	var ts string
+	require.NoError(t, db.Raw(`SELECT to_timestamp('1600000000')`).Row().Scan(&ts))
+	assert.Equal(t, `2020-09-13 12:26:40`, ts)
+
+	doc := `{"title": "Doc", "meta": {"size": 10, "tags": ["a", "b"]}}`
+	for expr, want := range map[string]string{
+		b.JSONText(`?`, `title`):             `Doc`,
+		b.JSONText(`?`, `meta`, `size`):      `10`,
+		b.JSONText(`?`, `meta`, `tags`, `1`): `b`,
+		b.JSONText(`?`, `meta`, `tags`):      `["a", "b"]`,
+		b.JSONText(`?`, `missing`):           ``,
+		b.JSONText(`?`, `title`, `missing`):  ``,
+	} {
+		var text string
+		require.NoError(t, db.Raw(`SELECT `+expr, doc).Row().Scan(&text))
+		assert.Equal(t, want, text, expr)
+	}
+}
+
+func TestJSONText(t *testing.T) {
+	pg, lite := &postgresBackend{}, &sqliteBackend{}
+	assert.Equal(t, `doc::jsonb->>'title'`, pg.JSONText(`doc`, `title`))
+	assert.Equal(t, `doc::jsonb#>>'{meta,size}'`, pg.JSONText(`doc`, `meta`, `size`))
+	assert.Equal(t, `'{}'::jsonb`, pg.JSONLiteral(`{}`))
+	assert.Equal(t, `json_text(doc, 'title')`, lite.JSONText(`doc`, `title`))
+	assert.Equal(t, `json_text(doc, 'meta', 'size')`, lite.JSONText(`doc`, `meta`, `size`))
+	assert.Equal(t, `'{}'`, lite.JSONLiteral(`{}`))
+}
+
+func TestInitDBSQLite(t *testing.T) {
+	dir, err := os.MkdirTemp(``, `sqlite`)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func() {
+		require.NoError(t, GormClose())
+		storage = backends[PostgresBackend]
+		require.NoError(t, migration.SetBackend(PostgresBackend))
+	}()
+
+	require.NoError(t, InitDB(conf.DBConfig{Backend: SQLiteBackend, Path: filepath.Join(dir, `node.db`),
+		LockTimeout: 1000, MaxIdleConns: 1, MaxOpenConns: 1}))
+	for _, table := range []string{`migration_history`, `block_chain`, `info_block`, `my_node_keys`, `install`} {
+		assert.True(t, storage.HasTable(DBConn, table, `table`), table)
+	}
+	version, err := (&MigrationHistory{}).CurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, `0.1.6`, version)
+
+	// next_id is provided by the driver and counts the rows inserted by the same statement
+	var id int64
+	require.NoError(t, DBConn.Raw(`SELECT next_id('install')`).Row().Scan(&id))
+	assert.Equal(t, int64(2), id)
+	require.NoError(t, DBConn.Exec(`INSERT INTO "install" ("progress") VALUES (next_id('install')), (next_id('install'))`).Error)
+	var progress []string
+	require.NoError(t, DBConn.Raw(`SELECT progress FROM "install" WHERE progress <> ?`, ProgressComplete).Scan(&progress).Error)
+	assert.Equal(t, []string{`2`, `3`}, progress)
+}
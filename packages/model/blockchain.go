@@ -14,6 +14,7 @@ type Block struct {
 	ID            int64  `gorm:"primary_key;not_null"`
 	Hash          []byte `gorm:"not null"`
 	RollbacksHash []byte `gorm:"not null"`
+	StateRoot     []byte `gorm:"not null"`
 	Data          []byte `gorm:"not null"`
 	EcosystemID   int64  `gorm:"not null"`
 	KeyID         int64  `gorm:"not null"`
@@ -0,0 +1,70 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+// ContractEvent is the event emitted by the contract
+type ContractEvent struct {
+	ID        int64  `gorm:"primary_key;not null" json:"id"`
+	BlockID   int64  `gorm:"not null" json:"block_id"`
+	TxHash    []byte `gorm:"not null" json:"tx_hash"`
+	Ecosystem int64  `gorm:"not null" json:"ecosystem"`
+	Contract  string `gorm:"not null;size:255" json:"contract"`
+	Name      string `gorm:"not null;size:255" json:"name"`
+	Data      string `gorm:"not null;type:jsonb" json:"data"`
+	Time      int64  `gorm:"not null" json:"time"`
+}
+
+// ContractEventFilter is the filter for searching events
+type ContractEventFilter struct {
+	Ecosystem int64
+	Contract  string
+	Name      string
+	TxHash    []byte
+	FromBlock int64
+	ToBlock   int64
+	Offset    int
+	Limit     int
+}
+
+// TableName returns name of table
+func (ContractEvent) TableName() string {
+	return "contract_events"
+}
+
+// Create is creating record of model
+func (e *ContractEvent) Create(transaction *DbTransaction) (err error) {
+	if e.ID, err = GetNextID(transaction, e.TableName()); err != nil {
+		return err
+	}
+	return GetDB(transaction).Create(e).Error
+}
+
+// GetContractEvents returns the events matching the filter ordered by id
+func GetContractEvents(f ContractEventFilter) ([]ContractEvent, error) {
+	q := DBConn.Model(&ContractEvent{})
+	if f.Ecosystem > 0 {
+		q = q.Where("ecosystem = ?", f.Ecosystem)
+	}
+	if len(f.Contract) > 0 {
+		q = q.Where("contract = ?", f.Contract)
+	}
+	if len(f.Name) > 0 {
+		q = q.Where("name = ?", f.Name)
+	}
+	if len(f.TxHash) > 0 {
+		q = q.Where("tx_hash = ?", f.TxHash)
+	}
+	if f.FromBlock > 0 {
+		q = q.Where("block_id >= ?", f.FromBlock)
+	}
+	if f.ToBlock > 0 {
+		q = q.Where("block_id <= ?", f.ToBlock)
+	}
+
+	var events []ContractEvent
+	err := q.Order("id asc").Offset(f.Offset).Limit(f.Limit).Find(&events).Error
+	return events, err
+}
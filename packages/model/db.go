@@ -16,10 +16,9 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/migration"
 
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var (
@@ -62,27 +61,21 @@ func isFound(db *gorm.DB) (bool, error) {
 
 // GormInit is initializes Gorm connection
 func GormInit(host string, port int, user string, pass string, dbName string) error {
-	var err error
-	dsn := fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=disable password=%s TimeZone=UTC", host, port, user, dbName, pass)
-open:
-	DBConn, err = gorm.Open(postgres.New(postgres.Config{
-		DSN:                  dsn,
-		PreferSimpleProtocol: true, // disables implicit prepared statement usage
-	}), &gorm.Config{
-		AllowGlobalUpdate: true, //allow global update
-		//PrepareStmt:       true,
-		Logger: logger.Default.LogMode(logger.Silent), // start Logger，show detail log
-	})
-	//DBConn, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	cfg := conf.Config.DB
+	cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name = host, port, user, pass, dbName
+	return OpenStorage(cfg)
+}
+
+// OpenStorage opens the connection to the database of the storage backend specified in the config
+func OpenStorage(cfg conf.DBConfig) error {
+	backend, err := GetStorageBackend(cfg.Backend)
 	if err != nil {
-		if strings.Contains(err.Error(), fmt.Sprintf("database \"%s\" does not exist", dbName)) {
-			err := createDatabase(fmt.Sprintf("host=%s port=%d user=%s sslmode=disable TimeZone=UTC", host, port, user), dbName)
-			if err != nil {
-				return err
-			}
-			goto open
-		}
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("cant open connection to DB")
+		log.WithFields(log.Fields{"type": consts.ConfigError, "error": err, "backends": StorageBackends()}).Error("getting storage backend")
+		return err
+	}
+	DBConn, err = backend.Open(cfg)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "backend": backend.Name()}).Error("cant open connection to DB")
 		DBConn = nil
 		return err
 	}
@@ -94,39 +87,21 @@ open:
 	}
 
 	sqlDB.SetConnMaxLifetime(time.Minute * 10)
-	sqlDB.SetMaxIdleConns(conf.Config.DB.MaxIdleConns)
-	sqlDB.SetMaxOpenConns(conf.Config.DB.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 
-	if err = setupConnOptions(DBConn); err != nil {
+	storage = backend
+	if err = migration.SetBackend(backend.Name()); err != nil {
 		return err
 	}
-	return nil
-}
-func createDatabase(dsn string, dbName string) error {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Error),
-	})
-	if err != nil {
+	if err = setupConnOptions(DBConn); err != nil {
 		return err
 	}
-	result := db.Exec("create database " + dbName)
-	defer func() {
-		d, _ := db.DB()
-		d.Close()
-	}()
-	return result.Error
+	return nil
 }
-func setupConnOptions(conr *gorm.DB) error {
-	if err := conr.Exec(fmt.Sprintf(`set lock_timeout = %d;`, conf.Config.DB.LockTimeout)).Error; err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("can't set lock timeout")
-		return err
-	}
 
-	if err := conr.Exec(fmt.Sprintf(`set idle_in_transaction_session_timeout = %d;`, conf.Config.DB.IdleInTxTimeout)).Error; err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("can't set idle_in_transaction_session_timeout")
-		return err
-	}
-	return conr.Exec("SET TIME ZONE 'UTC'").Error
+func setupConnOptions(conr *gorm.DB) error {
+	return storage.SetupConn(conr)
 }
 
 // GormClose is closing Gorm connection
@@ -170,6 +145,21 @@ func StartTransaction() (*DbTransaction, error) {
 	}, nil
 }
 
+// StartSnapshotTransaction starts the transaction which sees the snapshot of the database.
+// It is used for the execution of contracts concurrently with the blocks, the transaction
+// must be rolled back.
+func StartSnapshotTransaction() (*DbTransaction, error) {
+	tx, err := StartTransaction()
+	if err != nil {
+		return nil, err
+	}
+	if err = storage.SetupSnapshot(tx.conn); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
 // Rollback is transaction rollback
 func (tr *DbTransaction) Rollback() error {
 	return tr.conn.Rollback().Error
@@ -217,15 +207,7 @@ func GetDB(tr *DbTransaction) *gorm.DB {
 
 // DropTables is dropping all of the tables
 func DropTables() error {
-	return DBConn.Exec(`
-	DO $$ DECLARE
-	    r RECORD;
-	BEGIN
-	    FOR r IN (SELECT tablename FROM pg_tables WHERE schemaname = current_schema()) LOOP
-		EXECUTE 'DROP TABLE IF EXISTS ' || quote_ident(r.tablename) || ' CASCADE';
-	    END LOOP;
-	END $$;
-	`).Error
+	return storage.DropTables(DBConn)
 }
 
 // GetRecordsCountTx is counting all records of table in transaction
@@ -400,24 +382,11 @@ func GetList(query string, args ...interface{}) *ListResult {
 
 // IsTable returns is table exists
 func IsTable(tblname string) bool {
-	var name string
-	err := DBConn.Table("information_schema.tables").
-		Where("table_type = 'BASE TABLE' AND table_schema NOT IN ('pg_catalog', 'information_schema') AND table_name=?", tblname).
-		Select("table_name").Row().Scan(&name)
-	if err != nil {
-		return false
-	}
-
-	return name == tblname
+	return storage.HasTable(DBConn, tblname, "table")
 }
 
 func HasTableOrView(tr *DbTransaction, names string) bool {
-	var name string
-	DBConn.Table("information_schema.tables").
-		Where("table_type IN ('BASE TABLE', 'VIEW') AND table_schema NOT IN ('pg_catalog', 'information_schema') AND table_name=?", names).
-		Select("table_name").Row().Scan(&name)
-
-	return name == names
+	return storage.HasTable(DBConn, names, "")
 }
 
 type Namer struct {
@@ -429,20 +398,7 @@ type SchemaInter interface {
 }
 
 func (v Namer) HasExists(tr *DbTransaction, names string) bool {
-	var typs string
-	switch v.TableType {
-	case "table":
-		typs = `= 'BASE TABLE'`
-	case "view":
-		typs = `= 'VIEW'`
-	default:
-		typs = `IN ('BASE TABLE', 'VIEW')`
-	}
-	var name string
-	GetDB(tr).Table("information_schema.tables").
-		Where(fmt.Sprintf("table_type %s AND table_schema NOT IN ('pg_catalog', 'information_schema') AND table_name='%s'", typs, names)).
-		Select("table_name").Row().Scan(&name)
-	return name == names
+	return storage.HasTable(GetDB(tr), names, v.TableType)
 }
 
 // GetColumnByID returns the value of the column from the table by id
@@ -457,7 +413,7 @@ func GetColumnByID(table, column, id string) (result string, err error) {
 // InitDB drop all tables and exec db schema
 func InitDB(cfg conf.DBConfig) error {
 
-	err := GormInit(cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name)
+	err := OpenStorage(cfg)
 	if err != nil || DBConn == nil {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("initializing DB")
 		return ErrDBConn
@@ -509,24 +465,7 @@ func InitDB(cfg conf.DBConfig) error {
 
 // DropDatabase kill all process and drop database
 func DropDatabase(name string) error {
-	query := `SELECT
-	pg_terminate_backend (pg_stat_activity.pid)
-   FROM
-	pg_stat_activity
-   WHERE
-	pg_stat_activity.datname = ?`
-
-	if err := DBConn.Exec(query, name).Error; err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "dbname": name}).Error("on kill db process")
-		return err
-	}
-
-	if err := DBConn.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", name)).Error; err != nil {
-		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "dbname": name}).Error("on drop db")
-		return err
-	}
-
-	return nil
+	return storage.DropDatabase(DBConn, name)
 }
 
 // GetColumnByID returns the value of the column from the table by id
@@ -20,6 +20,7 @@ type InfoBlock struct {
 	CurrentVersion string `gorm:"not null"`
 	Sent           int8   `gorm:"not null"`
 	RollbacksHash  []byte `gorm:"not null"`
+	StateRoot      []byte `gorm:"not null"`
 }
 
 // TableName returns name of table
@@ -31,7 +32,7 @@ func (ib *InfoBlock) TableName() string {
 func (ib *InfoBlock) Get() (bool, error) {
 	return isFound(DBConn.Last(ib))
 }
-	return GetDB(transaction).Omit("rollbacks_hash").Create(ib).Error
+	return GetDB(transaction).Omit("rollbacks_hash", "state_root").Create(ib).Error
 }
 
 // MarkSent update model sent field
@@ -44,6 +45,11 @@ func UpdRollbackHash(transaction *DbTransaction, hash []byte) error {
 	return GetDB(transaction).Model(&InfoBlock{}).Update("rollbacks_hash", hash).Error
 }
 
+// UpdStateRoot update model state_root field
+func UpdStateRoot(transaction *DbTransaction, root []byte) error {
+	return GetDB(transaction).Model(&InfoBlock{}).Update("state_root", root).Error
+}
+
 // BlockGetUnsent returns InfoBlock
 func BlockGetUnsent() (*InfoBlock, error) {
 	ib := &InfoBlock{}
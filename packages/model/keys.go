@@ -26,6 +26,13 @@ type Key struct {
 	Maxpay      string `gorm:"not null"`
 	Deleted     int64  `gorm:"not null"`
 	Blocked     int64  `gorm:"not null"`
+	// Multi is the threshold of multi-signature account, PublicKey contains the public keys of its signers
+	Multi int64 `gorm:"not null"`
+}
+
+// IsMultisig returns true if the key is multi-signature account
+func (m *Key) IsMultisig() bool {
+	return m.Multi > 0
 }
 
 // SetTablePrefix is setting table prefix
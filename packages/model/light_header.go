@@ -0,0 +1,46 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+// LightHeader is model of the block header which has been checked by the light node
+type LightHeader struct {
+	ID                int64  `gorm:"primary_key;not_null"`
+	Hash              []byte `gorm:"not null"`
+	MrklRoot          []byte `gorm:"not null"`
+	PrevRollbacksHash []byte `gorm:"not null"`
+	PrevStateRoot     []byte `gorm:"not null"`
+	EcosystemID       int64  `gorm:"not null"`
+	KeyID             int64  `gorm:"not null"`
+	NodePosition      int64  `gorm:"not null"`
+	Time              int64  `gorm:"not null"`
+	Version           int    `gorm:"not null"`
+	Tx                int32  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (LightHeader) TableName() string {
+	return "light_headers"
+}
+
+// Create is creating record of model
+func (lh *LightHeader) Create(transaction *DbTransaction) error {
+	return GetDB(transaction).Create(lh).Error
+}
+
+// Get is retrieving model from database
+func (lh *LightHeader) Get(blockID int64) (bool, error) {
+	return isFound(DBConn.Where("id = ?", blockID).First(lh))
+}
+
+// GetLast returns the header of the last checked block
+func (lh *LightHeader) GetLast() (bool, error) {
+	return isFound(DBConn.Last(lh))
+}
+
+// DeleteLightHeadersAfter deletes the headers of the blocks after blockID
+func DeleteLightHeadersAfter(transaction *DbTransaction, blockID int64) error {
+	return GetDB(transaction).Where("id > ?", blockID).Delete(&LightHeader{}).Error
+}
@@ -0,0 +1,46 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+// MultisigTx is the transaction of multi-signature account which is waiting for the signatures,
+// the signatures are stored in msgpack in the order of signers
+type MultisigTx struct {
+	Hash       []byte `gorm:"primary_key;not null"`
+	Payload    []byte `gorm:"not null"`
+	Signatures []byte `gorm:"not null"`
+	Expire     int64  `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (MultisigTx) TableName() string {
+	return "multisig_txs"
+}
+
+// Create is creating record of model
+func (m *MultisigTx) Create() error {
+	return DBConn.Create(m).Error
+}
+
+// Get is retrieving the transaction which hasn't been expired at the time
+func (m *MultisigTx) Get(hash []byte, now int64) (bool, error) {
+	return isFound(DBConn.Where("hash = ? AND expire > ?", hash, now).First(m))
+}
+
+// UpdateSignatures saves the collected signatures
+func (m *MultisigTx) UpdateSignatures() error {
+	return DBConn.Model(&MultisigTx{}).Where("hash = ?", m.Hash).Update("signatures", m.Signatures).Error
+}
+
+// DeleteExpiredMultisigTxs deletes the transactions which have been expired at the time
+func DeleteExpiredMultisigTxs(now int64) error {
+	return DBConn.Where("expire <= ?", now).Delete(&MultisigTx{}).Error
+}
+
+// CountMultisigTxs returns the count of collected transactions
+func CountMultisigTxs() (count int64, err error) {
+	err = DBConn.Model(&MultisigTx{}).Count(&count).Error
+	return
+}
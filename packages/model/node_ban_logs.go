@@ -1,5 +1,41 @@
 /*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+import (
+	"time"
+)
+
+// NodeBanLogs represents model for node_ban_logs table
+type NodeBanLogs struct {
+	ID       int64
+	NodeID   int64
+	BannedAt time.Time
+	BanTime  int64
+	Reason   string
+}
+
 // TableName returns name of table
 func (r NodeBanLogs) TableName() string {
 	return "1_node_ban_logs"
 }
+
+// GetNodeBans returns the end of the last ban for every node which is banned at the time.
+// The ban time is stored in milliseconds.
+func (r *NodeBanLogs) GetNodeBans(now time.Time) (map[int64]time.Time, error) {
+	var list []NodeBanLogs
+	if err := DBConn.Table(r.TableName()).Select("node_id, banned_at, ban_time").Scan(&list).Error; err != nil {
+		return nil, err
+	}
+	bans := make(map[int64]time.Time)
+	for _, ban := range list {
+		to := ban.BannedAt.Add(time.Duration(ban.BanTime) * time.Millisecond)
+		if to.After(now) && to.After(bans[ban.NodeID]) {
+			bans[ban.NodeID] = to
+		}
+	}
+	return bans, nil
+}
@@ -1,7 +1,6 @@
 package model
 
 import (
-	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -90,9 +89,9 @@ func ExecOBSSchema(id int, wallet int64) error {
 				log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading private key from file")
 				return nil, err
 			}
-			privKey, err = hex.DecodeString(string(privkey))
+			privKey, err = crypto.DecodePrivateKey(privkey, conf.Config.Keystore.Passphrase)
 			if err != nil {
-				log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding private key from hex")
+				log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding private key")
 				return nil, err
 			}
 			pubKey, err = crypto.PrivateToPublic(privKey)
@@ -0,0 +1,45 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package model
+
+// StateRow is the hash of the row of the state. The row with the empty table name
+// keeps the homomorphic hash of the whole state.
+type StateRow struct {
+	Table     string `gorm:"primary_key;not null;column:table_name"`
+	RowID     string `gorm:"primary_key;not null"`
+	Ecosystem string `gorm:"primary_key;not null"`
+	Hash      []byte `gorm:"not null"`
+}
+
+// TableName returns name of table
+func (*StateRow) TableName() string {
+	return "state_rows"
+}
+
+// Get is retrieving model from database
+func (sr *StateRow) Get(transaction *DbTransaction, table, rowID, ecosystem string) (bool, error) {
+	return isFound(GetDB(transaction).Where("table_name = ? AND row_id = ? AND ecosystem = ?",
+		table, rowID, ecosystem).First(sr))
+}
+
+// Save is replacing the hash of the row
+func (sr *StateRow) Save(transaction *DbTransaction) error {
+	if err := sr.Delete(transaction); err != nil {
+		return err
+	}
+	return GetDB(transaction).Create(sr).Error
+}
+
+// Delete is deleting the hash of the row
+func (sr *StateRow) Delete(transaction *DbTransaction) error {
+	return GetDB(transaction).Exec(`DELETE FROM "state_rows" WHERE table_name = ? AND row_id = ? AND ecosystem = ?`,
+		sr.Table, sr.RowID, sr.Ecosystem).Error
+}
+
+// DeleteStateRows is deleting the hashes of all rows
+func DeleteStateRows(transaction *DbTransaction) error {
+	return GetDB(transaction).Exec(`DELETE FROM "state_rows"`).Error
+}
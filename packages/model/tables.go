@@ -6,6 +6,7 @@
 package model
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
@@ -84,49 +85,43 @@ func (t *Table) IsExistsByPermissionsAndTableName(transaction *DbTransaction, co
 
 // GetColumns returns columns from database
 func (t *Table) GetColumns(transaction *DbTransaction, name, jsonKey string) (map[string]string, error) {
-	keyStr := ""
-	if jsonKey != "" {
-		keyStr = `->'` + jsonKey + `'`
-	}
-	rows, err := GetDB(transaction).Raw(`SELECT data.* FROM "1_tables", jsonb_each_text(columns`+keyStr+`) AS data WHERE ecosystem = ? AND name = ?`, t.Ecosystem, name).Rows()
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var key, value string
-	result := map[string]string{}
-	for rows.Next() {
-		rows.Scan(&key, &value)
-		result[key] = value
-	}
-	err = rows.Err()
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
+	return t.getJSONFields(transaction, `columns`, name, jsonKey)
 }
 
 // GetPermissions returns table permissions by name
 func (t *Table) GetPermissions(transaction *DbTransaction, name, jsonKey string) (map[string]string, error) {
-	keyStr := ""
-	if jsonKey != "" {
-		keyStr = `->'` + jsonKey + `'`
+	return t.getJSONFields(transaction, `permissions`, name, jsonKey)
+}
+
+// getJSONFields returns the fields of the JSON object which is kept in the column of the table
+// or in its jsonKey field. It is decoded here because jsonb_each_text exists only in PostgreSQL.
+func (t *Table) getJSONFields(transaction *DbTransaction, column, name, jsonKey string) (map[string]string, error) {
+	var data []byte
+	err := GetDB(transaction).Table("1_tables").Select(column).
+		Where("ecosystem = ? AND name = ?", t.Ecosystem, name).Row().Scan(&data)
+	result := map[string]string{}
+	if err == sql.ErrNoRows || len(data) == 0 {
+		return result, nil
 	}
-	rows, err := GetDB(transaction).Raw(`SELECT data.* FROM "1_tables", jsonb_each_text(permissions`+keyStr+`) AS data WHERE ecosystem = ? AND name = ?`, t.Ecosystem, name).Rows()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var key, value string
-	result := map[string]string{}
-	for rows.Next() {
-		rows.Scan(&key, &value)
-		result[key] = value
+	var fields map[string]json.RawMessage
+	if jsonKey != "" {
+		if err = json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+		if data = fields[jsonKey]; data == nil {
+			return result, nil
+		}
 	}
-	err = rows.Err()
-	if err != nil {
+	fields = nil
+	if err = json.Unmarshal(data, &fields); err != nil {
 		return nil, err
 	}
+	for key, value := range fields {
+		result[key] = jsonText(value)
+	}
 	return result, nil
 }
 
@@ -54,6 +54,25 @@ func GetAllUnusedTransactions(dbTransaction *DbTransaction, limit int) ([]*Trans
 	return transactions, nil
 }
 
+// UnusedTransactionInfo is the unused transaction without the data
+type UnusedTransactionInfo struct {
+	Hash     []byte
+	HighRate int8
+	Expedite decimal.Decimal
+	KeyID    int64
+	Time     int64
+	Size     int
+}
+
+// GetAllUnusedTransactionsInfo is retrieving all unused transactions without the data, it is used to sync the mempool
+func GetAllUnusedTransactionsInfo(dbTransaction *DbTransaction) ([]*UnusedTransactionInfo, error) {
+	var list []*UnusedTransactionInfo
+	err := GetDB(dbTransaction).Table("transactions").
+		Select("hash,high_rate,expedite,key_id,time,octet_length(data) AS size").
+		Where("used = ?", "0").Scan(&list).Error
+	return list, err
+}
+
 // GetAllUnsentTransactions is retrieving all unset transactions
 func GetAllUnsentTransactions(limit int) (*[]Transaction, error) {
 	transactions := new([]Transaction)
@@ -85,6 +104,12 @@ func DeleteUsedTransactions(dbTransaction *DbTransaction) (int64, error) {
 	return query.RowsAffected, query.Error
 }
 
+// DeleteUsedCancelsBefore deleting used cancel transactions which are older than the time
+func DeleteUsedCancelsBefore(dbTransaction *DbTransaction, time int64) (int64, error) {
+	query := GetDB(dbTransaction).Exec("DELETE FROM transactions WHERE used = 1 AND type = ? AND time < ?", consts.TxTypeCancel, time)
+	return query.RowsAffected, query.Error
+}
+
 // DeleteTransactionIfUnused deleting unused transaction
 func DeleteTransactionIfUnused(transaction *DbTransaction, transactionHash []byte) (int64, error) {
 	query := GetDB(transaction).Exec("DELETE FROM transactions WHERE hash = ? and used = 0 and verified = 0", transactionHash)
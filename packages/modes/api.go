@@ -10,10 +10,14 @@ func RegisterRoutes() http.Handler {
 		EcosysLookupGetter: BuildEcosystemLookupGetter(),
 		ContractRunner:     GetSmartContractRunner(),
 		ClientTxProcessor:  GetClientTxPreprocessor(),
+		TxTracer:           GetTxTracer(),
+		TxSimulator:        GetTxSimulator(),
 	}
 
 	r := api.NewRouter(m)
-	if !conf.Config.IsSupportingOBS() {
+	if conf.Config.IsLightNode() {
+		m.SetLightRoutes(r)
+	} else if !conf.Config.IsSupportingOBS() {
 		m.SetBlockchainRoutes(r)
 	}
 	if conf.GetGFiles() {
@@ -11,9 +11,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/IBAX-io/go-ibax/packages/crypto"
-	"github.com/IBAX-io/go-ibax/packages/utils"
-
 	"github.com/shopspring/decimal"
 
 	"github.com/IBAX-io/go-ibax/packages/conf"
@@ -48,9 +45,7 @@ func (p blockchainTxPreprocessor) ProcessClientTranstaction(txData []byte, key i
 		return "", fmt.Errorf(`localization size is greater than 2`)
 	}
 
-	var PublicKeys [][]byte
-	PublicKeys = append(PublicKeys, crypto.CutPub(rtx.SmartTx().PublicKey))
-	f, err := utils.CheckSign(PublicKeys, rtx.Hash(), rtx.Signature(), false)
+	f, err := rtx.CheckSign()
 	if err != nil {
 		return "", err
 	}
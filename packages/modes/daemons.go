@@ -16,6 +16,15 @@ func (f BlockchainDaemonsListsFactory) GetDaemonsList() []string {
 		"Confirmations",
 		"Scheduler",
 		"ExternalNetwork",
+		"PeersDiscovery",
+	}
+}
+
+type LightDaemonsListFactory struct{}
+
+func (f LightDaemonsListFactory) GetDaemonsList() []string {
+	return []string{
+		"LightHeaders",
 	}
 }
 
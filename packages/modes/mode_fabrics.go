@@ -240,7 +240,30 @@ func (l OBSDaemonLoader) Load(ctx context.Context) error {
 	return nil
 }
 
+// LightDaemonLoader allows load light node daemons
+type LightDaemonLoader struct {
+	logger            *log.Entry
+	DaemonListFactory types.DaemonListFactory
+}
+
+// Load loads light node daemons, they don't play blocks and don't serve other nodes
+func (l LightDaemonLoader) Load(ctx context.Context) error {
+	logMode(l.logger, "Light node")
+
+	l.logger.Info("start light node daemons")
+	daemons.StartDaemons(ctx, l.DaemonListFactory.GetDaemonsList())
+
+	return nil
+}
+
 func GetDaemonLoader() types.DaemonLoader {
+	if conf.Config.IsLightNode() {
+		return LightDaemonLoader{
+			logger:            log.WithFields(log.Fields{"loader": "light_daemon_loader"}),
+			DaemonListFactory: LightDaemonsListFactory{},
+		}
+	}
+
 	if conf.Config.IsSupportingOBS() {
 		return OBSDaemonLoader{
 			logger:            log.WithFields(log.Fields{"loader": "obs_daemon_loader"}),
@@ -0,0 +1,35 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package modes
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxSimulations is the count of the contracts which can be simulated at the same time
+const maxSimulations = 2
+
+// BlockchainTxSimulator implements TxSimulator for blockchain mode
+type BlockchainTxSimulator struct{}
+
+var simulations = make(chan struct{}, maxSimulations)
+
+// SimulateTx executes the contract in the snapshot of the database concurrently with the daemons,
+// the count of the simultaneous simulations is limited by maxSimulations
+func (BlockchainTxSimulator) SimulateTx(contract string, ecosystemID, keyID int64,
+	params map[string]interface{}, expedite string, le *log.Entry) (interface{}, error) {
+	simulations <- struct{}{}
+	defer func() { <-simulations }()
+
+	return smart.SimulateContract(contract, ecosystemID, keyID, params, expedite)
+}
+
+// GetTxSimulator returns mode bounded implementation of TxSimulator
+func GetTxSimulator() types.TxSimulator {
+	return BlockchainTxSimulator{}
+}
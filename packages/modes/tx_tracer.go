@@ -0,0 +1,30 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package modes
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/daemons"
+	"github.com/IBAX-io/go-ibax/packages/rollback"
+	"github.com/IBAX-io/go-ibax/packages/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// BlockchainTxTracer implements TxTracer for blockchain mode
+type BlockchainTxTracer struct{}
+
+// TraceTx replays the transaction while the daemons are locked
+// because the replay rolls back the state and changes the contracts of the virtual machine
+func (BlockchainTxTracer) TraceTx(hash []byte, maxSteps int, le *log.Entry) (interface{}, error) {
+	daemons.DBLock()
+	defer daemons.DBUnlock()
+
+	return rollback.TraceTx(hash, maxSteps, le)
+}
+
+// GetTxTracer returns mode bounded implementation of TxTracer
+func GetTxTracer() types.TxTracer {
+	return BlockchainTxTracer{}
+}
@@ -0,0 +1,404 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/IBAX-io/go-ibax/packages/statsd"
+
+	"github.com/golang/snappy"
+)
+
+// RequestTypeFramed starts the framed protocol, the requests are sent inside the frames after it.
+// The nodes which don't support the framed protocol close the connection on this type.
+const RequestTypeFramed ReqTypesFlag = 0x4652
+
+// ProtocolVersion is the version of the framed protocol
+const ProtocolVersion uint16 = 1
+
+const (
+	frameHeaderSize = 17
+	maxFrameSize    = 1 << 20
+	maxFrameData    = 64 * 1024
+	// minCompressSize is the min size of data which is compressed
+	minCompressSize = 256
+)
+
+var frameMagic = [4]byte{'I', 'B', 'X', 'F'}
+
+// FrameKind is the kind of frame
+type FrameKind uint8
+
+// Kinds of frames
+const (
+	FrameHello FrameKind = iota + 1
+	FrameRequest
+	FrameData
+	FrameError
+	FrameCompressed
+)
+
+// Codec is the compression of data frames
+type Codec uint8
+
+// Codecs of compression
+const (
+	CodecNone Codec = iota
+	CodecSnappy
+)
+
+// SupportedCodecs are the codecs which can be decoded by this node, the preferred codec is first
+var SupportedCodecs = []Codec{CodecSnappy}
+
+// Codes of the error frames
+const (
+	ErrCodeUnknown uint16 = iota
+	ErrCodeUnsupportedRequest
+	ErrCodeAccessDenied
+	ErrCodeBadRequest
+)
+
+var (
+	// ErrFramedUnsupported is returned when the node closes the connection instead of the hello frame
+	ErrFramedUnsupported = errors.New("Node doesn't support the framed protocol")
+	// ErrFrameMagic is returned when the frame doesn't start with the magic
+	ErrFrameMagic = errors.New("Wrong magic of frame")
+	// ErrFrameKind is returned when the frame of the unexpected kind is received
+	ErrFrameKind = errors.New("Unexpected kind of frame")
+	// ErrFrameID is returned when the frame belongs to the other request
+	ErrFrameID = errors.New("Frame of unknown request")
+	// ErrUnsupportedRequest is returned when the request type isn't supported by the node
+	ErrUnsupportedRequest = errors.New("Request type isn't supported by node")
+	// ErrUnsupportedCodec is returned when the frame is compressed by the unknown codec
+	ErrUnsupportedCodec = errors.New("Compression codec isn't supported")
+)
+
+// SupportedRequestTypes are the request types which can be served by this node
+var SupportedRequestTypes = []ReqTypesFlag{
+	RequestTypeHonorNode,
+	RequestTypeNotHonorNode,
+	RequestTypeStopNetwork,
+	RequestTypeConfirmation,
+	RequestTypeBlockCollection,
+	RequestTypeMaxBlock,
+	RequestTypeSendPrivateData,
+	RequestTypeSendPrivateFile,
+	RequestTypeSendVDESrcData,
+	RequestTypeSendVDESrcDataAgent,
+	RequestTypeSendVDEAgentData,
+	RequestTypeSendSubNodeSrcData,
+	RequestTypeSendSubNodeSrcDataAgent,
+	RequestTypeSendSubNodeAgentData,
+	RequestTypeGetPeers,
+	RequestTypeHonorNodes,
+	RequestTypeRollbacksHash,
+}
+
+// ProtocolError is the error which is returned by the node in the error frame
+type ProtocolError struct {
+	Code    uint16
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("protocol error %d: %s", e.Code, e.Message)
+}
+
+// Frame is the envelope of the framed protocol
+type Frame struct {
+	Version uint16
+	Kind    FrameKind
+	Type    ReqTypesFlag
+	ID      uint32
+	Payload []byte
+}
+
+func (f *Frame) Read(r io.Reader) error {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if !bytes.Equal(header[:4], frameMagic[:]) {
+		return ErrFrameMagic
+	}
+	f.Version = binary.LittleEndian.Uint16(header[4:])
+	f.Kind = FrameKind(header[6])
+	f.Type = ReqTypesFlag(binary.LittleEndian.Uint16(header[7:]))
+	f.ID = binary.LittleEndian.Uint32(header[9:])
+	size := binary.LittleEndian.Uint32(header[13:])
+	if size > maxFrameSize {
+		return ErrMaxSize
+	}
+	f.Payload = make([]byte, size)
+	_, err := io.ReadFull(r, f.Payload)
+	return err
+}
+
+func (f *Frame) Write(w io.Writer) error {
+	buf := make([]byte, frameHeaderSize, frameHeaderSize+len(f.Payload))
+	copy(buf, frameMagic[:])
+	binary.LittleEndian.PutUint16(buf[4:], f.Version)
+	buf[6] = byte(f.Kind)
+	binary.LittleEndian.PutUint16(buf[7:], uint16(f.Type))
+	binary.LittleEndian.PutUint32(buf[9:], f.ID)
+	binary.LittleEndian.PutUint32(buf[13:], uint32(len(f.Payload)))
+	_, err := w.Write(append(buf, f.Payload...))
+	return err
+}
+
+// marshalHello returns the payload of hello frame, it contains the supported request types
+// which are finished by zero type and the supported codecs
+func marshalHello(types []ReqTypesFlag, codecs []Codec) []byte {
+	buf := make([]byte, 2*len(types)+2, 2*len(types)+2+len(codecs))
+	for i, t := range types {
+		binary.LittleEndian.PutUint16(buf[2*i:], uint16(t))
+	}
+	for _, c := range codecs {
+		buf = append(buf, byte(c))
+	}
+	return buf
+}
+
+func unmarshalHello(data []byte) (map[ReqTypesFlag]bool, map[Codec]bool) {
+	types := make(map[ReqTypesFlag]bool, len(data)/2)
+	codecs := make(map[Codec]bool)
+	i := 0
+	for ; i+1 < len(data); i += 2 {
+		t := ReqTypesFlag(binary.LittleEndian.Uint16(data[i:]))
+		if t == 0 {
+			i += 2
+			break
+		}
+		types[t] = true
+	}
+	for ; i < len(data); i++ {
+		codecs[Codec(data[i])] = true
+	}
+	return types, codecs
+}
+
+// FrameConn is the connection which carries the request types and the data of requests in frames.
+// RequestType reads and writes the request frames when it is used with FrameConn.
+type FrameConn struct {
+	net.Conn
+	// Version is the negotiated version of protocol
+	Version uint16
+	// Types are the request types supported by the peer
+	Types map[ReqTypesFlag]bool
+	// Codec is the compression which is supported by both nodes, it is CodecNone if there isn't such one
+	Codec Codec
+
+	reqType  ReqTypesFlag
+	reqID    uint32
+	nextID   uint32
+	readBuf  []byte
+	compress bool
+}
+
+func (c *FrameConn) writeFrame(kind FrameKind, payload []byte) error {
+	f := &Frame{Version: c.Version, Kind: kind, Type: c.reqType, ID: c.reqID, Payload: payload}
+	return f.Write(c.Conn)
+}
+
+// Write sends the data of the current request in the data frames
+func (c *FrameConn) Write(b []byte) (int, error) {
+	var n int
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > maxFrameData {
+			chunk = chunk[:maxFrameData]
+		}
+		if err := c.writeChunk(chunk); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		b = b[len(chunk):]
+	}
+	return n, nil
+}
+
+func (c *FrameConn) writeChunk(chunk []byte) error {
+	if c.compress && len(chunk) >= minCompressSize {
+		data := snappy.Encode(nil, chunk)
+		// the data isn't compressed if it doesn't become smaller
+		if len(data)+1 < len(chunk) {
+			c.recordCompression(len(chunk), len(data)+1)
+			return c.writeFrame(FrameCompressed, append([]byte{byte(c.Codec)}, data...))
+		}
+	}
+	return c.writeFrame(FrameData, chunk)
+}
+
+func (c *FrameConn) recordCompression(size, compressed int) {
+	if statsd.Client == nil {
+		return
+	}
+	name := statsd.CompressionCounterName(int(c.reqType))
+	statsd.Client.Inc(name+statsd.RawBytes, int64(size), 1.0)
+	statsd.Client.Inc(name+statsd.CompressedBytes, int64(compressed), 1.0)
+	statsd.Client.Gauge(name+statsd.Ratio, int64(compressed*100/size), 1.0)
+}
+
+func decompress(payload []byte) ([]byte, error) {
+	if len(payload) == 0 || Codec(payload[0]) != CodecSnappy {
+		return nil, ErrUnsupportedCodec
+	}
+	size, err := snappy.DecodedLen(payload[1:])
+	if err != nil {
+		return nil, err
+	}
+	if size > maxFrameSize {
+		return nil, ErrMaxSize
+	}
+	return snappy.Decode(nil, payload[1:])
+}
+
+// Compress enables the compression of data which is sent for the current request,
+// the data isn't compressed if the peer doesn't support any codec of this node
+func (c *FrameConn) Compress() {
+	c.compress = c.Codec != CodecNone
+}
+
+// Read receives the data of the current request, the error frame is returned as *ProtocolError
+func (c *FrameConn) Read(b []byte) (int, error) {
+	for len(c.readBuf) == 0 {
+		f := &Frame{}
+		if err := f.Read(c.Conn); err != nil {
+			return 0, err
+		}
+		if f.ID != c.reqID {
+			return 0, ErrFrameID
+		}
+		switch f.Kind {
+		case FrameData:
+			c.readBuf = f.Payload
+		case FrameCompressed:
+			data, err := decompress(f.Payload)
+			if err != nil {
+				return 0, err
+			}
+			c.readBuf = data
+		case FrameError:
+			return 0, unmarshalError(f.Payload)
+		default:
+			return 0, ErrFrameKind
+		}
+	}
+	n := copy(b, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+// Begin sends the request frame, the request type must be supported by the peer
+func (c *FrameConn) Begin(reqType ReqTypesFlag) error {
+	if !c.Types[reqType] {
+		return ErrUnsupportedRequest
+	}
+	c.nextID++
+	c.reqType, c.reqID, c.readBuf, c.compress = reqType, c.nextID, nil, false
+	return c.writeFrame(FrameRequest, nil)
+}
+
+// Accept receives the request frame and returns the request type
+func (c *FrameConn) Accept() (ReqTypesFlag, error) {
+	f := &Frame{}
+	if err := f.Read(c.Conn); err != nil {
+		return 0, err
+	}
+	if f.Kind != FrameRequest {
+		return 0, ErrFrameKind
+	}
+	c.reqType, c.reqID, c.readBuf, c.compress = f.Type, f.ID, nil, false
+	return f.Type, nil
+}
+
+// SendError sends the error frame for the current request
+func (c *FrameConn) SendError(code uint16, message string) error {
+	payload := make([]byte, 2, 2+len(message))
+	binary.LittleEndian.PutUint16(payload, code)
+	return c.writeFrame(FrameError, append(payload, message...))
+}
+
+func unmarshalError(payload []byte) error {
+	if len(payload) < 2 {
+		return &ProtocolError{Code: ErrCodeUnknown}
+	}
+	return &ProtocolError{Code: binary.LittleEndian.Uint16(payload), Message: string(payload[2:])}
+}
+
+// Compress enables the compression of data which is sent for the current request
+// if the connection uses the framed protocol
+func Compress(w io.Writer) {
+	if fc, ok := w.(*FrameConn); ok {
+		fc.Compress()
+	}
+}
+
+// SendError sends the error frame if the connection uses the framed protocol,
+// the connection of the previous version is just closed by the caller
+func SendError(w io.Writer, code uint16, message string) error {
+	if fc, ok := w.(*FrameConn); ok {
+		return fc.SendError(code, message)
+	}
+	return nil
+}
+
+func negotiate(conn net.Conn, hello *Frame) (*FrameConn, error) {
+	if hello.Kind != FrameHello {
+		return nil, ErrFrameKind
+	}
+	types, codecs := unmarshalHello(hello.Payload)
+	fc := &FrameConn{Conn: conn, Version: ProtocolVersion, Types: types}
+	if hello.Version < fc.Version {
+		fc.Version = hello.Version
+	}
+	for _, c := range SupportedCodecs {
+		if codecs[c] {
+			fc.Codec = c
+			break
+		}
+	}
+	return fc, nil
+}
+
+// ClientFraming starts the framed protocol and exchanges the capabilities with the node
+func ClientFraming(conn net.Conn) (*FrameConn, error) {
+	rt := &RequestType{Type: RequestTypeFramed}
+	if err := rt.Write(conn); err != nil {
+		return nil, err
+	}
+	hello := &Frame{Version: ProtocolVersion, Kind: FrameHello, Payload: marshalHello(SupportedRequestTypes, SupportedCodecs)}
+	// the node of the previous version closes the connection on the unknown request type
+	if err := hello.Write(conn); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFramedUnsupported, err)
+	}
+	if err := hello.Read(conn); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFramedUnsupported, err)
+	}
+	return negotiate(conn, hello)
+}
+
+// ServerFraming accepts the framed protocol after RequestTypeFramed has been read
+func ServerFraming(conn net.Conn) (*FrameConn, error) {
+	hello := &Frame{}
+	if err := hello.Read(conn); err != nil {
+		return nil, err
+	}
+	fc, err := negotiate(conn, hello)
+	if err != nil {
+		return nil, err
+	}
+	reply := &Frame{Version: ProtocolVersion, Kind: FrameHello, Payload: marshalHello(SupportedRequestTypes, SupportedCodecs)}
+	if err = reply.Write(conn); err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
@@ -0,0 +1,144 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(t *testing.T, handler func(conn net.Conn)) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}()
+	return l.Addr().String()
+}
+
+func TestFramedRequest(t *testing.T) {
+	data := bytes.Repeat([]byte("body"), maxFrameData/2)
+	addr := testServer(t, func(conn net.Conn) {
+		rt := &RequestType{}
+		require.NoError(t, rt.Read(conn))
+		require.Equal(t, RequestTypeFramed, rt.Type)
+		fc, err := ServerFraming(conn)
+		require.NoError(t, err)
+
+		require.NoError(t, rt.Read(fc))
+		assert.Equal(t, RequestTypeBlockCollection, rt.Type)
+		req, err := ReadSliceWithMaxSize(fc, maxFrameSize)
+		require.NoError(t, err)
+		assert.Equal(t, data, req)
+		require.NoError(t, writeSlice(fc, []byte("answer")))
+
+		// the unknown request gets the error instead of the dropped connection
+		require.NoError(t, rt.Read(fc))
+		fc.SendError(ErrCodeUnsupportedRequest, "unknown request type")
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	fc, err := ClientFraming(conn)
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolVersion, fc.Version)
+
+	rt := &RequestType{Type: RequestTypeBlockCollection}
+	require.NoError(t, rt.Write(fc))
+	require.NoError(t, writeSlice(fc, data))
+	answer, err := ReadSliceWithMaxSize(fc, maxFrameSize)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("answer"), answer)
+
+	// the request type which isn't supported by the node isn't sent
+	rt.Type = 0x100
+	assert.Equal(t, ErrUnsupportedRequest, rt.Write(fc))
+	fc.Types[rt.Type] = true
+	require.NoError(t, rt.Write(fc))
+	_, err = fc.Read(make([]byte, 1))
+	var perr *ProtocolError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, ErrCodeUnsupportedRequest, perr.Code)
+}
+
+func TestFramedUnsupported(t *testing.T) {
+	// the node of the previous version reads the request type and closes the connection
+	addr := testServer(t, func(conn net.Conn) {
+		rt := &RequestType{}
+		rt.Read(conn)
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = ClientFraming(conn)
+	assert.True(t, errors.Is(err, ErrFramedUnsupported))
+}
+
+func TestFrameMagic(t *testing.T) {
+	var buf bytes.Buffer
+	f := &Frame{Version: ProtocolVersion, Kind: FrameData, ID: 1, Payload: []byte("data")}
+	require.NoError(t, f.Write(&buf))
+	raw := buf.Bytes()
+
+	read := &Frame{}
+	require.NoError(t, read.Read(bytes.NewReader(raw)))
+	assert.Equal(t, f, read)
+
+	raw[0] = 'X'
+	assert.Equal(t, ErrFrameMagic, read.Read(bytes.NewReader(raw)))
+	assert.Equal(t, io.EOF, read.Read(bytes.NewReader(nil)))
+}
+
+func TestFrameCompression(t *testing.T) {
+	data := bytes.Repeat([]byte("transaction"), maxFrameData/4)
+	addr := testServer(t, func(conn net.Conn) {
+		rt := &RequestType{}
+		require.NoError(t, rt.Read(conn))
+		fc, err := ServerFraming(conn)
+		require.NoError(t, err)
+
+		require.NoError(t, rt.Read(fc))
+		Compress(fc)
+		require.NoError(t, writeSlice(fc, data))
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	fc, err := ClientFraming(conn)
+	require.NoError(t, err)
+	assert.Equal(t, CodecSnappy, fc.Codec)
+
+	rt := &RequestType{Type: RequestTypeBlockCollection}
+	require.NoError(t, rt.Write(fc))
+	answer, err := ReadSliceWithMaxSize(fc, uint64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, data, answer)
+}
+
+func TestHelloCodecs(t *testing.T) {
+	types, codecs := unmarshalHello(marshalHello([]ReqTypesFlag{RequestTypeMaxBlock, RequestTypeGetPeers}, []Codec{CodecSnappy}))
+	assert.Equal(t, map[ReqTypesFlag]bool{RequestTypeMaxBlock: true, RequestTypeGetPeers: true}, types)
+	assert.Equal(t, map[Codec]bool{CodecSnappy: true}, codecs)
+
+	// the peer without codecs
+	types, codecs = unmarshalHello(marshalHello([]ReqTypesFlag{RequestTypeMaxBlock}, nil))
+	assert.Len(t, types, 1)
+	assert.Empty(t, codecs)
+}
@@ -0,0 +1,380 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package peers
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxGossipHosts is the max count of hosts which are sent in the answer of peers request
+	MaxGossipHosts = 100
+	// maxPeers is the max count of known peers
+	maxPeers = 1000
+	// maxFailures is the count of failed requests in a row after that the discovered peer is forgotten
+	maxFailures = 5
+	// defaultLatency is used for the peer which hasn't answered yet
+	defaultLatency = 500 * time.Millisecond
+	// maxBanTime limits the ban time which is doubled for every next ban of peer
+	maxBanTime = 24 * time.Hour
+	// latencyWeight is the weight of the last request in the average latency
+	latencyWeight = 0.2
+)
+
+// Peer is the statistics of node which are used to choose the best peers
+type Peer struct {
+	Host string
+	// Static peers are taken from honor_nodes and the config, they are never forgotten
+	Static     bool
+	Latency    time.Duration
+	GoodBlocks int64
+	BadBlocks  int64
+	Failures   int64
+	Bans       int64
+	BannedTo   time.Time
+	LastSeen   time.Time
+}
+
+// Score returns the rating of peer, the peer with the greater score is better
+func (p *Peer) Score() float64 {
+	latency := p.Latency
+	if latency == 0 {
+		latency = defaultLatency
+	}
+	validity := float64(p.GoodBlocks+1) / float64(p.GoodBlocks+p.BadBlocks+1)
+	return validity / (1 + latency.Seconds()*10) / float64(1+p.Failures) / float64(1+p.Bans)
+}
+
+// IsBanned returns true if the peer has been banned for the bad blocks
+func (p *Peer) IsBanned(now time.Time) bool {
+	return now.Before(p.BannedTo)
+}
+
+// Manager keeps the known peers and their statistics
+type Manager struct {
+	mu    sync.RWMutex
+	peers map[string]*Peer
+	now   func() time.Time
+}
+
+var manager = NewManager()
+
+// GetManager returns the peer manager of node
+func GetManager() *Manager {
+	return manager
+}
+
+// NewManager returns the empty peer manager
+func NewManager() *Manager {
+	return &Manager{
+		peers: make(map[string]*Peer),
+		now:   time.Now,
+	}
+}
+
+// privateNets are the networks which addresses aren't accepted from other nodes
+var privateNets = parseNets("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7")
+
+func parseNets(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, s := range cidrs {
+		_, nets[i], _ = net.ParseCIDR(s)
+	}
+	return nets
+}
+
+// validAddr returns true if the host has the correct syntax of address
+func validAddr(host string) bool {
+	h, port, err := net.SplitHostPort(host)
+	if err != nil || len(h) == 0 {
+		return false
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil || p <= 0 || p > 65535 {
+		return false
+	}
+	if ip := net.ParseIP(h); ip != nil && (ip.IsUnspecified() || ip.IsMulticast()) {
+		return false
+	}
+	return true
+}
+
+// ValidHost returns true if the host can be added as discovered peer.
+// The loopback, link-local and private addresses are rejected, they can't be reached from other nodes.
+func ValidHost(host string) bool {
+	if !validAddr(host) {
+		return false
+	}
+	h, _, _ := net.SplitHostPort(host)
+	if strings.EqualFold(h, "localhost") {
+		return false
+	}
+	ip := net.ParseIP(h)
+	if ip == nil {
+		return true
+	}
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return false
+	}
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Announced returns true if the host has been announced by the node itself,
+// the address of announced host must be the same as the remote address of connection
+func Announced(host string, remote net.Addr) bool {
+	h, _, err := net.SplitHostPort(host)
+	if err != nil || remote == nil {
+		return false
+	}
+	ip := net.ParseIP(h)
+	if ip == nil {
+		return false
+	}
+	r, _, err := net.SplitHostPort(remote.String())
+	if err != nil {
+		return false
+	}
+	return ip.Equal(net.ParseIP(r))
+}
+
+func (m *Manager) peer(host string) *Peer {
+	p, ok := m.peers[host]
+	if !ok {
+		p = &Peer{Host: host}
+		m.peers[host] = p
+	}
+	return p
+}
+
+// AddStatic adds the peers which are known from honor_nodes and the config.
+// The addresses of private networks are allowed here for the test networks.
+func (m *Manager) AddStatic(hosts ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, h := range hosts {
+		if validAddr(h) {
+			m.peer(h).Static = true
+		}
+	}
+}
+
+// Add adds the discovered peers, the new peers aren't added when the max count of peers is reached
+// and all known peers are alive
+func (m *Manager) Add(hosts ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, h := range hosts {
+		if _, ok := m.peers[h]; ok || !ValidHost(h) {
+			continue
+		}
+		if len(m.peers) >= maxPeers && !m.evict() {
+			return
+		}
+		m.peers[h] = &Peer{Host: h}
+	}
+}
+
+// evict removes the worst discovered peer which has failed requests
+func (m *Manager) evict() bool {
+	var worst *Peer
+	for _, p := range m.peers {
+		if p.Static || p.Failures == 0 {
+			continue
+		}
+		if worst == nil || p.Score() < worst.Score() {
+			worst = p
+		}
+	}
+	if worst == nil {
+		return false
+	}
+	delete(m.peers, worst.Host)
+	return true
+}
+
+// RecordLatency saves the time of the successful request to the peer
+func (m *Manager) RecordLatency(host string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := m.peer(host)
+	if p.Latency == 0 {
+		p.Latency = d
+	} else {
+		p.Latency = time.Duration((1-latencyWeight)*float64(p.Latency) + latencyWeight*float64(d))
+	}
+	p.Failures = 0
+	p.LastSeen = m.now()
+}
+
+// RecordFailure saves the failed request to the peer, the discovered peer is forgotten
+// after maxFailures failed requests in a row
+func (m *Manager) RecordFailure(host string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := m.peer(host)
+	p.Failures++
+	if !p.Static && p.Failures >= maxFailures {
+		delete(m.peers, host)
+	}
+}
+
+// RecordBlocks saves the count of valid blocks which have been received from the peer
+func (m *Manager) RecordBlocks(host string, count int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.peer(host).GoodBlocks += count
+}
+
+// RecordBadBlock saves the invalid block which has been received from the peer and bans it.
+// The ban time is doubled for every next ban of peer.
+func (m *Manager) RecordBadBlock(host string, banTime time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := m.peer(host)
+	p.BadBlocks++
+	for i := int64(0); i < p.Bans && banTime < maxBanTime; i++ {
+		banTime *= 2
+	}
+	if banTime > maxBanTime {
+		banTime = maxBanTime
+	}
+	p.Bans++
+	p.BannedTo = m.now().Add(banTime)
+}
+
+// Ban bans the known peer till the time, it is used to restore the bans which have been saved
+func (m *Manager) Ban(host string, to time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p, ok := m.peers[host]; ok && to.After(p.BannedTo) {
+		p.BannedTo = to
+	}
+}
+
+// IsBanned returns true if the peer has been banned
+func (m *Manager) IsBanned(host string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p, ok := m.peers[host]
+	return ok && p.IsBanned(m.now())
+}
+
+// Peers returns the copy of statistics of known peers sorted by score
+func (m *Manager) Peers() []Peer {
+	m.mu.RLock()
+	list := make([]Peer, 0, len(m.peers))
+	for _, p := range m.peers {
+		list = append(list, *p)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if si, sj := list[i].Score(), list[j].Score(); si != sj {
+			return si > sj
+		}
+		return list[i].Host < list[j].Host
+	})
+	return list
+}
+
+// Hosts returns the alive hosts which aren't banned, the static hosts are first so
+// the discovered peers can't push them out, the best hosts are first among them.
+// If limit is greater than zero then no more than limit hosts are returned.
+func (m *Manager) Hosts(limit int) []string {
+	now := m.now()
+	list := m.Peers()
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Static && !list[j].Static
+	})
+	hosts := make([]string, 0)
+	for _, p := range list {
+		if p.LastSeen.IsZero() || p.Failures > 0 || p.IsBanned(now) {
+			continue
+		}
+		hosts = append(hosts, p.Host)
+		if limit > 0 && len(hosts) >= limit {
+			break
+		}
+	}
+	return hosts
+}
+
+// Unchecked returns the discovered hosts which haven't answered yet
+func (m *Manager) Unchecked(limit int) []string {
+	hosts := make([]string, 0)
+	for _, p := range m.Peers() {
+		if p.LastSeen.IsZero() && p.Failures == 0 {
+			hosts = append(hosts, p.Host)
+			if limit > 0 && len(hosts) >= limit {
+				break
+			}
+		}
+	}
+	return hosts
+}
+
+// Best sorts the hosts by score and removes the banned ones, the static hosts are first.
+// If limit is greater than zero then no more than limit hosts are returned.
+func (m *Manager) Best(hosts []string, limit int) []string {
+	now := m.now()
+	type scored struct {
+		host   string
+		static bool
+		score  float64
+	}
+	list := make([]scored, 0, len(hosts))
+	seen := make(map[string]bool, len(hosts))
+
+	m.mu.RLock()
+	for _, h := range hosts {
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		p, ok := m.peers[h]
+		if !ok {
+			p = &Peer{Host: h}
+		}
+		if p.IsBanned(now) {
+			continue
+		}
+		list = append(list, scored{h, p.Static, p.Score()})
+	}
+	m.mu.RUnlock()
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].static != list[j].static {
+			return list[i].static
+		}
+		return list[i].score > list[j].score
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	ret := make([]string, len(list))
+	for i, s := range list {
+		ret[i] = s.host
+	}
+	return ret
+}
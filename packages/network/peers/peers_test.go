@@ -0,0 +1,135 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package peers
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidHost(t *testing.T) {
+	for host, valid := range map[string]bool{
+		"203.0.113.1:7078":    true,
+		"node.ibax.io:7078":   true,
+		"[2001:db8::1]:7078":  true,
+		"203.0.113.1":         false,
+		":7078":               false,
+		"0.0.0.0:7078":        false,
+		"203.0.113.1:0":       false,
+		"203.0.113.1:70780":   false,
+		"127.0.0.1:7078":      false,
+		"localhost:7078":      false,
+		"[::1]:7078":          false,
+		"10.0.0.1:7078":       false,
+		"172.20.0.1:7078":     false,
+		"192.168.1.1:7078":    false,
+		"169.254.0.1:7078":    false,
+		"[fd00::1]:7078":      false,
+		"[fe80::1]:7078":      false,
+		"100.64.0.1:7078":     false,
+		"172.32.0.1:7078":     true,
+		"[2001:db8::1]:65535": true,
+	} {
+		assert.Equal(t, valid, ValidHost(host), host)
+	}
+}
+
+func TestAnnounced(t *testing.T) {
+	remote := &net.TCPAddr{IP: net.ParseIP("203.0.113.1"), Port: 51234}
+	assert.True(t, Announced("203.0.113.1:7078", remote))
+	assert.False(t, Announced("203.0.113.2:7078", remote))
+	assert.False(t, Announced("node.ibax.io:7078", remote))
+	assert.False(t, Announced("203.0.113.1:7078", nil))
+}
+
+func TestBestPeers(t *testing.T) {
+	m := NewManager()
+	m.AddStatic("10.0.0.1:7078")
+	m.Add("203.0.113.2:7078", "203.0.113.3:7078", "203.0.113.5:7078", "192.168.1.1:7078", "wrong")
+	assert.Len(t, m.Peers(), 4)
+
+	m.RecordLatency("10.0.0.1:7078", 300*time.Millisecond)
+	m.RecordLatency("203.0.113.2:7078", 50*time.Millisecond)
+	m.RecordLatency("203.0.113.3:7078", 50*time.Millisecond)
+	m.RecordLatency("203.0.113.5:7078", 100*time.Millisecond)
+	m.RecordBlocks("203.0.113.2:7078", 100)
+	m.RecordBlocks("203.0.113.3:7078", 100)
+	m.RecordBadBlock("203.0.113.3:7078", time.Minute)
+
+	// the static peer is kept first though the discovered peers are faster
+	assert.True(t, m.IsBanned("203.0.113.3:7078"))
+	assert.Equal(t, []string{"10.0.0.1:7078", "203.0.113.2:7078", "203.0.113.5:7078"}, m.Hosts(0))
+	assert.Equal(t, []string{"10.0.0.1:7078"}, m.Hosts(1))
+	// the unknown peer is better than the slow one
+	m.RecordLatency("203.0.113.5:7078", 5*time.Second)
+	assert.Equal(t, []string{"10.0.0.1:7078", "203.0.113.2:7078", "203.0.113.4:7078", "203.0.113.5:7078"},
+		m.Best([]string{"203.0.113.5:7078", "203.0.113.3:7078", "203.0.113.4:7078", "203.0.113.2:7078", "10.0.0.1:7078"}, 0))
+	assert.Equal(t, []string{"10.0.0.1:7078"}, m.Best([]string{"203.0.113.2:7078", "10.0.0.1:7078"}, 1))
+}
+
+func TestRestoreBan(t *testing.T) {
+	now := time.Now()
+	m := NewManager()
+	m.now = func() time.Time { return now }
+
+	m.AddStatic("10.0.0.1:7078")
+	m.Ban("10.0.0.1:7078", now.Add(time.Hour))
+	// the unknown peer isn't added by ban
+	m.Ban("203.0.113.1:7078", now.Add(time.Hour))
+	assert.Len(t, m.Peers(), 1)
+	assert.True(t, m.IsBanned("10.0.0.1:7078"))
+
+	// the earlier ban doesn't shorten the current one
+	m.Ban("10.0.0.1:7078", now.Add(time.Minute))
+	now = now.Add(30 * time.Minute)
+	assert.True(t, m.IsBanned("10.0.0.1:7078"))
+	now = now.Add(time.Hour)
+	assert.False(t, m.IsBanned("10.0.0.1:7078"))
+}
+
+func TestBanHistory(t *testing.T) {
+	now := time.Now()
+	m := NewManager()
+	m.now = func() time.Time { return now }
+
+	host := "10.0.0.1:7078"
+	m.RecordBadBlock(host, time.Minute)
+	now = now.Add(time.Minute)
+	assert.False(t, m.IsBanned(host))
+
+	// the next ban is longer
+	m.RecordBadBlock(host, time.Minute)
+	now = now.Add(time.Minute)
+	assert.True(t, m.IsBanned(host))
+	now = now.Add(time.Minute)
+	assert.False(t, m.IsBanned(host))
+
+	peers := m.Peers()
+	assert.Equal(t, int64(2), peers[0].Bans)
+	assert.Equal(t, int64(2), peers[0].BadBlocks)
+}
+
+func TestForgetPeers(t *testing.T) {
+	m := NewManager()
+	m.AddStatic("10.0.0.1:7078")
+	m.Add("203.0.113.2:7078")
+	assert.Equal(t, []string{"10.0.0.1:7078", "203.0.113.2:7078"}, m.Unchecked(0))
+
+	for i := 0; i < maxFailures; i++ {
+		m.RecordFailure("10.0.0.1:7078")
+		m.RecordFailure("203.0.113.2:7078")
+	}
+	// the static peer is kept
+	peers := m.Peers()
+	assert.Len(t, peers, 1)
+	assert.Equal(t, "10.0.0.1:7078", peers[0].Host)
+
+	m.RecordLatency("10.0.0.1:7078", time.Millisecond)
+	assert.Equal(t, []string{"10.0.0.1:7078"}, m.Hosts(0))
+}
@@ -6,9 +6,11 @@ package network
 
 import (
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
@@ -35,6 +37,9 @@ const (
 	RequestTypeSendSubNodeSrcData
 	RequestTypeSendSubNodeSrcDataAgent
 	RequestTypeSendSubNodeAgentData
+	RequestTypeGetPeers
+	RequestTypeHonorNodes
+	RequestTypeRollbacksHash
 
 	// BlocksPerRequest contains count of blocks per request
 	//BlocksPerRequest int32 = 1000
@@ -57,12 +62,19 @@ type RequestType struct {
 	Type ReqTypesFlag
 }
 
-// Read read first 2 bytes to uint16
-func (rt *RequestType) Read(r io.Reader) error {
+// Read read first 2 bytes to uint16, the request frame is read for the framed connection
+func (rt *RequestType) Read(r io.Reader) (err error) {
+	if fc, ok := r.(*FrameConn); ok {
+		rt.Type, err = fc.Accept()
+		return
+	}
 	return binary.Read(r, binary.LittleEndian, &rt.Type)
 }
 
 func (rt *RequestType) Write(w io.Writer) error {
+	if fc, ok := w.(*FrameConn); ok {
+		return fc.Begin(rt.Type)
+	}
 	return binary.Write(w, binary.LittleEndian, rt.Type)
 }
 
@@ -1653,3 +1665,121 @@ func (resp *VDEAgentDataResponse) Read(r io.Reader) error {
 func (resp *VDEAgentDataResponse) Write(w io.Writer) error {
 	return writeSlice(w, []byte(resp.Hash))
 }
+
+// maxPeersSize is the max size of the list of peers
+const maxPeersSize = 64 * 1024
+
+// PeersRequest contains the address of node which announces itself, it can be empty
+type PeersRequest struct {
+	Host string
+}
+
+func (req *PeersRequest) Read(r io.Reader) error {
+	slice, err := ReadSliceWithMaxSize(r, maxPeersSize)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading PeersRequest")
+		return err
+	}
+
+	req.Host = string(slice)
+	return nil
+}
+
+func (req *PeersRequest) Write(w io.Writer) error {
+	return writeSlice(w, []byte(req.Host))
+}
+
+// PeersResponse contains the addresses of peers which are known by node
+type PeersResponse struct {
+	Hosts []string
+}
+
+func (resp *PeersResponse) Read(r io.Reader) error {
+	slice, err := ReadSliceWithMaxSize(r, maxPeersSize)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading PeersResponse")
+		return err
+	}
+
+	resp.Hosts = nil
+	if len(slice) > 0 {
+		resp.Hosts = strings.Split(string(slice), ",")
+	}
+	return nil
+}
+
+func (resp *PeersResponse) Write(w io.Writer) error {
+	return writeSlice(w, []byte(strings.Join(resp.Hosts, ",")))
+}
+
+// maxHonorNodesSize is the max size of the list of public keys of honor nodes
+const maxHonorNodesSize = 256 * 1024
+
+// HonorNodesResponse contains the public keys of honor nodes ordered by the node position
+type HonorNodesResponse struct {
+	Keys [][]byte
+}
+
+func (resp *HonorNodesResponse) Read(r io.Reader) error {
+	slice, err := ReadSliceWithMaxSize(r, maxHonorNodesSize)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading HonorNodesResponse")
+		return err
+	}
+
+	resp.Keys = nil
+	if len(slice) == 0 {
+		return nil
+	}
+	for _, item := range strings.Split(string(slice), ",") {
+		key, err := hex.DecodeString(item)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("on decoding honor node key")
+			return err
+		}
+		resp.Keys = append(resp.Keys, key)
+	}
+	return nil
+}
+
+func (resp *HonorNodesResponse) Write(w io.Writer) error {
+	keys := make([]string, 0, len(resp.Keys))
+	for _, key := range resp.Keys {
+		keys = append(keys, hex.EncodeToString(key))
+	}
+	return writeSlice(w, []byte(strings.Join(keys, ",")))
+}
+
+// maxRollbacksHashSize is the max size of the rollbacks hash of block
+const maxRollbacksHashSize = 1024
+
+// RollbacksHashRequest contains the id of block
+type RollbacksHashRequest struct {
+	BlockID int64
+}
+
+func (req *RollbacksHashRequest) Read(r io.Reader) (err error) {
+	req.BlockID, err = ReadInt(r)
+	return
+}
+
+func (req *RollbacksHashRequest) Write(w io.Writer) error {
+	return WriteInt(req.BlockID, w)
+}
+
+// RollbacksHashResponse contains the rollbacks hash of the requested block
+type RollbacksHashResponse struct {
+	Hash []byte
+}
+
+func (resp *RollbacksHashResponse) Read(r io.Reader) (err error) {
+	resp.Hash, err = ReadSliceWithMaxSize(r, maxRollbacksHashSize)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("on reading RollbacksHashResponse")
+	}
+	return
+}
+
+func (resp *RollbacksHashResponse) Write(w io.Writer) error {
+	return writeSlice(w, resp.Hash)
+}
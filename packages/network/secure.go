@@ -0,0 +1,380 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/signer"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestTypeSecureSession starts the secure session, the real request type is sent inside the session.
+// The nodes which don't support the secure session close the connection on this type.
+const RequestTypeSecureSession ReqTypesFlag = 0x5353
+
+const (
+	handshakeNonceSize = 32
+	maxHandshakeSize   = 1024
+	maxRecordSize      = 64 * 1024
+	clientLabel        = "client"
+	serverLabel        = "server"
+)
+
+var (
+	// ErrSecureUnsupported is returned when the node closes the connection instead of the handshake
+	ErrSecureUnsupported = errors.New("Node doesn't support the secure session")
+	// ErrHandshakeSign is returned when the node can't prove the ownership of its key
+	ErrHandshakeSign = errors.New("Incorrect sign of handshake")
+	// ErrHandshakeKey is returned when the session key of the node is incorrect
+	ErrHandshakeKey = errors.New("Incorrect session key of handshake")
+	// ErrPeerKey is returned when the key of the node doesn't match the honor nodes
+	ErrPeerKey = errors.New("Public key of node doesn't match the honor nodes")
+	// ErrRecordAuth is returned when the received record of the session has been changed
+	ErrRecordAuth = errors.New("Record of the secure session is corrupted")
+)
+
+// RequiresHonorNode returns true if the request can be sent by the authenticated honor node only
+func (t ReqTypesFlag) RequiresHonorNode() bool {
+	switch t {
+	case RequestTypeHonorNode, RequestTypeStopNetwork, RequestTypeSendPrivateData, RequestTypeSendPrivateFile,
+		RequestTypeSendVDESrcData, RequestTypeSendVDESrcDataAgent, RequestTypeSendVDEAgentData,
+		RequestTypeSendSubNodeSrcData, RequestTypeSendSubNodeSrcDataAgent, RequestTypeSendSubNodeAgentData:
+		return true
+	}
+	return false
+}
+
+// HandshakeHello is sent by each side at the start of the secure session
+type HandshakeHello struct {
+	NodeKey    []byte // public key of the node, it is empty if the node doesn't have the key
+	SessionKey []byte // ephemeral public key of the session
+	Nonce      []byte
+}
+
+func (h *HandshakeHello) Read(r io.Reader) (err error) {
+	if h.NodeKey, err = ReadSliceWithMaxSize(r, maxHandshakeSize); err != nil {
+		return
+	}
+	if h.SessionKey, err = ReadSliceWithMaxSize(r, maxHandshakeSize); err != nil {
+		return
+	}
+	h.Nonce, err = ReadSliceWithMaxSize(r, maxHandshakeSize)
+	return
+}
+
+func (h *HandshakeHello) Write(w io.Writer) error {
+	if err := writeSlice(w, h.NodeKey); err != nil {
+		return err
+	}
+	if err := writeSlice(w, h.SessionKey); err != nil {
+		return err
+	}
+	return writeSlice(w, h.Nonce)
+}
+
+// HandshakeAuth contains the sign of the handshake by the node key
+type HandshakeAuth struct {
+	Sign []byte
+}
+
+func (a *HandshakeAuth) Read(r io.Reader) (err error) {
+	a.Sign, err = ReadSliceWithMaxSize(r, maxHandshakeSize)
+	return
+}
+
+func (a *HandshakeAuth) Write(w io.Writer) error {
+	return writeSlice(w, a.Sign)
+}
+
+// SecureConn is the connection which encrypts and authenticates the data with the session keys
+type SecureConn struct {
+	net.Conn
+	// PeerKey is the node key which the peer has proved, it is nil for the anonymous peer
+	PeerKey []byte
+	// Honor is true if PeerKey belongs to the honor node
+	Honor bool
+
+	reader, writer    cipher.AEAD
+	readSeq, writeSeq uint64
+	readBuf           []byte
+}
+
+func seqNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+// Write encrypts the data by records
+func (c *SecureConn) Write(b []byte) (int, error) {
+	var n int
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > maxRecordSize {
+			chunk = chunk[:maxRecordSize]
+		}
+		sealed := c.writer.Seal(nil, seqNonce(c.writer, c.writeSeq), chunk, nil)
+		c.writeSeq++
+		record := make([]byte, 4, 4+len(sealed))
+		binary.BigEndian.PutUint32(record, uint32(len(sealed)))
+		if _, err := c.Conn.Write(append(record, sealed...)); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		b = b[len(chunk):]
+	}
+	return n, nil
+}
+
+// Read decrypts the data of records
+func (c *SecureConn) Read(b []byte) (int, error) {
+	if len(c.readBuf) == 0 {
+		var size uint32
+		if err := binary.Read(c.Conn, binary.BigEndian, &size); err != nil {
+			return 0, err
+		}
+		if size > uint32(maxRecordSize+c.reader.Overhead()) {
+			return 0, ErrMaxSize
+		}
+		sealed := make([]byte, size)
+		if _, err := io.ReadFull(c.Conn, sealed); err != nil {
+			return 0, err
+		}
+		plain, err := c.reader.Open(nil, seqNonce(c.reader, c.readSeq), sealed, nil)
+		if err != nil {
+			return 0, ErrRecordAuth
+		}
+		c.readSeq++
+		c.readBuf = plain
+	}
+	n := copy(b, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+// handshake is the state of the handshake of one side
+type handshake struct {
+	conn       net.Conn
+	signer     signer.Signer
+	sessionKey []byte
+	local      HandshakeHello
+	remote     HandshakeHello
+}
+
+// newHandshake returns the state of the handshake, the side is anonymous if s is nil
+func newHandshake(conn net.Conn, s signer.Signer) (*handshake, error) {
+	sessionKey, x, y, err := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, handshakeNonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	h := &handshake{
+		conn:       conn,
+		signer:     s,
+		sessionKey: sessionKey,
+		local: HandshakeHello{
+			SessionKey: elliptic.Marshal(elliptic.P256(), x, y),
+			Nonce:      nonce,
+		},
+	}
+	if s != nil {
+		h.local.NodeKey = s.PublicKey()
+	}
+	return h, nil
+}
+
+// transcript returns the hash of both hello messages with the label of the side which signs it
+func (h *handshake) transcript(client, server *HandshakeHello, label string) []byte {
+	var buf bytes.Buffer
+	for _, hello := range []*HandshakeHello{client, server} {
+		hello.Write(&buf)
+	}
+	buf.WriteString(label)
+	hash := sha256.Sum256(buf.Bytes())
+	return hash[:]
+}
+
+func (h *handshake) sign(client, server *HandshakeHello, label string) (*HandshakeAuth, error) {
+	if h.signer == nil {
+		return &HandshakeAuth{}, nil
+	}
+	sign, err := h.signer.Sign(h.transcript(client, server, label))
+	if err != nil {
+		return nil, err
+	}
+	return &HandshakeAuth{Sign: sign}, nil
+}
+
+// verify checks the sign of the remote node, it returns the proved key or nil for the anonymous node
+func (h *handshake) verify(auth *HandshakeAuth, client, server *HandshakeHello, label string) ([]byte, error) {
+	if len(h.remote.NodeKey) == 0 && len(auth.Sign) == 0 {
+		return nil, nil
+	}
+	ok, err := crypto.CheckSign(h.remote.NodeKey, h.transcript(client, server, label), auth.Sign)
+	if err != nil || !ok {
+		return nil, ErrHandshakeSign
+	}
+	return h.remote.NodeKey, nil
+}
+
+// session derives the keys of both directions from the shared secret
+func (h *handshake) session(client, server *HandshakeHello, isClient bool) (*SecureConn, error) {
+	curve := elliptic.P256()
+	x, y := elliptic.Unmarshal(curve, h.remote.SessionKey)
+	if x == nil {
+		return nil, ErrHandshakeKey
+	}
+	shared, _ := curve.ScalarMult(x, y, h.sessionKey)
+
+	newAEAD := func(label string) (cipher.AEAD, error) {
+		key := sha256.Sum256(append(append(shared.Bytes(), h.transcript(client, server, "")...), label...))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	}
+	clientAEAD, err := newAEAD(clientLabel)
+	if err != nil {
+		return nil, err
+	}
+	serverAEAD, err := newAEAD(serverLabel)
+	if err != nil {
+		return nil, err
+	}
+	if isClient {
+		return &SecureConn{Conn: h.conn, reader: serverAEAD, writer: clientAEAD}, nil
+	}
+	return &SecureConn{Conn: h.conn, reader: clientAEAD, writer: serverAEAD}, nil
+}
+
+// clientHandshake starts the secure session, the server must prove the ownership of its node key
+func clientHandshake(conn net.Conn, s signer.Signer) (*SecureConn, error) {
+	h, err := newHandshake(conn, s)
+	if err != nil {
+		return nil, err
+	}
+	rt := &RequestType{Type: RequestTypeSecureSession}
+	if err = rt.Write(conn); err != nil {
+		return nil, err
+	}
+	if err = h.local.Write(conn); err != nil {
+		return nil, err
+	}
+	if err = h.remote.Read(conn); err != nil {
+		// the node of the previous version closes the connection on the unknown request type
+		return nil, fmt.Errorf("%w: %s", ErrSecureUnsupported, err)
+	}
+	auth := &HandshakeAuth{}
+	if err = auth.Read(conn); err != nil {
+		return nil, err
+	}
+	peerKey, err := h.verify(auth, &h.local, &h.remote, serverLabel)
+	if err != nil {
+		return nil, err
+	}
+	if peerKey == nil {
+		return nil, ErrHandshakeSign
+	}
+	if auth, err = h.sign(&h.local, &h.remote, clientLabel); err != nil {
+		return nil, err
+	}
+	if err = auth.Write(conn); err != nil {
+		return nil, err
+	}
+	sc, err := h.session(&h.local, &h.remote, true)
+	if err != nil {
+		return nil, err
+	}
+	sc.PeerKey = peerKey
+	return sc, nil
+}
+
+// serverHandshake accepts the secure session after RequestTypeSecureSession has been read.
+// The client may be anonymous, PeerKey of the session is nil in this case.
+func serverHandshake(conn net.Conn, s signer.Signer) (*SecureConn, error) {
+	h, err := newHandshake(conn, s)
+	if err != nil {
+		return nil, err
+	}
+	if err = h.remote.Read(conn); err != nil {
+		return nil, err
+	}
+	if err = h.local.Write(conn); err != nil {
+		return nil, err
+	}
+	auth, err := h.sign(&h.remote, &h.local, serverLabel)
+	if err != nil {
+		return nil, err
+	}
+	if err = auth.Write(conn); err != nil {
+		return nil, err
+	}
+	if err = auth.Read(conn); err != nil {
+		return nil, err
+	}
+	peerKey, err := h.verify(auth, &h.remote, &h.local, clientLabel)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := h.session(&h.remote, &h.local, false)
+	if err != nil {
+		return nil, err
+	}
+	sc.PeerKey = peerKey
+	return sc, nil
+}
+
+// ClientHandshake starts the secure session with the node at host.
+// The node must prove the ownership of the key listed for it in the honor nodes.
+func ClientHandshake(conn net.Conn, host string) (*SecureConn, error) {
+	sc, err := clientHandshake(conn, syspar.GetNodeSigner())
+	if err != nil {
+		return nil, err
+	}
+	if node, err := syspar.GetNodeByHost(host); err == nil {
+		sc.Honor = bytes.Equal(node.PublicKey, sc.PeerKey)
+	} else {
+		_, err = syspar.GetNodePositionByPublicKey(sc.PeerKey)
+		sc.Honor = err == nil
+	}
+	if !sc.Honor {
+		log.WithFields(log.Fields{"type": consts.AccessDenied, "host": host}).Error("node key doesn't match the honor nodes")
+		return nil, ErrPeerKey
+	}
+	return sc, nil
+}
+
+// ServerHandshake accepts the secure session, Honor of the session is true if the client
+// has proved the ownership of the honor node key
+func ServerHandshake(conn net.Conn) (*SecureConn, error) {
+	sc, err := serverHandshake(conn, syspar.GetNodeSigner())
+	if err != nil {
+		return nil, err
+	}
+	if sc.PeerKey != nil {
+		_, err = syspar.GetNodePositionByPublicKey(sc.PeerKey)
+		sc.Honor = err == nil
+	}
+	return sc, nil
+}
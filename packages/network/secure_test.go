@@ -0,0 +1,135 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package network
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/signer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type handshakeResult struct {
+	conn *SecureConn
+	err  error
+}
+
+// keySigner returns the signer of the key pair, it is nil for the empty keys of anonymous node
+func keySigner(t *testing.T, keys [2][]byte) signer.Signer {
+	if len(keys[0]) == 0 {
+		return nil
+	}
+	s, err := signer.NewLocalSigner(keys[0])
+	require.NoError(t, err)
+	return s
+}
+
+func testHandshake(t *testing.T, clientKeys, serverKeys [2][]byte) (client, server handshakeResult) {
+	clientSigner, serverSigner := keySigner(t, clientKeys), keySigner(t, serverKeys)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	ch := make(chan handshakeResult)
+	go func() {
+		serverConn, err := l.Accept()
+		if err != nil {
+			ch <- handshakeResult{err: err}
+			return
+		}
+		rt := &RequestType{}
+		if err = rt.Read(serverConn); err != nil {
+			ch <- handshakeResult{err: err}
+			return
+		}
+		assert.Equal(t, RequestTypeSecureSession, rt.Type)
+		conn, err := serverHandshake(serverConn, serverSigner)
+		if err != nil {
+			serverConn.Close()
+		}
+		ch <- handshakeResult{conn, err}
+	}()
+	clientConn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	conn, err := clientHandshake(clientConn, clientSigner)
+	if err != nil {
+		clientConn.Close()
+	}
+	return handshakeResult{conn, err}, <-ch
+}
+
+func TestSecureSession(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	var clientKeys, serverKeys [2][]byte
+	var err error
+	clientKeys[0], clientKeys[1], err = crypto.GenKeyPair()
+	require.NoError(t, err)
+	serverKeys[0], serverKeys[1], err = crypto.GenKeyPair()
+	require.NoError(t, err)
+
+	res, serverRes := testHandshake(t, clientKeys, serverKeys)
+	require.NoError(t, res.err)
+	require.NoError(t, serverRes.err)
+	client, server := res.conn, serverRes.conn
+	assert.Equal(t, serverKeys[1], client.PeerKey)
+	assert.Equal(t, clientKeys[1], server.PeerKey)
+
+	data := bytes.Repeat([]byte("block"), maxRecordSize/2)
+	go func() {
+		client.Write(data)
+		client.Write([]byte("end"))
+	}()
+	received := make([]byte, len(data)+3)
+	_, err = io.ReadFull(server, received)
+	require.NoError(t, err)
+	assert.Equal(t, append(data, "end"...), received)
+
+	go server.Write([]byte("answer"))
+	answer := make([]byte, 6)
+	_, err = io.ReadFull(client, answer)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("answer"), answer)
+
+	// the anonymous client gets the encrypted session without the proved key
+	res, serverRes = testHandshake(t, [2][]byte{}, serverKeys)
+	require.NoError(t, res.err)
+	require.NoError(t, serverRes.err)
+	assert.Nil(t, serverRes.conn.PeerKey)
+
+	// the server must prove its key
+	res, _ = testHandshake(t, clientKeys, [2][]byte{})
+	assert.Equal(t, ErrHandshakeSign, res.err)
+}
+
+func TestSecureRecordAuth(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	var keys [2][]byte
+	var err error
+	keys[0], keys[1], err = crypto.GenKeyPair()
+	require.NoError(t, err)
+
+	res, serverRes := testHandshake(t, keys, keys)
+	require.NoError(t, res.err)
+	require.NoError(t, serverRes.err)
+	client, server := res.conn, serverRes.conn
+
+	// the record which is sent outside of session can't be decrypted
+	go func() {
+		server.Write([]byte("data"))
+		client.Conn.Write([]byte{0, 0, 0, 20})
+		client.Conn.Write(bytes.Repeat([]byte{1}, 20))
+	}()
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(client, buf)
+	require.NoError(t, err)
+	_, err = server.Read(buf)
+	assert.Equal(t, ErrRecordAuth, err)
+}
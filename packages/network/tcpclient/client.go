@@ -9,9 +9,12 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -36,6 +39,67 @@ func newConnection(addr string) (net.Conn, error) {
 		return nil, err
 	}
 
+	conn, err := secureConnection(host)
+	if err != nil || isLegacyHost(host) {
+		return conn, err
+	}
+
+	fconn, err := network.ClientFraming(conn)
+	if err == nil {
+		return fconn, nil
+	}
+	conn.Close()
+	if !errors.Is(err, network.ErrFramedUnsupported) {
+		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "address": host}).Error("starting framed protocol")
+		return nil, err
+	}
+
+	// the node of previous version closes the connection on the framed protocol
+	log.WithFields(log.Fields{"type": consts.ConnectionError, "address": host}).Warn("node doesn't support framed protocol, using previous version")
+	legacyHosts.Store(host, time.Now())
+	return secureConnection(host)
+}
+
+// legacyHosts are the nodes which don't support the framed protocol,
+// they are asked again after legacyHostTimeout because the node can be updated
+var legacyHosts sync.Map
+
+const legacyHostTimeout = 10 * time.Minute
+
+func isLegacyHost(host string) bool {
+	v, ok := legacyHosts.Load(host)
+	if !ok {
+		return false
+	}
+	if time.Since(v.(time.Time)) > legacyHostTimeout {
+		legacyHosts.Delete(host)
+		return false
+	}
+	return true
+}
+
+func secureConnection(host string) (net.Conn, error) {
+	conn, err := dial(host)
+	if err != nil || !conf.Config.TCPTransport.Secure {
+		return conn, err
+	}
+
+	sconn, err := network.ClientHandshake(conn, host)
+	if err == nil {
+		return sconn, nil
+	}
+	conn.Close()
+	if conf.Config.TCPTransport.Require || !errors.Is(err, network.ErrSecureUnsupported) {
+		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "address": host}).Error("starting secure session")
+		return nil, err
+	}
+
+	// the node of previous version during the rollout of secure sessions
+	log.WithFields(log.Fields{"type": consts.ConnectionError, "address": host}).Warn("node doesn't support secure session, using plain connection")
+	return dial(host)
+}
+
+func dial(host string) (net.Conn, error) {
 	conn, err := net.DialTimeout("tcp", host, consts.TCPConnTimeout)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "address": host}).Debug("dialing tcp")
@@ -248,6 +248,8 @@ func sendDisseminatorRequest(con net.Conn, requestType network.ReqTypesFlag, pac
 		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing request type to host")
 		return err
 	}
+	// the transactions are compressed if the node supports it
+	network.Compress(con)
 
 	// data size
 	// size := converter.DecToBin(len(packet), 4)
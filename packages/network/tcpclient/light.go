@@ -0,0 +1,62 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GetHonorNodes requests the public keys of honor nodes which are known by the host
+func GetHonorNodes(host string) ([][]byte, error) {
+	con, err := newConnection(host)
+	if err != nil {
+		return nil, err
+	}
+	defer con.Close()
+
+	rt := &network.RequestType{Type: network.RequestTypeHonorNodes}
+	if err = rt.Write(con); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.ConnectionError, "host": host}).Error("on sending honor nodes request type")
+		return nil, err
+	}
+
+	resp := &network.HonorNodesResponse{}
+	if err = resp.Read(con); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.ConnectionError, "host": host}).Error("reading honor nodes from host")
+		return nil, err
+	}
+	return resp.Keys, nil
+}
+
+// GetRollbacksHash requests the rollbacks hash of block from the host
+func GetRollbacksHash(host string, blockID int64) ([]byte, error) {
+	con, err := newConnection(host)
+	if err != nil {
+		return nil, err
+	}
+	defer con.Close()
+
+	rt := &network.RequestType{Type: network.RequestTypeRollbacksHash}
+	if err = rt.Write(con); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.ConnectionError, "host": host}).Error("on sending rollbacks hash request type")
+		return nil, err
+	}
+
+	req := &network.RollbacksHashRequest{BlockID: blockID}
+	if err = req.Write(con); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.ConnectionError, "host": host}).Error("on sending rollbacks hash request")
+		return nil, err
+	}
+
+	resp := &network.RollbacksHashResponse{}
+	if err = resp.Read(con); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.ConnectionError, "host": host, "block_id": blockID}).Error("reading rollbacks hash from host")
+		return nil, err
+	}
+	return resp.Hash, nil
+}
@@ -8,9 +8,11 @@ import (
 	"context"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/peers"
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -21,7 +23,18 @@ func HostWithMaxBlock(ctx context.Context, hosts []string) (bestHost string, max
 		return "", -1, nil
 	}
 
-	return hostWithMaxBlock(ctx, hosts)
+	bestHost, maxBlockID, _, err = hostWithMaxBlock(ctx, hosts, 0)
+	return
+}
+
+// HostWithMaxBlockLimit returns the host with max block which isn't greater than limit.
+// The hosts which claim the greater blocks are returned as overclaimed.
+func HostWithMaxBlockLimit(ctx context.Context, hosts []string, limit int64) (bestHost string, maxBlockID int64, overclaimed []string, err error) {
+	if len(hosts) == 0 {
+		return "", -1, nil, nil
+	}
+
+	return hostWithMaxBlock(ctx, hosts, limit)
 }
 
 func GetMaxBlockID(host string) (blockID int64, err error) {
@@ -49,7 +62,8 @@ func getMaxBlock(host string) (blockID int64, err error) {
 	return resp.BlockID, nil
 }
 
-func hostWithMaxBlock(ctx context.Context, hosts []string) (bestHost string, maxBlockID int64, err error) {
+// hostWithMaxBlock asks the hosts for their max blocks, the limit of block is checked if it is greater than zero
+func hostWithMaxBlock(ctx context.Context, hosts []string, limit int64) (bestHost string, maxBlockID int64, overclaimed []string, err error) {
 	maxBlockID = -1
 
 	type blockAndHost struct {
@@ -69,15 +83,22 @@ func hostWithMaxBlock(ctx context.Context, hosts []string) (bestHost string, max
 	for _, h := range hosts {
 		if ctx.Err() != nil {
 			log.WithFields(log.Fields{"error": ctx.Err(), "type": consts.ContextError}).Error("context error")
-			return "", maxBlockID, ctx.Err()
+			return "", maxBlockID, nil, ctx.Err()
 		}
 
 		wg.Add(1)
 
 		go func(host string) {
+			start := time.Now()
 			blockID, err := getMaxBlock(host)
 			defer wg.Done()
 
+			if err != nil {
+				peers.GetManager().RecordFailure(host)
+			} else {
+				peers.GetManager().RecordLatency(host, time.Since(start))
+			}
+
 			resultChan <- blockAndHost{
 				host:    host,
 				blockID: blockID,
@@ -95,6 +116,10 @@ func hostWithMaxBlock(ctx context.Context, hosts []string) (bestHost string, max
 			errCount++
 			continue
 		}
+		if limit > 0 && bl.blockID > limit {
+			overclaimed = append(overclaimed, bl.host)
+			continue
+		}
 
 		// If blockID is maximal then the current host is the best
 		if bl.blockID > maxBlockID {
@@ -104,8 +129,8 @@ func hostWithMaxBlock(ctx context.Context, hosts []string) (bestHost string, max
 	}
 
 	if errCount == len(hosts) {
-		return "", 0, ErrNodesUnavailable
+		return "", 0, overclaimed, ErrNodesUnavailable
 	}
 
-	return bestHost, maxBlockID, nil
+	return bestHost, maxBlockID, overclaimed, nil
 }
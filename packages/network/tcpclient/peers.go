@@ -0,0 +1,59 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpclient
+
+import (
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/peers"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GetPeers requests the peers which are known by the host and announces this node if announce isn't empty.
+// The nodes of previous versions close the connection on the unknown request type,
+// so the request is sent only if the node supports it.
+func GetPeers(host, announce string) ([]string, error) {
+	start := time.Now()
+	con, err := newConnection(host)
+	if err != nil {
+		peers.GetManager().RecordFailure(host)
+		return nil, err
+	}
+	defer con.Close()
+
+	if _, ok := con.(*network.FrameConn); !ok {
+		return nil, network.ErrUnsupportedRequest
+	}
+
+	rt := &network.RequestType{Type: network.RequestTypeGetPeers}
+	if err = rt.Write(con); err != nil {
+		if err != network.ErrUnsupportedRequest {
+			log.WithFields(log.Fields{"error": err, "type": consts.ConnectionError, "host": host}).Error("on sending peers request type")
+		}
+		return nil, err
+	}
+
+	req := &network.PeersRequest{Host: announce}
+	if err = req.Write(con); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.ConnectionError, "host": host}).Error("on sending peers request")
+		return nil, err
+	}
+
+	resp := &network.PeersResponse{}
+	if err = resp.Read(con); err != nil {
+		log.WithFields(log.Fields{"error": err, "type": consts.ConnectionError, "host": host}).Error("reading peers from host")
+		peers.GetManager().RecordFailure(host)
+		return nil, err
+	}
+	peers.GetManager().RecordLatency(host, time.Since(start))
+
+	if len(resp.Hosts) > peers.MaxGossipHosts {
+		resp.Hosts = resp.Hosts[:peers.MaxGossipHosts]
+	}
+	return resp.Hosts, nil
+}
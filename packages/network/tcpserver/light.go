@@ -0,0 +1,45 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpserver
+
+import (
+	"errors"
+
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/network"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// errUnknownBlock is returned when the requested block isn't found
+var errUnknownBlock = errors.New("unknown block")
+
+// Type16 sends the public keys of honor nodes
+// lightHeaders daemon sends this request
+func Type16() (*network.HonorNodesResponse, error) {
+	nodes := syspar.GetNodes()
+	resp := &network.HonorNodesResponse{Keys: make([][]byte, 0, len(nodes))}
+	for _, node := range nodes {
+		resp.Keys = append(resp.Keys, node.PublicKey)
+	}
+	return resp, nil
+}
+
+// Type17 sends the rollbacks hash of block which is signed by the next block of version BvRollbackHash
+// lightHeaders daemon sends this request
+func Type17(req *network.RollbacksHashRequest) (*network.RollbacksHashResponse, error) {
+	block := &model.Block{}
+	found, err := block.Get(req.BlockID)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": req.BlockID}).Error("getting block")
+		return nil, err
+	}
+	if !found {
+		return nil, errUnknownBlock
+	}
+	return &network.RollbacksHashResponse{Hash: block.RollbacksHash}, nil
+}
@@ -0,0 +1,22 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tcpserver
+
+import (
+	"net"
+
+	"github.com/IBAX-io/go-ibax/packages/network"
+	"github.com/IBAX-io/go-ibax/packages/network/peers"
+)
+
+// Type15 sends the best known peers and adds the announced node to the peers
+// if the node has announced its own address. peersDiscovery daemon sends this request
+func Type15(req *network.PeersRequest, remote net.Addr) (*network.PeersResponse, error) {
+	pm := peers.GetManager()
+	if len(req.Host) > 0 && peers.Announced(req.Host, remote) {
+		pm.Add(req.Host)
+	}
+	return &network.PeersResponse{Hosts: pm.Hosts(peers.MaxGossipHosts)}, nil
+}
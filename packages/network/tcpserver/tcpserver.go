@@ -5,10 +5,12 @@
 package tcpserver
 
 import (
+	"fmt"
 	"net"
 	"strings"
 	"time"
 
+	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/network"
 	"github.com/IBAX-io/go-ibax/packages/service"
@@ -25,6 +27,40 @@ func HandleTCPRequest(rw net.Conn) {
 		return
 	}
 
+	var honor bool
+	if dType.Type == network.RequestTypeSecureSession {
+		sconn, err := network.ServerHandshake(rw)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err}).Error("accepting secure session")
+			return
+		}
+		rw, honor = sconn, sconn.Honor
+		if err = dType.Read(rw); err != nil {
+			log.Errorf("read request type failed: %s", err)
+			return
+		}
+	} else if conf.Config.TCPTransport.Require {
+		log.WithFields(log.Fields{"type": consts.AccessDenied, "request_type": dType.Type}).Warn("plain connection is rejected")
+		return
+	}
+	if dType.Type == network.RequestTypeFramed {
+		fconn, err := network.ServerFraming(rw)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err}).Error("accepting framed protocol")
+			return
+		}
+		rw = fconn
+		if err = dType.Read(rw); err != nil {
+			log.Errorf("read request type failed: %s", err)
+			return
+		}
+	}
+	if conf.Config.TCPTransport.Require && dType.Type.RequiresHonorNode() && !honor {
+		log.WithFields(log.Fields{"type": consts.AccessDenied, "request_type": dType.Type}).Warn("request of not honor node is rejected")
+		network.SendError(rw, network.ErrCodeAccessDenied, "request requires honor node")
+		return
+	}
+
 	log.WithFields(log.Fields{"request_type": dType.Type}).Debug("tcpserver got request type")
 	var response interface{}
 
@@ -97,9 +133,33 @@ func HandleTCPRequest(rw net.Conn) {
 		if err = req.Read(rw); err == nil {
 			response, err = Type99(req)
 		}
+
+	case network.RequestTypeGetPeers:
+		req := &network.PeersRequest{}
+		if err = req.Read(rw); err == nil {
+			response, err = Type15(req, rw.RemoteAddr())
+		}
+
+	case network.RequestTypeHonorNodes:
+		response, err = Type16()
+
+	case network.RequestTypeRollbacksHash:
+		req := &network.RollbacksHashRequest{}
+		if err = req.Read(rw); err == nil {
+			response, err = Type17(req)
+		}
+
+	default:
+		log.WithFields(log.Fields{"type": consts.UnknownObject, "request_type": dType.Type}).Warn("unknown request type")
+		network.SendError(rw, network.ErrCodeUnsupportedRequest, fmt.Sprintf("unknown request type %d", dType.Type))
+		return
 	}
 
-	if err != nil || response == nil {
+	if err != nil {
+		network.SendError(rw, network.ErrCodeBadRequest, err.Error())
+		return
+	}
+	if response == nil {
 		return
 	}
 
@@ -4,6 +4,8 @@
  *--------------------------------------------------------------------------------------------*/
 package tcpserver
 	block := &model.Block{}
+	// the bodies of blocks are compressed if the node supports it
+	network.Compress(w)
 
 	var blocks []model.Block
 	var err error
@@ -3,13 +3,16 @@
 	"github.com/IBAX-io/go-ibax/packages/crypto/ecies"
 	"github.com/IBAX-io/go-ibax/packages/model"
 	"github.com/IBAX-io/go-ibax/packages/network"
-	"github.com/IBAX-io/go-ibax/packages/utils"
 
 	log "github.com/sirupsen/logrus"
 )
 
 func Type88(r *network.PrivateDateRequest) (*network.PrivateDateResponse, error) {
-	node_pri := syspar.GetNodePrivKey()
+	node_pri, err := syspar.GetNodeDecryptKey()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("getting node key to decrypt private data")
+		return nil, err
+	}
 	data, err := ecies.EccDeCrypto(r.Data, node_pri)
 	if err != nil {
 		log.WithError(err)
@@ -25,13 +28,7 @@ func Type88(r *network.PrivateDateRequest) (*network.PrivateDateResponse, error)
 	resp.Hash = hash
 
 	//
-	NodePrivateKey, NodePublicKey := utils.GetNodeKeys()
-	if len(NodePrivateKey) < 1 {
-		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node private key is empty")
-		err = errors.New(`empty node private key`)
-		return nil, err
-	}
-	eccData, err := ecies.EccCryptoKey(data, NodePublicKey)
+	eccData, err := ecies.EccCryptoKey(data, crypto.PubToHex(syspar.GetNodePubKey()))
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("EccCryptoKey error")
 		return nil, err
@@ -3,6 +3,7 @@
 
 import (
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
 	"github.com/IBAX-io/go-ibax/packages/crypto/ecies"
 	"github.com/IBAX-io/go-ibax/packages/model"
@@ -12,7 +13,11 @@ import (
 )
 
 func Type99(r *network.PrivateFileRequest) (*network.PrivateFileResponse, error) {
-	node_pri := syspar.GetNodePrivKey()
+	node_pri, err := syspar.GetNodeDecryptKey()
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("getting node key to decrypt private file")
+		return nil, err
+	}
 
 	data, err := ecies.EccDeCrypto(r.Data, node_pri)
 	if err != nil {
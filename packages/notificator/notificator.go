@@ -11,6 +11,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/IBAX-io/go-ibax/packages/model"
 	"github.com/IBAX-io/go-ibax/packages/publisher"
+	"github.com/IBAX-io/go-ibax/packages/pubsub"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -29,7 +30,7 @@ func UpdateNotifications(ecosystemID int64, accounts []string) {
 	}
 
 	for account, n := range notificationsStats {
-		sendUserStats(account, *n)
+		sendUserStats(ecosystemID, account, *n)
 	}
 }
 
@@ -65,12 +66,14 @@ func parseRecipientNotification(rows []model.NotificationsCount, systemID int64)
 	return recipientNotifications
 }
 
-func sendUserStats(account string, stats []notificationRecord) {
+func sendUserStats(ecosystemID int64, account string, stats []notificationRecord) {
 	rawStats, err := json.Marshal(stats)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.JSONMarshallError, "error": err}).Error("notification statistic")
 	}
 
+	pubsub.Publish(pubsub.NewNotification(ecosystemID, account, rawStats))
+
 	err = publisher.Write(account, string(rawStats))
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Debug("writing to centrifugo")
@@ -0,0 +1,101 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package pubsub
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+// BlockEvent is the data of newBlock event
+type BlockEvent struct {
+	BlockID      int64  `json:"block_id"`
+	Hash         string `json:"hash"`
+	Time         int64  `json:"time"`
+	EcosystemID  int64  `json:"ecosystem_id"`
+	KeyID        int64  `json:"key_id"`
+	NodePosition int64  `json:"node_position"`
+	TxCount      int    `json:"tx_count"`
+}
+
+// TxStatusEvent is the data of txStatus event
+type TxStatusEvent struct {
+	Hash    string `json:"hash"`
+	BlockID int64  `json:"block_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TableChangedEvent is the data of tableChanged event
+type TableChangedEvent struct {
+	Ecosystem int64    `json:"ecosystem"`
+	Table     string   `json:"table"`
+	BlockID   int64    `json:"block_id"`
+	TxHash    string   `json:"tx_hash"`
+	RowIDs    []string `json:"row_ids"`
+}
+
+// NotificationEvent is the data of notifications event
+type NotificationEvent struct {
+	Ecosystem int64           `json:"ecosystem"`
+	Account   string          `json:"account"`
+	Stats     json.RawMessage `json:"stats"`
+}
+
+// NewBlock returns newBlock event
+func NewBlock(data *BlockEvent) *Event {
+	return &Event{
+		Topic: TopicNewBlock,
+		Data:  data,
+		Keys: Filter{
+			"ecosystem": strconv.FormatInt(data.EcosystemID, 10),
+		},
+	}
+}
+
+// NewTxStatus returns txStatus event
+func NewTxStatus(hash []byte, blockID int64, errText string) *Event {
+	data := &TxStatusEvent{
+		Hash:    hex.EncodeToString(hash),
+		BlockID: blockID,
+		Error:   errText,
+	}
+	return &Event{
+		Topic: TopicTxStatus,
+		Data:  data,
+		Keys: Filter{
+			"hash": data.Hash,
+		},
+	}
+}
+
+// NewTableChanged returns tableChanged event
+func NewTableChanged(data *TableChangedEvent) *Event {
+	return &Event{
+		Topic: TopicTableChanged,
+		Data:  data,
+		Keys: Filter{
+			"ecosystem": strconv.FormatInt(data.Ecosystem, 10),
+			"table":     data.Table,
+		},
+	}
+}
+
+// NewNotification returns notifications event
+func NewNotification(ecosystem int64, account string, stats []byte) *Event {
+	return &Event{
+		Topic: TopicNotifications,
+		Data: &NotificationEvent{
+			Ecosystem: ecosystem,
+			Account:   account,
+			Stats:     json.RawMessage(stats),
+		},
+		Keys: Filter{
+			"ecosystem": strconv.FormatInt(ecosystem, 10),
+			"account":   account,
+		},
+	}
+}
@@ -0,0 +1,161 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package pubsub
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Topics of the events which are published by the node
+const (
+	TopicNewBlock      = "newBlock"
+	TopicTxStatus      = "txStatus"
+	TopicTableChanged  = "tableChanged"
+	TopicNotifications = "notifications"
+)
+
+// DefaultBufferSize is the default count of events that can be queued for the subscriber
+const DefaultBufferSize = 256
+
+// Filter is the set of the event keys which must match for the delivery of the event
+type Filter map[string]string
+
+// Event is the published event
+type Event struct {
+	Topic string      `json:"topic"`
+	Data  interface{} `json:"data"`
+	Keys  Filter      `json:"-"`
+}
+
+func (f Filter) match(e *Event) bool {
+	for k, v := range f {
+		if len(v) == 0 {
+			continue
+		}
+		if e.Keys[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Subscription is receiving events of the topic which match the filter
+type Subscription struct {
+	ID      uint64
+	Topic   string
+	Filter  Filter
+	C       chan *Event
+	hub     *Hub
+	dropped uint64
+	once    sync.Once
+}
+
+// Unsubscribe removes the subscription from the hub and closes its channel
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+		close(s.C)
+	})
+}
+
+// Dropped returns the count of events that were dropped since the previous call
+// because the subscriber didn't read them in time
+func (s *Subscription) Dropped() uint64 {
+	return atomic.SwapUint64(&s.dropped, 0)
+}
+
+// Hub delivers published events to subscribers
+type Hub struct {
+	mu     sync.RWMutex
+	lastID uint64
+	topics map[string]map[uint64]*Subscription
+}
+
+// NewHub creates a new hub
+func NewHub() *Hub {
+	return &Hub{topics: make(map[string]map[uint64]*Subscription)}
+}
+
+// Subscribe adds a new subscription, size is the capacity of the subscription queue
+func (h *Hub) Subscribe(topic string, filter Filter, size int) *Subscription {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	s := &Subscription{
+		ID:     h.lastID,
+		Topic:  topic,
+		Filter: filter,
+		C:      make(chan *Event, size),
+		hub:    h,
+	}
+	subs, ok := h.topics[topic]
+	if !ok {
+		subs = make(map[uint64]*Subscription)
+		h.topics[topic] = subs
+	}
+	subs[s.ID] = s
+	return s
+}
+
+func (h *Hub) remove(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if subs, ok := h.topics[s.Topic]; ok {
+		delete(subs, s.ID)
+		if len(subs) == 0 {
+			delete(h.topics, s.Topic)
+		}
+	}
+}
+
+// HasSubscribers returns true if there are subscribers of the topic
+func (h *Hub) HasSubscribers(topic string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.topics[topic]) > 0
+}
+
+// Publish sends the event to all matching subscribers. It never blocks,
+// if the queue of the subscriber is full then the event is dropped for it
+func (h *Hub) Publish(e *Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, s := range h.topics[e.Topic] {
+		if !s.Filter.match(e) {
+			continue
+		}
+		select {
+		case s.C <- e:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
+
+var defaultHub = NewHub()
+
+// Subscribe adds a subscription to the default hub
+func Subscribe(topic string, filter Filter, size int) *Subscription {
+	return defaultHub.Subscribe(topic, filter, size)
+}
+
+// HasSubscribers checks subscribers of the topic in the default hub
+func HasSubscribers(topic string) bool {
+	return defaultHub.HasSubscribers(topic)
+}
+
+// Publish publishes the event to the default hub
+func Publish(e *Event) {
+	defaultHub.Publish(e)
+}
@@ -0,0 +1,55 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	h := NewHub()
+	all := h.Subscribe(TopicTableChanged, nil, 10)
+	keys := h.Subscribe(TopicTableChanged, Filter{"ecosystem": "1", "table": "keys"}, 10)
+	other := h.Subscribe(TopicTableChanged, Filter{"ecosystem": "2"}, 10)
+
+	h.Publish(NewTableChanged(&TableChangedEvent{Ecosystem: 1, Table: "keys"}))
+	h.Publish(NewTableChanged(&TableChangedEvent{Ecosystem: 1, Table: "members"}))
+
+	assert.Len(t, all.C, 2)
+	assert.Len(t, keys.C, 1)
+	assert.Len(t, other.C, 0)
+}
+
+func TestBackpressure(t *testing.T) {
+	h := NewHub()
+	s := h.Subscribe(TopicNewBlock, nil, 2)
+
+	for i := int64(1); i <= 5; i++ {
+		h.Publish(NewBlock(&BlockEvent{BlockID: i}))
+	}
+	assert.Len(t, s.C, 2)
+	assert.Equal(t, uint64(3), s.Dropped())
+	assert.Equal(t, uint64(0), s.Dropped())
+
+	e := <-s.C
+	assert.Equal(t, int64(1), e.Data.(*BlockEvent).BlockID)
+}
+
+func TestUnsubscribe(t *testing.T) {
+	h := NewHub()
+	s := h.Subscribe(TopicTxStatus, nil, 1)
+	assert.True(t, h.HasSubscribers(TopicTxStatus))
+
+	s.Unsubscribe()
+	s.Unsubscribe()
+	assert.False(t, h.HasSubscribers(TopicTxStatus))
+
+	h.Publish(NewTxStatus([]byte{1}, 1, ""))
+	_, ok := <-s.C
+	assert.False(t, ok)
+}
@@ -56,6 +56,7 @@ func RollbackBlock(data []byte) error {
 	ib := &model.InfoBlock{
 		Hash:           b.Hash,
 		RollbacksHash:  b.RollbacksHash,
+		StateRoot:      b.StateRoot,
 		BlockID:        b.ID,
 		NodePosition:   strconv.Itoa(int(b.NodePosition)),
 		KeyID:          b.KeyID,
@@ -72,54 +73,77 @@ func RollbackBlock(data []byte) error {
 }
 
 func rollbackBlock(dbTransaction *model.DbTransaction, block *block.Block) error {
-	// rollback transactions in reverse order
-	logger := block.GetLogger()
-	for i := len(block.Transactions) - 1; i >= 0; i-- {
-		t := block.Transactions[i]
-		t.DbTransaction = dbTransaction
-
-		_, err := model.MarkTransactionUnusedAndUnverified(dbTransaction, t.TxHash)
+	// the changed rows are required to return the state hashes after the rollback
+	var rts []*model.RollbackTx
+	for _, t := range block.Transactions {
+		list, err := (&model.RollbackTx{}).GetRollbackTransactions(dbTransaction, t.TxHash)
 		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
 			return err
 		}
-		_, err = model.DeleteLogTransactionsByHash(dbTransaction, t.TxHash)
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting log transactions by hash")
-			return err
+		for _, item := range list {
+			rts = append(rts, &model.RollbackTx{NameTable: item["table_name"], TableID: item["table_id"], Data: item["data"]})
 		}
+	}
 
-		ts := &model.TransactionStatus{}
-		err = ts.UpdateBlockID(dbTransaction, 0, t.TxHash)
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating block id in transaction status")
+	// rollback transactions in reverse order
+	logger := block.GetLogger()
+	for i := len(block.Transactions) - 1; i >= 0; i-- {
+		if err := rollbackTx(dbTransaction, block.Transactions[i], logger); err != nil {
 			return err
 		}
+	}
 
-		_, err = model.DeleteQueueTxByHash(dbTransaction, t.TxHash)
-		if err != nil {
-			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting transacion from queue by hash")
+	if err := block.RollbackState(dbTransaction, rts); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("rolling back state hashes")
+		return err
+	}
+	return nil
+}
+
+func rollbackTx(dbTransaction *model.DbTransaction, t *transaction.Transaction, logger *log.Entry) error {
+	t.DbTransaction = dbTransaction
+
+	_, err := model.MarkTransactionUnusedAndUnverified(dbTransaction, t.TxHash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return err
+	}
+	_, err = model.DeleteLogTransactionsByHash(dbTransaction, t.TxHash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting log transactions by hash")
+		return err
+	}
+
+	ts := &model.TransactionStatus{}
+	err = ts.UpdateBlockID(dbTransaction, 0, t.TxHash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating block id in transaction status")
+		return err
+	}
+
+	_, err = model.DeleteQueueTxByHash(dbTransaction, t.TxHash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting transacion from queue by hash")
+		return err
+	}
+
+	if t.TxContract != nil {
+		if err = rollbackTransaction(t.TxHash, t.DbTransaction, logger); err != nil {
 			return err
 		}
-
-		if t.TxContract != nil {
-			if err = rollbackTransaction(t.TxHash, t.DbTransaction, logger); err != nil {
-				return err
-			}
-		} else {
-			MethodName := consts.TxTypes[t.TxType]
-			txParser, err := transaction.GetTransaction(t, MethodName)
-			if err != nil {
-				return utils.ErrInfo(err)
-			}
-			result := txParser.Init()
-			if _, ok := result.(error); ok {
-				return utils.ErrInfo(result.(error))
-			}
-			result = txParser.Rollback()
-			if _, ok := result.(error); ok {
-				return utils.ErrInfo(result.(error))
-			}
+	} else {
+		MethodName := consts.TxTypes[t.TxType]
+		txParser, err := transaction.GetTransaction(t, MethodName)
+		if err != nil {
+			return utils.ErrInfo(err)
+		}
+		result := txParser.Init()
+		if _, ok := result.(error); ok {
+			return utils.ErrInfo(result.(error))
+		}
+		result = txParser.Rollback()
+		if _, ok := result.(error); ok {
+			return utils.ErrInfo(result.(error))
 		}
 	}
 
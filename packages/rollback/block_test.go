@@ -0,0 +1,139 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package rollback
+
+import (
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/smarttest"
+	"github.com/IBAX-io/go-ibax/packages/transaction"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rememberContract inserts the row with the JSON column, updates the row and selects the JSON field
+const rememberContract = `contract RememberNote {
+    data {
+        Note string
+    }
+    action {
+        DBInsert("app_params", {"app_id": 1, "name": $Note, "value": "first", "conditions": "true",
+            "permissions->update": "true"})
+        var row map
+        row = DBFind("parameters").Where({"name": "changing_page"}).Row()
+        DBUpdate("parameters", Int(row["id"]), {"value": $Note})
+        row = DBFind("app_params").Columns("permissions->update").
+            Where({"name": $Note, "permissions->update": "true"}).Row()
+        $result = row["permissions.update"]
+    }
+}`
+
+// transferContract emits the events, the name of the second event is wrong
+const transferContract = `contract NotifyTransfer {
+    data {
+        Amount int
+        Event string "optional"
+    }
+    action {
+        EmitEvent("transfer", {"amount": $Amount})
+        if $Event {
+            EmitEvent($Event, {})
+        }
+    }
+}`
+
+func init() {
+	crypto.InitHash("SHA256")
+	crypto.InitCurve("ECDSA")
+}
+
+func TestRollbackBlockSQLite(t *testing.T) {
+	chain, err := smarttest.NewChain()
+	require.NoError(t, err)
+	defer chain.Close()
+	require.NoError(t, chain.Deploy(rememberContract))
+	require.NoError(t, chain.Checkpoint())
+
+	dbTx := chain.DbTransaction()
+	param := func() map[string]string {
+		row, err := model.GetOneRowTransaction(dbTx, `SELECT value FROM "1_parameters" WHERE name = ? AND ecosystem = ?`,
+			`changing_page`, smarttest.EcosystemID).String()
+		require.NoError(t, err)
+		return row
+	}
+	appParams := func() int64 {
+		var count int64
+		require.NoError(t, model.GetDB(dbTx).Table(`1_app_params`).Where(`name = ?`, `note`).Count(&count).Error)
+		return count
+	}
+	before := param()
+
+	res, err := chain.Call(chain.Founder(), `RememberNote`, map[string]interface{}{"Note": "note"})
+	require.NoError(t, err)
+	assert.Equal(t, `true`, res.Result)
+	assert.Equal(t, `note`, param()["value"])
+	assert.Equal(t, int64(1), appParams())
+
+	b := &block.Block{
+		Header: utils.BlockData{BlockID: res.BlockID, Version: consts.BlockVersion},
+		Transactions: []*transaction.Transaction{{
+			TxHash:     res.Hash,
+			TxContract: smart.GetContract(`RememberNote`, smarttest.EcosystemID),
+		}},
+	}
+	require.NoError(t, rollbackBlock(dbTx, b))
+	assert.Equal(t, before, param())
+	assert.Equal(t, int64(0), appParams())
+}
+
+func TestRollbackEvents(t *testing.T) {
+	chain, err := smarttest.NewChain()
+	require.NoError(t, err)
+	defer chain.Close()
+	require.NoError(t, chain.Deploy(transferContract))
+	require.NoError(t, chain.Checkpoint())
+
+	dbTx := chain.DbTransaction()
+	events := func(hash []byte) []model.ContractEvent {
+		var list []model.ContractEvent
+		require.NoError(t, model.GetDB(dbTx).Where(`tx_hash = ?`, hash).Order(`id`).Find(&list).Error)
+		return list
+	}
+
+	_, err = chain.Call(42, `NotifyTransfer`, map[string]interface{}{"Amount": int64(10), "Event": "bad name"})
+	assert.Error(t, err)
+	var count int64
+	require.NoError(t, model.GetDB(dbTx).Model(&model.ContractEvent{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	res, err := chain.Call(42, `NotifyTransfer`, map[string]interface{}{"Amount": int64(10)})
+	require.NoError(t, err)
+	list := events(res.Hash)
+	require.Len(t, list, 1)
+	assert.Equal(t, res.BlockID, list[0].BlockID)
+	assert.Equal(t, int64(smarttest.EcosystemID), list[0].Ecosystem)
+	assert.Equal(t, `@1NotifyTransfer`, list[0].Contract)
+	assert.Equal(t, `transfer`, list[0].Name)
+	assert.JSONEq(t, `{"amount":10}`, list[0].Data)
+	assert.Equal(t, chain.Time().Unix(), list[0].Time)
+
+	b := &block.Block{
+		Header: utils.BlockData{BlockID: res.BlockID, Version: consts.BlockVersion},
+		Transactions: []*transaction.Transaction{{
+			TxHash:     res.Hash,
+			TxContract: smart.GetContract(`NotifyTransfer`, smarttest.EcosystemID),
+		}},
+	}
+	require.NoError(t, rollbackBlock(dbTx, b))
+	assert.Empty(t, events(res.Hash))
+}
@@ -0,0 +1,126 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package rollback
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/notificator"
+	"github.com/IBAX-io/go-ibax/packages/script"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxReplayBlocks is the max count of blocks which can be rolled back for the replay of transaction
+const MaxReplayBlocks = 1000
+
+var (
+	ErrReplayTxNotFound = errors.New("transaction has not been found in the blockchain")
+	ErrReplayDepth      = errors.New("transaction is too old for the replay")
+)
+
+// StateAtBlock rolls back the blocks after blockID in the database transaction and returns the block blockID.
+// The transaction must be rolled back by the caller, at most MaxReplayBlocks blocks can be rolled back.
+func StateAtBlock(dbTransaction *model.DbTransaction, blockID int64, logger *log.Entry) (*block.Block, error) {
+	b := &model.Block{}
+	blocks, err := b.GetBlocksFrom(blockID-1, "desc", MaxReplayBlocks+1)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting blocks for replay")
+		return nil, err
+	}
+	if len(blocks) == 0 || blocks[len(blocks)-1].ID != blockID {
+		return nil, ErrReplayDepth
+	}
+
+	// blocks are in descending order, so the block blockID is the last one
+	var bl *block.Block
+	for _, item := range blocks {
+		bl, err = block.UnmarshallBlock(bytes.NewBuffer(item.Data), true)
+		if err != nil {
+			return nil, err
+		}
+		if item.ID != blockID {
+			if err = rollbackBlock(dbTransaction, bl); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return bl, nil
+}
+
+// TraceTx replays the transaction against the state at its block and returns the trace of the execution.
+// The blocks after the transaction are rolled back in the database transaction which is never committed,
+// the contracts of the virtual machine and the system parameters are restored after the replay.
+func TraceTx(hash []byte, maxSteps int, logger *log.Entry) (*script.Trace, error) {
+	ltx := &model.LogTransaction{}
+	found, err := ltx.GetByHash(hash)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting log transaction by hash")
+		return nil, err
+	}
+	if !found {
+		return nil, ErrReplayTxNotFound
+	}
+
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return nil, err
+	}
+	defer dbTransaction.Rollback()
+
+	smart.SavepointSmartVMObjects()
+	defer smart.RollbackSmartVMObjects()
+
+	bl, err := StateAtBlock(dbTransaction, ltx.Block, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	blockLogger := bl.GetLogger()
+	for i := len(bl.Transactions) - 1; i >= 0; i-- {
+		tx := bl.Transactions[i]
+		if err = rollbackTx(dbTransaction, tx, blockLogger); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(tx.TxHash, hash) {
+			continue
+		}
+
+		if bl.Header.BlockID > 1 {
+			if tx.PrevBlock, err = block.GetBlockDataFromBlockChain(bl.Header.BlockID - 1); err != nil {
+				return nil, err
+			}
+		}
+		// the system parameters of the replayed state are loaded and the current ones are restored after the replay
+		defer func() {
+			if err := syspar.SysUpdate(nil); err != nil {
+				logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("restoring system parameters after replay")
+			}
+		}()
+		if err = syspar.SysUpdate(dbTransaction); err != nil {
+			return nil, err
+		}
+		tx.Rand = utils.NewRand(bl.Header.Time).BytesSeed(tx.TxHash)
+		tx.Notifications = notificator.NewQueue()
+		tx.Trace = script.NewTrace(maxSteps)
+		if err = dbTransaction.Savepoint(consts.SetSavePointMarkBlock(i)); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("using savepoint")
+			return nil, err
+		}
+		if _, _, err = tx.Play(i); err != nil && len(tx.Trace.Error) == 0 {
+			tx.Trace.Error = err.Error()
+		}
+		return tx.Trace, nil
+	}
+	return nil, ErrReplayTxNotFound
+}
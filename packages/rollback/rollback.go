@@ -54,6 +54,7 @@ func ToBlockID(blockID int64, dbTransaction *model.DbTransaction, logger *log.En
 		NodePosition:   converter.Int64ToStr(header.NodePosition),
 		CurrentVersion: strconv.Itoa(header.Version),
 		RollbacksHash:  block.RollbacksHash,
+		StateRoot:      block.StateRoot,
 	}
 
 	err = ib.Update(dbTransaction)
@@ -65,7 +65,8 @@ func rollbackTransaction(txHash []byte, dbTransaction *model.DbTransaction, logg
 			case "NewContract":
 				err = smart.SysRollbackNewContract(sysData, tx["table_id"])
 			case "EditContract":
-				err = smart.SysRollbackEditContract(dbTransaction, sysData, tx["table_id"])
+				err = smart.SysRollbackEditContract(dbTransaction, sysData, tx["table_id"],
+					converter.StrToInt64(tx["block_id"]))
 			case "NewEcosystem":
 				err = smart.SysRollbackEcosystem(dbTransaction, sysData)
 			case "ActivateContract":
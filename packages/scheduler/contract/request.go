@@ -15,10 +15,10 @@ import (
 	"strings"
 
 	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
-	"github.com/IBAX-io/go-ibax/packages/utils"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -45,17 +45,28 @@ type contractResult struct {
 // NodeContract creates a transaction to execute the contract.
 // The transaction is signed with a node key.
 func NodeContract(Name string) (result contractResult, err error) {
-	if len(NodePrivateKey) == 0 {
-		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node private key is empty")
+	var (
+		ret  authResult
+		auth string
+		sign []byte
+	)
+	nodeSigner := syspar.GetNodeSigner()
+	if nodeSigner == nil {
+		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("node signer is empty")
 		err = errors.New(`empty node private key`)
 		return
 	}
-	sign, err = crypto.SignString(NodePrivateKey, ret.UID)
+	err = sendAPIRequest(`GET`, `getuid`, nil, &ret, ``)
+	if err != nil {
+		return
+	}
+	auth = ret.Token
+	sign, err = nodeSigner.Sign([]byte(ret.UID))
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing node uid")
 		return
 	}
-	form := url.Values{"pubkey": {NodePublicKey}, "signature": {hex.EncodeToString(sign)},
+	form := url.Values{"pubkey": {crypto.PubToHex(nodeSigner.PublicKey())}, "signature": {hex.EncodeToString(sign)},
 		`ecosystem`: {converter.Int64ToStr(1)}}
 	var logret authResult
 	err = sendAPIRequest(`POST`, `login`, &form, &logret, auth)
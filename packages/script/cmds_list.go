@@ -15,6 +15,12 @@ package script
 	cmdMapInit               // map initialization
 	cmdArrayInit             // array initialization
 	cmdError                 // error command
+	cmdFor                   // for in
+	cmdSwitch                // switch
+	cmdCase                  // case of switch
+	cmdDefault               // default of switch
+	cmdTry                   // try
+	cmdCatch                 // catch of try
 )
 
 // the commands for operations in expressions are listed below
@@ -36,6 +42,12 @@ const (
 	cmdNotLess
 	cmdGreat
 	cmdNotGreat
+	cmdMod
+	cmdBitAnd
+	cmdBitOr
+	cmdBitXor
+	cmdShiftL
+	cmdShiftR
 
 	cmdSys          = 0xff
 	cmdUnary uint16 = 50
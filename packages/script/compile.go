@@ -97,6 +97,9 @@ const (
 	stateConstsAssign
 	stateConstsValue
 	stateFields
+	stateFor
+	stateCase
+	stateCatch
 	stateEval
 
 	// The list of state flags
@@ -158,6 +161,13 @@ const (
 	cfContinue
 	cfBreak
 	cfCmdError
+	cfForVar
+	cfFor
+	cfSwitch
+	cfCase
+	cfDefault
+	cfTry
+	cfCatch
 
 //	cfEval
 )
@@ -167,8 +177,10 @@ var (
 	opers = map[uint32]operPrior{
 		isOr: {cmdOr, 10}, isAnd: {cmdAnd, 15}, isEqEq: {cmdEqual, 20}, isNotEq: {cmdNotEq, 20},
 		isLess: {cmdLess, 22}, isGrEq: {cmdNotLess, 22}, isGreat: {cmdGreat, 22}, isLessEq: {cmdNotGreat, 22},
-		isPlus: {cmdAdd, 25}, isMinus: {cmdSub, 25}, isAsterisk: {cmdMul, 30},
-		isSolidus: {cmdDiv, 30}, isSign: {cmdSign, cmdUnary}, isNot: {cmdNot, cmdUnary}, isLPar: {cmdSys, 0xff}, isRPar: {cmdSys, 0},
+		isPlus: {cmdAdd, 25}, isMinus: {cmdSub, 25}, isPipe: {cmdBitOr, 25}, isCaret: {cmdBitXor, 25},
+		isAsterisk: {cmdMul, 30}, isSolidus: {cmdDiv, 30}, isPercent: {cmdMod, 30}, isAmp: {cmdBitAnd, 30},
+		isShiftL: {cmdShiftL, 30}, isShiftR: {cmdShiftR, 30},
+		isSign: {cmdSign, cmdUnary}, isNot: {cmdNot, cmdUnary}, isLPar: {cmdSys, 0xff}, isRPar: {cmdSys, 0},
 	}
 	// The array of functions corresponding to the constants cf...
 	funcs = []compileFunc{nil,
@@ -198,6 +210,13 @@ var (
 		fContinue,
 		fBreak,
 		fCmdError,
+		fForVar,
+		fFor,
+		fSwitch,
+		fCase,
+		fDefault,
+		fTry,
+		fCatch,
 	}
 
 	// 'states' describes a finite machine with states on the base of which a bytecode will be generated
@@ -217,6 +236,12 @@ var (
 			lexKeyword | (keyIf << 8):       {stateEval | statePush | stateToBlock | stateMustEval, cfIf},
 			lexKeyword | (keyWhile << 8):    {stateEval | statePush | stateToBlock | stateLabel | stateMustEval, cfWhile},
 			lexKeyword | (keyElse << 8):     {stateBlock | statePush, cfElse},
+			lexKeyword | (keyFor << 8):      {stateFor | statePush, 0},
+			lexKeyword | (keySwitch << 8):   {stateEval | stateMustEval, cfSwitch},
+			lexKeyword | (keyCase << 8):     {stateCase | statePush, 0},
+			lexKeyword | (keyDefault << 8):  {stateBlock | statePush, cfDefault},
+			lexKeyword | (keyTry << 8):      {stateBlock | statePush, cfTry},
+			lexKeyword | (keyCatch << 8):    {stateCatch | statePush, 0},
 			lexKeyword | (keyVar << 8):      {stateVar, 0},
 			lexKeyword | (keyTX << 8):       {stateTX, cfTX},
 			lexKeyword | (keySettings << 8): {stateSettings, cfSettings},
@@ -341,6 +366,20 @@ var (
 			isRCurly:   {stateToBody, cfFields},
 			0:          {errMustRCurly, cfError},
 		},
+		{ // stateFor
+			lexIdent:                  {stateFor, cfForVar},
+			isComma:                   {stateFor, 0},
+			lexKeyword | (keyIn << 8): {stateEval | stateToBlock | stateMustEval, cfFor},
+			0:                         {errVars, cfError},
+		},
+		{ // stateCase
+			0: {stateEval | stateToBlock | stateMustEval, cfCase},
+		},
+		{ // stateCatch
+			lexIdent: {stateBlock, cfCatch},
+			isLCurly: {stateBody, cfCatch},
+			0:        {errMustLCurly, cfError},
+		},
 	}
 )
 
@@ -515,6 +554,144 @@ func fWhile(buf *[]*Block, state int, lexem *Lexem) error {
 	return nil
 }
 
+// fForVar declares the variable of the key or the value in the block of for
+func fForVar(buf *[]*Block, state int, lexem *Lexem) error {
+	block := (*buf)[len(*buf)-1]
+	if block.Objects == nil {
+		block.Objects = make(map[string]*ObjInfo)
+	}
+	name := lexem.Value.(string)
+	if _, ok := block.Objects[name]; ok {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError, "lex_value": name}).Error("variable is already declared")
+		return fmt.Errorf(`variable %s is already declared [Ln:%d Col:%d]`, name, lexem.Line, lexem.Column)
+	}
+	block.Objects[name] = &ObjInfo{Type: ObjVar, Value: len(block.Vars)}
+	block.Vars = append(block.Vars, reflect.TypeOf((*interface{})(nil)).Elem())
+	return nil
+}
+
+// fFor moves the expression of for from its block to the parent block. cmdFor pushes
+// the key and the value of every item and the block starts with assigning them to its variables.
+func fFor(buf *[]*Block, state int, lexem *Lexem) error {
+	block := (*buf)[len(*buf)-1]
+	logger := lexem.GetLogger()
+	if len(block.Vars) != 2 {
+		logger.WithFields(log.Fields{"type": consts.ParseError}).Error("for must have key and value variables")
+		return fmt.Errorf(`for must have key and value variables [Ln:%d Col:%d]`, lexem.Line, lexem.Column)
+	}
+	for _, cmd := range block.Code {
+		if usesVars(cmd.Value, block) {
+			logger.WithFields(log.Fields{"type": consts.ParseError}).Error("for variable is used in expression")
+			return fmt.Errorf(`variables of for cannot be used in its expression [Ln:%d Col:%d]`, lexem.Line, lexem.Column)
+		}
+	}
+	vars := make([]*VarInfo, len(block.Vars))
+	for _, obj := range block.Objects {
+		vars[obj.Value.(int)] = &VarInfo{obj, block}
+	}
+	parent := (*buf)[len(*buf)-2]
+	parent.Code = append(parent.Code, block.Code...)
+	parent.Code = append(parent.Code, &ByteCode{cmdFor, lexem.Line, block})
+	block.Code = ByteCodes{&ByteCode{cmdAssignVar, lexem.Line, vars}, &ByteCode{cmdAssign, lexem.Line, 0}}
+	return nil
+}
+
+// usesVars returns true if the value of the byte-code refers to the variables of the block
+func usesVars(value interface{}, block *Block) bool {
+	switch v := value.(type) {
+	case *VarInfo:
+		return v.Owner == block
+	case *IndexInfo:
+		return v.Owner == block
+	case mapItem:
+		return usesVars(v.Value, block)
+	case []mapItem:
+		for _, item := range v {
+			if usesVars(item, block) {
+				return true
+			}
+		}
+	case *types.Map:
+		for _, item := range v.Values() {
+			if usesVars(item, block) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func fSwitch(buf *[]*Block, state int, lexem *Lexem) error {
+	(*(*buf)[len(*buf)-1]).Code = append((*(*buf)[len(*buf)-1]).Code, &ByteCode{cmdSwitch, lexem.Line, 0})
+	return nil
+}
+
+// isSwitchBefore returns true if the last command of the block is switch or case
+func isSwitchBefore(block *Block) bool {
+	if len(block.Code) == 0 {
+		return false
+	}
+	cmd := block.Code[len(block.Code)-1].Cmd
+	return cmd == cmdSwitch || cmd == cmdCase
+}
+
+// fCase moves the values of case from its block to the parent block, they are compared
+// with the value of switch by cmdCase
+func fCase(buf *[]*Block, state int, lexem *Lexem) error {
+	block := (*buf)[len(*buf)-1]
+	parent := (*buf)[len(*buf)-2]
+	if !isSwitchBefore(parent) {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError}).Error("there is not switch before")
+		return fmt.Errorf(`there is not switch before case [Ln:%d Col:%d]`, lexem.Line, lexem.Column)
+	}
+	parent.Code = append(parent.Code, block.Code...)
+	parent.Code = append(parent.Code, &ByteCode{cmdCase, lexem.Line, block})
+	block.Code = nil
+	return nil
+}
+
+func fDefault(buf *[]*Block, state int, lexem *Lexem) error {
+	parent := (*buf)[len(*buf)-2]
+	if !isSwitchBefore(parent) {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError}).Error("there is not switch before")
+		return fmt.Errorf(`there is not switch before default [Ln:%d Col:%d]`, lexem.Line, lexem.Column)
+	}
+	parent.Code = append(parent.Code, &ByteCode{cmdDefault, lexem.Line, (*buf)[len(*buf)-1]})
+	return nil
+}
+
+func fTry(buf *[]*Block, state int, lexem *Lexem) error {
+	parent := (*buf)[len(*buf)-2]
+	parent.Code = append(parent.Code, &ByteCode{cmdTry, lexem.Line, (*buf)[len(*buf)-1]})
+	return nil
+}
+
+// fCatch appends the catch block after the try block. If the variable of the error is specified
+// then cmdCatch pushes the error and the block starts with assigning it to the variable.
+func fCatch(buf *[]*Block, state int, lexem *Lexem) error {
+	block := (*buf)[len(*buf)-1]
+	parent := (*buf)[len(*buf)-2]
+	if len(parent.Code) == 0 || parent.Code[len(parent.Code)-1].Cmd != cmdTry {
+		lexem.GetLogger().WithFields(log.Fields{"type": consts.ParseError}).Error("there is not try before catch")
+		return fmt.Errorf(`there is not try before catch [Ln:%d Col:%d]`, lexem.Line, lexem.Column)
+	}
+	if lexem.Type == lexIdent {
+		obj := &ObjInfo{Type: ObjVar, Value: 0}
+		block.Objects = map[string]*ObjInfo{lexem.Value.(string): obj}
+		block.Vars = []reflect.Type{reflect.TypeOf(&types.Map{})}
+		block.Code = ByteCodes{&ByteCode{cmdAssignVar, lexem.Line, []*VarInfo{{obj, block}}},
+			&ByteCode{cmdAssign, lexem.Line, 0}}
+	}
+	parent.Code = append(parent.Code, &ByteCode{cmdCatch, lexem.Line, block})
+	return nil
+}
+
+// isMapVar returns true if the variable of the block has map type
+func isMapVar(obj *ObjInfo, block *Block) bool {
+	idx := obj.Value.(int)
+	return idx < len(block.Vars) && block.Vars[idx] == reflect.TypeOf(&types.Map{})
+}
+
 func fContinue(buf *[]*Block, state int, lexem *Lexem) error {
 	(*(*buf)[len(*buf)-1]).Code = append((*(*buf)[len(*buf)-1]).Code, &ByteCode{cmdContinue,
 		lexem.Line, 0})
@@ -719,7 +896,7 @@ func fNameBlock(buf *[]*Block, state int, lexem *Lexem) error {
 // CompileBlock compile the source code into the Block structure with a byte-code
 func (vm *VM) CompileBlock(input []rune, owner *OwnerInfo) (*Block, error) {
 	root := &Block{Info: owner.StateID, Owner: owner}
-	lexems, err := lexParser(input)
+	lexems, err := lexParser(input, owner.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -1104,6 +1281,7 @@ main:
 					return err
 				}
 				bytecode = append(bytecode, &ByteCode{cmdMapInit, lexem.Line, pMap})
+				noMap = true
 				continue
 			}
 			if lexem.Type == isLBrack {
@@ -1112,6 +1290,8 @@ main:
 					return err
 				}
 				bytecode = append(bytecode, &ByteCode{cmdArrayInit, lexem.Line, pArray})
+				// the block of for can follow the initialization such as for i, v in [1, 2] {
+				noMap = true
 				continue
 			}
 		}
@@ -1417,6 +1597,14 @@ main:
 					return fmt.Errorf(`unknown variable %s`, lexem.Value.(string))
 				}
 				cmd = &ByteCode{cmdVar, lexem.Line, &VarInfo{objInfo, tobj}}
+				// the field of the map variable such as err.Message is the same as err["Message"]
+				if i < len(*lexems)-3 && (*lexems)[i+1].Type == isDot && (*lexems)[i+2].Type == lexIdent &&
+					(*lexems)[i+3].Type != isLPar && isMapVar(objInfo, tobj) {
+					bytecode = append(bytecode, cmd, &ByteCode{cmdPush, lexem.Line, (*lexems)[i+2].Value.(string)},
+						&ByteCode{cmdIndex, lexem.Line, &IndexInfo{objInfo.Value.(int), tobj, ``}})
+					cmd = nil
+					i += 2
+				}
 			}
 		}
 		if lexem.Type != lexNewLine {
@@ -1452,7 +1640,7 @@ main:
 // ContractsList returns list of contracts names from source of code
 func ContractsList(value string) ([]string, error) {
 	names := make([]string, 0)
-	lexems, err := lexParser([]rune(value))
+	lexems, err := lexParser([]rune(value), LatestVersion)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.ParseError, "error": err}).Error("getting contract list")
 		return names, err
@@ -5,6 +5,8 @@
 package script
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -706,6 +708,392 @@ func TestVMCompile(t *testing.T) {
 	}
 }
 
+func TestVMForSwitch(t *testing.T) {
+	test := []TestVM{
+		{`func sumArr() string {
+			var total, keys int
+			var list array
+			list = [5, 7, 9]
+			for i, v in list {
+				total = total + v
+				keys = keys + i
+			}
+			return Sprintf("%d %d", total, keys)
+		}`, `sumArr`, `21 3`},
+		{`func forMap() string {
+			var m map
+			var out string
+			m = {"a": 1, "b": 2, "c": 3, "d": 4}
+			for k, v in m {
+				if v == 2 {
+					continue
+				}
+				if k == "d" {
+					break
+				}
+				out = out + k
+			}
+			return out
+		}`, `forMap`, `ac`},
+		{`func find(list array, val int) int {
+			for i, v in list {
+				if v == val {
+					return i
+				}
+			}
+			return -1
+		}
+		func forReturn() string {
+			return Sprintf("%d %d", find([1, 2, 3], 3), find([1], 5))
+		}`, `forReturn`, `2 -1`},
+		{`func size(v int) string {
+			var s string
+			switch v
+			case 1, 2 {
+				s = "small"
+			}
+			case 3 {
+				s = "three"
+			}
+			default {
+				s = "other"
+			}
+			return s
+		}
+		func switchInt() string {
+			return size(2) + " " + size(3) + " " + size(7)
+		}`, `switchInt`, `small three other`},
+		{`func switchLoop() string {
+			var out string
+			for i, v in ["a", "b", "stop", "c"] {
+				switch v
+				case "stop" {
+					if i > 0 {
+						break
+					}
+					out = out + "!"
+				}
+				default {
+					out = out + v
+				}
+			}
+			return out
+		}`, `switchLoop`, `abc`},
+		{`func switchType() bool {
+			var s string
+			s = "a"
+			switch s
+			case true {
+				return true
+			}
+			return false
+		}`, `switchType`, `unsupported combination of types in the operator [switchType:5]`},
+		{`func opers() string {
+			return Sprintf("%d %d %d %d %d %d %d %v", 17 % 5, 6 & 3, 6 | 3, 6 ^ 3, 1 << 4, -32 >> 2,
+				2 + 3 * 4 % 5, 1 | 2 == 3)
+		}`, `opers`, `2 2 7 5 16 -8 4 true`},
+		{`func modZero() int {
+			var i int
+			return 1 % i
+		}`, `modZero`, `divided by zero [modZero:3]`},
+		{`func forType() int {
+			for i, v in 10 {
+			}
+			return 0
+		}`, `forType`, `for cannot iterate over int64 [forType:2]`},
+		{`func forVars() {
+			for v in [1, 2] {
+			}
+		}`, ``, `for must have key and value variables [Ln:2 Col:11]`},
+		{`func forSelf() {
+			for i, v in [1, {"v": v}] {
+			}
+		}`, ``, `variables of for cannot be used in its expression [Ln:2 Col:14]`},
+		{`func caseOnly(v int) {
+			case 1 {
+			}
+		}`, ``, `there is not switch before case [Ln:2 Col:10]`},
+	}
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Sprintf": fmt.Sprintf}, nil, nil})
+	for _, item := range test {
+		err := vm.Compile([]rune(item.Input), &OwnerInfo{StateID: 1, Active: true, TableID: 1, Version: LatestVersion})
+		if len(item.Func) == 0 {
+			if err == nil || err.Error() != item.Output {
+				t.Errorf(`%v != %s`, err, item.Output)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		out, err := vm.Call(item.Func, nil, &map[string]interface{}{`stack`: []interface{}{item.Func}})
+		if err != nil {
+			out = []interface{}{err.Error()}
+		}
+		if fmt.Sprint(out[0]) != item.Output {
+			t.Errorf(`%s: %v != %s`, item.Func, out[0], item.Output)
+		}
+	}
+}
+
+func TestKeywordVersion(t *testing.T) {
+	source := []rune(`func f(in int, try int) int {
+		var default int
+		default = in + try
+		return default
+	}`)
+	vm := NewVM()
+	if err := vm.Compile(source, &OwnerInfo{StateID: 1, Active: true, TableID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := vm.Call(`f`, []interface{}{int64(1), int64(1)}, &map[string]interface{}{`stack`: []interface{}{`f`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(out[0]) != `2` {
+		t.Errorf(`wrong result %v`, out[0])
+	}
+	if err = NewVM().Compile(source, &OwnerInfo{StateID: 1, Active: true, TableID: 1,
+		Version: LatestVersion}); err == nil {
+		t.Error(`in, try and default must be the key words`)
+	}
+}
+
+func TestForCost(t *testing.T) {
+	vm := NewVM()
+	if err := vm.Compile([]rune(`func loop() {
+		for i, v in $list {
+		}
+	}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1, Version: LatestVersion}); err != nil {
+		t.Fatal(err)
+	}
+	run := func(count int, cost int64) (int64, error) {
+		extend := map[string]interface{}{`txcost`: cost, `stack`: []interface{}{`loop`},
+			`list`: make([]interface{}, count)}
+		_, err := vm.Call(`loop`, nil, &extend)
+		return cost - extend[`txcost`].(int64), err
+	}
+	one, err := run(1, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ten, err := run(10, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ten-one < 9*CostIteration {
+		t.Errorf(`every iteration must cost at least %d, %d != %d`, CostIteration, ten, one)
+	}
+	if _, err = run(100, 1000); err == nil || !strings.HasPrefix(err.Error(), `paid CPU resource is over`) {
+		t.Errorf(`wrong error %v`, err)
+	}
+}
+
+// testSavepoints records the calls of the savepoints of try blocks
+type testSavepoints struct {
+	calls []string
+}
+
+func (sp *testSavepoints) Savepoint() error {
+	sp.calls = append(sp.calls, `save`)
+	return nil
+}
+
+func (sp *testSavepoints) RollbackSavepoint() error {
+	sp.calls = append(sp.calls, `rollback`)
+	return nil
+}
+
+func (sp *testSavepoints) ReleaseSavepoint() error {
+	sp.calls = append(sp.calls, `release`)
+	return nil
+}
+
+func testThrow(code, text string) error {
+	out, _ := json.Marshal(&VMError{Type: `exception`, Error: text, ID: code})
+	return errors.New(string(out))
+}
+
+func TestVMTryCatch(t *testing.T) {
+	test := []TestVM{
+		{`func catchThrow() string {
+			try {
+				Throw("E01", "bad value")
+			} catch err {
+				return err.Type + " " + err.Code + " " + err.Message
+			}
+			return "none"
+		}`, `catchThrow`, `exception E01 bad value`},
+		{`func catchError() string {
+			try {
+				error "oops"
+			} catch e {
+				return Sprintf("%s %s [%s]", e["Type"], e.Message, e.Code)
+			}
+			return "none"
+		}`, `catchError`, `error oops []`},
+		{`func div(a int, b int) int {
+			return a / b
+		}
+		func catchPanic() string {
+			try {
+				div(1, 0)
+			} catch err {
+				return err.Type + ": " + err.Message
+			}
+			return "none"
+		}`, `catchPanic`, `panic: divided by zero [catchPanic:2 catchPanic:6]`},
+		{`func catchNoVar() string {
+			var s string
+			try {
+				s = "a"
+				error "x"
+				s = s + "b"
+			} catch {
+				s = s + "c"
+			}
+			return s + "d"
+		}`, `catchNoVar`, `acd`},
+		{`func noError() string {
+			var s string
+			try {
+				s = "ok"
+			} catch {
+				s = "fail"
+			}
+			return s
+		}`, `noError`, `ok`},
+		{`func rethrow() string {
+			try {
+				try {
+					Throw("E02", "inner")
+				} catch err {
+					Throw(err.Code, "outer " + err.Message)
+				}
+			} catch err {
+				return err.Code + " " + err.Message
+			}
+			return "none"
+		}`, `rethrow`, `E02 outer inner`},
+		{`func mapField() string {
+			var m map
+			m = {"name": "Alice"}
+			return m.name
+		}`, `mapField`, `Alice`},
+		{`func stringField() string {
+			var s string
+			s = "Alice"
+			return s.name
+		}`, ``, `unknown identifier name`},
+		{`func tryLoop() string {
+			var out string
+			for i, v in [1, 2, 3, 4] {
+				try {
+					if v == 2 {
+						continue
+					}
+					if v == 4 {
+						break
+					}
+					out = out + Str(v)
+				} catch {
+				}
+			}
+			return out
+		}`, `tryLoop`, `13`},
+		{`func tryOnly() {
+			try {
+				error "not caught"
+			}
+		}`, `tryOnly`, `{"type":"error","error":"not caught"}`},
+		{`func catchOnly() {
+			catch err {
+			}
+		}`, ``, `there is not try before catch [Ln:2 Col:11]`},
+	}
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Sprintf": fmt.Sprintf, "Throw": testThrow,
+		"Str": func(v int64) string { return fmt.Sprint(v) }}, nil, nil})
+	for _, item := range test {
+		err := vm.Compile([]rune(item.Input), &OwnerInfo{StateID: 1, Active: true, TableID: 1, Version: LatestVersion})
+		if len(item.Func) == 0 {
+			if err == nil || err.Error() != item.Output {
+				t.Errorf(`%v != %s`, err, item.Output)
+			}
+			continue
+		}
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		out, err := vm.Call(item.Func, nil, &map[string]interface{}{`stack`: []interface{}{item.Func}})
+		if err != nil {
+			out = []interface{}{err.Error()}
+		}
+		if fmt.Sprint(out[0]) != item.Output {
+			t.Errorf(`%s: %v != %s`, item.Func, out[0], item.Output)
+		}
+	}
+}
+
+func TestTrySavepoints(t *testing.T) {
+	vm := NewVM()
+	if err := vm.Compile([]rune(`func savepoints() {
+		try {
+			try {
+			} catch {
+			}
+			error "rollback"
+		} catch {
+		}
+	}
+	func fatal() {
+		try {
+			while true {
+			}
+		} catch {
+		}
+	}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1, Version: LatestVersion}); err != nil {
+		t.Fatal(err)
+	}
+	sp := &testSavepoints{}
+	extend := map[string]interface{}{`sc`: sp, `stack`: []interface{}{`savepoints`}}
+	if _, err := vm.Call(`savepoints`, nil, &extend); err != nil {
+		t.Fatal(err)
+	}
+	if calls := strings.Join(sp.calls, ` `); calls != `save save release rollback` {
+		t.Errorf(`wrong savepoints %s`, calls)
+	}
+	// the errors of the limits of the resources are not caught
+	sp.calls = nil
+	extend = map[string]interface{}{`sc`: sp, `txcost`: int64(1000), `stack`: []interface{}{`fatal`}}
+	if _, err := vm.Call(`fatal`, nil, &extend); !errors.Is(err, ErrCPULimit) {
+		t.Errorf(`wrong error %v`, err)
+	}
+	if calls := strings.Join(sp.calls, ` `); calls != `save rollback` {
+		t.Errorf(`wrong savepoints %s`, calls)
+	}
+}
+
+func TestIsFatal(t *testing.T) {
+	rt := &RunTime{cost: 1}
+	for _, item := range []struct {
+		err   error
+		fatal bool
+	}{
+		{fmt.Errorf("%w [fatal:1]", ErrMemoryLimit), true},
+		{&lineError{text: `time limit exceeded [fatal:2]`, err: ErrVMTimeLimit}, true},
+		{errors.New(`time limit exceeded by the user`), false},
+		{errDivZero, false},
+	} {
+		if fatal := rt.isFatal(item.err); fatal != item.fatal {
+			t.Errorf(`%v: %v != %v`, item.err, fatal, item.fatal)
+		}
+	}
+}
+
 func TestContractList(t *testing.T) {
 	test := []TestLexem{{`contract NewContract {
 		conditions {
@@ -9,6 +9,7 @@ const (
 	eWrongParams     = `function %s must have %d parameters`
 	eArrIndex        = `index of array cannot be type %s`
 	eMapIndex        = `index of map cannot be type %s`
+	eForType         = `for cannot iterate over %T`
 	eUnknownIdent    = `unknown identifier %s`
 	eWrongVar        = `wrong var %v`
 	eDataType        = `expecting type of the data field [Ln:%d Col:%d]`
@@ -35,4 +36,5 @@ var (
 	errSelfAssignment  = errors.New(`self assignment`)
 	errEndExp          = errors.New(`unexpected end of the expression`)
 	errOper            = errors.New(`unexpected operator; expecting operand`)
+	errShiftCount      = errors.New(`negative shift count`)
 )
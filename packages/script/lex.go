@@ -47,6 +47,8 @@ const (
 
 	// Constants for operations
 	isNot      = 0x0021 // !
+	isPercent  = 0x0025 // %
+	isAmp      = 0x0026 // &
 	isAsterisk = 0x002a // *
 	isPlus     = 0x002b // +
 	isMinus    = 0x002d // -
@@ -54,11 +56,15 @@ const (
 	isSolidus  = 0x002f // /
 	isLess     = 0x003c // <
 	isGreat    = 0x003e // >
+	isCaret    = 0x005e // ^
+	isPipe     = 0x007c // |
 	isNotEq    = 0x213d // !=
 	isAnd      = 0x2626 // &&
+	isShiftL   = 0x3c3c // <<
 	isLessEq   = 0x3c3d // <=
 	isEqEq     = 0x3d3d // ==
 	isGrEq     = 0x3e3d // >=
+	isShiftR   = 0x3e3e // >>
 	isOr       = 0x7c7c // ||
 
 )
@@ -88,6 +94,13 @@ const (
 	keyCond
 	keyTail
 	keyError
+	keyFor
+	keyIn
+	keySwitch
+	keyCase
+	keyDefault
+	keyTry
+	keyCatch
 )
 
 const (
@@ -121,7 +134,12 @@ var (
 		msgInfo: keyInfo, `while`: keyWhile, `data`: keyTX, `settings`: keySettings, `nil`: keyNil,
 		`action`: keyAction, `conditions`: keyCond,
 		`true`: keyTrue, `false`: keyFalse, `break`: keyBreak, `continue`: keyContinue,
-		`var`: keyVar, `...`: keyTail}
+		`var`: keyVar, `...`: keyTail, `for`: keyFor, `in`: keyIn, `switch`: keySwitch,
+		`case`: keyCase, `default`: keyDefault, `try`: keyTry, `catch`: keyCatch}
+	// The versions of the blocks from which the key words are enabled
+	keyVersions = map[uint32]int{keyFor: consts.BvStatements, keyIn: consts.BvStatements,
+		keySwitch: consts.BvStatements, keyCase: consts.BvStatements, keyDefault: consts.BvStatements,
+		keyTry: consts.BvTryCatch, keyCatch: consts.BvTryCatch}
 
 	// list of available types
 	// The list of types which save the corresponding 'reflect' type
@@ -166,8 +184,9 @@ type Lexems []*Lexem
 // tools/lextable/lextable.go. lextable.go generates a representation of a finite machine as an array
 // and records it in the file lex_table.go. In fact, the lexTable array is a set of states and
 // depending on the next sign, the machine goes into a new state.
-// lexParser parsers the input language source code
-func lexParser(input []rune) (Lexems, error) {
+// lexParser parsers the input language source code, the key words which are enabled in the later
+// versions than version are parsed as identifiers
+func lexParser(input []rune, version int) (Lexems, error) {
 	var (
 		curState                                        uint8
 		length, line, off, offline, flags, start, lexID uint32
@@ -295,7 +314,7 @@ func lexParser(input []rune) (Lexems, error) {
 				if name[0] == '$' {
 					lexID = lexExtend
 					value = name[1:]
-				} else if keyID, ok := keywords[name]; ok {
+				} else if keyID, ok := keywords[name]; ok && keyVersions[keyID] <= version {
 					switch keyID {
 					case keyIf:
 						ifbuf = append(ifbuf, ifBuf{})
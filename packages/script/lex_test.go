@@ -31,7 +31,7 @@ func TestLexParser(t *testing.T) {
 		{"`my string` \"another String\"" + `"test \"subtest\" test"`, "[6 my string][6 another String][6 test \"subtest\" test]"},
 		{"contract my { func init {}}", "[264 1][4 my][31489 123][520 2][4 init][31489 123][32001 125][32001 125]"},
 		{`callfunc( 1, name + 10)`, `[4 callfunc][10241 40][3 1][11265 44][4 name][2 43][3 10][10497 41]`},
-		if out, err := lexParser(source); err != nil {
+		if out, err := lexParser(source, LatestVersion); err != nil {
 			if err.Error() != item.Output {
 				fmt.Println(string(source))
 				t.Error(`error of lexical parser ` + err.Error())
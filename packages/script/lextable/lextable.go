@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -21,7 +22,7 @@ type States map[string]Action
 
 const (
 	// AlphaSize is the length of alphabet
-	AlphaSize = 34
+	AlphaSize = 36
 )
 
 /* Здесь мы определяем алфавит, с которым будет работать наш язык и описываем конечный автомат, который
@@ -38,7 +39,7 @@ var (
 	alphabet = []byte{0x01, 0x0a, ' ', '`', '"', ';', '(', ')', '[', ']', '{', '}', '&',
 		//           default  n    s    q    Q
 		'|', '#', '.', ',', '<', '>', '=', '!', '*', '$', '@', ':',
-		'+', '-', '/', '\\', '0', '1', 'a', '_', 128}
+		'+', '-', '/', '%', '^', '\\', '0', '1', 'a', '_', 128}
 	//													r
 
 	// В states мы обозначили за d - все символы, которые не указаны в состоянии
@@ -66,8 +67,10 @@ var (
 			"|": ["or", "", "push next"],
 			"=": ["eq", "", "push next"],
 			"/": ["solidus", "", "push next"],
-			"<>!": ["oneq", "", "push next"],
-			"*+-": ["main", "oper", "next"],
+			"<": ["less", "", "push next"],
+			">": ["great", "", "push next"],
+			"!": ["oneq", "", "push next"],
+			"*+-%^": ["main", "oper", "next"],
 			"01": ["number", "", "push next"],
 			"a_r": ["ident", "", "push next"],
 			"@$": ["mustident", "", "push next"],
@@ -97,11 +100,11 @@ var (
 	},
 	"and": {
 			"&": ["main", "oper", "pop next"],
-			"d": ["error", "", ""]
+			"d": ["main", "oper", "pop"]
 		},
 	"or": {
 			"|": ["main", "oper", "pop next"],
-			"d": ["error", "", ""]
+			"d": ["main", "oper", "pop"]
 		},
 	"eq": {
 			"=": ["main", "oper", "pop next"],
@@ -116,6 +119,14 @@ var (
 			"=": ["main", "oper", "pop next"],
 			"d": ["main", "oper", "pop"]
 		},
+	"less": {
+			"=<": ["main", "oper", "pop next"],
+			"d": ["main", "oper", "pop"]
+		},
+	"great": {
+			"=>": ["main", "oper", "pop next"],
+			"d": ["main", "oper", "pop"]
+		},
 	"number": {
 			"01.": ["number", "", "next"],
 			"a_r": ["error", "", ""],
@@ -170,14 +181,38 @@ func main() {
 			alpha[ch] = i
 		}
 	}
-	out := `package script
-	// This file was generated with lextable.go
-	
+	out := `/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package script
+
+// This file was generated with lextable.go
+
 var (
 		alphabet = []byte{`
 	for i, ch := range alpha {
 		out += fmt.Sprintf(`%d,`, ch)
 		if i > 0 && i%24 == 0 {
+			out += "\r\n\t\t\t"
+		}
+	}
+	out += "\r\n\t\t}\r\n"
+
+	var data States
+	state2int := map[string]uint{`main`: 0}
+	if err := json.Unmarshal([]byte(states), &data); err == nil {
+		// the states are numbered in the alphabetical order to get the same table every time
+		names := make([]string, 0, len(data))
+		for key := range data {
+			if key != `main` {
+				names = append(names, key)
+			}
+		}
+		sort.Strings(names)
+		for _, key := range names {
+			state2int[key] = uint(len(state2int))
+		}
 		table = make([][AlphaSize]uint32, len(state2int))
 		for key, istate := range data {
 			curstate := state2int[key]
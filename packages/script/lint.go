@@ -0,0 +1,214 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package script
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// The linter finds the code which is compiled but it is likely wrong. It works with the lexems
+// because the byte-code doesn't keep the declarations and positions of the source code.
+
+const (
+	LintUnusedVar    = `unused-var`
+	LintUnreachable  = `unreachable`
+	LintNoConditions = `no-conditions`
+	LintWriteAccess  = `write-access`
+)
+
+var (
+	// errPosition is the position of the compiler error such as [Ln:2 Col:5]
+	errPosition = regexp.MustCompile(`\s*\[Ln:(\d+)(?: Col:(\d+))?\]`)
+
+	// accessFuncs are the functions which restrict the callers of the contract
+	accessFuncs = map[string]bool{`ContractAccess`: true, `ContractConditions`: true}
+)
+
+// Issue is the problem of the source code which is found by the linter
+type Issue struct {
+	Line    int
+	Column  int
+	Rule    string
+	Message string
+}
+
+// ErrorPosition splits the error of the compiler to the message and the position,
+// the line and column are zero if the error doesn't contain the position
+func ErrorPosition(err error) (message string, line, column int) {
+	message = err.Error()
+	match := errPosition.FindStringSubmatchIndex(message)
+	if match == nil {
+		return
+	}
+	line, _ = strconv.Atoi(message[match[2]:match[3]])
+	if match[4] >= 0 {
+		column, _ = strconv.Atoi(message[match[4]:match[5]])
+	}
+	message = message[:match[0]] + message[match[1]:]
+	return
+}
+
+type lintVar struct {
+	lexem *Lexem
+	depth int
+	used  bool
+}
+
+type lintContract struct {
+	name       *Lexem
+	depth      int
+	conditions bool
+	access     bool
+	write      *Lexem
+}
+
+func newIssue(lexem *Lexem, rule, format string, args ...interface{}) *Issue {
+	return &Issue{Line: int(lexem.Line), Column: int(lexem.Column), Rule: rule,
+		Message: fmt.Sprintf(format, args...)}
+}
+
+// Lint returns the issues of the source code, the functions of the virtual machine
+// are used to find the calls which change the database
+func (vm *VM) Lint(input []rune) ([]*Issue, error) {
+	lexems, err := lexParser(input, LatestVersion)
+	if err != nil {
+		return nil, err
+	}
+	return vm.lint(lexems), nil
+}
+
+// canWrite returns true if the function of the virtual machine changes the database
+func (vm *VM) canWrite(name string) bool {
+	obj, ok := vm.Objects[name]
+	if !ok {
+		return false
+	}
+	switch obj.Type {
+	case ObjExtFunc:
+		return obj.Value.(ExtFuncInfo).CanWrite
+	case ObjFunc:
+		return obj.Value.(*Block).Info.(*FuncInfo).CanWrite
+	}
+	return false
+}
+
+func (vm *VM) lint(lexems Lexems) []*Issue {
+	var (
+		issues          []*Issue
+		vars            []*lintVar
+		contract        *lintContract
+		pendingContract *lintContract
+		depth           int
+	)
+	lexemType := func(i int) uint32 {
+		if i < 0 || i >= len(lexems) {
+			return 0
+		}
+		return lexems[i].Type
+	}
+
+	for i := 0; i < len(lexems); i++ {
+		lexem := lexems[i]
+		switch lexem.Type {
+		case lexKeyword | (keyContract << 8):
+			if lexemType(i+1) == lexIdent {
+				pendingContract = &lintContract{name: lexems[i+1]}
+				i++
+			}
+		case isLCurly:
+			depth++
+			if pendingContract != nil {
+				contract, pendingContract = pendingContract, nil
+				contract.depth = depth
+			}
+		case isRCurly:
+			for len(vars) > 0 && vars[len(vars)-1].depth == depth {
+				v := vars[len(vars)-1]
+				if !v.used {
+					issues = append(issues, newIssue(v.lexem, LintUnusedVar,
+						`variable %s is declared but not used`, v.lexem.Value))
+				}
+				vars = vars[:len(vars)-1]
+			}
+			if contract != nil && contract.depth == depth {
+				name := contract.name.Value
+				if !contract.conditions {
+					issues = append(issues, newIssue(contract.name, LintNoConditions,
+						`contract %s doesn't have conditions`, name))
+				}
+				if contract.write != nil && !contract.access {
+					issues = append(issues, newIssue(contract.write, LintWriteAccess,
+						`contract %s changes the database by %s without ContractAccess or ContractConditions`,
+						name, contract.write.Value))
+				}
+				contract = nil
+			}
+			depth--
+		case lexKeyword | (keyVar << 8):
+			for ; lexemType(i+1) != lexNewLine && i+1 < len(lexems); i++ {
+				if lexems[i+1].Type == lexIdent {
+					vars = append(vars, &lintVar{lexem: lexems[i+1], depth: depth})
+				}
+			}
+		case lexKeyword | (keyReturn << 8), lexKeyword | (keyError << 8),
+			lexKeyword | (keyWarning << 8), lexKeyword | (keyInfo << 8),
+			lexKeyword | (keyBreak << 8), lexKeyword | (keyContinue << 8):
+			// the statement can be continued on the next lines inside the brackets
+			j, level := i+1, 0
+			for ; j < len(lexems); j++ {
+				t := lexems[j].Type
+				if level == 0 && (t == lexNewLine || t == isRCurly) {
+					break
+				}
+				switch t {
+				case isLPar, isLBrack, isLCurly:
+					level++
+				case isRPar, isRBrack, isRCurly:
+					level--
+				}
+			}
+			for j < len(lexems) && lexems[j].Type == lexNewLine {
+				j++
+			}
+			if j < len(lexems) && lexems[j].Type != isRCurly {
+				issues = append(issues, newIssue(lexems[j], LintUnreachable, `unreachable code`))
+			}
+		case lexIdent:
+			name := lexem.Value.(string)
+			if name == `conditions` && contract != nil && depth == contract.depth &&
+				lexemType(i-1) == lexKeyword|(keyFunc<<8) {
+				contract.conditions = true
+				continue
+			}
+			if lexemType(i-1) == isDot {
+				// the tail function such as .Columns
+				continue
+			}
+			if lexemType(i+1) == isLPar {
+				if contract != nil {
+					if accessFuncs[name] {
+						contract.access = true
+					} else if contract.write == nil && vm.canWrite(name) {
+						contract.write = lexem
+					}
+				}
+				continue
+			}
+			if lexemType(i+1) == isEq {
+				// the assignment isn't the usage of variable
+				continue
+			}
+			for k := len(vars) - 1; k >= 0; k-- {
+				if vars[k].lexem.Value == name {
+					vars[k].used = true
+					break
+				}
+			}
+		}
+	}
+	return issues
+}
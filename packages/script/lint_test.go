@@ -0,0 +1,79 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	vm := NewVM()
+	vm.Extend(&ExtendData{
+		Objects: map[string]interface{}{
+			"DBInsert": func(table string, values map[string]interface{}) int64 { return 0 },
+		},
+		WriteFuncs: map[string]struct{}{"DBInsert": {}},
+	})
+	issues, err := vm.Lint([]rune("contract Transfer {\n" +
+		"\tdata {\n\t\tAmount int\n\t}\n" +
+		"\taction {\n" +
+		"\t\tvar unused string\n" +
+		"\t\tvar total int\n" +
+		"\t\ttotal = $Amount\n" +
+		"\t\tDBInsert(\"history\", {\"amount\": total})\n" +
+		"\t\terror \"stop\"\n" +
+		"\t\ttotal = 0\n" +
+		"\t}\n}\n" +
+		"contract Guarded {\n" +
+		"\tconditions {\n\t\tContractAccess(\"@1Transfer\")\n\t}\n" +
+		"\taction {\n" +
+		"\t\tif $Amount {\n\t\t\treturn\n\t\t}\n" +
+		"\t\tDBInsert(\"history\", {\"amount\": 1})\n" +
+		"\t}\n}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []*Issue{
+		{Line: 11, Column: 4, Rule: LintUnreachable, Message: `unreachable code`},
+		{Line: 6, Column: 8, Rule: LintUnusedVar, Message: `variable unused is declared but not used`},
+		{Line: 1, Column: 10, Rule: LintNoConditions, Message: `contract Transfer doesn't have conditions`},
+		{Line: 9, Column: 4, Rule: LintWriteAccess,
+			Message: `contract Transfer changes the database by DBInsert without ContractAccess or ContractConditions`},
+	}, issues)
+}
+
+func TestErrorPosition(t *testing.T) {
+	msg, line, column := ErrorPosition(errors.New(`unknown lexem " [Ln:8 Col:18]`))
+	assert.Equal(t, `unknown lexem "`, msg)
+	assert.Equal(t, []int{8, 18}, []int{line, column})
+
+	msg, line, column = ErrorPosition(errors.New(`must be '}' (unexpected new line) [Ln:3]`))
+	assert.Equal(t, `must be '}' (unexpected new line)`, msg)
+	assert.Equal(t, []int{3, 0}, []int{line, column})
+
+	msg, line, column = ErrorPosition(errors.New(`unknown identifier row`))
+	assert.Equal(t, `unknown identifier row`, msg)
+	assert.Equal(t, []int{0, 0}, []int{line, column})
+}
+
+func TestSplitDeclarations(t *testing.T) {
+	source := "// the comment\ncontract A {\n\tfunc a() {\n\t\tvar s string\n\t\ts = \"}\"\n\t}\n}\n" +
+		"func b(v int) int {\n\treturn v\n\ncontract C {\n\taction {}\n}\n"
+	parts := SplitDeclarations([]rune(source))
+	require.Len(t, parts, 3)
+	for _, part := range parts {
+		assert.Equal(t, len(source), len(part))
+		assert.Equal(t, strings.Count(source, "\n"), strings.Count(string(part), "\n"))
+	}
+	assert.Equal(t, "contract A", strings.TrimSpace(string(parts[0]))[:10])
+	assert.True(t, strings.HasPrefix(strings.TrimSpace(string(parts[1])), "func b(v int)"))
+	// the contract starts the new declaration after the function without the closing brace
+	assert.Equal(t, "contract C {\n\taction {}\n}", strings.TrimSpace(string(parts[2])))
+
+	assert.Len(t, SplitDeclarations([]rune("contract A {\n}")), 1)
+}
@@ -0,0 +1,260 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package script
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The declarations of the source code are used by the editor tools such as the language server.
+
+// Symbol is the contract or the function which is declared in the source code
+type Symbol struct {
+	Name     string
+	Type     int    // ObjContract or ObjFunc
+	Contract string // name of the contract which contains the function
+	Line     int    // line of the name, it starts from 1
+	Column   int    // column of the name which is counted by the lexer in the same way as in the errors
+}
+
+// Symbols returns the contracts and functions which are declared in the source code
+func Symbols(input []rune) ([]*Symbol, error) {
+	lexems, err := lexParser(input, LatestVersion)
+	if err != nil {
+		return nil, err
+	}
+	return declarations(lexems), nil
+}
+
+func declarations(lexems Lexems) []*Symbol {
+	var (
+		symbols               []*Symbol
+		contract              *Symbol
+		depth, contractDepth  int
+		pendingContract, open bool
+	)
+	for i, lexem := range lexems {
+		switch lexem.Type {
+		case lexKeyword | (keyContract << 8), lexKeyword | (keyFunc << 8):
+			if i+1 >= len(lexems) || lexems[i+1].Type != lexIdent {
+				continue
+			}
+			name := lexems[i+1]
+			symbol := &Symbol{Name: name.Value.(string), Type: ObjFunc,
+				Line: int(name.Line), Column: int(name.Column)}
+			if lexem.Type == lexKeyword|(keyContract<<8) {
+				symbol.Type = ObjContract
+				contract = symbol
+				pendingContract = true
+			} else if open {
+				symbol.Contract = contract.Name
+			}
+			symbols = append(symbols, symbol)
+		case isLCurly:
+			depth++
+			if pendingContract {
+				pendingContract = false
+				open = true
+				contractDepth = depth
+			}
+		case isRCurly:
+			if open && depth == contractDepth {
+				open = false
+			}
+			depth--
+		}
+	}
+	return symbols
+}
+
+// SplitDeclarations returns the copies of the source where one top-level contract or function is kept
+// and the rest is replaced by spaces, so the positions of the compiler errors are the same as in the source.
+// The contract at the beginning of the line starts the new declaration even if the braces aren't balanced,
+// so the errors of the next declarations are found after the error of the previous one.
+func SplitDeclarations(input []rune) [][]rune {
+	var (
+		starts    []int
+		depth     int
+		quote     rune
+		lineStart = true
+	)
+	for i := 0; i < len(input); i++ {
+		ch := input[i]
+		if quote != 0 {
+			switch {
+			case ch == '\\' && quote == '"':
+				i++
+			case ch == quote:
+				quote = 0
+			case ch == '\n' && quote == '"':
+				// the unclosed string doesn't hide the next lines
+				quote, lineStart = 0, true
+			}
+			continue
+		}
+		switch {
+		case ch == '\n':
+			lineStart = true
+			continue
+		case unicode.IsSpace(ch):
+			continue
+		case ch == '/' && i+1 < len(input) && input[i+1] == '/':
+			for i+1 < len(input) && input[i+1] != '\n' {
+				i++
+			}
+			continue
+		case ch == '/' && i+1 < len(input) && input[i+1] == '*':
+			for i += 2; i+1 < len(input) && (input[i] != '*' || input[i+1] != '/'); i++ {
+			}
+			i++
+		case ch == '"' || ch == '`':
+			quote = ch
+		case ch == '{':
+			depth++
+		case ch == '}':
+			if depth > 0 {
+				depth--
+			}
+		case isDeclRune(ch):
+			j := i
+			for j < len(input) && isDeclRune(input[j]) {
+				j++
+			}
+			switch string(input[i:j]) {
+			case `contract`:
+				if depth == 0 || lineStart {
+					depth = 0
+					starts = append(starts, i)
+				}
+			case `func`:
+				if depth == 0 {
+					starts = append(starts, i)
+				}
+			}
+			i = j - 1
+		}
+		lineStart = false
+	}
+	if len(starts) < 2 {
+		return [][]rune{input}
+	}
+	parts := make([][]rune, len(starts))
+	for k, start := range starts {
+		end := len(input)
+		if k+1 < len(starts) {
+			end = starts[k+1]
+		}
+		part := make([]rune, len(input))
+		for i, ch := range input {
+			switch {
+			case i >= start && i < end:
+				part[i] = ch
+			case ch == '\n':
+				part[i] = ch
+			default:
+				part[i] = ' '
+			}
+		}
+		parts[k] = part
+	}
+	return parts
+}
+
+func isDeclRune(ch rune) bool {
+	return ch == '_' || ch == '$' || unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
+
+// Keywords returns the key words and the names of types of the contract language
+func Keywords() []string {
+	list := make([]string, 0, len(keywords)+len(typesMap))
+	for name, key := range keywords {
+		if key != keyTail {
+			list = append(list, name)
+		}
+	}
+	for name := range typesMap {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// typeNames is the order of the names of types, the first name is used if the types are the same
+var typeNames = []string{`bool`, `bytes`, `int`, `array`, `map`, `money`, `float`, `string`}
+
+// TypeName returns the name of the type in the contract language
+func TypeName(t reflect.Type) string {
+	for _, name := range typeNames {
+		if typesMap[name].Type == t {
+			return name
+		}
+	}
+	return t.String()
+}
+
+func typeList(params []reflect.Type, variadic bool) string {
+	list := make([]string, len(params))
+	for i, t := range params {
+		if variadic && i == len(params)-1 && t.Kind() == reflect.Slice {
+			list[i] = `...` + TypeName(t.Elem())
+			continue
+		}
+		list[i] = TypeName(t)
+	}
+	return strings.Join(list, `, `)
+}
+
+func resultList(results []reflect.Type) string {
+	switch len(results) {
+	case 0:
+		return ``
+	case 1:
+		return ` ` + TypeName(results[0])
+	}
+	list := make([]string, len(results))
+	for i, t := range results {
+		list[i] = TypeName(t)
+	}
+	return ` (` + strings.Join(list, `, `) + `)`
+}
+
+// Signature returns the declaration of the extended function, the parameters
+// which are filled automatically aren't shown
+func (f *ExtFuncInfo) Signature() string {
+	params := make([]reflect.Type, 0, len(f.Params))
+	for i, t := range f.Params {
+		if len(f.Auto[i]) == 0 {
+			params = append(params, t)
+		}
+	}
+	results := f.Results
+	if len(results) > 0 && results[len(results)-1].String() == `error` {
+		results = results[:len(results)-1]
+	}
+	return f.Name + `(` + typeList(params, f.Variadic) + `)` + resultList(results)
+}
+
+// Signature returns the declaration of the function with its tail functions
+func (f *FuncInfo) Signature(name string) string {
+	ret := name + `(` + typeList(f.Params, f.Variadic) + `)`
+	if f.Names != nil {
+		tails := make([]string, 0, len(*f.Names))
+		for tail := range *f.Names {
+			// the name with '_' is the temporary name of the compiling tail function
+			if tail[0] != '_' {
+				tails = append(tails, tail)
+			}
+		}
+		sort.Strings(tails)
+		for _, tail := range tails {
+			item := (*f.Names)[tail]
+			ret += `.` + tail + `(` + typeList(item.Params, item.Variadic) + `)`
+		}
+	}
+	return ret + resultList(f.Results)
+}
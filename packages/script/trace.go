@@ -0,0 +1,284 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package script
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+const (
+	// DefaultTraceSteps is the default limit of the commands stored in the trace
+	DefaultTraceSteps = 50000
+	// traceValueLen is the max length of the value in the trace
+	traceValueLen = 256
+)
+
+var cmdNames = map[uint16]string{
+	cmdPush:       `push`,
+	cmdVar:        `var`,
+	cmdExtend:     `extend`,
+	cmdCallExtend: `callextend`,
+	cmdPushStr:    `pushstr`,
+	cmdCall:       `call`,
+	cmdCallVari:   `callvari`,
+	cmdReturn:     `return`,
+	cmdIf:         `if`,
+	cmdElse:       `else`,
+	cmdAssignVar:  `assignvar`,
+	cmdAssign:     `assign`,
+	cmdLabel:      `label`,
+	cmdContinue:   `continue`,
+	cmdWhile:      `while`,
+	cmdBreak:      `break`,
+	cmdIndex:      `index`,
+	cmdSetIndex:   `setindex`,
+	cmdFuncName:   `funcname`,
+	cmdUnwrapArr:  `unwraparr`,
+	cmdMapInit:    `mapinit`,
+	cmdArrayInit:  `arrayinit`,
+	cmdError:      `error`,
+	cmdFor:        `for`,
+	cmdSwitch:     `switch`,
+	cmdCase:       `case`,
+	cmdDefault:    `default`,
+	cmdTry:        `try`,
+	cmdCatch:      `catch`,
+	cmdNot:        `not`,
+	cmdSign:       `sign`,
+	cmdAdd:        `add`,
+	cmdSub:        `sub`,
+	cmdMul:        `mul`,
+	cmdDiv:        `div`,
+	cmdAnd:        `and`,
+	cmdOr:         `or`,
+	cmdEqual:      `equal`,
+	cmdNotEq:      `noteq`,
+	cmdLess:       `less`,
+	cmdNotLess:    `notless`,
+	cmdGreat:      `great`,
+	cmdNotGreat:   `notgreat`,
+	cmdMod:        `mod`,
+	cmdBitAnd:     `bitand`,
+	cmdBitOr:      `bitor`,
+	cmdBitXor:     `bitxor`,
+	cmdShiftL:     `shiftl`,
+	cmdShiftR:     `shiftr`,
+}
+
+// TraceStep is the executed command of the byte-code
+type TraceStep struct {
+	Contract string            `json:"contract"`
+	Line     uint16            `json:"line"`
+	Cmd      string            `json:"cmd"`
+	Depth    int               `json:"depth"`
+	Cost     int64             `json:"cost"`
+	Stack    []string          `json:"stack"`
+	Vars     map[string]string `json:"vars,omitempty"`
+}
+
+// TraceCall is the call of the extended function
+type TraceCall struct {
+	Step    int      `json:"step"`
+	Name    string   `json:"name"`
+	Params  []string `json:"params"`
+	Results []string `json:"results,omitempty"`
+	Fuel    int64    `json:"fuel"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// TraceLine is the fuel consumed by the line of the contract
+type TraceLine struct {
+	Contract string `json:"contract"`
+	Line     uint16 `json:"line"`
+	Count    int64  `json:"count"`
+	Fuel     int64  `json:"fuel"`
+}
+
+// Trace collects the execution trace of the virtual machine
+type Trace struct {
+	MaxSteps  int          `json:"-"`
+	Steps     []TraceStep  `json:"steps"`
+	Calls     []TraceCall  `json:"calls"`
+	Lines     []*TraceLine `json:"lines"`
+	Truncated bool         `json:"truncated"`
+	Error     string       `json:"error,omitempty"`
+
+	count    int
+	runs     int
+	lastCost int64
+	last     *TraceLine
+	lines    map[string]*TraceLine
+}
+
+// NewTrace creates a new trace which stores up to maxSteps commands
+func NewTrace(maxSteps int) *Trace {
+	if maxSteps <= 0 {
+		maxSteps = DefaultTraceSteps
+	}
+	return &Trace{
+		MaxSteps: maxSteps,
+		Steps:    make([]TraceStep, 0),
+		Calls:    make([]TraceCall, 0),
+		Lines:    make([]*TraceLine, 0),
+		lines:    make(map[string]*TraceLine),
+	}
+}
+
+func traceValue(v interface{}) string {
+	var out string
+	switch val := v.(type) {
+	case string:
+		out = strconv.Quote(val)
+	default:
+		out = fmt.Sprintf(`%v`, val)
+	}
+	if len(out) > traceValueLen {
+		out = out[:traceValueLen] + `...`
+	}
+	return out
+}
+
+func traceValues(values []interface{}) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = traceValue(v)
+	}
+	return out
+}
+
+// account adds the fuel spent since the previous command to the line of that command
+func (t *Trace) account(cost int64) {
+	if t.last != nil {
+		t.last.Fuel += t.lastCost - cost
+	}
+	t.lastCost = cost
+}
+
+func (t *Trace) step(rt *RunTime, cmd *ByteCode) {
+	t.account(rt.cost)
+
+	contract := rt.currentContract()
+	key := contract + `:` + strconv.Itoa(int(cmd.Line))
+	line, ok := t.lines[key]
+	if !ok {
+		line = &TraceLine{Contract: contract, Line: cmd.Line}
+		t.lines[key] = line
+		t.Lines = append(t.Lines, line)
+	}
+	line.Count++
+	t.last = line
+
+	t.count++
+	if len(t.Steps) >= t.MaxSteps {
+		t.Truncated = true
+		return
+	}
+	name, ok := cmdNames[cmd.Cmd]
+	if !ok {
+		name = strconv.Itoa(int(cmd.Cmd))
+	}
+	t.Steps = append(t.Steps, TraceStep{
+		Contract: contract,
+		Line:     cmd.Line,
+		Cmd:      name,
+		Depth:    len(rt.blocks),
+		Cost:     rt.cost,
+		Stack:    traceValues(rt.stack),
+		Vars:     rt.traceVars(),
+	})
+}
+
+func (t *Trace) call(name string, pars []reflect.Value, cost int64) int {
+	params := make([]string, len(pars))
+	for i, par := range pars {
+		if par.IsValid() && par.CanInterface() {
+			params[i] = traceValue(par.Interface())
+		}
+	}
+	t.Calls = append(t.Calls, TraceCall{
+		Step:   t.count - 1,
+		Name:   name,
+		Params: params,
+		Fuel:   cost,
+	})
+	return len(t.Calls) - 1
+}
+
+func (t *Trace) result(call int, results []interface{}, cost int64, err error) {
+	c := &t.Calls[call]
+	c.Fuel -= cost
+	if err != nil {
+		c.Error = err.Error()
+		return
+	}
+	c.Results = traceValues(results)
+}
+
+// Start marks the beginning of the run. The contracts called by the contract are run
+// by the nested runs which share the trace
+func (t *Trace) Start() {
+	t.runs++
+}
+
+// Finish completes the trace with the remaining fuel and the error of the execution.
+// Only the outermost run completes the trace, the error of the nested run can be caught
+func (t *Trace) Finish(cost int64, err error) {
+	if t.runs--; t.runs > 0 {
+		return
+	}
+	t.runs = 0
+	t.account(cost)
+	t.last = nil
+	if err != nil {
+		t.Error = err.Error()
+	}
+}
+
+// SetTrace enables the tracing of the execution
+func (rt *RunTime) SetTrace(t *Trace) {
+	rt.trace = t
+}
+
+func (rt *RunTime) currentContract() string {
+	if rt.extend == nil {
+		return ``
+	}
+	if stack, ok := (*rt.extend)[`stack`].([]interface{}); ok && len(stack) > 0 {
+		if name, ok := stack[len(stack)-1].(string); ok {
+			return name
+		}
+	}
+	return ``
+}
+
+// traceVars returns the values of the variables which are visible in the current block
+func (rt *RunTime) traceVars() map[string]string {
+	var vars map[string]string
+	for i := len(rt.blocks) - 1; i >= 0; i-- {
+		block := rt.blocks[i]
+		for name, obj := range block.Block.Objects {
+			if obj.Type != ObjVar {
+				continue
+			}
+			if _, ok := vars[name]; ok {
+				continue
+			}
+			off := block.Offset + obj.Value.(int)
+			if off >= len(rt.vars) {
+				continue
+			}
+			if vars == nil {
+				vars = make(map[string]string)
+			}
+			vars[name] = traceValue(rt.vars[off])
+		}
+		if block.Block.Type == ObjFunc {
+			break
+		}
+	}
+	return vars
+}
@@ -0,0 +1,87 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package script
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Double(v int64) int64 {
+	return v * 2
+}
+
+func runTrace(t *testing.T, trace *Trace) (int64, int64) {
+	vm := NewVM()
+	vm.Extend(&ExtendData{map[string]interface{}{"Double": Double}, nil, nil})
+	require.NoError(t, vm.Compile([]rune(`func sum() int {
+		var i int
+		var s int
+		while i < 3 {
+			i = i + 1
+			s = s + Double(i)
+		}
+		return s
+	}`), &OwnerInfo{StateID: 1, Active: true, TableID: 1}))
+
+	rt := vm.RunInit(10000)
+	rt.SetTrace(trace)
+	trace.Start()
+	out, err := rt.Run(vm.Objects[`sum`].Value.(*Block), nil, &map[string]interface{}{})
+	require.NoError(t, err)
+	trace.Finish(rt.Cost(), err)
+	return out[0].(int64), 10000 - rt.Cost()
+}
+
+func TestTrace(t *testing.T) {
+	trace := NewTrace(0)
+	sum, used := runTrace(t, trace)
+	assert.Equal(t, int64(12), sum)
+	assert.False(t, trace.Truncated)
+	assert.NotEmpty(t, trace.Steps)
+
+	require.Len(t, trace.Calls, 3)
+	for i, call := range trace.Calls {
+		assert.Equal(t, `Double`, call.Name)
+		assert.Equal(t, `call`, trace.Steps[call.Step].Cmd)
+		assert.Equal(t, []string{string(rune('1' + i))}, call.Params)
+		assert.Equal(t, []string{string(rune('2' + 2*i))}, call.Results)
+	}
+
+	last := trace.Steps[len(trace.Steps)-1]
+	assert.Equal(t, `3`, last.Vars[`i`])
+	assert.Equal(t, `12`, last.Vars[`s`])
+
+	var fuel int64
+	for _, line := range trace.Lines {
+		fuel += line.Fuel
+	}
+	assert.True(t, fuel > 0 && fuel <= used)
+}
+
+func TestTraceLimit(t *testing.T) {
+	trace := NewTrace(5)
+	runTrace(t, trace)
+	assert.True(t, trace.Truncated)
+	assert.Len(t, trace.Steps, 5)
+	assert.Len(t, trace.Calls, 3)
+}
+
+func TestTraceNested(t *testing.T) {
+	trace := NewTrace(0)
+	trace.Start()
+	trace.Start()
+	trace.Finish(100, errors.New(`caught`))
+	assert.Empty(t, trace.Error)
+	trace.Finish(50, nil)
+	assert.Empty(t, trace.Error)
+
+	trace.Start()
+	trace.Finish(10, errors.New(`failed`))
+	assert.Equal(t, `failed`, trace.Error)
+}
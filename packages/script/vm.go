@@ -71,12 +71,28 @@ var sysVars = map[string]struct{}{
 var (
 	ErrMemoryLimit = errors.New("Memory limit exceeded")
 	ErrVMTimeLimit = errors.New(`time limit exceeded`)
+	ErrCPULimit    = errors.New(`paid CPU resource is over`)
 )
 
+// lineError is the error with the line of the contract, it keeps the original error for errors.Is
+type lineError struct {
+	text string
+	err  error
+}
+
+func (e *lineError) Error() string {
+	return e.text
+}
+
+func (e *lineError) Unwrap() error {
+	return e.err
+}
+
 // VMError represents error of VM
 type VMError struct {
 	Type  string `json:"type"`
 	Error string `json:"error"`
+	ID    string `json:"id,omitempty"`
 }
 
 type blockStack struct {
@@ -105,6 +121,7 @@ type RunTime struct {
 	mem       int64
 	memVars   map[interface{}]int64
 	errInfo   ErrInfo
+	trace     *Trace
 }
 
 func isSysVar(name string) bool {
@@ -233,6 +250,16 @@ func (rt *RunTime) callFunc(cmd uint16, obj *ObjInfo) (err error) {
 		if finfo.Name == `ExecContract` && (pars[2].Type().String() != `string` || !pars[3].IsValid()) {
 			return fmt.Errorf(`unknown function %v`, pars[1])
 		}
+		if rt.trace != nil {
+			call := rt.trace.call(finfo.Name, pars, rt.cost)
+			defer func() {
+				var results []interface{}
+				if len(rt.stack) > shift {
+					results = rt.stack[shift:]
+				}
+				rt.trace.result(call, results, rt.cost, err)
+			}()
+		}
 		if finfo.Variadic {
 			result = foo.CallSlice(pars)
 		} else {
@@ -251,10 +278,13 @@ func (rt *RunTime) callFunc(cmd uint16, obj *ObjInfo) (err error) {
 					if cost > rt.cost {
 						rt.cost = 0
 						rt.vm.logger.WithFields(log.Fields{"type": consts.VMError}).Error("paid CPU resource is over")
-						return fmt.Errorf("paid CPU resource is over")
+						return ErrCPULimit
 					}
 
 					rt.cost -= cost
+					if counter, ok := (*rt.extend)["sc"].(FuelCounter); ok {
+						counter.AddQueryFuel(cost)
+					}
 					continue
 				}
 			}
@@ -431,6 +461,122 @@ func ValueToDecimal(v interface{}) (ret decimal.Decimal, err error) {
 	return
 }
 
+// valueEqual compares the value of switch with the value of case. The error is returned
+// if the values of these types cannot be compared.
+func valueEqual(left, right interface{}) (bool, error) {
+	if left == nil || right == nil {
+		return left == right, nil
+	}
+	switch l := left.(type) {
+	case string:
+		switch r := right.(type) {
+		case string:
+			return l == r, nil
+		case int64:
+			val, err := converter.ValueToInt(l)
+			if err != nil {
+				return false, err
+			}
+			return val == r, nil
+		case float64:
+			return ValueToFloat(l) == r, nil
+		case decimal.Decimal:
+			dec, err := ValueToDecimal(l)
+			if err != nil {
+				return false, err
+			}
+			return dec.Cmp(r) == 0, nil
+		}
+	case float64:
+		switch right.(type) {
+		case string, int64, float64:
+			return l == ValueToFloat(right), nil
+		}
+	case int64:
+		switch r := right.(type) {
+		case int64:
+			return l == r, nil
+		case float64:
+			return ValueToFloat(l) == r, nil
+		}
+	case bool:
+		if r, ok := right.(bool); ok {
+			return l == r, nil
+		}
+	case decimal.Decimal:
+		switch right.(type) {
+		case string, int64, float64, decimal.Decimal:
+			dec, err := ValueToDecimal(right)
+			if err != nil {
+				return false, err
+			}
+			return l.Cmp(dec) == 0, nil
+		}
+	}
+	return false, errUnsupportedType
+}
+
+// intOperands converts the operands of the integer operators, they can be int or string
+func intOperands(left, right interface{}) (l, r int64, err error) {
+	for _, v := range []interface{}{left, right} {
+		switch v.(type) {
+		case int64, string:
+		default:
+			return 0, 0, errUnsupportedType
+		}
+	}
+	if l, err = converter.ValueToInt(left); err != nil {
+		return
+	}
+	r, err = converter.ValueToInt(right)
+	return
+}
+
+// modValues returns the remainder of % operator for the integers or the money values
+func modValues(left, right interface{}) (interface{}, error) {
+	if l, ok := left.(decimal.Decimal); ok {
+		r, ok := right.(decimal.Decimal)
+		if !ok {
+			return nil, errUnsupportedType
+		}
+		if r.Cmp(decimal.New(0, 0)) == 0 {
+			return nil, errDivZero
+		}
+		return l.Mod(r), nil
+	}
+	l, r, err := intOperands(left, right)
+	if err != nil {
+		return nil, err
+	}
+	if r == 0 {
+		return nil, errDivZero
+	}
+	return l % r, nil
+}
+
+// bitValues returns the result of the bitwise operators and the shifts
+func bitValues(cmd uint16, left, right interface{}) (int64, error) {
+	l, r, err := intOperands(left, right)
+	if err != nil {
+		return 0, err
+	}
+	switch cmd {
+	case cmdBitAnd:
+		return l & r, nil
+	case cmdBitOr:
+		return l | r, nil
+	case cmdBitXor:
+		return l ^ r, nil
+	}
+	if r < 0 {
+		return 0, errShiftCount
+	}
+	if cmd == cmdShiftL {
+		return l << uint64(r), nil
+	}
+	return l >> uint64(r), nil
+}
+
 // SetCost sets the max cost of the execution.
 func (rt *RunTime) SetCost(cost int64) {
 	rt.cost = cost
@@ -452,6 +598,56 @@ func (vm *VM) RunInit(cost int64) *RunTime {
 	return &rt
 }
 
+// runTry runs the block of try. If catch is true and the error of the block can be caught then
+// it is returned as caught. The changes of the database made by the failed block are rolled back.
+func (rt *RunTime) runTry(block *Block, catch bool) (status int, caught error, err error) {
+	sp, _ := (*rt.extend)[`sc`].(Savepointer)
+	if sp != nil {
+		if err = sp.Savepoint(); err != nil {
+			return
+		}
+	}
+	status, err = rt.RunCode(block)
+	if sp != nil {
+		var errSave error
+		if err != nil {
+			errSave = sp.RollbackSavepoint()
+		} else {
+			errSave = sp.ReleaseSavepoint()
+		}
+		if errSave != nil {
+			return status, nil, errSave
+		}
+	}
+	if err != nil && catch && !rt.isFatal(err) {
+		caught, err = err, nil
+		status = statusNormal
+		rt.errInfo = ErrInfo{}
+	}
+	return
+}
+
+// isFatal returns true if the error cannot be caught by try. These are the errors of exceeding
+// the limits of the resources.
+func (rt *RunTime) isFatal(err error) bool {
+	return rt.cost <= 0 || rt.timeLimit || errors.Is(err, ErrCPULimit) || errors.Is(err, ErrVMTimeLimit) ||
+		errors.Is(err, ErrMemoryLimit)
+}
+
+// errorToMap returns the caught error as the map with Type, Message and Code. The errors
+// which are not raised by Throw or error statements have panic type.
+func errorToMap(err error) *types.Map {
+	var verr VMError
+	if text := err.Error(); !strings.HasPrefix(text, `{`) || json.Unmarshal([]byte(text), &verr) != nil {
+		verr = VMError{Type: `panic`, Error: text}
+	}
+	ret := types.NewMap()
+	ret.Set(`Type`, verr.Type)
+	ret.Set(`Message`, verr.Error)
+	ret.Set(`Code`, verr.ID)
+	return ret
+}
+
 // SetVMError sets error of VM
 func SetVMError(eType string, eText interface{}) error {
 	errText := fmt.Sprintf(`%v`, eText)
@@ -589,10 +785,13 @@ func (rt *RunTime) RunCode(block *Block) (status int, err error) {
 		start -= len(block.Info.(*FuncInfo).Params)
 	}
 	var (
-		assign []*VarInfo
-		tmpInt int64
-		tmpDec decimal.Decimal
-		cmd    *ByteCode
+		assign     []*VarInfo
+		tmpInt     int64
+		tmpDec     decimal.Decimal
+		cmd        *ByteCode
+		switchOff  int
+		switchDone bool
+		tryErr     error
 	)
 	labels := make([]int, 0)
 main:
@@ -600,7 +799,7 @@ main:
 		rt.cost--
 		if rt.cost <= 0 {
 			rt.vm.logger.WithFields(log.Fields{"type": consts.VMError}).Warn("paid CPU resource is over")
-			err = fmt.Errorf(`paid CPU resource is over`)
+			err = ErrCPULimit
 			break
 		}
 		if rt.timeLimit {
@@ -615,6 +814,9 @@ main:
 		}
 
 		cmd = block.Code[ci]
+		if rt.trace != nil {
+			rt.trace.step(rt, cmd)
+		}
 		var bin interface{}
 		size := len(rt.stack)
 		if size < int(cmd.Cmd>>8) {
@@ -655,6 +857,90 @@ main:
 					break
 				}
 			}
+		case cmdFor:
+			val := rt.stack[len(rt.stack)-1]
+			rt.stack = rt.stack[:len(rt.stack)-1]
+			var (
+				count int
+				keys  []string
+				rv    reflect.Value
+			)
+			if m, ok := val.(*types.Map); ok {
+				keys = m.Keys()
+				count = len(keys)
+			} else if rv = reflect.ValueOf(val); rv.Kind() == reflect.Slice {
+				count = rv.Len()
+			} else {
+				err = fmt.Errorf(eForType, val)
+				break
+			}
+			for i := 0; i < count; i++ {
+				rt.cost -= CostIteration
+				if rt.cost <= 0 {
+					rt.vm.logger.WithFields(log.Fields{"type": consts.VMError}).Warn("paid CPU resource is over")
+					err = ErrCPULimit
+					break
+				}
+				if keys != nil {
+					item, _ := val.(*types.Map).Get(keys[i])
+					rt.stack = append(rt.stack, keys[i], item)
+				} else {
+					rt.stack = append(rt.stack, int64(i), rv.Index(i).Interface())
+				}
+				status, err = rt.RunCode(cmd.Value.(*Block))
+				if err != nil || status == statusReturn {
+					break
+				}
+				rt.stack = rt.stack[:len(rt.stack)-2]
+				if status == statusBreak {
+					status = statusNormal
+					break
+				}
+				status = statusNormal
+			}
+		case cmdSwitch:
+			switchOff = len(rt.stack)
+			switchDone = false
+		case cmdCase:
+			var found bool
+			if !switchDone {
+				for _, value := range rt.stack[switchOff:] {
+					if found, err = valueEqual(rt.stack[switchOff-1], value); found || err != nil {
+						break
+					}
+				}
+			}
+			rt.stack = rt.stack[:switchOff]
+			if found {
+				switchDone = true
+				status, err = rt.RunCode(cmd.Value.(*Block))
+				// break leaves the switch but not the enclosing loop
+				if status == statusBreak {
+					status = statusNormal
+				}
+			}
+		case cmdDefault:
+			if !switchDone {
+				status, err = rt.RunCode(cmd.Value.(*Block))
+				if status == statusBreak {
+					status = statusNormal
+				}
+			}
+		case cmdTry:
+			catch := ci+1 < len(block.Code) && block.Code[ci+1].Cmd == cmdCatch
+			status, tryErr, err = rt.runTry(cmd.Value.(*Block), catch)
+		case cmdCatch:
+			if tryErr != nil {
+				catchBlock := cmd.Value.(*Block)
+				if len(catchBlock.Vars) > 0 {
+					rt.stack = append(rt.stack, errorToMap(tryErr))
+				}
+				tryErr = nil
+				status, err = rt.RunCode(catchBlock)
+				if err == nil && status != statusReturn && len(catchBlock.Vars) > 0 {
+					rt.stack = rt.stack[:len(rt.stack)-1]
+				}
+			}
 		case cmdLabel:
 			labels = append(labels, ci)
 		case cmdContinue:
@@ -734,7 +1020,7 @@ main:
 					if cost > rt.cost {
 						rt.cost = 0
 						rt.vm.logger.WithFields(log.Fields{"type": consts.VMError}).Warning("paid CPU resource is over")
-						err = fmt.Errorf(`paid CPU resource is over`)
+						err = ErrCPULimit
 						break main
 					} else if cost == -1 {
 						rt.cost -= CostCall
@@ -1097,6 +1383,12 @@ main:
 					break main
 				}
 			}
+		case cmdMod:
+			bin, err = modValues(top[1], top[0])
+		case cmdBitAnd, cmdBitOr, cmdBitXor, cmdShiftL, cmdShiftR:
+			if tmpInt, err = bitValues(cmd.Cmd, top[1], top[0]); err == nil {
+				bin = tmpInt
+			}
 		case cmdAnd:
 			bin = valueToBool(top[1]) && valueToBool(top[0])
 		case cmdOr:
@@ -1283,7 +1575,7 @@ main:
 		stack := (*rt.extend)["stack"].([]interface{})
 		curContract := stack[len(stack)-1].(string)
 		if len(rt.errInfo.Name) > 0 && rt.errInfo.Name != `ExecContract` {
-			err = fmt.Errorf("%s [%s %s:%d]", err, rt.errInfo.Name, curContract, cmd.Line)
+			err = fmt.Errorf("%w [%s %s:%d]", err, rt.errInfo.Name, curContract, cmd.Line)
 			rt.errInfo.Name = ``
 		} else {
 			out := err.Error()
@@ -1297,7 +1589,7 @@ main:
 			} else {
 				out += ` [`
 			}
-			err = fmt.Errorf(`%s%s:%d]`, out, curContract, cmd.Line)
+			err = &lineError{text: fmt.Sprintf(`%s%s:%d]`, out, curContract, cmd.Line), err: err}
 		}
 		//rt.vm.logger.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("error in vm")
 	}
@@ -53,6 +53,11 @@ const (
 	CostContract = 100
 	// CostExtend is the cost of the extend function calling
 	CostExtend = 10
+	// CostIteration is the cost of the every iteration of for
+	CostIteration = 10
+
+	// LatestVersion is the version of block which enables all the key words of the language
+	LatestVersion = consts.BvStateRoot
 
 	// VMTypeSmart is smart vm type
 	VMTypeSmart VMType = 1
@@ -165,6 +170,7 @@ type OwnerInfo struct {
 	TableID  int64  `json:"tableid"`
 	WalletID int64  `json:"walletid"`
 	TokenID  int64  `json:"tokenid"`
+	Version  int    `json:"-"` // version of the block, it defines the available key words
 }
 
 // Block contains all information about compiled block {...} and its children
@@ -205,6 +211,19 @@ type Stacker interface {
 	PopStack(fn string)
 }
 
+// Savepointer saves the changes of the database before the try block, they are rolled back
+// if the block fails
+type Savepointer interface {
+	Savepoint() error
+	RollbackSavepoint() error
+	ReleaseSavepoint() error
+}
+
+// FuelCounter collects the fuel spent by the functions which make queries to DB
+type FuelCounter interface {
+	AddQueryFuel(fuel int64)
+}
+
 // ExecContract runs the name contract where txs contains the list of parameters and
 // params are the values of parameters
 func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interface{}, error) {
@@ -240,6 +259,21 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 		prevExtend[key] = item
 		delete(*rt.extend, key)
 	}
+	prevthis := (*rt.extend)[`this_contract`]
+	prevparent := (*rt.extend)[`parent`]
+	// the variables of the caller are restored even if the contract fails because the error can be caught by try
+	defer func() {
+		(*rt.extend)[`parent`] = prevparent
+		(*rt.extend)[`this_contract`] = prevthis
+		for key := range *rt.extend {
+			if !isSysVar(key) {
+				delete(*rt.extend, key)
+			}
+		}
+		for key, item := range prevExtend {
+			(*rt.extend)[key] = item
+		}
+	}()
 
 	var isSignature bool
 	if cblock.Info.(*ContractInfo).Tx != nil {
@@ -259,11 +293,9 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 	for i, ipar := range pars {
 		(*rt.extend)[ipar] = params[i]
 	}
-	prevthis := (*rt.extend)[`this_contract`]
 	_, nameContract := converter.ParseName(name)
 	(*rt.extend)[`this_contract`] = nameContract
 
-	prevparent := (*rt.extend)[`parent`]
 	parent := ``
 	for i := len(rt.blocks) - 1; i >= 0; i-- {
 		if rt.blocks[i].Block.Type == ObjFunc && rt.blocks[i].Block.Parent != nil &&
@@ -292,14 +324,12 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 		}
 	}
 
-	var (
-		stack Stacker
-		err   error
-	)
-	if stack, ok = (*rt.extend)["sc"].(Stacker); ok {
+	var err error
+	if stack, ok := (*rt.extend)["sc"].(Stacker); ok {
 		if err := stack.AppendStack(name); err != nil {
 			return nil, err
 		}
+		defer stack.PopStack(name)
 	}
 	if (*rt.extend)[`sc`] != nil && isSignature {
 		obj := rt.vm.Objects[`check_signature`]
@@ -312,6 +342,7 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 	for _, method := range []string{`conditions`, `action`} {
 		if block, ok := (*cblock).Objects[method]; ok && block.Type == ObjFunc {
 			rtemp := rt.vm.RunInit(rt.cost)
+			rtemp.trace = rt.trace
 			(*rt.extend)[`parent`] = parent
 			_, err = rtemp.Run(block.Value.(*Block), nil, rt.extend)
 			rt.cost = rtemp.cost
@@ -321,28 +352,10 @@ func ExecContract(rt *RunTime, name, txs string, params ...interface{}) (interfa
 			}
 		}
 	}
-	if stack != nil {
-		stack.PopStack(name)
-	}
 	if err != nil {
 		return nil, err
 	}
-	(*rt.extend)[`parent`] = prevparent
-	(*rt.extend)[`this_contract`] = prevthis
-
-	result := (*rt.extend)[`result`]
-	for key := range *rt.extend {
-		if isSysVar(key) {
-			continue
-		}
-		delete(*rt.extend, key)
-	}
-
-	for key, item := range prevExtend {
-		(*rt.extend)[key] = item
-	}
-
-	return result, nil
+	return (*rt.extend)[`result`], nil
 }
 
 // NewVM creates a new virtual machine
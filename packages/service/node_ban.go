@@ -116,8 +116,6 @@ func (nbs *NodesBanService) localBan(node syspar.HonorNode) {
 }
 
 func (nbs *NodesBanService) newBadBlock(producer syspar.HonorNode, blockId, blockTime int64, reason string) error {
-	nodePrivateKey := syspar.GetNodePrivKey()
-
 	var currentNode syspar.HonorNode
 	nbs.m.Lock()
 	for _, fn := range nbs.honorNodes {
@@ -152,7 +150,7 @@ func (nbs *NodesBanService) newBadBlock(producer syspar.HonorNode, blockId, bloc
 		},
 	}
 
-	txData, txHash, err := tx.NewInternalTransaction(sc, nodePrivateKey)
+	txData, txHash, err := tx.NewInternalTransaction(sc, syspar.GetNodeSigner())
 	if err != nil {
 		return err
 	}
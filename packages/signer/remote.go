@@ -0,0 +1,158 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package signer
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The protocol of remote signer over the unix socket. Every request is the byte of type and
+// the data with uint32 length, the response is the byte of status and the data with uint32 length.
+// The data of the error response is the text of error.
+const (
+	RequestPublicKey byte = 1
+	RequestSign      byte = 2
+
+	statusOK    byte = 0
+	statusError byte = 1
+
+	// maxMessageSize is the max size of the data of request or response
+	maxMessageSize = 1 << 20
+	// DefaultTimeout is the timeout of request to remote signer
+	DefaultTimeout = 5 * time.Second
+)
+
+func writeMessage(w io.Writer, kind byte, data []byte) error {
+	buf := make([]byte, 5, 5+len(data))
+	buf[0] = kind
+	binary.BigEndian.PutUint32(buf[1:], uint32(len(data)))
+	_, err := w.Write(append(buf, data...))
+	return err
+}
+
+func readMessage(r io.Reader) (byte, []byte, error) {
+	var head [5]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(head[1:])
+	if size > maxMessageSize {
+		return 0, nil, ErrRemoteResponse
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+	return head[0], data, nil
+}
+
+// RemoteSigner signs the data by the signer daemon which keeps the node key
+type RemoteSigner struct {
+	mu        sync.Mutex
+	network   string
+	address   string
+	timeout   time.Duration
+	conn      net.Conn
+	publicKey []byte
+}
+
+// NewRemoteSigner connects to the signer daemon at the unix socket and gets its public key
+func NewRemoteSigner(address string, timeout time.Duration) (*RemoteSigner, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	s := &RemoteSigner{network: "unix", address: address, timeout: timeout}
+	publicKey, err := s.request(RequestPublicKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(publicKey) != consts.PubkeySizeLength {
+		return nil, ErrRemoteResponse
+	}
+	s.publicKey = publicKey
+	return s, nil
+}
+
+func (s *RemoteSigner) request(kind byte, data []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the connection is reopened once if the signer daemon has been restarted
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+			if err != nil {
+				log.WithFields(log.Fields{"type": consts.ConnectionError, "error": err, "address": s.address}).Error("dialing to remote signer")
+				return nil, err
+			}
+			s.conn = conn
+		}
+		status, resp, err := s.exchange(kind, data)
+		if err == nil {
+			if status != statusOK {
+				return nil, errors.New(string(resp))
+			}
+			return resp, nil
+		}
+		s.conn.Close()
+		s.conn = nil
+		if attempt > 0 {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err, "address": s.address}).Error("requesting remote signer")
+			return nil, err
+		}
+	}
+}
+
+func (s *RemoteSigner) exchange(kind byte, data []byte) (byte, []byte, error) {
+	if err := s.conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return 0, nil, err
+	}
+	if err := writeMessage(s.conn, kind, data); err != nil {
+		return 0, nil, err
+	}
+	return readMessage(s.conn)
+}
+
+// PublicKey returns the public key of the signer daemon
+func (s *RemoteSigner) PublicKey() []byte {
+	return s.publicKey
+}
+
+// Sign returns the signature of the data, the signature is checked with the public key
+// to detect the signer daemon with other key
+func (s *RemoteSigner) Sign(data []byte) ([]byte, error) {
+	sign, err := s.request(RequestSign, data)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := crypto.CheckSign(s.publicKey, data, sign); err != nil || !ok {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err, "address": s.address}).Error("checking sign of remote signer")
+		return nil, ErrRemoteSign
+	}
+	return sign, nil
+}
+
+// Close closes the connection to the signer daemon
+func (s *RemoteSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,59 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package signer
+
+import (
+	"io"
+	"net"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Serve serves the requests of remote signers with the signer until the listener is closed,
+// it is used by the signer daemon which keeps the node key
+func Serve(l net.Listener, s Signer) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveConn(conn, s)
+	}
+}
+
+func serveConn(conn net.Conn, s Signer) {
+	defer conn.Close()
+	for {
+		kind, data, err := readMessage(conn)
+		if err != nil {
+			if err != io.EOF {
+				log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading signer request")
+			}
+			return
+		}
+		var resp []byte
+		switch kind {
+		case RequestPublicKey:
+			resp = s.PublicKey()
+		case RequestSign:
+			resp, err = s.Sign(data)
+		default:
+			err = ErrUnknownRequest
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("processing signer request")
+			err = writeMessage(conn, statusError, []byte(err.Error()))
+		} else {
+			err = writeMessage(conn, statusOK, resp)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing signer response")
+			return
+		}
+	}
+}
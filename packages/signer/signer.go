@@ -0,0 +1,72 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package signer
+
+import (
+	"errors"
+	"os"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+)
+
+var (
+	ErrEmptyKey       = errors.New("empty private key")
+	ErrRemoteSign     = errors.New("remote signer returned incorrect signature")
+	ErrRemoteResponse = errors.New("incorrect response of remote signer")
+	ErrUnknownRequest = errors.New("unknown request of signer")
+)
+
+// Signer signs the data by the node key. The data is hashed in the same way as crypto.Sign does it,
+// so the signatures can be checked by crypto.CheckSign with PublicKey.
+// The private key of Signer can be kept outside of the node process.
+type Signer interface {
+	// PublicKey returns the public key of the signer without the first 04 byte
+	PublicKey() []byte
+	// Sign returns the signature of the data
+	Sign(data []byte) ([]byte, error)
+}
+
+// LocalSigner keeps the private key in memory
+type LocalSigner struct {
+	privateKey []byte
+	publicKey  []byte
+}
+
+// NewLocalSigner returns the signer with the private key
+func NewLocalSigner(privateKey []byte) (*LocalSigner, error) {
+	if len(privateKey) == 0 {
+		return nil, ErrEmptyKey
+	}
+	publicKey, err := crypto.PrivateToPublic(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalSigner{privateKey: privateKey, publicKey: publicKey}, nil
+}
+
+// LoadLocalSigner returns the signer with the private key from the file,
+// the file contains the hex key or the keystore which is decrypted with the passphrase
+func LoadLocalSigner(filename, passphrase string) (*LocalSigner, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := crypto.DecodePrivateKey(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalSigner(privateKey)
+}
+
+// PublicKey returns the public key of the signer
+func (s *LocalSigner) PublicKey() []byte {
+	return s.publicKey
+}
+
+// Sign returns the signature of the data
+func (s *LocalSigner) Sign(data []byte) ([]byte, error) {
+	return crypto.Sign(s.privateKey, data)
+}
@@ -0,0 +1,65 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package signer
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	crypto.InitCurve("ECDSA")
+	priv, pub, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	local, err := NewLocalSigner(priv)
+	require.NoError(t, err)
+	assert.Equal(t, pub, local.PublicKey())
+
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	go Serve(l, local)
+
+	remote, err := NewRemoteSigner(socket, 0)
+	require.NoError(t, err)
+	defer remote.Close()
+	assert.Equal(t, pub, remote.PublicKey())
+
+	data := []byte("block")
+	sign, err := remote.Sign(data)
+	require.NoError(t, err)
+	ok, err := crypto.CheckSign(pub, data, sign)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// the error of the daemon is returned to the node
+	_, err = remote.request(0, nil)
+	assert.EqualError(t, err, ErrUnknownRequest.Error())
+
+	// the node reconnects after the restart of the daemon
+	l.Close()
+	remote.conn.Close()
+	l, err = net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer l.Close()
+	go Serve(l, local)
+	_, err = remote.Sign(data)
+	assert.NoError(t, err)
+
+	// the daemon with other key is detected by the sign
+	other, _, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	remote.publicKey, err = crypto.PrivateToPublic(other)
+	require.NoError(t, err)
+	_, err = remote.Sign(data)
+	assert.Equal(t, ErrRemoteSign, err)
+}
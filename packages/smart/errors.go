@@ -33,6 +33,10 @@ const (
 	eEcoKeyDisable       = `%s disable in ecosystem %d`
 	eEcoFuelRate         = `fuel rate must be greater than 0 or empty in ecosystem %d`
 	eEcoCurrentBalance   = `current balance is not enough in ecosystem %d, at least [%s] difference`
+	eEventName           = `Event name %s must only contain latin, digit and '_', '-' characters`
+	eEventData           = `The size of event data exceeds %d bytes`
+	eMultisigThreshold   = `%d signers are less than the threshold %d of multi-signature account`
+	eMultisigSigner      = `%s isn't the signer of multi-signature account`
 )
 
 var (
@@ -75,6 +79,10 @@ var (
 	errNotValidUTF        = errors.New(`result is not valid utf-8 string`)
 	errFloat              = errors.New(`incorrect float value`)
 	errFloatResult        = errors.New(`incorrect float result`)
+	errEventBlock         = errors.New(`it is impossible to emit event when Block is undefined`)
+	errConcurrent         = errors.New(`contracts, tables and system parameters cannot be changed by the simulation`)
+	errMultisigAccount    = errors.New(`signers can only be used by multi-signature account`)
+	errMultisigExists     = errors.New(`multi-signature account already exists`)
 
 	errMaxPrice = fmt.Errorf(`price value is more than %d`, MaxPrice)
 )
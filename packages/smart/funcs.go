@@ -51,6 +51,8 @@ const (
 	historyLimit              = 250
 	firstEcosystemID          = 1
 	contractTxType            = 128
+	maxEventName              = 255
+	maxEventData              = 16384
 )
 
 var (
@@ -59,8 +61,14 @@ var (
 
 type ThrowError struct {
 	Type    string `json:"type"`
-	Code    string `json:"id"`
 	ErrText string `json:"error"`
+	Code    string `json:"id"`
+}
+
+// tryPoint is the state of the contract before the try block
+type tryPoint struct {
+	rollbackTx int
+	flush      int
 }
 
 var BOM = []byte{0xEF, 0xBB, 0xBF}
@@ -134,6 +142,12 @@ type SmartContract struct {
 	RollBackTx    []*model.RollbackTx
 	multiPays     multiPays
 	taxes         bool
+	Trace         *script.Trace
+	Simulate      bool  // The contract is executed without checking the signature
+	Concurrent    bool  // The contract is executed concurrently with the blocks, it can't change the state of the node
+	Penalty       bool  // The contract failed and the penalty was paid
+	QueryFuel     int64 // The fuel of DB queries
+	tryPoints     []tryPoint
 }
 
 var (
@@ -158,6 +172,11 @@ func (sc *SmartContract) AppendStack(fn string) error {
 	return nil
 }
 
+// AddQueryFuel adds the fuel of DB query
+func (sc *SmartContract) AddQueryFuel(fuel int64) {
+	sc.QueryFuel += fuel
+}
+
 func (sc *SmartContract) PopStack(fn string) {
 	if sc.isAllowStack(fn) {
 		cont := sc.TxContract
@@ -168,6 +187,54 @@ func (sc *SmartContract) PopStack(fn string) {
 	}
 }
 
+// Savepoint creates the savepoint of the database transaction before the try block
+func (sc *SmartContract) Savepoint() error {
+	if err := sc.DbTransaction.Savepoint(consts.SetSavePointMarkTry(len(sc.tryPoints))); err != nil {
+		return logErrorDB(err, "creating savepoint of try")
+	}
+	sc.tryPoints = append(sc.tryPoints, tryPoint{rollbackTx: len(sc.RollBackTx), flush: len(sc.FlushRollback)})
+	return nil
+}
+
+// RollbackSavepoint rolls back the changes of the failed try block including the rollback records
+// and the contracts which were flushed to the virtual machine
+func (sc *SmartContract) RollbackSavepoint() error {
+	point := sc.tryPoints[len(sc.tryPoints)-1]
+	if err := sc.DbTransaction.RollbackSavepoint(consts.SetSavePointMarkTry(len(sc.tryPoints) - 1)); err != nil {
+		return logErrorDB(err, "rolling back savepoint of try")
+	}
+	sc.RollBackTx = sc.RollBackTx[:point.rollbackTx]
+	rollbackFlush(sc.VM, sc.FlushRollback[point.flush:])
+	sc.FlushRollback = sc.FlushRollback[:point.flush]
+	return sc.ReleaseSavepoint()
+}
+
+// ReleaseSavepoint releases the savepoint of the try block
+func (sc *SmartContract) ReleaseSavepoint() error {
+	depth := len(sc.tryPoints) - 1
+	sc.tryPoints = sc.tryPoints[:depth]
+	if err := sc.DbTransaction.ReleaseSavepoint(depth, consts.SavePointMarkTry); err != nil {
+		return logErrorDB(err, "releasing savepoint of try")
+	}
+	return nil
+}
+
+// rollbackFlush restores the objects of the virtual machine which were changed by flushing the contracts
+func rollbackFlush(vm *script.VM, flush []FlushInfo) {
+	for i := len(flush) - 1; i >= 0; i-- {
+		finfo := flush[i]
+		if finfo.Prev == nil {
+			if finfo.ID == uint32(len(vm.Children)-1) {
+				vm.Children = vm.Children[:len(vm.Children)-1]
+				delete(vm.Objects, finfo.Name)
+			}
+			continue
+		}
+		vm.Children[finfo.ID] = finfo.Prev
+		vm.Objects[finfo.Name] = finfo.Info
+	}
+}
+
 func (sc *SmartContract) isAllowStack(fn string) bool {
 	// Stack contains only contracts
 	c := VMGetContract(sc.VM, fn, uint32(sc.TxSmart.EcosystemID))
@@ -323,6 +390,8 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 		"DBCount":          DBCount,
 		"MathMod":          MathMod,
 		"CreateView":       CreateView,
+		"EmitEvent":        EmitEvent,
+		"NewMultisigKey":   NewMultisigKey,
 	}
 
 	switch vt {
@@ -382,6 +451,8 @@ func EmbedFuncs(vm *script.VM, vt script.VMType) {
 			"DeleteOBS":        {},
 			"DelColumn":        {},
 			"DelTable":         {},
+			"EmitEvent":        {},
+			"NewMultisigKey":   {},
 		},
 	})
 }
@@ -406,7 +477,8 @@ func CompileContract(sc *SmartContract, code string, state, id, token int64) (in
 	if err := validateAccess(sc, "CompileContract"); err != nil {
 		return nil, err
 	}
-	return VMCompileBlock(sc.VM, code, &script.OwnerInfo{StateID: uint32(state), WalletID: id, TokenID: token})
+	return VMCompileBlock(sc.VM, code, &script.OwnerInfo{StateID: uint32(state),
+		WalletID: id, TokenID: token, Version: sc.blockVersion()})
 }
 
 // ContractAccess checks whether the name of the executable contract matches one of the names listed in the parameters.
@@ -700,7 +772,7 @@ func CreateView(sc *SmartContract, vname, columns, where string, applicationID i
 		return logErrorDB(err, "insert table info")
 	}
 	if !sc.OBS {
-		if err = syspar.SysTableColType(sc.DbTransaction); err != nil {
+		if err = sc.updateColumnTypes(); err != nil {
 			return logErrorDB(err, "updating sys table col type")
 		}
 		if err = SysRollback(sc, SysRollData{Type: "NewView", TableName: viewName}); err != nil {
@@ -911,7 +983,7 @@ func CreateTable(sc *SmartContract, name, columns, permissions string, applicati
 		return logErrorDB(err, "insert table info")
 	}
 	if !sc.OBS {
-		if err = syspar.SysTableColType(sc.DbTransaction); err != nil {
+		if err = sc.updateColumnTypes(); err != nil {
 			return logErrorDB(err, "updating sys table col type")
 		}
 		if err = SysRollback(sc, SysRollData{Type: "NewTable", TableName: tableName}); err != nil {
@@ -974,18 +1046,14 @@ func DBInsert(sc *SmartContract, tblname string, values *types.Map) (qcost int64
 }
 
 // PrepareColumns replaces jsonb fields -> in the list of columns for db selecting
-// For example, name,doc->title => name,doc::jsonb->>'title' as "doc.title"
+// For example, name,doc->title => name,doc::jsonb->>'title' as "doc.title" in PostgreSQL
 func PrepareColumns(columns []string) string {
 	colList := make([]string, 0)
 	for _, icol := range columns {
 		if strings.Contains(icol, `->`) {
 			colfield := strings.Split(icol, `->`)
-			if len(colfield) == 2 {
-				icol = fmt.Sprintf(`%s::jsonb->>'%s' as "%[1]s.%[2]s"`, colfield[0], colfield[1])
-			} else {
-				icol = fmt.Sprintf(`%s::jsonb#>>'{%s}' as "%[1]s.%[3]s"`, colfield[0],
-					strings.Join(colfield[1:], `,`), strings.Join(colfield[1:], `.`))
-			}
+			icol = fmt.Sprintf(`%s as "%s"`, model.Storage().JSONText(colfield[0], colfield[1:]...),
+				strings.Join(colfield, `.`))
 		} else if !strings.ContainsAny(icol, `:*>"`) {
 			icol = `"` + icol + `"`
 		}
@@ -1270,6 +1338,9 @@ func FlushContract(sc *SmartContract, iroot interface{}, id int64) error {
 	if err := validateAccess(sc, "FlushContract"); err != nil {
 		return err
 	}
+	if err := sc.checkNodeState(); err != nil {
+		return err
+	}
 	root := iroot.(*script.Block)
 	if id != 0 {
 		if len(root.Children) != 1 || root.Children[0].Type != script.ObjContract {
@@ -1652,7 +1723,7 @@ func CreateColumn(sc *SmartContract, tableName, name, colType, permissions strin
 		return err
 	}
 	if !sc.OBS {
-		if err := syspar.SysTableColType(sc.DbTransaction); err != nil {
+		if err := sc.updateColumnTypes(); err != nil {
 			return err
 		}
 		return SysRollback(sc, SysRollData{Type: "NewColumn", TableName: tblname, Data: name})
@@ -2382,7 +2453,7 @@ func DelColumn(sc *SmartContract, tableName, name string) (err error) {
 		return err
 	}
 	if !sc.OBS {
-		if err = syspar.SysTableColType(sc.DbTransaction); err != nil {
+		if err = sc.updateColumnTypes(); err != nil {
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating sys table col type")
 			return err
 		}
@@ -2458,7 +2529,7 @@ func DelTable(sc *SmartContract, tableName string) (err error) {
 		if err != nil {
 			return err
 		}
-		if err = syspar.SysTableColType(sc.DbTransaction); err != nil {
+		if err = sc.updateColumnTypes(); err != nil {
 			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating sys table col type")
 			return err
 		}
@@ -2501,8 +2572,13 @@ func FormatMoney(sc *SmartContract, exp string, digit int64) (string, error) {
 	return exp, nil
 }
 
+// Error returns the error in JSON format, so it's passed to the response with the code
 func (throw *ThrowError) Error() string {
-	return throw.ErrText
+	out, err := json.Marshal(throw)
+	if err != nil {
+		return throw.ErrText
+	}
+	return string(out)
 }
 
 func Throw(code, errText string) error {
@@ -2515,6 +2591,99 @@ func Throw(code, errText string) error {
 	return &ThrowError{Code: code, ErrText: errText, Type: `exception`}
 }
 
+// EmitEvent saves the event of the executing contract. The event is deleted
+// together with the other changes when the transaction is rolled back
+func EmitEvent(sc *SmartContract, name string, data *types.Map) error {
+	if len(name) == 0 || len(name) > maxEventName || !converter.IsLatin(name) {
+		return fmt.Errorf(eEventName, name)
+	}
+	if sc.BlockData == nil {
+		return logErrorShort(errEventBlock, consts.EmptyObject)
+	}
+	if data == nil {
+		data = types.NewMap()
+	}
+	out, err := JSONEncode(data)
+	if err != nil {
+		return err
+	}
+	if len(out) > maxEventData {
+		return fmt.Errorf(eEventData, maxEventData)
+	}
+
+	contract := sc.TxContract.Name
+	if len(sc.TxContract.StackCont) > 0 {
+		if cname, ok := sc.TxContract.StackCont[len(sc.TxContract.StackCont)-1].(string); ok {
+			contract = cname
+		}
+	}
+	event := &model.ContractEvent{
+		BlockID:   sc.BlockData.BlockID,
+		TxHash:    sc.TxHash,
+		Ecosystem: sc.TxSmart.EcosystemID,
+		Contract:  contract,
+		Name:      name,
+		Data:      out,
+		Time:      sc.BlockData.Time,
+	}
+	if err = event.Create(sc.DbTransaction); err != nil {
+		return logErrorDB(err, "creating contract event")
+	}
+
+	rollbackTx := &model.RollbackTx{
+		BlockID:   sc.BlockData.BlockID,
+		TxHash:    sc.TxHash,
+		NameTable: event.TableName(),
+		TableID:   converter.Int64ToStr(event.ID),
+	}
+	sc.RollBackTx = append(sc.RollBackTx, rollbackTx)
+	if err = rollbackTx.Create(sc.DbTransaction); err != nil {
+		return logErrorDB(err, "creating rollback tx")
+	}
+	return nil
+}
+
+// NewMultisigKey creates the multi-signature account in the current ecosystem. The transactions of
+// the account must be signed by threshold signers from keys. It returns the id of account.
+func NewMultisigKey(sc *SmartContract, threshold int64, keys []interface{}) (int64, error) {
+	pubs := make([][]byte, 0, len(keys))
+	for _, item := range keys {
+		pub, err := hex.DecodeString(fmt.Sprint(item))
+		if err != nil {
+			return 0, logError(err, consts.ConversionError, "decoding public key from hex")
+		}
+		pubs = append(pubs, pub)
+	}
+	sorted, err := crypto.SortPublicKeys(threshold, pubs)
+	if err != nil {
+		return 0, logErrorShort(err, consts.InvalidObject)
+	}
+	id, err := crypto.MultisigAddress(threshold, sorted)
+	if err != nil {
+		return 0, logErrorShort(err, consts.InvalidObject)
+	}
+	key := &model.Key{}
+	found, err := key.SetTablePrefix(sc.TxSmart.EcosystemID).Get(sc.DbTransaction, id)
+	if err != nil {
+		return 0, logErrorDB(err, "getting multi-signature key")
+	}
+	if found {
+		return 0, logErrorShort(errMultisigExists, consts.DuplicateObject)
+	}
+	_, _, err = DBInsert(sc, "@1keys", types.LoadMap(map[string]interface{}{
+		"id":        id,
+		"account":   IDToAddress(id),
+		"pub":       hex.EncodeToString(crypto.JoinPublicKeys(sorted)),
+		"multi":     threshold,
+		"amount":    0,
+		"ecosystem": sc.TxSmart.EcosystemID,
+	}))
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
 func PubToHex(in interface{}) (ret string) {
 	switch v := in.(type) {
 	case string:
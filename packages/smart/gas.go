@@ -140,6 +140,47 @@ func (sc *SmartContract) payContract(errNeedPay bool) error {
 	return nil
 }
 
+// FuelPayment is the payment for the execution of the contract in the token ecosystem
+type FuelPayment struct {
+	TokenEcosystem int64           `json:"token_ecosystem"`
+	FromID         int64           `json:"from_id"`
+	FuelRate       decimal.Decimal `json:"fuel_rate"`
+	VM             decimal.Decimal `json:"vm"`
+	Query          decimal.Decimal `json:"query"`
+	Size           decimal.Decimal `json:"size"`
+	Storage        decimal.Decimal `json:"storage"`
+	NewElement     decimal.Decimal `json:"new_element"`
+	Expedite       decimal.Decimal `json:"expedite"`
+	Total          decimal.Decimal `json:"total"`
+}
+
+// FuelPayments returns the payments which were charged by payContract
+func (sc *SmartContract) FuelPayments() []FuelPayment {
+	sizeFuel := sc.sizeFuel()
+	vmFuel := sc.TxFuel - sc.QueryFuel - sizeFuel
+	payments := make([]FuelPayment, 0, len(sc.multiPays))
+	for _, pay := range sc.multiPays {
+		p := FuelPayment{
+			TokenEcosystem: pay.tokenEco,
+			FromID:         pay.fromID,
+			FuelRate:       pay.fuelRate,
+			VM:             decimal.New(vmFuel, 0).Mul(pay.fuelRate),
+			Query:          decimal.New(sc.QueryFuel, 0).Mul(pay.fuelRate),
+			Size:           decimal.New(sizeFuel, 0).Mul(pay.fuelRate),
+			Storage:        pay.storageFuel,
+		}
+		if !sc.Penalty {
+			p.NewElement = pay.newElementFuel
+		}
+		if len(sc.TxSmart.Expedite) > 0 {
+			p.Expedite = StringToAmount(sc.TxSmart.Expedite)
+		}
+		p.Total = sc.TxUsedCost.Mul(pay.fuelRate).Add(p.Storage).Add(p.NewElement).Add(p.Expedite)
+		payments = append(payments, p)
+	}
+	return payments
+}
+
 func (sc *SmartContract) needPayment() bool {
 	return sc.TxSmart.EcosystemID > 0 && !sc.OBS && !syspar.IsPrivateBlockchain() && sc.payFreeContract()
 }
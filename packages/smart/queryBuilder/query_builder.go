@@ -15,6 +15,7 @@ import (
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
 	"github.com/IBAX-io/go-ibax/packages/types"
 
 	log "github.com/sirupsen/logrus"
@@ -263,7 +264,7 @@ func (b *SQLQueryBuilder) GetSQLUpdateExpr(logData map[string]string) (string, e
 		}
 
 		insFields = append(insFields, colname)
-		insValues = append(insValues, fmt.Sprintf(`'%s'::jsonb`, string(out)))
+		insValues = append(insValues, model.Storage().JSONLiteral(string(out)))
 	}
 
 	if !isID {
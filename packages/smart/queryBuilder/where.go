@@ -11,44 +11,22 @@ import (
 	"strings"
 
 	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
 	"github.com/IBAX-io/go-ibax/packages/types"
 )
 
 var (
 	errWhereFalse = errors.New(`false result`)
+
+	jsonFieldRE = regexp.MustCompile(`[\w\d_]+(->[\w\d_]+)+`)
 )
 
+// PrepareWhere replaces jsonb fields -> with the expressions of the storage backend
 func PrepareWhere(where string) string {
-	whereSlice := regexp.MustCompile(`->([\w\d_]+)`).FindAllStringSubmatchIndex(where, -1)
-	startWhere := 0
-	out := ``
-	for i := 0; i < len(whereSlice); i++ {
-		slice := whereSlice[i]
-		if len(slice) != 4 {
-			continue
-		}
-		if i < len(whereSlice)-1 && slice[1] == whereSlice[i+1][0] {
-			colsWhere := []string{where[slice[2]:slice[3]]}
-			from := slice[0]
-			for i < len(whereSlice)-1 && slice[1] == whereSlice[i+1][0] {
-				i++
-				slice = whereSlice[i]
-				if len(slice) != 4 {
-					break
-				}
-				colsWhere = append(colsWhere, where[slice[2]:slice[3]])
-			}
-			out += fmt.Sprintf(`%s::jsonb#>>'{%s}'`, where[startWhere:from], strings.Join(colsWhere, `,`))
-			startWhere = slice[3]
-		} else {
-			out += fmt.Sprintf(`%s->>'%s'`, where[startWhere:slice[0]], where[slice[2]:slice[3]])
-			startWhere = slice[3]
-		}
-	}
-	if len(out) > 0 {
-		return out + where[startWhere:]
-	}
-	return where
+	return jsonFieldRE.ReplaceAllStringFunc(where, func(field string) string {
+		path := strings.Split(field, `->`)
+		return model.Storage().JSONText(path[0], path[1:]...)
+	})
 }
 
 func GetWhere(inWhere *types.Map) (string, error) {
@@ -0,0 +1,214 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package smart
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/notificator"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	"github.com/shopspring/decimal"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// SimulateRow is the row of the table which would be written by the contract
+type SimulateRow struct {
+	Table  string            `json:"table"`
+	ID     string            `json:"id"`
+	Action string            `json:"action"`
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// SimulateFuel is the fuel which would be spent by the contract
+type SimulateFuel struct {
+	Total    int64         `json:"total"`
+	VM       int64         `json:"vm"`
+	Query    int64         `json:"query"`
+	Size     int64         `json:"size"`
+	Payments []FuelPayment `json:"payments"`
+}
+
+// SimulateResult is the result of the dry-run execution of the contract
+type SimulateResult struct {
+	Result string        `json:"result"`
+	Error  string        `json:"error,omitempty"`
+	Fuel   SimulateFuel  `json:"fuel"`
+	Rows   []SimulateRow `json:"rows"`
+}
+
+// SimulateContract executes the contract on behalf of keyID without the signature.
+// The contract is executed concurrently with the blocks in the snapshot transaction
+// which is always rolled back, so it can't change the state of the node.
+func SimulateContract(name string, ecosystemID, keyID int64, params map[string]interface{},
+	expedite string) (*SimulateResult, error) {
+	contract := GetContract(name, uint32(ecosystemID))
+	if contract == nil {
+		return nil, fmt.Errorf(eUnknownContract, name)
+	}
+	cinfo := contract.Info()
+
+	txData := make(map[string]interface{})
+	if cinfo.Tx != nil {
+		var err error
+		if txData, err = FillTxData(*cinfo.Tx, params); err != nil {
+			return nil, err
+		}
+	}
+
+	ib := &model.InfoBlock{}
+	if _, err := ib.Get(); err != nil {
+		return nil, logErrorDB(err, "getting info block")
+	}
+	prevBlock := &utils.BlockData{
+		BlockID:       ib.BlockID,
+		Time:          ib.Time,
+		EcosystemID:   ib.EcosystemID,
+		KeyID:         ib.KeyID,
+		NodePosition:  converter.StrToInt64(ib.NodePosition),
+		Hash:          ib.Hash,
+		RollbacksHash: ib.RollbacksHash,
+		StateRoot:     ib.StateRoot,
+	}
+	blockData := &utils.BlockData{
+		BlockID:      ib.BlockID + 1,
+		Time:         time.Now().Unix(),
+		KeyID:        conf.Config.KeyID,
+		NodePosition: prevBlock.NodePosition,
+		Version:      converter.StrToInt(ib.CurrentVersion),
+	}
+
+	smartTx := tx.SmartContract{
+		Header: tx.Header{
+			ID:          int(cinfo.ID),
+			Time:        blockData.Time,
+			EcosystemID: ecosystemID,
+			KeyID:       keyID,
+			NetworkID:   conf.Config.NetworkID,
+		},
+		Params:   params,
+		Expedite: expedite,
+	}
+	payload, err := msgpack.Marshal(&smartTx)
+	if err != nil {
+		return nil, err
+	}
+	txHash := crypto.DoubleHash(payload)
+
+	dbTransaction, err := model.StartSnapshotTransaction()
+	if err != nil {
+		return nil, logErrorDB(err, "starting transaction")
+	}
+	defer dbTransaction.Rollback()
+
+	if err = dbTransaction.Savepoint(consts.SetSavePointMarkBlock(0)); err != nil {
+		return nil, logErrorDB(err, "using savepoint")
+	}
+
+	sc := SmartContract{
+		Rollback:      true,
+		Simulate:      true,
+		Concurrent:    true,
+		VM:            GetVM(),
+		TxSmart:       smartTx,
+		TxData:        txData,
+		TxContract:    contract,
+		TxUsedCost:    decimal.New(0, 0),
+		BlockData:     blockData,
+		PreBlockData:  prevBlock,
+		TxHash:        txHash,
+		TxSize:        int64(len(payload)),
+		DbTransaction: dbTransaction,
+		Rand:          utils.NewRand(blockData.Time).BytesSeed(txHash),
+		Notifications: notificator.NewQueue(),
+		RollBackTx:    make([]*model.RollbackTx, 0),
+	}
+	result := &SimulateResult{}
+	out, err := sc.CallContract(0)
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+	case sc.Penalty:
+		result.Error = out
+	default:
+		result.Result = out
+	}
+
+	result.Fuel = SimulateFuel{
+		Total:    sc.TxFuel,
+		Query:    sc.QueryFuel,
+		Size:     sc.sizeFuel(),
+		Payments: sc.FuelPayments(),
+	}
+	if sc.TxFuel > 0 {
+		result.Fuel.VM = sc.TxFuel - sc.QueryFuel - result.Fuel.Size
+	}
+	if result.Rows, err = sc.writtenRows(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkNodeState returns the error if the contract is executed concurrently with the blocks.
+// Such contract can't change the contracts of the virtual machine and the cached system parameters.
+func (sc *SmartContract) checkNodeState() error {
+	if sc.Concurrent {
+		return errConcurrent
+	}
+	return nil
+}
+
+// updateColumnTypes reloads the cached types of the columns after the table has been changed
+func (sc *SmartContract) updateColumnTypes() error {
+	if err := sc.checkNodeState(); err != nil {
+		return err
+	}
+	return syspar.SysTableColType(sc.DbTransaction)
+}
+
+// writtenRows returns the current values of the rows which were changed by the contract
+func (sc *SmartContract) writtenRows() ([]SimulateRow, error) {
+	rows := make([]SimulateRow, 0, len(sc.RollBackTx))
+	found := make(map[string]bool)
+	for _, rtx := range sc.RollBackTx {
+		key := rtx.NameTable + `:` + rtx.TableID
+		if found[key] {
+			continue
+		}
+		found[key] = true
+
+		row := SimulateRow{Table: rtx.NameTable, ID: rtx.TableID, Action: `update`}
+		if rtx.NameTable == SysName {
+			row.Action = `system`
+			rows = append(rows, row)
+			continue
+		}
+		if len(rtx.Data) == 0 {
+			row.Action = `insert`
+		}
+		query := fmt.Sprintf(`SELECT * FROM "%s" WHERE id = ?`, rtx.NameTable)
+		args := []interface{}{rtx.TableID}
+		if ids := strings.Split(rtx.TableID, `,`); len(ids) == 2 {
+			query += ` AND ecosystem = ?`
+			args = []interface{}{ids[0], ids[1]}
+		}
+		values, err := model.GetOneRowTransaction(sc.DbTransaction, query, args...).String()
+		if err != nil {
+			return nil, logErrorDB(err, "getting written row")
+		}
+		row.Values = values
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
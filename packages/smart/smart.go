@@ -6,6 +6,7 @@
 package smart
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -14,8 +15,6 @@ import (
 	"strings"
 	"unicode/utf8"
 
-	"github.com/pkg/errors"
-
 	"github.com/IBAX-io/go-ibax/packages/conf"
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
@@ -116,6 +115,11 @@ func init() {
 	smartVM = newVM()
 }
 
+// ResetVM replaces the virtual machine with the new one without functions and contracts
+func ResetVM() {
+	smartVM = newVM()
+}
+
 // GetVM is returning smart vm
 func GetAllContracts() (string, error) {
 	var ret []string
@@ -130,29 +134,58 @@ func GetAllContracts() (string, error) {
 }
 
 var SmartObjects map[string]*script.ObjInfo
-var Chid uint32
+var SmartChildren []*script.Block
+
+// smartValues and smartOwners keep the values which are changed in place. The compiler replaces
+// the values of edited functions, activation and SetContractWallet change the owners of contracts.
+var (
+	smartValues map[*script.ObjInfo]script.ObjInfo
+	smartOwners map[*script.OwnerInfo]script.OwnerInfo
+)
 
+// SavepointSmartVMObjects saves the objects and the contracts of the virtual machine
 func SavepointSmartVMObjects() {
 	SmartObjects = make(map[string]*script.ObjInfo)
+	smartValues = make(map[*script.ObjInfo]script.ObjInfo)
 	for k, v := range smartVM.Objects {
 		SmartObjects[k] = v
+		smartValues[v] = *v
+	}
+	SmartChildren = make([]*script.Block, len(smartVM.Children))
+	copy(SmartChildren, smartVM.Children)
+	smartOwners = make(map[*script.OwnerInfo]script.OwnerInfo)
+	for _, item := range smartVM.Children {
+		if item != nil && item.Type == script.ObjContract {
+			if owner := item.Info.(*script.ContractInfo).Owner; owner != nil {
+				smartOwners[owner] = *owner
+			}
+		}
 	}
-	Chid = uint32(len(smartVM.Children))
 }
 
+// RollbackSmartVMObjects restores the virtual machine to the last savepoint. The contracts which
+// have been edited are replaced in place by the compiler so all children are restored.
 func RollbackSmartVMObjects() {
 	smartVM.Objects = make(map[string]*script.ObjInfo)
 	for k, v := range SmartObjects {
 		smartVM.Objects[k] = v
 	}
 
-	smartVM.Children = smartVM.Children[:Chid]
-	SmartObjects = nil
+	smartVM.Children = SmartChildren
+	for obj, saved := range smartValues {
+		*obj = saved
+	}
+	for owner, saved := range smartOwners {
+		*owner = saved
+	}
+	ReleaseSmartVMObjects()
 }
 
 func ReleaseSmartVMObjects() {
 	SmartObjects = nil
-	Chid = 0
+	SmartChildren = nil
+	smartValues = nil
+	smartOwners = nil
 }
 
 // GetVM is returning smart vm
@@ -237,7 +270,15 @@ func VMRun(vm *script.VM, block *script.Block, params []interface{}, extend *map
 		cost = syspar.GetMaxCost()
 	}
 	rt := vm.RunInit(cost)
+	sc, _ := (*extend)[`sc`].(*SmartContract)
+	if sc != nil && sc.Trace != nil {
+		rt.SetTrace(sc.Trace)
+		sc.Trace.Start()
+	}
 	ret, err = rt.Run(block, params, extend)
+	if sc != nil && sc.Trace != nil {
+		sc.Trace.Finish(rt.Cost(), err)
+	}
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.VMError, "error": err, "original_contract": (*extend)[`original_contract`], "this_contract": (*extend)[`this_contract`], "ecosystem_id": (*extend)[`ecosystem_id`]}).Error("running block in smart vm")
 		return nil, err
@@ -383,6 +424,9 @@ func SetContractWallet(sc *SmartContract, tblid, state int64, wallet int64) erro
 	if err := validateAccess(sc, "SetContractWallet"); err != nil {
 		return err
 	}
+	if err := sc.checkNodeState(); err != nil {
+		return err
+	}
 	for i, item := range smartVM.Block.Children {
 		if item != nil && item.Type == script.ObjContract {
 			cinfo := item.Info.(*script.ContractInfo)
@@ -417,7 +461,7 @@ func (contract *Contract) GetFunc(name string) *script.Block {
 	return nil
 }
 
-func loadContractList(list []model.Contract) error {
+func loadContractList(list []model.Contract, version int) error {
 	if smartVM.ShiftContract == 0 {
 		LoadSysFuncs(smartVM, 1)
 		smartVM.ShiftContract = int64(len(smartVM.Children) - 1)
@@ -434,6 +478,7 @@ func loadContractList(list []model.Contract) error {
 			TableID:  item.ID,
 			WalletID: item.WalletID,
 			TokenID:  item.TokenID,
+			Version:  version,
 		}
 		if err = Compile(item.Value, &owner); err != nil {
 			logErrorValue(err, consts.EvalError, "Load Contract", strings.Join(clist, `,`))
@@ -462,6 +507,12 @@ func LoadContracts() error {
 	if err != nil {
 		return logErrorDB(err, "getting count of contracts")
 	}
+	infoBlock := &model.InfoBlock{}
+	if _, err = infoBlock.Get(); err != nil {
+		return logErrorDB(err, "getting the last block")
+	}
+	// the contracts are compiled for the next block
+	version := syspar.GetBlockVersion(infoBlock.BlockID + 1)
 
 	defer ExternOff()
 	var offset int
@@ -471,7 +522,7 @@ func LoadContracts() error {
 		if err != nil {
 			return logErrorDB(err, "getting list of contracts")
 		}
-		if err = loadContractList(list); err != nil {
+		if err = loadContractList(list, version); err != nil {
 			return err
 		}
 	}
@@ -568,8 +619,8 @@ func CurrentKeyFromAccount(account string) int {
 	return vmCompile(vm, code, &script.OwnerInfo{StateID: uint32(state)})
 }
 
-// LoadContract reads and compiles contract of new state
-func LoadContract(transaction *model.DbTransaction, ecosystem int64) (err error) {
+// LoadContract reads and compiles contract of new state, version is the version of the block
+func LoadContract(transaction *model.DbTransaction, ecosystem int64, version int) (err error) {
 
 	contract := &model.Contract{}
 
@@ -578,12 +629,20 @@ func LoadContract(transaction *model.DbTransaction, ecosystem int64) (err error)
 	if err != nil {
 		return logErrorDB(err, "selecting all contracts from ecosystem")
 	}
-	if err = loadContractList(list); err != nil {
+	if err = loadContractList(list, version); err != nil {
 		return err
 	}
 	return
 }
 
+// blockVersion returns the version of the block which defines the key words of the compiled contracts
+func (sc *SmartContract) blockVersion() int {
+	if sc.BlockData == nil {
+		return consts.BlockVersion
+	}
+	return syspar.GetBlockVersion(sc.BlockData.BlockID)
+}
+
 func (sc *SmartContract) getExtend() *map[string]interface{} {
 	var block, blockTime, blockKeyID, blockNodePosition int64
 	var perBlockHash string
@@ -873,6 +932,11 @@ func (sc *SmartContract) EvalIf(conditions string) (bool, error) {
 	return VMEvalIf(sc.VM, conditions, uint32(sc.TxSmart.EcosystemID), sc.getExtend())
 }
 
+// sizeFuel returns the fuel which is charged for the size of the transaction
+func (sc *SmartContract) sizeFuel() int64 {
+	return syspar.GetSizeFuel() * sc.TxSize / 1024
+}
+
 // GetContractLimit returns the default maximal cost of contract
 func (sc *SmartContract) GetContractLimit() (ret int64) {
 	// default maximum cost of F
@@ -927,15 +991,7 @@ func (sc *SmartContract) CallContract(point int) (string, error) {
 	retError := func(err error) (string, error) {
 		eText := err.Error()
 		if !strings.HasPrefix(eText, `{`) && err != script.ErrVMTimeLimit {
-			if throw, ok := err.(*ThrowError); ok {
-				out, errThrow := json.Marshal(throw)
-				if errThrow != nil {
-					out = []byte(`{"type": "panic", "error": "marshalling throw"}`)
-				}
-				err = errors.New(string(out))
-			} else {
-				err = script.SetVMError(`panic`, eText)
-			}
+			err = script.SetVMError(`panic`, eText)
 		}
 		return ``, err
 	}
@@ -963,8 +1019,7 @@ func (sc *SmartContract) CallContract(point int) (string, error) {
 
 	ctrctExtend := *sc.TxContract.Extend
 	before := ctrctExtend[`txcost`].(int64)
-	txSizeFuel := syspar.GetSizeFuel() * sc.TxSize / 1024
-	ctrctExtend[`txcost`] = ctrctExtend[`txcost`].(int64) - txSizeFuel
+	ctrctExtend[`txcost`] = ctrctExtend[`txcost`].(int64) - sc.sizeFuel()
 
 	_, nameContract := converter.ParseName(sc.TxContract.Name)
 	ctrctExtend[`original_contract`] = nameContract
@@ -1017,6 +1072,7 @@ func (sc *SmartContract) CallContract(point int) (string, error) {
 lp:
 	if err != nil {
 		if needPayment {
+			sc.Penalty = true
 			if ierr := sc.DbTransaction.ResetSavepoint(consts.SetSavePointMarkBlock(point)); ierr != nil {
 				return retError(ierr)
 			}
@@ -1040,6 +1096,57 @@ lp:
 	return result, nil
 }
 
+// checkMultiSign checks that the transaction is signed by the threshold count of signers of multi-signature account
+func (sc *SmartContract) checkMultiSign(signedBy int64) error {
+	logger := sc.GetLogger().WithFields(log.Fields{"key_id": signedBy})
+	if !sc.Key.IsMultisig() || sc.TxSmart.SignedBy != 0 {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject}).Error("signers of single key account")
+		return errMultisigAccount
+	}
+	keys, err := crypto.SplitPublicKeys(sc.Key.PublicKey)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "error": err}).Error("splitting public keys of multi-signature account")
+		return err
+	}
+	if int64(len(sc.TxSmart.Signers)) < sc.Key.Multi {
+		err = fmt.Errorf(eMultisigThreshold, len(sc.TxSmart.Signers), sc.Key.Multi)
+		logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": err}).Error("not enough signers")
+		return err
+	}
+	signers := make(map[string]bool, len(sc.TxSmart.Signers))
+	for _, signer := range sc.TxSmart.Signers {
+		signer = crypto.CutPub(signer)
+		found := false
+		for _, key := range keys {
+			if bytes.Equal(key, signer) {
+				found = true
+				break
+			}
+		}
+		if !found || signers[string(signer)] {
+			err = fmt.Errorf(eMultisigSigner, crypto.PubToHex(signer))
+			logger.WithFields(log.Fields{"type": consts.AccessDenied, "error": err}).Error("unknown or duplicate signer")
+			return err
+		}
+		signers[string(signer)] = true
+		sc.PublicKeys = append(sc.PublicKeys, signer)
+	}
+	if sc.Simulate {
+		return nil
+	}
+
+	ok, err := utils.CheckMultiSign(sc.PublicKeys, sc.TxHash, sc.TxSignature)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("checking tx data multi sign")
+		return err
+	}
+	if !ok {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject}).Error("incorrect multi sign")
+		return errIncorrectSign
+	}
+	return nil
+}
+
 func (sc *SmartContract) checkTxSign() error {
 	var public []byte
 	if len(sc.TxSmart.PublicKey) > 0 && string(sc.TxSmart.PublicKey) != `null` {
@@ -1066,6 +1173,9 @@ func (sc *SmartContract) checkTxSign() error {
 		sc.GetLogger().WithFields(log.Fields{"type": consts.ContractError, "error": err}).Error("disable keyid")
 		return err
 	}
+	if sc.Key.IsMultisig() || len(sc.TxSmart.Signers) > 0 {
+		return sc.checkMultiSign(signedBy)
+	}
 	if len(sc.Key.PublicKey) > 0 {
 		public = sc.Key.PublicKey
 	}
@@ -1074,6 +1184,9 @@ func (sc *SmartContract) checkTxSign() error {
 		return errEmptyPublicKey
 	}
 	sc.PublicKeys = append(sc.PublicKeys, public)
+	if sc.Simulate {
+		return nil
+	}
 
 	var CheckSignResult bool
 
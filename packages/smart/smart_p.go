@@ -110,6 +110,9 @@ func getCost(name string) int64 {
 		switch name {
 		case syspar.GapsBetweenBlocks:
 			ok = ival > 0 && ival < 86400
+		case syspar.StateRootBlock:
+			// the state root can be enabled only from the next blocks and can't be disabled after that
+			ok = !syspar.IsStateRootBlock(sc.BlockData.BlockID) && (ival == 0 || ival > sc.BlockData.BlockID)
 		case syspar.RbBlocks1,
 			syspar.NumberNodes:
 			ok = ival > 0 && ival < 1000
@@ -176,6 +179,9 @@ func getCost(name string) int64 {
 	if len(fields) == 0 {
 		return 0, logErrorShort(errEmpty, consts.EmptyObject)
 	}
+	if err = sc.checkNodeState(); err != nil {
+		return 0, err
+	}
 	_, _, err = sc.update(fields, values, "1_system_parameters", "id", par.ID)
 	if err != nil {
 		return 0, err
@@ -427,7 +433,7 @@ func CreateEcosystem(sc *SmartContract, wallet int64, name string) (int64, error
 	}
 
 	idStr := converter.Int64ToStr(id)
-	if err := LoadContract(sc.DbTransaction, id); err != nil {
+	if err := LoadContract(sc.DbTransaction, id, sc.blockVersion()); err != nil {
 		return 0, err
 	}
 	if !sc.OBS {
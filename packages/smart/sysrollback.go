@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
 	"github.com/IBAX-io/go-ibax/packages/converter"
 	"github.com/IBAX-io/go-ibax/packages/model"
@@ -133,9 +134,9 @@ func SysSetContractWallet(tblid, state int64, wallet int64) error {
 	return nil
 }
 
-// SysRollbackEditContract rollbacks the contract
+// SysRollbackEditContract rollbacks the contract, the previous source is compiled for the block with blockID
 func SysRollbackEditContract(transaction *model.DbTransaction, sysData SysRollData,
-	EcosystemID string) error {
+	EcosystemID string, blockID int64) error {
 
 	fields, err := model.GetOneRowTransaction(transaction, `select * from "1_contracts" where id=?`,
 		sysData.ID).String()
@@ -164,7 +165,8 @@ func SysRollbackEditContract(transaction *model.DbTransaction, sysData SysRollDa
 			wallet = converter.StrToInt64(fields["wallet_id"])
 		}
 		root, err := VMCompileBlock(GetVM(), fields["value"],
-			&script.OwnerInfo{StateID: uint32(owner.StateID), WalletID: wallet, TokenID: owner.TokenID})
+			&script.OwnerInfo{StateID: uint32(owner.StateID), WalletID: wallet, TokenID: owner.TokenID,
+				Version: syspar.GetBlockVersion(blockID)})
 		if err != nil {
 			log.WithFields(log.Fields{"type": consts.VMError, "error": err}).Error("compiling contract")
 			return err
@@ -0,0 +1,427 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+// Package smarttest runs the contracts on the chain of one node without the network and PostgreSQL.
+// The state is kept in the throwaway SQLite database, the system contracts are loaded from
+// the migration as by the first block of the real chain.
+package smarttest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/notificator"
+	"github.com/IBAX-io/go-ibax/packages/script"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/transaction/custom"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// EcosystemID is the ecosystem of the contracts called by the chain
+	EcosystemID = 1
+
+	dbName = "smarttest"
+)
+
+var (
+	// ErrClosed is returned when the chain has been closed
+	ErrClosed = errors.New("Chain is closed")
+	// ErrUnknownContract is returned when the contract isn't found in the virtual machine
+	ErrUnknownContract = errors.New("Unknown contract")
+)
+
+// Result is the result of the contract call
+type Result struct {
+	BlockID int64
+	Hash    []byte
+	Result  string
+	Fuel    int64
+}
+
+// vmState is the contracts of the virtual machine at the checkpoint. The edited contracts
+// and functions are replaced in place by the compiler, so the children and the values
+// of the objects and the owners are saved as well as the list of the objects.
+type vmState struct {
+	objects  map[string]*script.ObjInfo
+	values   map[*script.ObjInfo]script.ObjInfo
+	owners   map[*script.OwnerInfo]script.OwnerInfo
+	children []*script.Block
+}
+
+// Chain is the chain of one node. Every call of the contract is played as the transaction
+// of the new block, the signatures aren't checked so the contracts can be called by any key.
+// The changes are made in the database transaction which is committed by Checkpoint and
+// rolled back by Reset, so the tests can share the initialized chain.
+type Chain struct {
+	dir       string
+	tx        *model.DbTransaction
+	founder   int64
+	nodeKey   int64
+	blockID   int64
+	blockTime int64
+	prevHash  []byte
+
+	checkpoint struct {
+		blockID   int64
+		blockTime int64
+		prevHash  []byte
+		vm        vmState
+	}
+}
+
+// NewChain creates the database and plays the first block. The virtual machine of the smart package
+// is replaced, so only one chain can be used at a time.
+func NewChain() (*Chain, error) {
+	dir, err := os.MkdirTemp("", dbName)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("creating temporary dir")
+		return nil, err
+	}
+	c := &Chain{dir: dir, blockID: 1, blockTime: time.Now().Unix()}
+	if err = c.init(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Chain) init() error {
+	_, founderPub, err := crypto.GenKeyPair()
+	if err != nil {
+		return err
+	}
+	_, nodePub, err := crypto.GenKeyPair()
+	if err != nil {
+		return err
+	}
+	c.founder = crypto.Address(founderPub)
+	c.nodeKey = crypto.Address(nodePub)
+
+	conf.Config.KeyID = c.nodeKey
+	conf.Config.DB = conf.DBConfig{
+		Name:         dbName,
+		Backend:      model.SQLiteBackend,
+		Path:         filepath.Join(c.dir, dbName+".db"),
+		LockTimeout:  5000,
+		MaxIdleConns: 1,
+		MaxOpenConns: 4,
+	}
+	if err = model.InitDB(conf.Config.DB); err != nil {
+		return err
+	}
+
+	smart.ResetVM()
+	smart.InitVM()
+	// the first block is played without the transaction as ExecSchemaEcosystem uses the connection
+	first := &custom.FirstBlockTransaction{
+		Logger: log.WithFields(log.Fields{"block_id": c.blockID}),
+		Data: &consts.FirstBlock{
+			TxHeader: consts.TxHeader{
+				Type:  consts.TxTypeFirstBlock,
+				Time:  uint32(c.blockTime),
+				KeyID: c.nodeKey,
+			},
+			PublicKey:         founderPub,
+			NodePublicKey:     nodePub,
+			Test:              1,
+			PrivateBlockchain: 1,
+		},
+	}
+	if err = first.Action(); err != nil {
+		return err
+	}
+	if err = syspar.SysUpdate(nil); err != nil {
+		return err
+	}
+	c.prevHash = crypto.DoubleHash([]byte(fmt.Sprint(c.blockID, c.blockTime)))
+
+	if c.tx, err = model.StartTransaction(); err != nil {
+		return err
+	}
+	c.saveCheckpoint()
+	return nil
+}
+
+// Close rolls back the changes after the checkpoint and removes the database
+func (c *Chain) Close() error {
+	if c.tx != nil {
+		c.tx.Rollback()
+		c.tx = nil
+	}
+	model.GormClose()
+	return os.RemoveAll(c.dir)
+}
+
+// Founder returns the key of the founder of the first ecosystem
+func (c *Chain) Founder() int64 {
+	return c.founder
+}
+
+// BlockID returns the id of the last block
+func (c *Chain) BlockID() int64 {
+	return c.blockID
+}
+
+// DbTransaction returns the database transaction of the blocks after the last checkpoint
+func (c *Chain) DbTransaction() *model.DbTransaction {
+	return c.tx
+}
+
+// Time returns the time of the next block
+func (c *Chain) Time() time.Time {
+	return time.Unix(c.blockTime, 0)
+}
+
+// AdvanceTime moves the time of the next blocks
+func (c *Chain) AdvanceTime(d time.Duration) {
+	c.blockTime += int64(d / time.Second)
+}
+
+// Checkpoint commits the changes, Reset returns the chain to the last checkpoint
+func (c *Chain) Checkpoint() error {
+	if c.tx == nil {
+		return ErrClosed
+	}
+	if err := c.tx.Commit(); err != nil {
+		return err
+	}
+	var err error
+	if c.tx, err = model.StartTransaction(); err != nil {
+		c.tx = nil
+		return err
+	}
+	c.saveCheckpoint()
+	return nil
+}
+
+// Reset discards the blocks and the contracts after the last checkpoint
+func (c *Chain) Reset() error {
+	if c.tx == nil {
+		return ErrClosed
+	}
+	if err := c.tx.Rollback(); err != nil {
+		return err
+	}
+	vm := smart.GetVM()
+	vm.Objects = make(map[string]*script.ObjInfo, len(c.checkpoint.vm.objects))
+	for name, obj := range c.checkpoint.vm.objects {
+		vm.Objects[name] = obj
+	}
+	for obj, saved := range c.checkpoint.vm.values {
+		*obj = saved
+	}
+	for owner, saved := range c.checkpoint.vm.owners {
+		*owner = saved
+	}
+	vm.Children = make([]*script.Block, len(c.checkpoint.vm.children))
+	copy(vm.Children, c.checkpoint.vm.children)
+	c.blockID, c.blockTime, c.prevHash = c.checkpoint.blockID, c.checkpoint.blockTime, c.checkpoint.prevHash
+
+	var err error
+	if c.tx, err = model.StartTransaction(); err != nil {
+		c.tx = nil
+		return err
+	}
+	// the system parameters could be changed by the discarded blocks
+	return syspar.SysUpdate(c.tx)
+}
+
+func (c *Chain) saveCheckpoint() {
+	vm := smart.GetVM()
+	state := vmState{
+		objects:  make(map[string]*script.ObjInfo, len(vm.Objects)),
+		values:   make(map[*script.ObjInfo]script.ObjInfo, len(vm.Objects)),
+		owners:   make(map[*script.OwnerInfo]script.OwnerInfo),
+		children: make([]*script.Block, len(vm.Children)),
+	}
+	for name, obj := range vm.Objects {
+		state.objects[name] = obj
+		state.values[obj] = *obj
+	}
+	copy(state.children, vm.Children)
+	for _, item := range vm.Children {
+		if item != nil && item.Type == script.ObjContract {
+			if owner := item.Info.(*script.ContractInfo).Owner; owner != nil {
+				state.owners[owner] = *owner
+			}
+		}
+	}
+	c.checkpoint.vm = state
+	c.checkpoint.blockID, c.checkpoint.blockTime, c.checkpoint.prevHash = c.blockID, c.blockTime, c.prevHash
+}
+
+// AddKey adds the key to the ecosystem with the amount of tokens
+func (c *Chain) AddKey(keyID int64, amount string) error {
+	if c.tx == nil {
+		return ErrClosed
+	}
+	key := &model.Key{}
+	found, err := key.SetTablePrefix(EcosystemID).Get(c.tx, keyID)
+	if err != nil {
+		return err
+	}
+	if found {
+		return model.GetDB(c.tx).Exec(`UPDATE "1_keys" SET amount = ? WHERE id = ? AND ecosystem = ?`,
+			amount, keyID, EcosystemID).Error
+	}
+	// the signatures aren't checked, so the public key is only the placeholder
+	pub := make([]byte, consts.PubkeySizeLength)
+	return model.GetDB(c.tx).Exec(`INSERT INTO "1_keys" (id, account, pub, amount, ecosystem) VALUES (?, ?, ?, ?, ?)`,
+		keyID, converter.AddressToString(keyID), pub, amount, EcosystemID).Error
+}
+
+// Deploy creates the contracts of the source code by the founder
+func (c *Chain) Deploy(source string) error {
+	_, err := c.Call(c.founder, `@1NewContract`, map[string]interface{}{
+		"ApplicationId": int64(1),
+		"Value":         source,
+		"Conditions":    `ContractConditions("MainCondition")`,
+	})
+	return err
+}
+
+// Call plays the contract as the transaction of the new block on behalf of keyID.
+// The key is added to the ecosystem if it doesn't exist. The block isn't created
+// if the contract fails, the error of the contract is returned.
+func (c *Chain) Call(keyID int64, name string, params map[string]interface{}) (*Result, error) {
+	if c.tx == nil {
+		return nil, ErrClosed
+	}
+	contract := smart.GetContract(name, EcosystemID)
+	if contract == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownContract, name)
+	}
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	txData := make(map[string]interface{})
+	if info := contract.Info(); info.Tx != nil {
+		var err error
+		if txData, err = smart.FillTxData(*info.Tx, params); err != nil {
+			return nil, err
+		}
+	}
+
+	key := &model.Key{}
+	found, err := key.SetTablePrefix(EcosystemID).Get(c.tx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if err = c.AddKey(keyID, `0`); err != nil {
+			return nil, err
+		}
+	}
+
+	blockData := &utils.BlockData{
+		BlockID:     c.blockID + 1,
+		Time:        c.blockTime,
+		EcosystemID: EcosystemID,
+		KeyID:       c.nodeKey,
+		Version:     consts.BlockVersion,
+	}
+	smartTx := tx.SmartContract{
+		Header: tx.Header{
+			ID:          int(contract.Info().ID),
+			Time:        c.blockTime,
+			EcosystemID: EcosystemID,
+			KeyID:       keyID,
+			NetworkID:   conf.Config.NetworkID,
+		},
+		Params: params,
+	}
+	payload, err := msgpack.Marshal(&smartTx)
+	if err != nil {
+		return nil, err
+	}
+	txHash := crypto.DoubleHash(payload)
+
+	mark := consts.SetSavePointMarkBlock(0)
+	if err = c.tx.Savepoint(mark); err != nil {
+		return nil, err
+	}
+	smart.SavepointSmartVMObjects()
+
+	sc := smart.SmartContract{
+		Rollback:      true,
+		Simulate:      true,
+		VM:            smart.GetVM(),
+		TxSmart:       smartTx,
+		TxData:        txData,
+		TxContract:    contract,
+		TxUsedCost:    decimal.New(0, 0),
+		BlockData:     blockData,
+		PreBlockData:  &utils.BlockData{BlockID: c.blockID, Hash: c.prevHash},
+		TxHash:        txHash,
+		TxSize:        int64(len(payload)),
+		DbTransaction: c.tx,
+		Rand:          utils.NewRand(blockData.Time).BytesSeed(txHash),
+		Notifications: notificator.NewQueue(),
+		RollBackTx:    make([]*model.RollbackTx, 0),
+	}
+	out, err := sc.CallContract(0)
+	if err == nil && sc.Penalty {
+		err = errors.New(out)
+	}
+	if err != nil {
+		smart.RollbackSmartVMObjects()
+		if ierr := c.tx.RollbackSavepoint(mark); ierr != nil {
+			return nil, ierr
+		}
+		if sc.SysUpdate {
+			if ierr := syspar.SysUpdate(c.tx); ierr != nil {
+				return nil, ierr
+			}
+		}
+		return nil, err
+	}
+	smart.ReleaseSmartVMObjects()
+	if err = c.tx.ReleaseSavepoint(0, consts.SavePointMarkBlock); err != nil {
+		return nil, err
+	}
+
+	c.blockID = blockData.BlockID
+	c.prevHash = crypto.DoubleHash(append(c.prevHash, txHash...))
+	return &Result{BlockID: c.blockID, Hash: txHash, Result: out, Fuel: sc.TxFuel}, nil
+}
+
+// Row returns the row of the table by id, nil is returned if the row doesn't exist.
+// The table name without the ecosystem prefix is the table of the first ecosystem.
+func (c *Chain) Row(table string, id int64) (map[string]string, error) {
+	if c.tx == nil {
+		return nil, ErrClosed
+	}
+	table = converter.ParseTable(table, EcosystemID)
+	query := fmt.Sprintf(`SELECT * FROM "%s" WHERE id = ?`, table)
+	args := []interface{}{id}
+	if name := strings.SplitN(table, `_`, 2)[1]; converter.FirstEcosystemTables[name] {
+		query += ` AND ecosystem = ?`
+		args = append(args, EcosystemID)
+	}
+	row, err := model.GetOneRowTransaction(c.tx, query, args...).String()
+	if err != nil {
+		return nil, err
+	}
+	if len(row) == 0 {
+		return nil, nil
+	}
+	return row, nil
+}
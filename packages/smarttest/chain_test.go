@@ -0,0 +1,71 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package smarttest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	crypto.InitHash("SHA256")
+	crypto.InitCurve("ECDSA")
+}
+
+func TestChain(t *testing.T) {
+	chain, err := NewChain()
+	require.NoError(t, err)
+	defer chain.Close()
+
+	source, err := os.ReadFile(filepath.Join("testdata", "greet.sim"))
+	require.NoError(t, err)
+	require.NoError(t, chain.Deploy(string(source)))
+	require.NoError(t, chain.Checkpoint())
+	blockID, now := chain.BlockID(), chain.Time()
+
+	chain.AdvanceTime(time.Hour)
+	res, err := chain.Call(42, "Greet", map[string]interface{}{"Name": "Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, blockID+1, res.BlockID)
+	assert.Contains(t, res.Result, "Hello, Alice at ")
+	assert.Equal(t, now.Add(time.Hour).Unix(), chain.Time().Unix())
+	assert.True(t, res.Fuel > 0)
+
+	row, err := chain.Row("keys", 42)
+	require.NoError(t, err)
+	assert.Equal(t, "42", row["id"])
+
+	_, err = chain.Call(42, "Greet", map[string]interface{}{"Name": ""})
+	assert.Error(t, err)
+	assert.Equal(t, blockID+1, chain.BlockID())
+
+	_, err = chain.Call(42, "Unknown", nil)
+	assert.True(t, errors.Is(err, ErrUnknownContract))
+
+	require.NoError(t, chain.Reset())
+	assert.Equal(t, blockID, chain.BlockID())
+	row, err = chain.Row("keys", 42)
+	require.NoError(t, err)
+	assert.Nil(t, row)
+}
+
+func TestRunDir(t *testing.T) {
+	results, err := RunDir("testdata", nil)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, r := range results {
+		assert.NoError(t, r.Error, r.Name)
+	}
+	assert.Equal(t, "TestGreet", results[0].Name)
+}
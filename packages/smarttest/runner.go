@@ -0,0 +1,261 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package smarttest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/lsp"
+	"github.com/IBAX-io/go-ibax/packages/script"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+	"github.com/IBAX-io/go-ibax/packages/types"
+)
+
+// The test functions are the functions of *_test.sim files which names start with Test.
+// They are executed by the virtual machine with the following builtin functions:
+//
+//	Founder() int                                 the key of the founder of the ecosystem
+//	Call(key int, contract string, params map) string  calls the contract, its error fails the test
+//	CallError(key int, contract string, params map) string  returns the error of the contract
+//	AdvanceTime(seconds int)                      moves the time of the next blocks
+//	Row(table string, id int) map                 returns the row of the table
+//	Fuel() int                                    returns the fuel of the last call
+//
+// The test fails if it raises the error, e.g. by the error statement.
+
+const (
+	// TestSuffix is the suffix of the files with the test functions
+	TestSuffix = "_test" + lsp.SourceExt
+
+	testPrefix = "Test"
+	// testParam is the name of the auto parameter of the builtin functions of the tests
+	testParam = "test"
+)
+
+// TestResult is the result of the test function, Error is nil if the test passed
+type TestResult struct {
+	File     string
+	Name     string
+	Error    error
+	Duration time.Duration
+}
+
+// testRuntime is passed to the builtin functions of the test
+type testRuntime struct {
+	chain *Chain
+	last  *Result
+}
+
+func testFounder(rt *testRuntime) int64 {
+	return rt.chain.Founder()
+}
+
+func testCall(rt *testRuntime, keyID int64, name string, params *types.Map) (string, error) {
+	res, err := rt.chain.Call(keyID, name, mapToParams(params))
+	if err != nil {
+		return ``, fmt.Errorf("%s: %v", name, err)
+	}
+	rt.last = res
+	return res.Result, nil
+}
+
+func testCallError(rt *testRuntime, keyID int64, name string, params *types.Map) (string, error) {
+	res, err := rt.chain.Call(keyID, name, mapToParams(params))
+	if err == nil {
+		rt.last = res
+		return ``, fmt.Errorf("%s: the contract hasn't failed", name)
+	}
+	return err.Error(), nil
+}
+
+func testAdvanceTime(rt *testRuntime, seconds int64) {
+	rt.chain.AdvanceTime(time.Duration(seconds) * time.Second)
+}
+
+func testRow(rt *testRuntime, table string, id int64) (*types.Map, error) {
+	row, err := rt.chain.Row(table, id)
+	if err != nil {
+		return nil, err
+	}
+	ret := types.NewMap()
+	for key, val := range row {
+		ret.Set(key, val)
+	}
+	return ret, nil
+}
+
+func testFuel(rt *testRuntime) int64 {
+	if rt.last == nil {
+		return 0
+	}
+	return rt.last.Fuel
+}
+
+func mapToParams(m *types.Map) map[string]interface{} {
+	params := make(map[string]interface{})
+	if m == nil {
+		return params
+	}
+	for _, key := range m.Keys() {
+		params[key], _ = m.Get(key)
+	}
+	return params
+}
+
+// sourceFile is the file of the directory with the contract source code
+type sourceFile struct {
+	path   string
+	source string
+}
+
+// readSources returns the source files and the test files of the directory sorted by the path
+func readSources(dir string) (sources, tests []*sourceFile, err error) {
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != lsp.SourceExt {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file := &sourceFile{path: path, source: string(data)}
+		if strings.HasSuffix(path, TestSuffix) {
+			tests = append(tests, file)
+		} else {
+			sources = append(sources, file)
+		}
+		return nil
+	})
+	return
+}
+
+// deploy creates the contracts of the files, the failed files are deployed again while any
+// file is deployed successfully because the contracts can call the contracts of other files
+func (c *Chain) deploy(files []*sourceFile) error {
+	errs := make(map[*sourceFile]error)
+	pending := files
+	for len(pending) > 0 {
+		var failed []*sourceFile
+		for _, file := range pending {
+			if err := c.Deploy(file.source); err != nil {
+				errs[file] = err
+				failed = append(failed, file)
+				continue
+			}
+			delete(errs, file)
+		}
+		if len(failed) == len(pending) {
+			break
+		}
+		pending = failed
+	}
+	for _, file := range files {
+		if err, ok := errs[file]; ok {
+			return fmt.Errorf("deploying %s: %v", file.path, err)
+		}
+	}
+	return nil
+}
+
+// testFunc is the compiled test function
+type testFunc struct {
+	file  string
+	name  string
+	block *script.Block
+}
+
+// compileTests compiles the test files and returns the test functions in the order of declaration
+func compileTests(vm *script.VM, files []*sourceFile) ([]*testFunc, error) {
+	var funcs []*testFunc
+	owner := &script.OwnerInfo{StateID: EcosystemID, Active: true, Version: script.LatestVersion}
+	for _, file := range files {
+		root, err := smart.VMCompileBlock(vm, file.source, owner)
+		if err != nil {
+			return nil, fmt.Errorf("compiling %s: %v", file.path, err)
+		}
+		index := make(map[*script.Block]int, len(root.Children))
+		for i, child := range root.Children {
+			index[child] = i
+		}
+		var list []*testFunc
+		for name, obj := range root.Objects {
+			if obj.Type != script.ObjFunc || !strings.HasPrefix(name, testPrefix) {
+				continue
+			}
+			list = append(list, &testFunc{file: file.path, name: name, block: obj.Value.(*script.Block)})
+		}
+		sort.Slice(list, func(i, j int) bool { return index[list[i].block] < index[list[j].block] })
+		vm.FlushBlock(root)
+		funcs = append(funcs, list...)
+	}
+	return funcs, nil
+}
+
+// RunDir runs the tests of the directory. The contracts of other .sim files are deployed
+// to the new chain by the founder and every test starts from this state of the chain.
+// The tests which names don't match filter are skipped if filter isn't nil.
+func RunDir(dir string, filter *regexp.Regexp) ([]*TestResult, error) {
+	sources, tests, err := readSources(dir)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := NewChain()
+	if err != nil {
+		return nil, err
+	}
+	defer chain.Close()
+
+	if err = chain.deploy(sources); err != nil {
+		return nil, err
+	}
+	rt := &testRuntime{chain: chain}
+	vm := smart.GetVM()
+	vm.Extend(&script.ExtendData{
+		Objects: map[string]interface{}{
+			"Founder":     testFounder,
+			"Call":        testCall,
+			"CallError":   testCallError,
+			"AdvanceTime": testAdvanceTime,
+			"Row":         testRow,
+			"Fuel":        testFuel,
+		},
+		AutoPars: map[string]string{
+			`*smarttest.testRuntime`: testParam,
+		},
+	})
+	funcs, err := compileTests(vm, tests)
+	if err != nil {
+		return nil, err
+	}
+	if err = chain.Checkpoint(); err != nil {
+		return nil, err
+	}
+
+	var results []*TestResult
+	for _, f := range funcs {
+		if filter != nil && !filter.MatchString(f.name) {
+			continue
+		}
+		rt.last = nil
+		start := time.Now()
+		_, err := smart.VMRun(vm, f.block, nil, &map[string]interface{}{testParam: rt})
+		results = append(results, &TestResult{File: f.file, Name: f.name, Error: err,
+			Duration: time.Since(start)})
+		if err = chain.Reset(); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
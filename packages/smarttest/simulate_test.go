@@ -0,0 +1,62 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package smarttest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/modes"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateTx(t *testing.T) {
+	chain, err := NewChain()
+	require.NoError(t, err)
+	defer chain.Close()
+
+	source, err := os.ReadFile(filepath.Join("testdata", "greet.sim"))
+	require.NoError(t, err)
+	require.NoError(t, chain.Deploy(string(source)))
+	require.NoError(t, chain.Checkpoint())
+
+	ib := &model.InfoBlock{
+		Hash:           chain.prevHash,
+		BlockID:        chain.BlockID(),
+		Time:           chain.Time().Unix(),
+		CurrentVersion: "1",
+	}
+	require.NoError(t, ib.Create(nil))
+
+	objects := len(smart.GetVM().Objects)
+	simulator := modes.GetTxSimulator()
+	logger := log.WithFields(log.Fields{})
+
+	out, err := simulator.SimulateTx("Greet", EcosystemID, chain.Founder(),
+		map[string]interface{}{"Name": "Bob"}, "", logger)
+	require.NoError(t, err)
+	res := out.(*smart.SimulateResult)
+	assert.Empty(t, res.Error)
+	assert.Contains(t, res.Result, "Hello, Bob at ")
+	assert.True(t, res.Fuel.Total > 0)
+
+	// the simulation can't change the contracts of the virtual machine
+	out, err = simulator.SimulateTx("@1NewContract", EcosystemID, chain.Founder(), map[string]interface{}{
+		"ApplicationId": int64(1),
+		"Value":         `contract Simulated { action {} }`,
+		"Conditions":    `ContractConditions("MainCondition")`,
+	}, "", logger)
+	require.NoError(t, err)
+	assert.Contains(t, out.(*smart.SimulateResult).Error, "cannot be changed by the simulation")
+	assert.Nil(t, smart.GetContract("Simulated", EcosystemID))
+	assert.Equal(t, objects, len(smart.GetVM().Objects))
+}
@@ -0,0 +1,291 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Writer writes the tables of the state to the snapshot archive.
+// The archive consists of the schema and the chunks of every table and ends with the manifest.
+type Writer struct {
+	tw       *tar.Writer
+	manifest *Manifest
+	table    *Table
+	chunk    bytes.Buffer
+	rows     int64
+}
+
+// NewWriter returns the writer of the snapshot, the chunk rows and the tables of the manifest are filled by the writer
+func NewWriter(w io.Writer, m *Manifest) *Writer {
+	if m.ChunkRows <= 0 {
+		m.ChunkRows = DefaultChunkRows
+	}
+	m.Version = Version
+	m.Tables = make([]*Table, 0)
+	return &Writer{tw: tar.NewWriter(w), manifest: m}
+}
+
+func (w *Writer) writeFile(name string, data []byte) error {
+	err := w.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Unix(w.manifest.BlockTime, 0),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.tw.Write(data)
+	return err
+}
+
+// BeginTable writes the schema of the table
+func (w *Writer) BeginTable(t *Table) error {
+	t.Chunks = make([]Chunk, 0)
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	w.table = t
+	return w.writeFile(schemaName(t.Name), data)
+}
+
+// WriteRow adds the row to the current chunk of the table, the values must be in the order of the columns
+func (w *Writer) WriteRow(values []interface{}) error {
+	if w.table == nil || len(values) != len(w.table.Columns) {
+		return ErrBadFormat
+	}
+	row := make([]interface{}, len(values))
+	for i, v := range values {
+		switch val := v.(type) {
+		case []byte:
+			if isBytea(w.table.Columns[i]) {
+				row[i] = base64.StdEncoding.EncodeToString(val)
+			} else {
+				row[i] = string(val)
+			}
+		case time.Time:
+			row[i] = val.UTC().Format(time.RFC3339Nano)
+		default:
+			row[i] = val
+		}
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	w.chunk.Write(data)
+	w.chunk.WriteByte('\n')
+	w.rows++
+	if w.rows >= int64(w.manifest.ChunkRows) {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *Writer) flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+	hash := sha256.Sum256(w.chunk.Bytes())
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write(w.chunk.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := w.writeFile(chunkName(w.table.Name, len(w.table.Chunks)), gz.Bytes()); err != nil {
+		return err
+	}
+	w.table.Chunks = append(w.table.Chunks, Chunk{Rows: w.rows, Hash: hash[:]})
+	w.chunk.Reset()
+	w.rows = 0
+	return nil
+}
+
+// EndTable writes the rest rows of the table
+func (w *Writer) EndTable() error {
+	if w.table == nil {
+		return ErrBadFormat
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.manifest.Tables = append(w.manifest.Tables, w.table)
+	w.table = nil
+	return nil
+}
+
+// Close calculates the state hash and writes the manifest
+func (w *Writer) Close() error {
+	if w.table != nil {
+		return ErrBadFormat
+	}
+	w.manifest.StateHash = w.manifest.stateHash()
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = w.writeFile(manifestName, data); err != nil {
+		return err
+	}
+	return w.tw.Close()
+}
+
+// Handler receives the data of the snapshot while it is read
+type Handler interface {
+	// Table is called before the rows of the table
+	Table(t *Table) error
+	// Rows is called for every chunk of the table
+	Rows(t *Table, rows [][]interface{}) error
+}
+
+// Read reads the snapshot archive, passes the tables to the handler and verifies the hashes.
+// The manifest is returned only if the whole archive is correct, so the handler should
+// keep the changes in the transaction until Read is finished.
+func Read(r io.Reader, h Handler) (*Manifest, error) {
+	tr := tar.NewReader(r)
+	var (
+		tables []*Table
+		table  *Table
+	)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: manifest is missing", ErrBadFormat)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case hdr.Name == manifestName:
+			m := &Manifest{}
+			if err = json.NewDecoder(tr).Decode(m); err != nil {
+				return nil, err
+			}
+			if err = verifyManifest(m, tables); err != nil {
+				return nil, err
+			}
+			return m, nil
+
+		case table == nil || hdr.Name != chunkName(table.Name, len(table.Chunks)):
+			table = &Table{}
+			if err = json.NewDecoder(tr).Decode(table); err != nil {
+				return nil, err
+			}
+			if hdr.Name != schemaName(table.Name) || len(table.Columns) == 0 {
+				return nil, fmt.Errorf("%w: unexpected file %s", ErrBadFormat, hdr.Name)
+			}
+			table.Chunks = make([]Chunk, 0)
+			tables = append(tables, table)
+			if err = h.Table(table); err != nil {
+				return nil, err
+			}
+
+		default:
+			rows, chunk, err := readChunk(tr, table)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", hdr.Name, err)
+			}
+			table.Chunks = append(table.Chunks, chunk)
+			if err = h.Rows(table, rows); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func readChunk(r io.Reader, t *Table) ([][]interface{}, Chunk, error) {
+	var chunk Chunk
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, chunk, err
+	}
+	defer zr.Close()
+
+	hash := sha256.New()
+	scanner := bufio.NewScanner(io.TeeReader(zr, hash))
+	scanner.Buffer(make([]byte, 64*1024), 1<<30)
+	rows := make([][]interface{}, 0)
+	for scanner.Scan() {
+		dec := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		dec.UseNumber()
+		var row []interface{}
+		if err = dec.Decode(&row); err != nil {
+			return nil, chunk, err
+		}
+		if row, err = decodeRow(t, row); err != nil {
+			return nil, chunk, err
+		}
+		rows = append(rows, row)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, chunk, err
+	}
+	chunk.Rows = int64(len(rows))
+	chunk.Hash = hash.Sum(nil)
+	return rows, chunk, nil
+}
+
+func decodeRow(t *Table, row []interface{}) ([]interface{}, error) {
+	if len(row) != len(t.Columns) {
+		return nil, fmt.Errorf("%w: wrong count of values in %s", ErrBadFormat, t.Name)
+	}
+	for i, v := range row {
+		switch val := v.(type) {
+		case string:
+			if isBytea(t.Columns[i]) {
+				b, err := base64.StdEncoding.DecodeString(val)
+				if err != nil {
+					return nil, err
+				}
+				row[i] = b
+			}
+		case json.Number:
+			row[i] = val.String()
+		}
+	}
+	return row, nil
+}
+
+func verifyManifest(m *Manifest, tables []*Table) error {
+	if m.Version != Version {
+		return fmt.Errorf("%w: unsupported version %d", ErrBadFormat, m.Version)
+	}
+	if len(m.Tables) != len(tables) {
+		return fmt.Errorf("%w: wrong count of tables", ErrBadFormat)
+	}
+	for i, t := range m.Tables {
+		read := tables[i]
+		if t.Name != read.Name || len(t.Chunks) != len(read.Chunks) {
+			return fmt.Errorf("%w: table %s", ErrChunkHash, t.Name)
+		}
+		for j, c := range t.Chunks {
+			if c.Rows != read.Chunks[j].Rows || !bytes.Equal(c.Hash, read.Chunks[j].Hash) {
+				return fmt.Errorf("%w: table %s, chunk %d", ErrChunkHash, t.Name, j)
+			}
+		}
+		// the schema from the archive is used for hashing to be sure that the tables were created as described
+		t.Columns, t.Constraints, t.Indexes = read.Columns, read.Constraints, read.Indexes
+	}
+	if !bytes.Equal(m.stateHash(), m.StateHash) {
+		return ErrStateHash
+	}
+	return nil
+}
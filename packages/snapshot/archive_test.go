@@ -0,0 +1,165 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testHandler struct {
+	tables []string
+	rows   map[string][][]interface{}
+}
+
+func (h *testHandler) Table(t *Table) error {
+	h.tables = append(h.tables, t.Name)
+	return nil
+}
+
+func (h *testHandler) Rows(t *Table, rows [][]interface{}) error {
+	h.rows[t.Name] = append(h.rows[t.Name], rows...)
+	return nil
+}
+
+func writeTestSnapshot(t *testing.T) ([]byte, *Manifest) {
+	var buf bytes.Buffer
+	m := &Manifest{NetworkID: 1, BlockID: 10, BlockHash: []byte{1, 2, 3}, StateRoot: []byte{4, 5}, BlockTime: 1600000000, ChunkRows: 2}
+	w := NewWriter(&buf, m)
+
+	require.NoError(t, w.BeginTable(&Table{Name: "1_keys", Columns: []Column{
+		{Name: "id", Type: "bigint", NotNull: true},
+		{Name: "pub", Type: "bytea"},
+		{Name: "amount", Type: "numeric(30,0)"},
+	}, Constraints: []string{"PRIMARY KEY (id)"}}))
+	for i := int64(1); i <= 5; i++ {
+		require.NoError(t, w.WriteRow([]interface{}{i, []byte{0, byte(i), 0xff}, "100"}))
+	}
+	require.NoError(t, w.EndTable())
+
+	require.NoError(t, w.BeginTable(&Table{Name: "info_block", Columns: []Column{
+		{Name: "block_id", Type: "bigint"},
+		{Name: "time", Type: "timestamp without time zone"},
+		{Name: "data", Type: "jsonb"},
+	}}))
+	require.NoError(t, w.WriteRow([]interface{}{int64(10), time.Unix(0, 0), []byte(`{"a":1}`)}))
+	require.NoError(t, w.EndTable())
+	require.NoError(t, w.Close())
+	return buf.Bytes(), m
+}
+
+func TestSnapshotArchive(t *testing.T) {
+	data, m := writeTestSnapshot(t)
+	require.Len(t, m.Tables, 2)
+	assert.Len(t, m.Tables[0].Chunks, 3)
+	assert.Equal(t, int64(1), m.Tables[0].Chunks[2].Rows)
+	assert.NotEmpty(t, m.StateHash)
+
+	h := &testHandler{rows: make(map[string][][]interface{})}
+	read, err := Read(bytes.NewReader(data), h)
+	require.NoError(t, err)
+	assert.Equal(t, m.StateHash, read.StateHash)
+	assert.Equal(t, int64(10), read.BlockID)
+	assert.Equal(t, []string{"1_keys", "info_block"}, h.tables)
+
+	require.Len(t, h.rows["1_keys"], 5)
+	assert.Equal(t, []interface{}{"3", []byte{0, 3, 0xff}, "100"}, h.rows["1_keys"][2])
+	assert.Equal(t, []interface{}{"10", "1970-01-01T00:00:00Z", `{"a":1}`}, h.rows["info_block"][0])
+
+	// the same state gives the same hash
+	again, _ := writeTestSnapshot(t)
+	assert.Equal(t, data, again)
+}
+
+// rewrite copies the archive and changes the files by the function
+func rewrite(t *testing.T, data []byte, change func(name string, body []byte) []byte) []byte {
+	var buf bytes.Buffer
+	tr := tar.NewReader(bytes.NewReader(data))
+	tw := tar.NewWriter(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		body = change(hdr.Name, body)
+		hdr.Size = int64(len(body))
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err = tw.Write(body)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestSnapshotArchiveTampered(t *testing.T) {
+	data, _ := writeTestSnapshot(t)
+	other, _ := writeTestSnapshot(t)
+
+	// the chunk from the other table
+	broken := rewrite(t, data, func(name string, body []byte) []byte {
+		if name == chunkName("1_keys", 1) {
+			var chunk []byte
+			rewrite(t, other, func(name string, body []byte) []byte {
+				if name == chunkName("info_block", 0) {
+					chunk = body
+				}
+				return body
+			})
+			return chunk
+		}
+		return body
+	})
+	_, err := Read(bytes.NewReader(broken), &testHandler{rows: make(map[string][][]interface{})})
+	assert.Error(t, err)
+
+	// the manifest with the other state hash
+	broken = rewrite(t, data, func(name string, body []byte) []byte {
+		if name == manifestName {
+			return bytes.Replace(body, []byte(`"block_id": 10`), []byte(`"block_id": 11`), 1)
+		}
+		return body
+	})
+	_, err = Read(bytes.NewReader(broken), &testHandler{rows: make(map[string][][]interface{})})
+	assert.True(t, errors.Is(err, ErrStateHash))
+
+	// the schema with the other constraint which would be executed by the import
+	broken = rewrite(t, data, func(name string, body []byte) []byte {
+		if name == schemaName("1_keys") {
+			return bytes.Replace(body, []byte(`PRIMARY KEY (id)`), []byte(`UNIQUE (pub)`), 1)
+		}
+		return body
+	})
+	_, err = Read(bytes.NewReader(broken), &testHandler{rows: make(map[string][][]interface{})})
+	assert.True(t, errors.Is(err, ErrStateHash))
+
+	// the archive without manifest
+	_, err = Read(bytes.NewReader(data[:len(data)/2]), &testHandler{rows: make(map[string][][]interface{})})
+	assert.Error(t, err)
+}
+
+func TestLocalTables(t *testing.T) {
+	for name, local := range map[string]bool{
+		"my_node_keys":             true,
+		"queue_tx":                 true,
+		"subnode_src_task":         true,
+		"vde_dest_data":            true,
+		"1_keys":                   false,
+		"2_subnode_share_data_502": false,
+		"block_chain":              false,
+	} {
+		assert.Equal(t, local, isLocalTable(name), name)
+	}
+}
@@ -0,0 +1,252 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/block"
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/rollback"
+	"github.com/IBAX-io/go-ibax/packages/smart"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrBlockNotFound is returned when the block of snapshot is absent in the blockchain
+var ErrBlockNotFound = errors.New("block has not been found in the blockchain")
+
+// ExportFile writes the snapshot to the file, see Export
+func ExportFile(path string, blockID int64, chunkRows int, logger *log.Entry) (*Manifest, error) {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": tmp}).Error("creating snapshot file")
+		return nil, err
+	}
+	m, err := Export(f, blockID, chunkRows, logger)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("renaming snapshot file")
+		return nil, err
+	}
+	return m, nil
+}
+
+// Export writes the snapshot of the state at blockID, the last block is used if blockID is 0.
+// The blocks after blockID are rolled back in the database transaction which is never committed.
+func Export(w io.Writer, blockID int64, chunkRows int, logger *log.Entry) (*Manifest, error) {
+	last := &model.Block{}
+	found, err := last.GetMaxBlock()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting max block")
+		return nil, err
+	}
+	if blockID == 0 {
+		blockID = last.ID
+	}
+	if !found || blockID <= 0 || blockID > last.ID {
+		return nil, ErrBlockNotFound
+	}
+
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return nil, err
+	}
+	defer dbTransaction.Rollback()
+
+	conn := model.GetDB(dbTransaction)
+	if err = conn.Exec(`SET TRANSACTION ISOLATION LEVEL REPEATABLE READ`).Error; err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("setting isolation level")
+		return nil, err
+	}
+
+	smart.SavepointSmartVMObjects()
+	defer smart.RollbackSmartVMObjects()
+
+	var bl *block.Block
+	if blockID == last.ID {
+		bl, err = block.UnmarshallBlock(bytes.NewBuffer(last.Data), false)
+	} else {
+		bl, err = rollback.StateAtBlock(dbTransaction, blockID, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b := &model.Block{}
+	if _, err = b.Get(blockID); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "block_id": blockID}).Error("getting block")
+		return nil, err
+	}
+	ib := &model.InfoBlock{
+		Hash:           b.Hash,
+		RollbacksHash:  b.RollbacksHash,
+		StateRoot:      b.StateRoot,
+		BlockID:        b.ID,
+		NodePosition:   converter.Int64ToStr(b.NodePosition),
+		EcosystemID:    b.EcosystemID,
+		KeyID:          b.KeyID,
+		Time:           b.Time,
+		CurrentVersion: strconv.Itoa(bl.Header.Version),
+		Sent:           1,
+	}
+	if err = ib.Update(dbTransaction); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("updating info block")
+		return nil, err
+	}
+
+	m := &Manifest{
+		NetworkID:   conf.Config.NetworkID,
+		BlockID:     b.ID,
+		BlockHash:   b.Hash,
+		StateRoot:   b.StateRoot,
+		BlockTime:   b.Time,
+		ChunkRows:   chunkRows,
+		CreatedTime: time.Now().Unix(),
+	}
+	sw := NewWriter(w, m)
+
+	list, err := model.GetAllTransaction(dbTransaction, `SELECT table_name FROM information_schema.tables
+		WHERE table_type = 'BASE TABLE' AND table_schema = current_schema() ORDER BY table_name`, -1)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting list of tables")
+		return nil, err
+	}
+	for _, item := range list {
+		name := item["table_name"]
+		if isLocalTable(name) {
+			continue
+		}
+		var where string
+		if name == b.TableName() {
+			// only the last block is required to continue the blockchain
+			where = fmt.Sprintf(`WHERE id = %d`, b.ID)
+		}
+		if err = exportTable(dbTransaction, sw, name, where); err != nil {
+			logger.WithFields(log.Fields{"type": consts.DBError, "error": err, "table": name}).Error("exporting table")
+			return nil, err
+		}
+	}
+	if err = sw.Close(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("writing snapshot")
+		return nil, err
+	}
+	return m, nil
+}
+
+func exportTable(dbTransaction *model.DbTransaction, sw *Writer, name, where string) error {
+	t, err := tableSchema(dbTransaction, name)
+	if err != nil {
+		return err
+	}
+	if err = sw.BeginTable(t); err != nil {
+		return err
+	}
+
+	// the rows are ordered to get the same state hash on every node
+	var order []string
+	for _, c := range t.Columns {
+		if c.Name == "id" || c.Name == "ecosystem" {
+			order = append(order, `"`+c.Name+`"`)
+		}
+	}
+	if len(order) == 0 {
+		for _, c := range t.Columns {
+			if c.Type != "json" {
+				order = append(order, `"`+c.Name+`"`)
+			}
+		}
+	}
+	query := fmt.Sprintf(`SELECT * FROM "%s" %s`, name, where)
+	if len(order) > 0 {
+		query += ` ORDER BY ` + strings.Join(order, `,`)
+	}
+	rows, err := model.GetDB(dbTransaction).Raw(query).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(t.Columns))
+	pointers := make([]interface{}, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(pointers...); err != nil {
+			return err
+		}
+		if err = sw.WriteRow(values); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return sw.EndTable()
+}
+
+// tableSchema returns the columns, the constraints and the indexes of the table
+func tableSchema(dbTransaction *model.DbTransaction, name string) (*Table, error) {
+	t := &Table{Name: name}
+	regclass := `"` + name + `"`
+	columns, err := model.GetAllTransaction(dbTransaction, `SELECT a.attname AS name,
+		format_type(a.atttypid, a.atttypmod) AS type, a.attnotnull AS not_null,
+		COALESCE(pg_get_expr(d.adbin, d.adrelid), '') AS def
+		FROM pg_attribute a LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attrelid = ?::regclass AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, -1, regclass)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		t.Columns = append(t.Columns, Column{
+			Name:    c["name"],
+			Type:    c["type"],
+			NotNull: c["not_null"] == "true",
+			Default: c["def"],
+		})
+	}
+
+	constraints, err := model.GetAllTransaction(dbTransaction, `SELECT pg_get_constraintdef(oid) AS def
+		FROM pg_constraint WHERE conrelid = ?::regclass AND contype IN ('p', 'u') ORDER BY conname`, -1, regclass)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range constraints {
+		t.Constraints = append(t.Constraints, c["def"])
+	}
+
+	indexes, err := model.GetAllTransaction(dbTransaction, `SELECT indexdef FROM pg_indexes i
+		WHERE schemaname = current_schema() AND tablename = ?
+		AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conname = i.indexname)
+		ORDER BY indexname`, -1, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		t.Indexes = append(t.Indexes, i["indexdef"])
+	}
+	return t, nil
+}
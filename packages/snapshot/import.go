@@ -0,0 +1,217 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/model"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxInsertValues is the max count of values in one INSERT query
+const maxInsertValues = 10000
+
+// nextvalRe matches the sequence in the default value of the column
+var nextvalRe = regexp.MustCompile(`^nextval\('([\w."]+)'(::regclass)?\)$`)
+
+// ImportFile loads the snapshot from the file, see Import
+func ImportFile(path string, stateHash []byte, logger *log.Entry) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.IOError, "error": err, "path": path}).Error("opening snapshot file")
+		return nil, err
+	}
+	defer f.Close()
+	return Import(bufio.NewReader(f), stateHash, logger)
+}
+
+// Import loads the snapshot to the database without blocks. The data is committed only if
+// the hashes of all chunks are correct and the state hash matches the trusted stateHash,
+// the archive can't be trusted by itself. After the import the node continues the blockchain
+// from the next block after the block of snapshot.
+func Import(r io.Reader, stateHash []byte, logger *log.Entry) (*Manifest, error) {
+	if len(stateHash) == 0 {
+		return nil, ErrNoStateHash
+	}
+	last := &model.Block{}
+	found, err := last.GetMaxBlock()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting max block")
+		return nil, err
+	}
+	if found {
+		return nil, ErrNotEmpty
+	}
+
+	dbTransaction, err := model.StartTransaction()
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("starting transaction")
+		return nil, err
+	}
+	defer dbTransaction.Rollback()
+
+	im := &importer{dbTransaction: dbTransaction, sequences: make(map[string]sequence)}
+	m, err := Read(r, im)
+	if err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("reading snapshot")
+		return nil, err
+	}
+	if m.NetworkID != conf.Config.NetworkID {
+		return nil, fmt.Errorf("%w: network %d is expected, got %d", ErrBadFormat, conf.Config.NetworkID, m.NetworkID)
+	}
+	if !bytes.Equal(stateHash, m.StateHash) {
+		return nil, ErrStateHash
+	}
+	if err = checkBlock(dbTransaction, m); err != nil {
+		return nil, err
+	}
+	if err = im.resetSequences(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("resetting sequences")
+		return nil, err
+	}
+
+	if err = dbTransaction.Commit(); err != nil {
+		logger.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("committing snapshot")
+		return nil, err
+	}
+	logger.WithFields(log.Fields{"block_id": m.BlockID, "state_hash": fmt.Sprintf("%x", m.StateHash)}).Info("snapshot has been imported")
+	return m, nil
+}
+
+// checkBlock checks that the imported block and info block match the manifest
+func checkBlock(dbTransaction *model.DbTransaction, m *Manifest) error {
+	var b model.Block
+	if err := model.GetDB(dbTransaction).Where("id = ?", m.BlockID).First(&b).Error; err != nil {
+		return fmt.Errorf("%w: block %d: %s", ErrBadFormat, m.BlockID, err)
+	}
+	if !bytes.Equal(b.Hash, m.BlockHash) {
+		return fmt.Errorf("%w: hash of block %d", ErrStateHash, m.BlockID)
+	}
+	if !bytes.Equal(b.StateRoot, m.StateRoot) {
+		return fmt.Errorf("%w: state root of block %d", ErrStateHash, m.BlockID)
+	}
+	var ib model.InfoBlock
+	if err := model.GetDB(dbTransaction).First(&ib).Error; err != nil {
+		return fmt.Errorf("%w: info block: %s", ErrBadFormat, err)
+	}
+	if ib.BlockID != m.BlockID || !bytes.Equal(ib.Hash, m.BlockHash) || !bytes.Equal(ib.StateRoot, m.StateRoot) {
+		return fmt.Errorf("%w: info block doesn't match block %d", ErrStateHash, m.BlockID)
+	}
+	return nil
+}
+
+// sequence is the sequence which generates the values of the column
+type sequence struct {
+	name   string
+	column string
+}
+
+// importer writes the tables of snapshot in the database transaction
+type importer struct {
+	dbTransaction *model.DbTransaction
+	sequences     map[string]sequence
+}
+
+// Table recreates the rows of the existing table or creates the new table
+func (im *importer) Table(t *Table) error {
+	if isLocalTable(t.Name) {
+		return fmt.Errorf("%w: local table %s", ErrBadFormat, t.Name)
+	}
+	for _, c := range t.Columns {
+		if match := nextvalRe.FindStringSubmatch(c.Default); match != nil {
+			im.sequences[t.Name] = sequence{name: match[1], column: c.Name}
+		}
+	}
+
+	conn := model.GetDB(im.dbTransaction)
+	if model.Storage().HasTable(conn, t.Name, "table") {
+		return conn.Exec(fmt.Sprintf(`DELETE FROM "%s"`, t.Name)).Error
+	}
+
+	defs := make([]string, 0, len(t.Columns)+len(t.Constraints))
+	for _, c := range t.Columns {
+		def := fmt.Sprintf(`"%s" %s`, c.Name, c.Type)
+		if c.NotNull {
+			def += ` NOT NULL`
+		}
+		if len(c.Default) > 0 {
+			if strings.Contains(c.Default, `nextval(`) {
+				seq, ok := im.sequences[t.Name]
+				if !ok || seq.column != c.Name {
+					return fmt.Errorf("%w: default of %s.%s", ErrBadFormat, t.Name, c.Name)
+				}
+				if err := conn.Exec(`CREATE SEQUENCE IF NOT EXISTS ` + seq.name).Error; err != nil {
+					return err
+				}
+			}
+			def += ` DEFAULT ` + c.Default
+		}
+		defs = append(defs, def)
+	}
+	defs = append(defs, t.Constraints...)
+	if err := conn.Exec(fmt.Sprintf(`CREATE TABLE "%s" (%s)`, t.Name, strings.Join(defs, `, `))).Error; err != nil {
+		return err
+	}
+	for _, index := range t.Indexes {
+		if err := conn.Exec(index).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rows inserts the rows by batches
+func (im *importer) Rows(t *Table, rows [][]interface{}) error {
+	columns := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		columns[i] = `"` + c.Name + `"`
+	}
+	placeholder := `(?` + strings.Repeat(`,?`, len(columns)-1) + `)`
+	prefix := fmt.Sprintf(`INSERT INTO "%s" (%s) VALUES `, t.Name, strings.Join(columns, `,`))
+
+	batch := maxInsertValues / len(columns)
+	if batch == 0 {
+		batch = 1
+	}
+	for len(rows) > 0 {
+		n := batch
+		if n > len(rows) {
+			n = len(rows)
+		}
+		values := make([]interface{}, 0, n*len(columns))
+		for _, row := range rows[:n] {
+			values = append(values, row...)
+		}
+		query := prefix + placeholder + strings.Repeat(`,`+placeholder, n-1)
+		if err := model.GetDB(im.dbTransaction).Exec(query, values...).Error; err != nil {
+			return err
+		}
+		rows = rows[n:]
+	}
+	return nil
+}
+
+// resetSequences sets the sequences of the imported tables after the max values of their columns
+func (im *importer) resetSequences() error {
+	for table, seq := range im.sequences {
+		query := fmt.Sprintf(`SELECT setval('%s', COALESCE(MAX("%s"), 0) + 1, false) FROM "%s"`,
+			seq.name, seq.column, table)
+		if err := model.GetDB(im.dbTransaction).Exec(query).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
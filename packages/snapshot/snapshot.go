@@ -0,0 +1,172 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+const (
+	// Version is the version of the snapshot format
+	Version = 1
+	// DefaultChunkRows is the default count of rows in the chunk
+	DefaultChunkRows = 10000
+
+	manifestName = "manifest.json"
+	tablesDir    = "tables/"
+	schemaExt    = ".json"
+	chunkExt     = ".jsonl.gz"
+)
+
+var (
+	// ErrNotEmpty is returned when the snapshot is imported to the database with blocks
+	ErrNotEmpty = errors.New("database already contains blocks")
+	// ErrBadFormat is returned when the archive doesn't match the snapshot format
+	ErrBadFormat = errors.New("wrong format of snapshot")
+	// ErrChunkHash is returned when the hash of the chunk doesn't match the manifest
+	ErrChunkHash = errors.New("hash of snapshot chunk is incorrect")
+	// ErrStateHash is returned when the state hash doesn't match the manifest or the expected hash
+	ErrStateHash = errors.New("state hash of snapshot is incorrect")
+	// ErrNoStateHash is returned when the snapshot is imported without the trusted state hash
+	ErrNoStateHash = errors.New("trusted state hash of snapshot is required")
+)
+
+// skipTables are the tables of the node which don't belong to the state of blockchain
+var skipTables = map[string]bool{
+	"confirmations":         true,
+	"external_blockchain":   true,
+	"install":               true,
+	"light_headers":         true,
+	"multisig_txs":          true,
+	"my_node_keys":          true,
+	"queue_blocks":          true,
+	"queue_tx":              true,
+	"rollback_tx":           true,
+	"stop_daemons":          true,
+	"transactions":          true,
+	"transactions_attempts": true,
+	"transactions_status":   true,
+}
+
+// skipPrefixes are the prefixes of the private tables of subnodes and VDE
+var skipPrefixes = []string{"subnode_", "vde_"}
+
+// isLocalTable returns true if the table is the local table of the node
+func isLocalTable(name string) bool {
+	if skipTables[name] {
+		return true
+	}
+	for _, prefix := range skipPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Column is the column of the table
+type Column struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	NotNull bool   `json:"not_null,omitempty"`
+	Default string `json:"default,omitempty"`
+}
+
+// Table is the schema of the table with the list of its chunks
+type Table struct {
+	Name        string   `json:"name"`
+	Columns     []Column `json:"columns"`
+	Constraints []string `json:"constraints,omitempty"`
+	Indexes     []string `json:"indexes,omitempty"`
+	Chunks      []Chunk  `json:"chunks,omitempty"`
+}
+
+// Chunk is the part of the table rows
+type Chunk struct {
+	Rows int64  `json:"rows"`
+	Hash []byte `json:"hash"`
+}
+
+// Manifest describes the snapshot, it is the last file of the archive
+type Manifest struct {
+	Version     int      `json:"version"`
+	NetworkID   int64    `json:"network_id"`
+	BlockID     int64    `json:"block_id"`
+	BlockHash   []byte   `json:"block_hash"`
+	StateRoot   []byte   `json:"state_root,omitempty"`
+	BlockTime   int64    `json:"block_time"`
+	ChunkRows   int      `json:"chunk_rows"`
+	Tables      []*Table `json:"tables"`
+	StateHash   []byte   `json:"state_hash"`
+	CreatedTime int64    `json:"created_time"`
+}
+
+func isBytea(c Column) bool {
+	return c.Type == "bytea"
+}
+
+func schemaName(table string) string {
+	return tablesDir + table + schemaExt
+}
+
+func chunkName(table string, index int) string {
+	return fmt.Sprintf("%s%s/%06d%s", tablesDir, table, index, chunkExt)
+}
+
+// stateHash is calculating the hash of the snapshot state. It covers the block with its state root,
+// the schema of every table including the statements executed by the import and the hashes
+// of the chunks in the order of the manifest.
+func (m *Manifest) stateHash() []byte {
+	h := sha256.New()
+	writeInt(h, int64(m.Version))
+	writeInt(h, m.NetworkID)
+	writeInt(h, m.BlockID)
+	writeBytes(h, m.BlockHash)
+	writeBytes(h, m.StateRoot)
+	for _, t := range m.Tables {
+		writeBytes(h, []byte(t.Name))
+		for _, c := range t.Columns {
+			writeBytes(h, []byte(c.Name))
+			writeBytes(h, []byte(c.Type))
+			writeBytes(h, []byte(c.Default))
+			if c.NotNull {
+				writeInt(h, 1)
+			} else {
+				writeInt(h, 0)
+			}
+		}
+		writeInt(h, int64(len(t.Constraints)))
+		for _, c := range t.Constraints {
+			writeBytes(h, []byte(c))
+		}
+		writeInt(h, int64(len(t.Indexes)))
+		for _, index := range t.Indexes {
+			writeBytes(h, []byte(index))
+		}
+		writeInt(h, int64(len(t.Chunks)))
+		for _, c := range t.Chunks {
+			writeInt(h, c.Rows)
+			writeBytes(h, c.Hash)
+		}
+	}
+	return h.Sum(nil)
+}
+
+func writeInt(h hash.Hash, v int64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(v))
+	h.Write(buf[:])
+}
+
+func writeBytes(h hash.Hash, b []byte) {
+	writeInt(h, int64(len(b)))
+	h.Write(b)
+}
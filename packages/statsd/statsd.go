@@ -12,8 +12,11 @@ import (
 )
 
 const (
-	Count = ".count"
-	Time  = ".time"
+	Count           = ".count"
+	Time            = ".time"
+	RawBytes        = ".raw_bytes"
+	CompressedBytes = ".compressed_bytes"
+	Ratio           = ".ratio"
 )
 
 var Client statsd.Statter
@@ -34,3 +37,11 @@ func Close() {
 func DaemonCounterName(daemonName string) string {
 	return "daemon." + daemonName
 }
+
+func BlocksCollectionCounterName(stage string) string {
+	return "blocks_collection." + stage
+}
+
+func CompressionCounterName(requestType int) string {
+	return fmt.Sprintf("network.compression.%d", requestType)
+}
@@ -0,0 +1,142 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package transaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// maxCancelHashes is the max count of transactions which can be dropped by one cancel
+const maxCancelHashes = 100
+
+var (
+	ErrCancelHashes = utils.WithBan(errors.New("Wrong count of canceled transactions"))
+	ErrCancelKey    = utils.WithBan(errors.New("Public key of cancel doesn't match KeyID"))
+	ErrCancelSign   = utils.WithBan(errors.New("Incorrect sign of cancel"))
+	ErrCancelNet    = errors.New("Cancel is signed for other network")
+)
+
+func (t *Transaction) parseFromCancel() error {
+	cancel := tx.Cancel{}
+	if err := msgpack.Unmarshal(t.TxBinaryData, &cancel); err != nil {
+		log.WithFields(log.Fields{"tx_hash": t.TxHash, "error": err, "type": consts.UnmarshallingError}).Error("unmarshalling cancel msgpack")
+		return err
+	}
+	t.TxPtr = nil
+	t.TxCancel = &cancel
+	t.TxHeader = &cancel.Header
+	t.TxTime = cancel.Time
+	t.TxKeyID = cancel.KeyID
+	return nil
+}
+
+// checkCancel checks that the cancel is signed by the key of account
+func (t *Transaction) checkCancel() error {
+	logger := log.WithFields(log.Fields{"tx_hash": hex.EncodeToString(t.TxHash), "key_id": t.TxKeyID})
+	if len(t.TxCancel.Hashes) == 0 || len(t.TxCancel.Hashes) > maxCancelHashes {
+		logger.WithFields(log.Fields{"type": consts.ParameterExceeded, "count": len(t.TxCancel.Hashes)}).Error("wrong count of canceled transactions")
+		return ErrCancelHashes
+	}
+	if t.TxCancel.NetworkID != conf.Config.NetworkID {
+		logger.WithFields(log.Fields{"type": consts.InvalidObject, "network_id": t.TxCancel.NetworkID}).Error("cancel is signed for other network")
+		return ErrCancelNet
+	}
+	if crypto.Address(t.TxCancel.PublicKey) != t.TxKeyID {
+		logger.WithFields(log.Fields{"type": consts.AccessDenied}).Error("public key of cancel doesn't match key id")
+		return ErrCancelKey
+	}
+	ok, err := utils.CheckSign([][]byte{crypto.CutPub(t.TxCancel.PublicKey)}, t.TxHash, t.TxSignature, false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logger.WithFields(log.Fields{"type": consts.CryptoError}).Error("incorrect sign of cancel")
+		return ErrCancelSign
+	}
+	return nil
+}
+
+// cancelPending drops the pending transactions of the account which are listed in the cancel,
+// batch is the transactions which have been verified but haven't been saved yet.
+// It returns the hashes of dropped transactions.
+func cancelPending(dbTransaction *model.DbTransaction, t *Transaction, batch []*model.Transaction) ([][]byte, error) {
+	var dropped [][]byte
+	found := make(map[string]bool)
+	drop := func(hash []byte, keyID int64) {
+		if keyID == t.TxKeyID && !found[string(hash)] {
+			found[string(hash)] = true
+			dropped = append(dropped, hash)
+		}
+	}
+
+	txs, err := model.GetManyTransactions(dbTransaction, t.TxCancel.Hashes)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting canceled transactions")
+		return nil, err
+	}
+	for _, item := range txs {
+		if item.Used == 0 {
+			drop(item.Hash, item.KeyID)
+		}
+	}
+	for _, item := range batch {
+		for _, hash := range t.TxCancel.Hashes {
+			if bytes.Equal(item.Hash, hash) {
+				drop(item.Hash, item.KeyID)
+			}
+		}
+	}
+	for _, hash := range t.TxCancel.Hashes {
+		qtx := &model.QueueTx{}
+		ok, err := qtx.GetByHash(dbTransaction, hash)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting canceled transaction from queue")
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if qt, err := UnmarshallTransaction(bytes.NewBuffer(qtx.Data), false); err == nil {
+			drop(qtx.Hash, qt.TxKeyID)
+		}
+	}
+
+	for _, hash := range dropped {
+		if err = MarkTransactionBad(dbTransaction, hash, ErrCanceled.Error()); err != nil {
+			return nil, err
+		}
+	}
+	return dropped, nil
+}
+
+// withoutTransactions returns the transactions of batch except the transactions with the hashes
+func withoutTransactions(batch []*model.Transaction, hashes [][]byte) []*model.Transaction {
+	result := batch[:0]
+	for _, item := range batch {
+		found := false
+		for _, hash := range hashes {
+			if bytes.Equal(item.Hash, hash) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, item)
+		}
+	}
+	return result
+}
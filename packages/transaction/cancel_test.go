@@ -0,0 +1,66 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package transaction
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckValidUntil(t *testing.T) {
+	tr := &Transaction{TxHeader: &tx.Header{ValidUntil: 100}}
+	assert.NoError(t, tr.CheckValidUntil(100))
+	assert.Equal(t, ErrValidUntil, tr.CheckValidUntil(101))
+
+	// the time isn't limited without ValidUntil
+	tr.TxHeader.ValidUntil = 0
+	assert.NoError(t, tr.CheckValidUntil(time.Now().Unix()))
+}
+
+func TestCheckCancel(t *testing.T) {
+	private, public, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+
+	newCancel := func(change func(*tx.Cancel)) *Transaction {
+		cancel := tx.Cancel{
+			Header: tx.Header{
+				Time:      time.Now().Unix(),
+				KeyID:     crypto.Address(public),
+				NetworkID: conf.Config.NetworkID,
+			},
+			Hashes: [][]byte{crypto.DoubleHash([]byte("pending"))},
+		}
+		change(&cancel)
+		data, hash, err := tx.NewCancel(cancel, private)
+		require.NoError(t, err)
+		tr, err := UnmarshallTransaction(bytes.NewBuffer(data), false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(consts.TxTypeCancel), tr.TxType)
+		assert.Equal(t, hash, tr.TxHash)
+		return tr
+	}
+
+	assert.NoError(t, newCancel(func(*tx.Cancel) {}).checkCancel())
+	assert.Equal(t, ErrCancelKey, newCancel(func(c *tx.Cancel) { c.KeyID++ }).checkCancel())
+	assert.Equal(t, ErrCancelNet, newCancel(func(c *tx.Cancel) { c.NetworkID++ }).checkCancel())
+	assert.Equal(t, ErrCancelHashes, newCancel(func(c *tx.Cancel) { c.Hashes = nil }).checkCancel())
+
+	// the cancel which has been signed by other key
+	tr := newCancel(func(*tx.Cancel) {})
+	other, _, err := crypto.GenKeyPair()
+	require.NoError(t, err)
+	tr.TxSignature, err = crypto.Sign(other, tr.TxHash)
+	require.NoError(t, err)
+	assert.Error(t, tr.checkCancel())
+}
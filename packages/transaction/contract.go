@@ -10,11 +10,12 @@ import (
 	"time"
 
 	"github.com/IBAX-io/go-ibax/packages/conf"
+	"github.com/IBAX-io/go-ibax/packages/signer"
 	errUnknownContract = `Cannot find %s contract`
 )
 
 func CreateContract(contractName string, keyID int64, params map[string]interface{},
-	privateKey []byte) error {
+	s signer.Signer) error {
 	ecosysID, _ := converter.ParseName(contractName)
 	if ecosysID == 0 {
 		ecosysID = 1
@@ -33,7 +34,7 @@ func CreateContract(contractName string, keyID int64, params map[string]interfac
 		},
 		Params: params,
 	}
-	txData, _, err := tx.NewTransaction(sc, privateKey)
+	txData, _, err := tx.NewSignerTransaction(sc, s)
 	if err == nil {
 		rtx := &RawTransaction{}
 		if err = rtx.Unmarshall(bytes.NewBuffer(txData)); err == nil {
@@ -6,6 +6,7 @@ package transaction
 
 import (
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -13,7 +14,9 @@ import (
 
 	"github.com/IBAX-io/go-ibax/packages/conf/syspar"
 	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/mempool"
 	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/pubsub"
 	"github.com/IBAX-io/go-ibax/packages/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -25,6 +28,8 @@ var (
 	ErrExpiredTime  = errors.New("Transaction processing time is expired")
 	ErrEarlyTime    = utils.WithBan(errors.New("Early transaction time"))
 	ErrEmptyKey     = utils.WithBan(errors.New("KeyID is empty"))
+	ErrValidUntil   = errors.New("Transaction valid until time has passed")
+	ErrCanceled     = errors.New("Transaction has been canceled")
 )
 
 // InsertInLogTx is inserting tx in log
@@ -82,6 +87,7 @@ func DeleteQueueTx(dbTransaction *model.DbTransaction, hash []byte) error {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Debug("deleting transaction if unused")
 		return err
 	}
+	mempool.GetPool().Remove(hash)
 	//err = model.DeleteTransactionsAttemptsByHash(dbTransaction, hash)
 	//if err != nil {
 	//	log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Debug("deleting DeleteTransactionsAttemptsByHash")
@@ -99,7 +105,7 @@ func MarkTransactionBad(dbTransaction *model.DbTransaction, hash []byte, errText
 	}
 	log.WithFields(log.Fields{"type": consts.BadTxError, "tx_hash": hash, "error": errText}).Debug("tx marked as bad")
 
-	return model.NewDbTransaction(model.DBConn).Connection().Transaction(func(tx *gorm.DB) error {
+	err := model.NewDbTransaction(model.DBConn).Connection().Transaction(func(tx *gorm.DB) error {
 		// looks like there is not hash in queue_tx in this moment
 		qtx := &model.QueueTx{}
 		_, err := qtx.GetByHash(model.NewDbTransaction(tx), hash)
@@ -123,6 +129,10 @@ func MarkTransactionBad(dbTransaction *model.DbTransaction, hash []byte, errText
 		}
 		return nil
 	})
+	if err == nil {
+		pubsub.Publish(pubsub.NewTxStatus(hash, 0, errText))
+	}
+	return err
 }
 
 // ProcessQueueTransaction writes transactions into the queue
@@ -187,6 +197,11 @@ func ProcessTransactionsQueue(dbTransaction *model.DbTransaction) error {
 		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting all unverified and unused transactions")
 		return err
 	}
+	// the cancels are kept until their time is expired to skip them if they are received again
+	if _, err = model.DeleteUsedCancelsBefore(dbTransaction, time.Now().Unix()-consts.MAX_TX_BACK); err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("deleting expired cancels")
+		return err
+	}
 	//for i := 0; i < len(all); i++ {
 	//	err := ProcessQueueTransaction(dbTransaction, all[i].Hash, all[i].Data, false)
 	//	if err != nil {
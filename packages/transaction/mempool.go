@@ -0,0 +1,133 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package transaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/mempool"
+	"github.com/IBAX-io/go-ibax/packages/model"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ErrMempoolEvicted is the error of pending transaction which has been replaced
+// by the transaction with a greater expedite or evicted from the full mempool
+var ErrMempoolEvicted = errors.New("Transaction has been replaced or evicted from mempool")
+
+// mempoolSlot returns the key of the contract call without the expedite.
+// The call with the same key and a greater expedite replaces the pending transaction of the account.
+func mempoolSlot(smartTx *tx.SmartContract) string {
+	if smartTx == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode([]interface{}{smartTx.ID, smartTx.EcosystemID, smartTx.Time, smartTx.Params}); err != nil {
+		log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling contract call for mempool")
+		return ""
+	}
+	return hex.EncodeToString(crypto.Hash(buf.Bytes()))
+}
+
+func mempoolEntry(t *Transaction, mtx *model.Transaction) *mempool.Entry {
+	return &mempool.Entry{
+		Hash:     mtx.Hash,
+		KeyID:    mtx.KeyID,
+		HighRate: int8(mtx.HighRate),
+		Expedite: mtx.Expedite,
+		Time:     mtx.Time,
+		Size:     len(mtx.Data),
+		Slot:     mempoolSlot(t.TxSmart),
+	}
+}
+
+// fillSlots rebuilds the slots of the transactions which are added to the mempool from the transactions table,
+// e.g. after the restart of node or the rollback of block, so they can be replaced as before
+func fillSlots(dbTransaction *model.DbTransaction, list []*mempool.Entry) error {
+	if len(list) == 0 {
+		return nil
+	}
+	hashes := make([][]byte, len(list))
+	for i, e := range list {
+		hashes[i] = e.Hash
+	}
+	txs, err := model.GetManyTransactions(dbTransaction, hashes)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting transactions for mempool")
+		return err
+	}
+	slots := make(map[string]string, len(txs))
+	for _, mtx := range txs {
+		rtx := &RawTransaction{}
+		if err := rtx.Unmarshall(bytes.NewBuffer(mtx.Data)); err != nil || !IsContractTransaction(rtx.Type()) {
+			continue
+		}
+		smartTx := rtx.SmartTx()
+		slots[string(mtx.Hash)] = mempoolSlot(&smartTx)
+	}
+	for _, e := range list {
+		e.Slot = slots[string(e.Hash)]
+	}
+	return nil
+}
+
+// PendingTransactions syncs the mempool with the transactions table and returns
+// the unused transactions in the order of mempool
+func PendingTransactions(dbTransaction *model.DbTransaction, limit int) ([]*model.Transaction, error) {
+	infos, err := model.GetAllUnusedTransactionsInfo(dbTransaction)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting unused transactions info")
+		return nil, err
+	}
+	list := make([]*mempool.Entry, len(infos))
+	for i, info := range infos {
+		list[i] = &mempool.Entry{
+			Hash:     info.Hash,
+			KeyID:    info.KeyID,
+			HighRate: info.HighRate,
+			Expedite: info.Expedite,
+			Time:     info.Time,
+			Size:     info.Size,
+		}
+	}
+	pool := mempool.GetPool()
+	if err = fillSlots(dbTransaction, pool.Missing(list)); err != nil {
+		return nil, err
+	}
+	pool.Sync(list)
+
+	pending := pool.Pending(limit)
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	hashes := make([][]byte, len(pending))
+	for i, e := range pending {
+		hashes[i] = e.Hash
+	}
+	txs, err := model.GetManyTransactions(dbTransaction, hashes)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.DBError, "error": err}).Error("getting pending transactions")
+		return nil, err
+	}
+	byHash := make(map[string]*model.Transaction, len(txs))
+	for i := range txs {
+		byHash[string(txs[i].Hash)] = &txs[i]
+	}
+	trs := make([]*model.Transaction, 0, len(txs))
+	for _, hash := range hashes {
+		if t, ok := byHash[string(hash)]; ok {
+			trs = append(trs, t)
+		}
+	}
+	return trs, nil
+}
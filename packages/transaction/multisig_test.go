@@ -0,0 +1,65 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package transaction
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/utils/tx"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestCheckMultisign(t *testing.T) {
+	var privates, signers [][]byte
+	for i := 0; i < 3; i++ {
+		private, public, err := crypto.GenKeyPair()
+		require.NoError(t, err)
+		privates = append(privates, private)
+		signers = append(signers, public)
+	}
+	payload, err := msgpack.Marshal(tx.SmartContract{
+		Header: tx.Header{
+			ID:          1,
+			Time:        time.Now().Unix(),
+			EcosystemID: 1,
+			KeyID:       crypto.Address(signers[0]),
+			Signers:     signers,
+		},
+	})
+	require.NoError(t, err)
+	hash := crypto.DoubleHash(payload)
+
+	signatures := make([][]byte, 0, len(privates))
+	for _, private := range privates {
+		signature, err := crypto.Sign(private, hash)
+		require.NoError(t, err)
+		signatures = append(signatures, signature)
+	}
+	checkSign := func(signatures ...[]byte) (bool, error) {
+		rtx := &RawTransaction{}
+		require.NoError(t, rtx.Unmarshall(bytes.NewBuffer(tx.CombineSignatures(payload, signatures))))
+		assert.Equal(t, hash, rtx.Hash())
+		return rtx.CheckSign()
+	}
+
+	ok, err := checkSign(signatures...)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the signatures must follow in the order of signers
+	ok, _ = checkSign(signatures[1], signatures[0], signatures[2])
+	assert.False(t, ok)
+	// every signer must sign the transaction
+	ok, _ = checkSign(signatures[:2]...)
+	assert.False(t, ok)
+	ok, _ = checkSign(append(signatures, signatures[0])...)
+	assert.False(t, ok)
+}
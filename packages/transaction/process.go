@@ -5,7 +5,10 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
 
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/mempool"
 	"github.com/IBAX-io/go-ibax/packages/model"
 	defer func() {
 		if err != nil {
@@ -15,7 +18,13 @@ import (
 			}
 		}
 	}()
+	var evicted [][]byte
+	canceled := make(map[string]bool)
 	for i := 0; i < len(qs); i++ {
+		if canceled[string(qs[i].Hash)] {
+			// it has been dropped from the queue by the cancel from this batch
+			continue
+		}
 		binaryTx := qs[i].Data
 		hs = qs[i].Hash
 		tx := &Transaction{}
@@ -27,6 +36,35 @@ import (
 		if err != nil {
 			return err
 		}
+		// the expired transaction can't be played in the next blocks
+		if err = tx.CheckValidUntil(checkTime); err != nil {
+			return err
+		}
+		if tx.TxType == consts.TxTypeCancel {
+			if err = tx.checkCancel(); err != nil {
+				return err
+			}
+			var dropped [][]byte
+			if dropped, err = cancelPending(dbTransaction, tx, trxs); err != nil {
+				return err
+			}
+			for _, h := range dropped {
+				canceled[string(h)] = true
+			}
+			trxs = withoutTransactions(trxs, dropped)
+			// the cancel is kept as used to be sent to other nodes, it is never played in the block
+			trxs = append(trxs, &model.Transaction{
+				Hash:     hs,
+				Data:     binaryTx,
+				Type:     int8(tx.TxType),
+				KeyID:    tx.TxKeyID,
+				Time:     tx.TxTime,
+				Verified: 1,
+				Used:     1,
+			})
+			hashes = append(hashes, hs)
+			continue
+		}
 		var expedite decimal.Decimal
 		if len(tx.TxSmart.Expedite) > 0 {
 			expedite, err = decimal.NewFromString(tx.TxSmart.Expedite)
@@ -45,8 +83,24 @@ import (
 			Used:     0,
 			Sent:     0,
 		}
+		removed, errPool := mempool.GetPool().Add(mempoolEntry(tx, newTx))
+		if errPool != nil {
+			if err = MarkTransactionBad(dbTransaction, hs, errPool.Error()); err != nil {
+				return err
+			}
+			continue
+		}
 		trxs = append(trxs, newTx)
 		hashes = append(hashes, hs)
+		evicted = append(evicted, removed...)
+	}
+
+	// the transactions which have been replaced or evicted can be in this batch or in the transactions table
+	trxs = withoutTransactions(trxs, evicted)
+	for _, h := range evicted {
+		if errBad := MarkTransactionBad(dbTransaction, h, ErrMempoolEvicted.Error()); errBad != nil {
+			log.WithFields(log.Fields{"type": consts.DBError, "error": errBad, "tx_hash": h}).Error("marking evicted transaction as bad")
+		}
 	}
 
 	if len(trxs) > 0 {
@@ -65,6 +65,20 @@ func (rtx *RawTransaction) Unmarshall(buffer *bytes.Buffer) error {
 		}
 		rtx.expedite = rtx.smartTx.Expedite
 		rtx.time = rtx.smartTx.Time
+	} else if rtx.txType == consts.TxTypeCancel {
+		if err = converter.BinUnmarshalBuff(buffer, &rtx.payload); err != nil {
+			return err
+		}
+		rtx.signature = buffer.Bytes()
+
+		cancel := tx.Cancel{}
+		if err = msgpack.Unmarshal(rtx.Payload(), &cancel); err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("on unmarshalling to cancel")
+			return err
+		}
+		// the header is checked and signed in the same way as the header of contract
+		rtx.smartTx.Header = cancel.Header
+		rtx.time = cancel.Time
 	} else {
 		buffer.UnreadByte()
 		rtx.payload = buffer.Bytes()
@@ -124,14 +138,27 @@ func (rtx *RawTransaction) Processing(txData []byte) error {
 	if len(strings.TrimSpace(rtx.SmartTx().Lang)) > 2 {
 		return fmt.Errorf(`localization size is greater than 2`)
 	}
-	var PublicKeys [][]byte
-	PublicKeys = append(PublicKeys, crypto.CutPub(rtx.SmartTx().PublicKey))
-	_, err := utils.CheckSign(PublicKeys, rtx.Hash(), rtx.Signature(), false)
+	_, err := rtx.CheckSign()
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// CheckSign checks the signature of transaction. The transaction of multi-signature account
+// is checked by the public keys of its signers, they are checked against the account when it is played.
+func (rtx *RawTransaction) CheckSign() (bool, error) {
+	if signers := rtx.SmartTx().Signers; len(signers) > 0 {
+		publicKeys := make([][]byte, 0, len(signers))
+		for _, signer := range signers {
+			publicKeys = append(publicKeys, crypto.CutPub(signer))
+		}
+		return utils.CheckMultiSign(publicKeys, rtx.Hash(), rtx.Signature())
+	}
+	var PublicKeys [][]byte
+	PublicKeys = append(PublicKeys, crypto.CutPub(rtx.SmartTx().PublicKey))
+	return utils.CheckSign(PublicKeys, rtx.Hash(), rtx.Signature(), false)
+}
 func (rtx *RawTransaction) SetRawTx() *model.RawTx {
 	return &model.RawTx{
 		Hash:     rtx.Hash(),
@@ -162,6 +189,7 @@ type Transaction struct {
 	TxPtr         interface{}     // Pointer to the corresponding struct in consts/struct.go
 	TxData        map[string]interface{}
 	TxSmart       *tx.SmartContract
+	TxCancel      *tx.Cancel
 	TxContract    *smart.Contract
 	TxHeader      *tx.Header
 	tx            custom.TransactionInterface
@@ -174,6 +202,7 @@ type Transaction struct {
 
 	SmartContract *smart.SmartContract
 	RollBackTx    []*model.RollbackTx
+	Trace         *script.Trace
 }
 
 // GetLogger returns logger
@@ -215,6 +244,11 @@ func UnmarshallTransaction(buffer *bytes.Buffer, fillData bool) (*Transaction, e
 		if err := t.parseFromContract(fillData); err != nil {
 			return nil, err
 		}
+	} else if rtx.Type() == consts.TxTypeCancel {
+		t.TxSignature = rtx.Signature()
+		if err := t.parseFromCancel(); err != nil {
+			return nil, err
+		}
 		// struct transaction (only first block transaction for now)
 	} else if consts.IsStruct(rtx.Type()) {
 		if err := t.parseFromStruct(); err != nil {
@@ -370,6 +404,16 @@ func (tx *Transaction) CheckTime(checkTime int64) error {
 	return nil
 }
 
+// CheckValidUntil checks that the time of block isn't greater than the ValidUntil time of transaction.
+// It is checked in the blocks from the version BvValidUntil.
+func (t *Transaction) CheckValidUntil(checkTime int64) error {
+	if t.TxHeader == nil || t.TxHeader.ValidUntil == 0 || checkTime <= t.TxHeader.ValidUntil {
+		return nil
+	}
+	log.WithFields(log.Fields{"tx_hash": hex.EncodeToString(t.TxHash), "valid_until": t.TxHeader.ValidUntil, "check_time": checkTime, "type": consts.ParameterExceeded}).Error("transaction is expired by valid until time")
+	return ErrValidUntil
+}
+
 func (t *Transaction) Play(point int) (string, []smart.FlushInfo, error) {
 	// smart-contract
 	if t.TxContract != nil {
@@ -408,6 +452,7 @@ func (t *Transaction) CallContract(point int) (resultContract string, flushRollb
 		TimeLimit:     t.TimeLimit,
 		Notifications: t.Notifications,
 		RollBackTx:    make([]*model.RollbackTx, 0),
+		Trace:         t.Trace,
 	}
 	resultContract, err = sc.CallContract(point)
 	t.RollBackTx = sc.RollBackTx
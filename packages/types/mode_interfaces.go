@@ -21,6 +21,16 @@ type SmartContractRunner interface {
 	RunContract(data, hash []byte, keyID, tnow int64, le *log.Entry) error
 }
 
+// TxTracer replays the transaction and returns the trace of its execution
+type TxTracer interface {
+	TraceTx(hash []byte, maxSteps int, le *log.Entry) (interface{}, error)
+}
+
+// TxSimulator executes the contract without the signature and discards its changes
+type TxSimulator interface {
+	SimulateTx(contract string, ecosystemID, keyID int64, params map[string]interface{}, expedite string, le *log.Entry) (interface{}, error)
+}
+
 type DaemonListFactory interface {
 	GetDaemonsList() []string
 }
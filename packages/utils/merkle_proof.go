@@ -0,0 +1,161 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+)
+
+var (
+	// ErrMerkleIndex is returned when the leaf is out of the Merkle tree
+	ErrMerkleIndex = errors.New("Index of leaf is out of Merkle tree")
+	// ErrProofTxHash is returned when the transaction data of proof doesn't match the transaction hash
+	ErrProofTxHash = errors.New("Transaction hash doesn't match the transaction data")
+	// ErrProofMrklRoot is returned when the Merkle path doesn't lead to the Merkle root of block
+	ErrProofMrklRoot = errors.New("Merkle path doesn't match the Merkle root")
+	// ErrProofBlockHash is returned when the block header of proof doesn't match the block hash
+	ErrProofBlockHash = errors.New("Block header doesn't match the block hash")
+)
+
+// MerkleProofStep is the sibling node on the path from the leaf to the Merkle root
+type MerkleProofStep struct {
+	Hash []byte `json:"hash"`
+	// Left is true if the sibling is on the left side
+	Left bool `json:"left,omitempty"`
+}
+
+// TxProof is the proof of the inclusion of transaction in the block.
+// It contains the fields of block header which are required to calculate the block hash.
+type TxProof struct {
+	BlockID           int64             `json:"block_id"`
+	Time              int64             `json:"time"`
+	EcosystemID       int64             `json:"ecosystem_id"`
+	KeyID             int64             `json:"key_id"`
+	NodePosition      int64             `json:"node_position"`
+	Version           int               `json:"version"`
+	PrevHash          []byte            `json:"prev_hash"`
+	PrevRollbacksHash []byte            `json:"prev_rollbacks_hash,omitempty"`
+	PrevStateRoot     []byte            `json:"prev_state_root,omitempty"`
+	MrklRoot          []byte            `json:"mrkl_root"`
+	TxHash            []byte            `json:"tx_hash"`
+	TxData            []byte            `json:"tx_data"`
+	Path              []MerkleProofStep `json:"path"`
+}
+
+// BlockHash returns the hash of block calculated from the header and the Merkle root of the proof
+func (p *TxProof) BlockHash() []byte {
+	header := BlockData{
+		BlockID:      p.BlockID,
+		Time:         p.Time,
+		EcosystemID:  p.EcosystemID,
+		KeyID:        p.KeyID,
+		NodePosition: p.NodePosition,
+		Version:      p.Version,
+	}
+	prev := BlockData{
+		Hash:          p.PrevHash,
+		RollbacksHash: p.PrevRollbacksHash,
+		StateRoot:     p.PrevStateRoot,
+	}
+	return crypto.DoubleHash([]byte(header.ForSha(&prev, p.MrklRoot)))
+}
+
+// Verify checks that the transaction of proof is included in the block with blockHash.
+// The block hash must be received from the trusted source.
+func (p *TxProof) Verify(blockHash []byte) error {
+	hash, err := txHash(p.TxData)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, p.TxHash) {
+		return ErrProofTxHash
+	}
+	if !bytes.Equal(MerkleProofRoot(MrklLeaf(p.TxData), p.Path), p.MrklRoot) {
+		return ErrProofMrklRoot
+	}
+	if !bytes.Equal(p.BlockHash(), blockHash) {
+		return ErrProofBlockHash
+	}
+	return nil
+}
+
+// txHash returns the hash of the transaction data in the same way as the node
+func txHash(data []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(data)
+	txType, err := buf.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	payload := data
+	if txType > 127 || txType == consts.TxTypeApiContract || txType == consts.TxTypeEcosystemMiner ||
+		txType == consts.TxTypeSystemMiner {
+		if err = converter.BinUnmarshalBuff(buf, &payload); err != nil {
+			return nil, err
+		}
+	}
+	return crypto.DoubleHash(payload), nil
+}
+
+// MrklLeaf returns the leaf of transactions Merkle tree for the transaction data as in MarshallBlock
+func MrklLeaf(txData []byte) []byte {
+	return converter.BinToHex(crypto.DoubleHash(txData))
+}
+
+func merkleNode(left, right []byte) []byte {
+	data := make([]byte, 0, len(left)+len(right))
+	data = append(data, left...)
+	return converter.BinToHex(crypto.DoubleHash(append(data, right...)))
+}
+
+// MerkleTreeProof returns the path from the leaf with index to the root of the tree built by MerkleTreeRoot
+func MerkleTreeProof(dataArray [][]byte, index int) ([]MerkleProofStep, error) {
+	if index < 0 || index >= len(dataArray) {
+		return nil, ErrMerkleIndex
+	}
+	level := make([][]byte, len(dataArray))
+	for i, v := range dataArray {
+		level[i] = converter.BinToHex(crypto.DoubleHash(v))
+	}
+	path := make([]MerkleProofStep, 0)
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			// the last odd node is moved to the next level as is
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			switch index {
+			case i:
+				path = append(path, MerkleProofStep{Hash: level[i+1]})
+			case i + 1:
+				path = append(path, MerkleProofStep{Hash: level[i], Left: true})
+			}
+			next = append(next, merkleNode(level[i], level[i+1]))
+		}
+		index /= 2
+		level = next
+	}
+	return path, nil
+}
+
+// MerkleProofRoot returns the Merkle root calculated from the leaf data and its path
+func MerkleProofRoot(data []byte, path []MerkleProofStep) []byte {
+	hash := converter.BinToHex(crypto.DoubleHash(data))
+	for _, step := range path {
+		if step.Left {
+			hash = merkleNode(step.Hash, hash)
+		} else {
+			hash = merkleNode(hash, step.Hash)
+		}
+	}
+	return hash
+}
@@ -0,0 +1,38 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleTreeProof(t *testing.T) {
+	crypto.InitHash("SHA256")
+	for n := 1; n <= 9; n++ {
+		data := make([][]byte, n)
+		for i := range data {
+			data[i] = []byte(fmt.Sprintf("tx%d", i))
+		}
+		root, err := MerkleTreeRoot(data)
+		require.NoError(t, err)
+		for i := range data {
+			path, err := MerkleTreeProof(data, i)
+			require.NoError(t, err)
+			assert.Equal(t, root, MerkleProofRoot(data[i], path), "leaf %d of %d", i, n)
+			if n > 1 {
+				assert.NotEqual(t, root, MerkleProofRoot([]byte("other"), path))
+			}
+		}
+	}
+	_, err := MerkleTreeProof([][]byte{[]byte("tx")}, 1)
+	assert.Equal(t, ErrMerkleIndex, err)
+}
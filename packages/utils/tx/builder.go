@@ -13,14 +13,16 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/vmihailenco/msgpack/v5"
 	"github.com/IBAX-io/go-ibax/packages/crypto"
+	"github.com/IBAX-io/go-ibax/packages/signer"
 )
 
-func newTransaction(smartTx SmartContract, privateKey []byte, internal bool) (data, hash []byte, err error) {
-	var publicKey []byte
-	if publicKey, err = crypto.PrivateToPublic(privateKey); err != nil {
-		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("converting node private key to public")
+func newTransaction(smartTx SmartContract, s signer.Signer, internal bool) (data, hash []byte, err error) {
+	if s == nil {
+		err = signer.ErrEmptyKey
+		log.WithFields(log.Fields{"type": consts.EmptyObject, "error": err}).Error("signer of node key is empty")
 		return
 	}
+	publicKey := s.PublicKey()
 	smartTx.PublicKey = publicKey
 
 	if internal {
@@ -32,7 +34,7 @@ func newTransaction(smartTx SmartContract, privateKey []byte, internal bool) (da
 		return
 	}
 	hash = crypto.DoubleHash(data)
-	signature, err := crypto.Sign(privateKey, hash)
+	signature, err := s.Sign(hash)
 	if err != nil {
 		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing by node private key")
 		return
@@ -42,12 +44,23 @@ func newTransaction(smartTx SmartContract, privateKey []byte, internal bool) (da
 	return
 }
 
-func NewInternalTransaction(smartTx SmartContract, privateKey []byte) (data, hash []byte, err error) {
-	return newTransaction(smartTx, privateKey, true)
+// NewInternalTransaction returns the transaction of node which is signed by the signer of node key
+func NewInternalTransaction(smartTx SmartContract, s signer.Signer) (data, hash []byte, err error) {
+	return newTransaction(smartTx, s, true)
 }
 
 func NewTransaction(smartTx SmartContract, privateKey []byte) (data, hash []byte, err error) {
-	return newTransaction(smartTx, privateKey, false)
+	s, err := signer.NewLocalSigner(privateKey)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("converting private key to public")
+		return
+	}
+	return newTransaction(smartTx, s, false)
+}
+
+// NewSignerTransaction returns the transaction which is signed by the signer
+func NewSignerTransaction(smartTx SmartContract, s signer.Signer) (data, hash []byte, err error) {
+	return newTransaction(smartTx, s, false)
 }
 
 // CreateTransaction creates transaction
@@ -0,0 +1,43 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tx
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/consts"
+	"github.com/IBAX-io/go-ibax/packages/converter"
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Cancel is the signed request of account to drop its pending transactions from the queue.
+// The transactions which have been played or belong to other accounts aren't dropped.
+// Replace the pending transaction by sending the cancel and the new transaction.
+type Cancel struct {
+	Header
+	Hashes [][]byte
+}
+
+// NewCancel returns the binary cancel transaction which is signed by the private key of account
+func NewCancel(cancel Cancel, privateKey []byte) (data, hash []byte, err error) {
+	if cancel.PublicKey, err = crypto.PrivateToPublic(privateKey); err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("converting private key to public")
+		return
+	}
+	if data, err = msgpack.Marshal(cancel); err != nil {
+		log.WithFields(log.Fields{"type": consts.MarshallingError, "error": err}).Error("marshalling cancel to msgpack")
+		return
+	}
+	hash = crypto.DoubleHash(data)
+	signature, err := crypto.Sign(privateKey, hash)
+	if err != nil {
+		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("signing cancel by private key")
+		return
+	}
+
+	data = append(append([]byte{consts.TxTypeCancel}, converter.EncodeLengthPlusData(data)...), converter.EncodeLengthPlusData(signature)...)
+	return
+}
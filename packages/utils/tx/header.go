@@ -10,4 +10,9 @@ type Header struct {
 	//
 	//Add sub node processing
 	PrivateFor []string
+	// ValidUntil is the unix time after which the transaction can't be played, it isn't limited if it is zero.
+	// It is omitted if it is empty to keep the hashes of transactions of previous versions.
+	ValidUntil int64 `msgpack:",omitempty"`
+	// Signers are the public keys of signers of multi-signature account, the signatures follow in the same order
+	Signers [][]byte `msgpack:",omitempty"`
 }
@@ -0,0 +1,19 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+package tx
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/converter"
+)
+
+// CombineSignatures returns the binary transaction of multi-signature account,
+// signatures must be in the same order as the signers of payload
+func CombineSignatures(payload []byte, signatures [][]byte) []byte {
+	data := append([]byte{128}, converter.EncodeLengthPlusData(payload)...)
+	for _, signature := range signatures {
+		data = append(data, converter.EncodeLengthPlusData(signature)...)
+	}
+	return data
+}
@@ -53,6 +53,7 @@ type BlockData struct {
 	Sign              []byte
 	Hash              []byte
 	RollbacksHash     []byte
+	StateRoot         []byte
 	Version           int
 	PrivateBlockchain bool
 }
@@ -65,6 +66,9 @@ func blockVer(cur, prev *BlockData) (ret string) {
 	if cur.Version >= consts.BvRollbackHash {
 		ret = fmt.Sprintf(",%x", prev.RollbacksHash)
 	}
+	if cur.Version >= consts.BvStateRoot {
+		ret += fmt.Sprintf(",%x", prev.StateRoot)
+	}
 	return
 }
 
@@ -105,6 +109,13 @@ func ParseBlockHeader(buf *bytes.Buffer) (header, prev BlockData, err error) {
 			return
 		}
 	}
+	// for version of block with included the state root
+	if header.Version >= consts.BvStateRoot {
+		prev.StateRoot, err = converter.DecodeBytesBuf(buf)
+		if err != nil {
+			return
+		}
+	}
 
 	if header.BlockID == firstBlock {
 		buf.Next(1)
@@ -286,6 +297,34 @@ func CheckSign(publicKeys [][]byte, forSign []byte, signs []byte, nodeKeyOrLogin
 	return crypto.CheckSign(publicKeys[0], forSign, signsSlice[0])
 }
 
+// CheckMultiSign checks the signatures of multi-signature transaction, signs contains the signatures
+// with their lengths in the same order as the public keys of signers
+func CheckMultiSign(publicKeys [][]byte, forSign []byte, signs []byte) (bool, error) {
+	if len(forSign) == 0 || len(publicKeys) == 0 || len(signs) == 0 {
+		log.WithFields(log.Fields{"type": consts.EmptyObject}).Error("empty multi sign data")
+		return false, ErrInfoFmt("empty multi sign data")
+	}
+	for i, public := range publicKeys {
+		length, err := converter.DecodeLength(&signs)
+		if err != nil {
+			log.WithFields(log.Fields{"type": consts.UnmarshallingError, "error": err}).Error("decoding signs length")
+			return false, err
+		}
+		if length == 0 || int64(len(signs)) < length {
+			log.WithFields(log.Fields{"public_keys_length": len(publicKeys), "signs_length": i, "type": consts.SizeDoesNotMatch}).Error("public keys and signs lengths does not match")
+			return false, fmt.Errorf("sign error publicKeys length %d != signs length %d", len(publicKeys), i)
+		}
+		if ok, err := crypto.CheckSign(public, forSign, converter.BytesShift(&signs, length)); !ok || err != nil {
+			return false, err
+		}
+	}
+	if len(signs) > 0 {
+		log.WithFields(log.Fields{"public_keys_length": len(publicKeys), "type": consts.SizeDoesNotMatch}).Error("there are more signs than public keys")
+		return false, fmt.Errorf("sign error there are more signs than %d public keys", len(publicKeys))
+	}
+	return true, nil
+}
+
 // MerkleTreeRoot rertun Merkle value
 func MerkleTreeRoot(dataArray [][]byte) ([]byte, error) {
 	result := make(map[int32][][]byte)
@@ -385,9 +424,9 @@ func VDEGetNodeKeys() (string, string, error) {
 		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading node private key from file")
 		return "", "", err
 	}
-	key, err := hex.DecodeString(string(nprivkey))
+	key, err := crypto.DecodePrivateKey(nprivkey, conf.Config.Keystore.Passphrase)
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding private key from hex")
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding node private key")
 		return "", "", err
 	}
 	npubkey, err := crypto.PrivateToPublic(key)
@@ -395,7 +434,7 @@ func VDEGetNodeKeys() (string, string, error) {
 		log.WithFields(log.Fields{"type": consts.CryptoError, "error": err}).Error("converting node private key to public")
 		return "", "", err
 	}
-	return string(nprivkey), crypto.PubToHex(npubkey), nil
+	return hex.EncodeToString(key), crypto.PubToHex(npubkey), nil
 }
 
 //
@@ -405,9 +444,9 @@ func GetNodePrivateKey() ([]byte, error) {
 		log.WithFields(log.Fields{"type": consts.IOError, "error": err}).Error("reading node private key from file")
 		return nil, err
 	}
-	privateKey, err := hex.DecodeString(string(data))
+	privateKey, err := crypto.DecodePrivateKey(data, conf.Config.Keystore.Passphrase)
 	if err != nil {
-		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding private key from hex")
+		log.WithFields(log.Fields{"type": consts.ConversionError, "error": err}).Error("decoding node private key")
 		return nil, err
 	}
 	return privateKey, nil
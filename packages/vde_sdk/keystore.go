@@ -0,0 +1,27 @@
+/*---------------------------------------------------------------------------------------------
+ *  Copyright (c) IBAX. All rights reserved.
+ *  See LICENSE in the project root for license information.
+ *--------------------------------------------------------------------------------------------*/
+
+package vde_sdk
+
+import (
+	"github.com/IBAX-io/go-ibax/packages/crypto"
+)
+
+// UnlockKeystore returns the hex private key from the keystore file which is decrypted with the passphrase
+func UnlockKeystore(filename, passphrase string) (string, error) {
+	return crypto.ReadKeystoreFile(filename, passphrase)
+}
+
+// WriteKeystore encrypts the hex private key with the passphrase and writes the keystore file,
+// kdf is crypto.KDFScrypt or crypto.KDFArgon2
+func WriteKeystore(filename, hexKey, passphrase, kdf string) error {
+	return crypto.WriteKeystoreFile(filename, hexKey, passphrase, kdf)
+}
+
+// KeyFromMnemonic returns the hex private key with the index which is derived from the mnemonic,
+// passphrase is the optional BIP39 password
+func KeyFromMnemonic(mnemonic, passphrase string, index uint32) (string, error) {
+	return crypto.HexKeyFromMnemonic(mnemonic, passphrase, index)
+}